@@ -0,0 +1,18 @@
+/*
+ * Copyright (c) 2021 Terminus, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+ALTER TABLE `dice_branch_rules` ADD `min_coverage` double NOT NULL DEFAULT 0 COMMENT 'minimum line coverage percentage required by the coverage check-run';
+ALTER TABLE `dice_branch_rules` ADD `min_diff_coverage` double NOT NULL DEFAULT 0 COMMENT 'minimum line coverage percentage of changed lines required by the coverage check-run';
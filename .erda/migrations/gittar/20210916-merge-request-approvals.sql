@@ -0,0 +1,27 @@
+/*
+ * Copyright (c) 2021 Terminus, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+CREATE TABLE `dice_repo_merge_request_approvals`
+(
+    `id`         bigint(20) NOT NULL AUTO_INCREMENT,
+    `repo_id`    bigint(20) DEFAULT NULL,
+    `merge_id`   bigint(20) DEFAULT NULL,
+    `user_id`    varchar(150) DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    KEY          `idx_repo_id` (`repo_id`),
+    KEY          `idx_merge_id` (`merge_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Gittar 合并请求审批表';
@@ -127,6 +127,32 @@ type CreateAPIAssetVersionBody struct {
 	SpecDiceFileUUID string `json:"specDiceFileUUID"`
 }
 
+// CompareAPIAssetVersionsReq 比较 API 资料两个版本的差异
+type CompareAPIAssetVersionsReq struct {
+	OrgID         uint64
+	Identity      *IdentityInfo
+	AssetID       string
+	VersionID     uint64
+	BaseVersionID uint64 // 为 0 时与 VersionID 之前的最近一个版本比较
+}
+
+// CheckAPIAssetCompatibilityBody 检查文档相对已发布版本的兼容性, 可在发布前由流水线调用
+type CheckAPIAssetCompatibilityBody struct {
+	SpecProtocol     string `json:"specProtocol"`
+	Spec             string `json:"spec"`
+	SpecDiceFileUUID string `json:"specDiceFileUUID"`
+	BaseVersionID    uint64 `json:"baseVersionID"` // 为 0 时与当前最新版本比较
+	// FailOnBreaking 为 true 时存在不兼容变更则返回错误 (InvalidState), 错误上下文中附带比较结果, 供流水线卡点使用
+	FailOnBreaking bool `json:"failOnBreaking"`
+}
+
+type CheckAPIAssetCompatibilityReq struct {
+	OrgID    uint64
+	Identity *IdentityInfo
+	AssetID  string
+	Body     *CheckAPIAssetCompatibilityBody
+}
+
 type PagingAPIAssetVersionsReq struct {
 	OrgID    uint64
 	Identity *IdentityInfo
@@ -89,6 +89,8 @@ var (
 	APIOutParamSourceBodyJsonJsonPath    APIOutParamSource = "body:json:jsonpath"
 	APIOutParamSourceBodyJsonJacksonPath APIOutParamSource = "body:json:jackson"
 	APIOutParamSourceBodyText            APIOutParamSource = "body:text"
+	APIOutParamSourceBodyTextRegex       APIOutParamSource = "body:text:regex"
+	APIOutParamSourceBodyXmlXPath        APIOutParamSource = "body:xml:xpath"
 	APIOutParamSourceHeader              APIOutParamSource = "header"
 )
 
@@ -101,7 +103,7 @@ type APIOutParam struct {
 	Key        string            `json:"key"`
 	Source     APIOutParamSource `json:"source"`
 	Expression string            `json:"expression,omitempty"`
-	MatchIndex string            `json:"matchIndex,omitempty"`
+	MatchIndex string            `json:"matchIndex,omitempty"` // body:text:regex 取值的捕获组, 序号或组名, 默认为第一个捕获组
 }
 
 // APIAssert API测试的断言信息
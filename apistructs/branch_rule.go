@@ -29,7 +29,31 @@ type BranchRule struct {
 	Workspace string `json:"workspace"`
 	// 制品可部署的环境
 	ArtifactWorkspace string `json:"artifactWorkspace"`
+	MergeProtection
 }
+
+// MergeProtection 目标分支的合并请求保护规则
+type MergeProtection struct {
+	// 合并前至少需要的审批人数, 0 表示不限制
+	MinApprovals int `json:"minApprovals"`
+	// 合并前必须审批的用户 ID
+	RequiredApprovers []string `json:"requiredApprovers"`
+	// 合并前必须成功的 check-run 名称
+	RequiredChecks []string `json:"requiredChecks"`
+	// 合并前至少需要一位 CODEOWNERS 中的 owner 审批
+	RequireCodeOwnerApproval bool `json:"requireCodeOwnerApproval"`
+	// 覆盖率 check-run 的总体行覆盖率下限，百分比，0 表示不检查
+	MinCoverage float64 `json:"minCoverage"`
+	// 覆盖率 check-run 的新增代码行覆盖率下限，百分比，0 表示不检查
+	MinDiffCoverage float64 `json:"minDiffCoverage"`
+}
+
+// IsEmpty 是否未配置任何保护规则
+func (p MergeProtection) IsEmpty() bool {
+	return p.MinApprovals <= 0 && len(p.RequiredApprovers) == 0 && len(p.RequiredChecks) == 0 &&
+		!p.RequireCodeOwnerApproval
+}
+
 type QueryBranchRuleRequest struct {
 	ProjectID int64 `query:"projectId"`
 	AppID     int64 `query:"appId"`
@@ -50,6 +74,7 @@ type CreateBranchRuleRequest struct {
 	Workspace         string    `json:"workspace"`
 	ArtifactWorkspace string    `json:"artifactWorkspace"`
 	Desc              string    `json:"desc"`
+	MergeProtection
 }
 
 type CreateBranchRuleResponse struct {
@@ -66,6 +91,7 @@ type UpdateBranchRuleRequest struct {
 	Desc              string `json:"desc"`
 	Workspace         string `json:"workspace"`
 	ArtifactWorkspace string `json:"artifactWorkspace"`
+	MergeProtection
 }
 
 type UpdateBranchRuleResponse struct {
@@ -0,0 +1,29 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+// CoverageReportMetaFileUUID 覆盖率报告 meta 中完整报告在文件服务中的 uuid
+const CoverageReportMetaFileUUID = "reportFileUUID"
+
+// CoverageCheckRequest 根据流水线覆盖率报告检查 MR 覆盖率，结果以 check-run 的形式展示在 MR 上
+// 覆盖率阈值取目标分支规则中的 MinCoverage/MinDiffCoverage
+type CoverageCheckRequest struct {
+	AppID        int64  `json:"appID"`
+	MergeID      int    `json:"mergeId"`
+	SourceSha    string `json:"sourceSha"`
+	TargetSha    string `json:"targetSha"`
+	TargetBranch string `json:"targetBranch"`
+	PipelineID   uint64 `json:"pipelineID"`
+}
@@ -287,6 +287,8 @@ type MergeRequestInfo struct {
 	RebaseBranch         string       `json:"rebaseBranch" default:"-"`
 	EventName            string       `json:"eventName"`
 	CheckRuns            CheckRuns    `json:"checkRuns,omitempty"`
+	ApproverIds          []string     `json:"approverIds"` // 已审批的用户
+	CodeOwners           []string     `json:"codeOwners"`  // 根据 CODEOWNERS 自动分配的评审人
 }
 
 type MergeStatusInfo struct {
@@ -477,6 +479,18 @@ type LockedRepoResponse struct {
 	Data LockedRepoRequest `json:"data"`
 }
 
+// RepoMergeStrategyRequest 仓库合并策略设置请求
+type RepoMergeStrategyRequest struct {
+	// MergeStrategy merge, squash or rebase, empty means any strategy is allowed
+	MergeStrategy string `json:"mergeStrategy"`
+}
+
+// RepoMergeStrategyResponse 仓库合并策略设置响应
+type RepoMergeStrategyResponse struct {
+	Header
+	Data RepoMergeStrategyRequest `json:"data"`
+}
+
 // UpdateRepoResponse 更新repo响应
 type UpdateRepoResponse struct {
 	Header
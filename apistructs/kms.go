@@ -45,6 +45,15 @@ type KMSDecryptResponse struct {
 	Data *kmstypes.DecryptResponse `json:"data,omitempty"`
 }
 
+// seal
+type KMSSealRequest struct {
+	kmstypes.SealRequest
+}
+type KMSSealResponse struct {
+	Header
+	Data *kmstypes.SealResponse `json:"data,omitempty"`
+}
+
 // generate data key
 type KMSGenerateDataKeyRequest struct {
 	kmstypes.GenerateDataKeyRequest
@@ -33,6 +33,9 @@ const (
 	DingdingNotifyTarget           NotifyTargetType = "dingding"
 	DingdingWorkNoticeNotifyTarget NotifyTargetType = "dingding_worknotice"
 	WebhookNotifyTarget            NotifyTargetType = "webhook"
+	FeishuNotifyTarget             NotifyTargetType = "feishu"
+	WeComNotifyTarget              NotifyTargetType = "wecom"
+	SlackNotifyTarget              NotifyTargetType = "slack"
 )
 
 // NotifyTarget 通知目标
@@ -50,10 +53,16 @@ type OldNotifyTarget struct {
 // Target 目标详情
 type Target struct {
 	Receiver string `json:"receiver"`
-	// 目前只有钉钉用
+	// 钉钉、飞书机器人加签使用
 	Secret string `json:"secret"`
 }
 
+// ChatWebhookTarget 聊天工具机器人 webhook 目标
+type ChatWebhookTarget struct {
+	Type NotifyTargetType `json:"type"`
+	Target
+}
+
 func (n *OldNotifyTarget) CovertToNewNotifyTarget() NotifyTarget {
 	var values []Target
 	for _, v := range n.Values {
@@ -113,15 +122,16 @@ type NotifyGroup struct {
 
 // NotifyGroupDetail 通知组详情信息
 type NotifyGroupDetail struct {
-	ID                     int64          `json:"id"`
-	Name                   string         `json:"name"`
-	ScopeType              string         `json:"scopeType"`
-	ScopeID                string         `json:"scopeId"`
-	Users                  []NotifyUser   `json:"users"`
-	Targets                []NotifyTarget `json:"targets"`
-	DingdingList           []Target       `json:"dingdingList"`
-	DingdingWorkNoticeList []Target       `json:"dingdingWorknoticeList"`
-	WebHookList            []string       `json:"webhookList"`
+	ID                     int64               `json:"id"`
+	Name                   string              `json:"name"`
+	ScopeType              string              `json:"scopeType"`
+	ScopeID                string              `json:"scopeId"`
+	Users                  []NotifyUser        `json:"users"`
+	Targets                []NotifyTarget      `json:"targets"`
+	DingdingList           []Target            `json:"dingdingList"`
+	DingdingWorkNoticeList []Target            `json:"dingdingWorknoticeList"`
+	WebHookList            []string            `json:"webhookList"`
+	ChatWebhookList        []ChatWebhookTarget `json:"chatWebhookList"`
 }
 
 // CreateNotifyGroupRequest 创建通知组请求
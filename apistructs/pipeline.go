@@ -585,6 +585,7 @@ type ValidBranch struct {
 	Workspace string `json:"workspace"`
 	// 制品可部署的环境
 	ArtifactWorkspace string `json:"artifactWorkspace"`
+	MergeProtection
 }
 
 func (branch *ValidBranch) GetPermissionResource() string {
@@ -609,6 +610,12 @@ type PipelineCallbackResponse struct {
 	Header
 }
 
+// PipelineWebhookTriggerResponse return pipeline ids started by the webhook
+type PipelineWebhookTriggerResponse struct {
+	Header
+	Data []uint64 `json:"data"`
+}
+
 type PipelineCallbackType string
 
 var (
@@ -133,6 +133,9 @@ type PipelineDetailDTO struct {
 	Events []*PipelineEvent `json:"events,omitempty"`
 
 	NeedApproval bool `json:"needApproval"`
+
+	// WebhookURL 声明 on.webhook 时外部系统触发流水线的地址
+	WebhookURL string `json:"webhookURL,omitempty"`
 }
 
 type PipelineParamDTO struct {
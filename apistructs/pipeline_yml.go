@@ -73,8 +73,9 @@ type NetworkHookInfo struct {
 }
 
 type TriggerConfig struct {
-	Push  *PushTrigger  `yaml:"push,omitempty" json:"push,omitempty"`
-	Merge *MergeTrigger `yaml:"merge,omitempty" json:"merge,omitempty"`
+	Push    *PushTrigger    `yaml:"push,omitempty" json:"push,omitempty"`
+	Merge   *MergeTrigger   `yaml:"merge,omitempty" json:"merge,omitempty"`
+	Webhook *WebhookTrigger `yaml:"webhook,omitempty" json:"webhook,omitempty"`
 }
 
 type PushTrigger struct {
@@ -86,6 +87,11 @@ type MergeTrigger struct {
 	Branches []string `yaml:"branches,omitempty" json:"branches,omitempty"`
 }
 
+type WebhookTrigger struct {
+	Secret string            `yaml:"secret,omitempty" json:"secret,omitempty"`
+	Params map[string]string `yaml:"params,omitempty" json:"params,omitempty"`
+}
+
 type PipelineYmlAction struct {
 	Alias         string                 `json:"alias,omitempty"`                                          // action 实例名
 	Type          string                 `json:"type"`                                                     // action 类型，比如：git-checkout, release
@@ -53,6 +53,29 @@ type SceneSetRequest struct {
 	IdentityInfo
 }
 
+// SceneSetGenerateFromOpenAPIRequest 根据 API 资料版本或上传的 OAS2/OAS3 文档生成场景集
+type SceneSetGenerateFromOpenAPIRequest struct {
+	SpaceID     uint64 `json:"spaceID"`
+	ProjectID   uint64 `json:"projectID"`
+	Name        string `json:"name"` // 场景集名称, 默认为文档标题
+	Description string `json:"description"`
+
+	// 文档来源: API 资料版本, dice 文件或文档内容
+	AssetID          string `json:"assetID"`
+	VersionID        uint64 `json:"versionID"`
+	SpecDiceFileUUID string `json:"specDiceFileUUID"`
+	Spec             string `json:"spec"`
+
+	IdentityInfo
+}
+
+// SceneSetGenerateFromOpenAPIResponse 生成结果
+type SceneSetGenerateFromOpenAPIResponse struct {
+	SetID      uint64 `json:"setID"`
+	SceneCount uint64 `json:"sceneCount"`
+	StepCount  uint64 `json:"stepCount"`
+}
+
 // type SceneSetUpdateRequest struct {
 // 	Name        string `json:"name"`
 // 	Description string `json:"description"`
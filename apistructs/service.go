@@ -257,6 +257,8 @@ type Service struct {
 	Depends []string `json:"depends,omitempty"`
 	// environment variables inject into container
 	Env map[string]string `json:"env"`
+	// environment variables sealed by KMS envelope encryption, inject into container by secret
+	SecretEnvs map[string]string `json:"secretEnvs,omitempty"`
 	// labels for extension and some tags
 	Labels map[string]string `json:"labels"`
 	// deploymentLabels 会转化到 pod spec label 中, dcos 忽略此字段
@@ -294,6 +296,8 @@ type Service struct {
 	ProjectServiceName string `json:"projectServiceName,omitempty"`
 	// K8s Container Snippet
 	K8SSnippet *diceyml.K8SSnippet `json:"k8sSnippet,omitempty"`
+	// Autoscaling horizontal pod autoscaling, Scale is used as the initial replicas when enabled
+	Autoscaling *diceyml.Autoscaling `json:"autoscaling,omitempty"`
 
 	StatusDesc
 }
@@ -346,10 +350,31 @@ type ExecHealthCheck struct {
 	Duration int `json:"duration,omitempty"`
 }
 
-// 暂定支持"HTTP" 和 "COMMAND"两种方式
+type TcpHealthCheck struct {
+	Port int `json:"port,omitempty"`
+	//单位是秒
+	Duration         int `json:"duration,omitempty"`
+	InitialDelay     int `json:"initialDelay,omitempty"`
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+	SuccessThreshold int `json:"successThreshold,omitempty"`
+}
+
+type GrpcHealthCheck struct {
+	Port    int    `json:"port,omitempty"`
+	Service string `json:"service,omitempty"`
+	//单位是秒
+	Duration         int `json:"duration,omitempty"`
+	InitialDelay     int `json:"initialDelay,omitempty"`
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+	SuccessThreshold int `json:"successThreshold,omitempty"`
+}
+
+// 支持 "HTTP", "COMMAND", "TCP" 和 "GRPC" 四种方式
 type NewHealthCheck struct {
 	HttpHealthCheck *HttpHealthCheck `json:"http,omitempty"`
 	ExecHealthCheck *ExecHealthCheck `json:"exec,omitempty"`
+	TcpHealthCheck  *TcpHealthCheck  `json:"tcp,omitempty"`
+	GrpcHealthCheck *GrpcHealthCheck `json:"grpc,omitempty"`
 }
 
 type Volume struct {
@@ -428,6 +453,9 @@ type ServiceGroupCreateV2Request struct {
 	// map[servicename]volumeinfo
 	Volumes          map[string]RequestVolumeInfo `json:"volumes"`
 	ProjectNamespace string                       `json:"projectNamespace"`
+	// dice.yml 中 secret:// 引用解析出的 KMS 信封密文, 只在 k8s executor 中解密
+	// map[servicename]map[envname]sealed
+	SecretEnvs map[string]map[string]string `json:"secretEnvs,omitempty"`
 }
 type RequestVolumeInfo struct {
 	ID            string `json:"id"`
@@ -18,6 +18,7 @@ import (
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/bundle/apierrors"
 	"github.com/erda-project/erda/pkg/http/httputil"
+	"github.com/erda-project/erda/pkg/kms/envelope"
 	"github.com/erda-project/erda/pkg/kms/kmstypes"
 )
 
@@ -92,6 +93,29 @@ func (b *Bundle) KMSDecrypt(req apistructs.KMSDecryptRequest) (*kmstypes.Decrypt
 // 在本地进行数据解密：
 // 1. 调用 KMSDecrypt 解密本地存储的 DEK 密文，获取 DEK 明文
 // 2. 使用 DEK 明文，在本地完成离线数据解密，随后清除内存中的 DEK 明文
+// KMSSeal seals plaintext by KMS envelope encryption, the result can be saved into config-center
+// and referenced by secret://<keyID>/<name> in dice.yml.
+func (b *Bundle) KMSSeal(req apistructs.KMSSealRequest) (*kmstypes.SealResponse, error) {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var sealResp apistructs.KMSSealResponse
+	httpResp, err := hc.Post(host).Path("/api/kms/seal").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&sealResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !sealResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), sealResp.Error)
+	}
+	return sealResp.Data, nil
+}
+
 func (b *Bundle) KMSGenerateDataKey(req apistructs.KMSGenerateDataKeyRequest) (*kmstypes.GenerateDataKeyResponse, error) {
 	host, err := b.urls.KMS()
 	if err != nil {
@@ -154,3 +178,20 @@ func (b *Bundle) KMSDescribeKey(req apistructs.KMSDescribeKeyRequest) (*kmstypes
 	}
 	return descResp.Data, nil
 }
+
+// KMSDataKeyService returns an envelope.DataKeyService backed by KMS, used for envelope.Seal and envelope.Open.
+func (b *Bundle) KMSDataKeyService() envelope.DataKeyService {
+	return kmsDataKeyService{b}
+}
+
+type kmsDataKeyService struct {
+	b *Bundle
+}
+
+func (s kmsDataKeyService) GenerateDataKey(req *kmstypes.GenerateDataKeyRequest) (*kmstypes.GenerateDataKeyResponse, error) {
+	return s.b.KMSGenerateDataKey(apistructs.KMSGenerateDataKeyRequest{GenerateDataKeyRequest: *req})
+}
+
+func (s kmsDataKeyService) Decrypt(req *kmstypes.DecryptRequest) (*kmstypes.DecryptResponse, error) {
+	return s.b.KMSDecrypt(apistructs.KMSDecryptRequest{DecryptRequest: *req})
+}
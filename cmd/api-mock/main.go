@@ -0,0 +1,126 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// api-mock 根据 API 资料版本的文档启动 mock 服务, 可以作为流水线中的服务运行.
+//
+// 文档来源:
+//
+//	MOCK_SPEC_FILE                       本地文档文件, OAS2/OAS3 json 或 yaml
+//	MOCK_ASSET_ID, MOCK_ASSET_VERSION_ID 从 dop 导出 API 资料版本的文档, 需要 DICE_OPENAPI_ADDR, DICE_OPENAPI_TOKEN, DICE_ORG_ID
+//
+// 其他配置:
+//
+//	MOCK_LISTEN    监听地址, 默认 :8080
+//	MOCK_BASE_PATH 路由前去掉的路径前缀, 默认为文档中第一个 server 的路径
+//	MOCK_VALIDATE  是否校验请求, 默认 true
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	_ "github.com/erda-project/erda-infra/base/version"
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+	"github.com/erda-project/erda/pkg/swagger"
+	"github.com/erda-project/erda/pkg/swagger/mock"
+)
+
+const (
+	envSpecFile       = "MOCK_SPEC_FILE"
+	envAssetID        = "MOCK_ASSET_ID"
+	envAssetVersionID = "MOCK_ASSET_VERSION_ID"
+	envListen         = "MOCK_LISTEN"
+	envBasePath       = "MOCK_BASE_PATH"
+	envValidate       = "MOCK_VALIDATE"
+
+	envOpenapiAddr = "DICE_OPENAPI_ADDR"
+	envOrgID       = "DICE_ORG_ID"
+)
+
+func main() {
+	spec, err := loadSpec()
+	if err != nil {
+		logrus.Fatalf("failed to load spec: %v", err)
+	}
+	oas3, err := swagger.LoadFromData(spec)
+	if err != nil {
+		logrus.Fatalf("failed to parse spec: %v", err)
+	}
+
+	var options []mock.Option
+	if basePath, ok := os.LookupEnv(envBasePath); ok {
+		options = append(options, mock.WithBasePath(basePath))
+	}
+	if v := os.Getenv(envValidate); v != "" {
+		validate, err := strconv.ParseBool(v)
+		if err != nil {
+			logrus.Fatalf("invalid %s: %s", envValidate, v)
+		}
+		options = append(options, mock.WithValidation(validate))
+	}
+	server, err := mock.New(oas3, options...)
+	if err != nil {
+		logrus.Fatalf("failed to create mock server: %v", err)
+	}
+
+	listen := os.Getenv(envListen)
+	if listen == "" {
+		listen = ":8080"
+	}
+	logrus.Infof("api mock server listening on %s", listen)
+	if err := http.ListenAndServe(listen, server); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+func loadSpec() ([]byte, error) {
+	if filename := os.Getenv(envSpecFile); filename != "" {
+		return ioutil.ReadFile(filename)
+	}
+
+	assetID := os.Getenv(envAssetID)
+	versionID := os.Getenv(envAssetVersionID)
+	if assetID == "" || versionID == "" {
+		return nil, errors.Errorf("either %s or %s and %s should be specified", envSpecFile, envAssetID, envAssetVersionID)
+	}
+	openapiAddr := os.Getenv(envOpenapiAddr)
+	if openapiAddr == "" {
+		return nil, errors.Errorf("missing env %s", envOpenapiAddr)
+	}
+
+	var body bytes.Buffer
+	r, err := httpclient.New(httpclient.WithCompleteRedirect()).
+		Get(openapiAddr).
+		Path(fmt.Sprintf("/api/api-assets/%s/versions/%s/export", assetID, versionID)).
+		Param("specProtocol", string(apistructs.APISpecProtocolOAS3Json)).
+		Header("Authorization", os.Getenv(apistructs.EnvOpenapiToken)).
+		Header("Org-ID", os.Getenv(envOrgID)).
+		Do().
+		Body(&body)
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsOK() {
+		return nil, errors.Errorf("failed to export spec, status-code: %d, resp body: %s", r.StatusCode(), body.String())
+	}
+	return body.Bytes(), nil
+}
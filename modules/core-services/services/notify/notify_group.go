@@ -151,6 +151,10 @@ func (o *NotifyGroup) GetDetail(id int64, orgID int64) (*apistructs.NotifyGroupD
 			for _, webhookUrl := range target.Values {
 				result.WebHookList = append(result.WebHookList, webhookUrl.Receiver)
 			}
+		case apistructs.FeishuNotifyTarget, apistructs.WeComNotifyTarget, apistructs.SlackNotifyTarget:
+			for _, chatWebhook := range target.Values {
+				result.ChatWebhookList = append(result.ChatWebhookList, apistructs.ChatWebhookTarget{Type: target.Type, Target: chatWebhook})
+			}
 		case apistructs.RoleNotifyTarget:
 			scopeID, err := strconv.ParseInt(group.ScopeID, 10, 32)
 			if err != nil {
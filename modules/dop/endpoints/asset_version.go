@@ -22,12 +22,15 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/modules/pkg/user"
 	"github.com/erda-project/erda/pkg/http/httpserver"
 	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
 	"github.com/erda-project/erda/pkg/strutil"
+	"github.com/erda-project/erda/pkg/swagger/oas3"
 )
 
 // CreateAPIVersion 创建 API 资料版本
@@ -69,7 +72,86 @@ func (e *Endpoints) CreateAPIVersion(ctx context.Context, r *http.Request, vars
 	userIDs := strutil.DedupSlice([]string{asset.CreatorID, asset.UpdaterID, version.CreatorID, version.UpdaterID,
 		spec.CreatorID, spec.UpdaterID})
 
-	return httpserver.OkResp(version, userIDs)
+	// 附带与上一个版本的兼容性比较结果, 比较失败不影响版本创建
+	compatibility, apiError := e.assetSvc.CompareAPIAssetVersions(&apistructs.CompareAPIAssetVersionsReq{
+		OrgID:     orgID,
+		Identity:  &identity,
+		AssetID:   version.AssetID,
+		VersionID: version.ID,
+	})
+	if apiError != nil {
+		logrus.Errorf("failed to CompareAPIAssetVersions, assetID: %s, versionID: %d, err: %v", version.AssetID, version.ID, apiError)
+	}
+
+	return httpserver.OkResp(struct {
+		*apistructs.APIAssetVersionsModel
+		Compatibility *oas3.DiffResult `json:"compatibility,omitempty"`
+	}{version, compatibility}, userIDs)
+}
+
+// CompareAPIAssetVersions 比较 API 资料版本与基准版本的差异
+func (e *Endpoints) CompareAPIAssetVersions(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identity, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.CompareAPIAssetVersions.NotLogin().ToResp(), nil
+	}
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apierrors.CompareAPIAssetVersions.MissingParameter(apierrors.MissingOrgID).ToResp(), nil
+	}
+
+	versionID, err := strconv.ParseUint(vars[urlPathVersionID], 10, 64)
+	if err != nil {
+		return apierrors.CompareAPIAssetVersions.InvalidParameter(err).ToResp(), nil
+	}
+	var baseVersionID uint64
+	if s := r.URL.Query().Get("baseVersionID"); s != "" {
+		if baseVersionID, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return apierrors.CompareAPIAssetVersions.InvalidParameter(fmt.Errorf("baseVersionID: %v", err)).ToResp(), nil
+		}
+	}
+
+	result, apiError := e.assetSvc.CompareAPIAssetVersions(&apistructs.CompareAPIAssetVersionsReq{
+		OrgID:         orgID,
+		Identity:      &identity,
+		AssetID:       vars[urlPathAssetID],
+		VersionID:     versionID,
+		BaseVersionID: baseVersionID,
+	})
+	if apiError != nil {
+		return apiError.ToResp(), nil
+	}
+
+	return httpserver.OkResp(result)
+}
+
+// CheckAPIAssetCompatibility 检查文档相对已发布版本的兼容性, 供流水线 action 在发布前调用
+func (e *Endpoints) CheckAPIAssetCompatibility(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identity, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.CheckAPICompatibility.NotLogin().ToResp(), nil
+	}
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apierrors.CheckAPICompatibility.MissingParameter(apierrors.MissingOrgID).ToResp(), nil
+	}
+
+	var body apistructs.CheckAPIAssetCompatibilityBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return apierrors.CheckAPICompatibility.InvalidParameter(err).ToResp(), nil
+	}
+
+	result, apiError := e.assetSvc.CheckAPIAssetCompatibility(&apistructs.CheckAPIAssetCompatibilityReq{
+		OrgID:    orgID,
+		Identity: &identity,
+		AssetID:  vars[urlPathAssetID],
+		Body:     &body,
+	})
+	if apiError != nil {
+		return apiError.ToResp(), nil
+	}
+
+	return httpserver.OkResp(result)
 }
 
 // PagingAPIAssetVersions 查询 API 资料版本列表
@@ -0,0 +1,136 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/dop/services/cq"
+	"github.com/erda-project/erda/modules/pkg/diceworkspace"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// getMRDiffCoverage 查询 MR 新增代码的覆盖率
+func (e *Endpoints) getMRDiffCoverage(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetCoverage.NotLogin().ToResp(), nil
+	}
+
+	query := r.URL.Query()
+	appID, err := strconv.ParseInt(query.Get("appID"), 10, 64)
+	if err != nil {
+		return apierrors.ErrGetCoverage.InvalidParameter(fmt.Errorf("invalid appID: %v", err)).ToResp(), nil
+	}
+	pipelineID, err := strconv.ParseUint(query.Get("pipelineID"), 10, 64)
+	if err != nil {
+		return apierrors.ErrGetCoverage.InvalidParameter(fmt.Errorf("invalid pipelineID: %v", err)).ToResp(), nil
+	}
+	mr := apistructs.MergeRequestInfo{
+		AppID:     appID,
+		SourceSha: query.Get("sourceSha"),
+		TargetSha: query.Get("targetSha"),
+	}
+	if mr.SourceSha == "" {
+		return apierrors.ErrGetCoverage.MissingParameter("sourceSha").ToResp(), nil
+	}
+	if mr.TargetSha == "" {
+		return apierrors.ErrGetCoverage.MissingParameter("targetSha").ToResp(), nil
+	}
+
+	if err := e.permission.CheckAppAction(identityInfo, uint64(appID), apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	report, err := e.cq.GetPipelineCoverage(appID, pipelineID)
+	if err != nil {
+		return apierrors.ErrGetCoverage.InternalError(err).ToResp(), nil
+	}
+	if report == nil {
+		return apierrors.ErrGetCoverage.NotFound().ToResp(), nil
+	}
+	diff, err := e.cq.GetMRDiffCoverage(mr, report, identityInfo.UserID)
+	if err != nil {
+		return apierrors.ErrGetCoverage.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(diff)
+}
+
+// checkMRCoverage 检查 MR 覆盖率，低于阈值时 check-run 失败
+func (e *Endpoints) checkMRCoverage(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrCheckCoverage.NotLogin().ToResp(), nil
+	}
+	if r.Body == nil {
+		return apierrors.ErrCheckCoverage.MissingParameter("body").ToResp(), nil
+	}
+	var req apistructs.CoverageCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCheckCoverage.InvalidParameter(err).ToResp(), nil
+	}
+	if req.AppID == 0 {
+		return apierrors.ErrCheckCoverage.MissingParameter("appID").ToResp(), nil
+	}
+	if req.PipelineID == 0 {
+		return apierrors.ErrCheckCoverage.MissingParameter("pipelineID").ToResp(), nil
+	}
+	if req.SourceSha == "" || req.TargetSha == "" {
+		return apierrors.ErrCheckCoverage.MissingParameter("sourceSha/targetSha").ToResp(), nil
+	}
+	if req.TargetBranch == "" {
+		return apierrors.ErrCheckCoverage.MissingParameter("targetBranch").ToResp(), nil
+	}
+
+	// 阈值来自分支规则，check-run 只允许由流水线等内部服务上报
+	if !identityInfo.IsInternalClient() {
+		return apierrors.ErrCheckCoverage.AccessDenied().ToResp(), nil
+	}
+
+	rules, err := e.branchRule.Query(apistructs.AppScope, req.AppID)
+	if err != nil {
+		return apierrors.ErrCheckCoverage.InternalError(err).ToResp(), nil
+	}
+	protection := diceworkspace.GetValidBranchByGitReference(req.TargetBranch, rules).MergeProtection
+
+	result, err := e.cq.CheckCoverage(cq.CoverageCheckRequest{
+		MR: apistructs.MergeRequestInfo{
+			AppID:        req.AppID,
+			RepoMergeId:  req.MergeID,
+			SourceSha:    req.SourceSha,
+			TargetSha:    req.TargetSha,
+			TargetBranch: req.TargetBranch,
+		},
+		PipelineID:      req.PipelineID,
+		MinCoverage:     protection.MinCoverage,
+		MinDiffCoverage: protection.MinDiffCoverage,
+	}, identityInfo.UserID)
+	if err != nil {
+		return apierrors.ErrCheckCoverage.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(result)
+}
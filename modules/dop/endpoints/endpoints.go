@@ -126,6 +126,8 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/api-assets/{assetID}/versions/{versionID}", Method: http.MethodPut, Handler: e.UpdateAssetVersion},
 		{Path: "/api/api-assets/{assetID}/versions/{versionID}", Method: http.MethodDelete, Handler: e.DeleteAPIAssetVersion},
 		{Path: "/api/api-assets/{assetID}/versions/{versionID}/export", Method: http.MethodGet, WriterHandler: e.DownloadSpecText},
+		{Path: "/api/api-assets/{assetID}/versions/{versionID}/compatibility", Method: http.MethodGet, Handler: e.CompareAPIAssetVersions},
+		{Path: "/api/api-assets/{assetID}/actions/check-compatibility", Method: http.MethodPost, Handler: e.CheckAPIAssetCompatibility},
 
 		{Path: "/api/api-assets/{assetID}/swagger-versions", Method: http.MethodGet, Handler: e.ListSwaggerVersions},
 
@@ -204,6 +206,8 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/cicds/actions/app-invoked-combos", Method: http.MethodGet, Handler: e.pipelineAppInvokedCombos},
 		{Path: "/api/cicds/actions/fetch-pipeline-id", Method: http.MethodGet, Handler: e.fetchPipelineByAppInfo},
 		{Path: "/api/cicds/actions/app-all-valid-branch-workspaces", Method: http.MethodGet, Handler: e.branchWorkspaceMap},
+		{Path: "/api/cicds/actions/diff-coverage", Method: http.MethodGet, Handler: e.getMRDiffCoverage},
+		{Path: "/api/cicds/actions/check-coverage", Method: http.MethodPost, Handler: e.checkMRCoverage},
 		{Path: "/api/cicds/{pipelineID}/actions/run", Method: http.MethodPost, Handler: e.pipelineRun},
 		{Path: "/api/cicds/{pipelineID}/actions/cancel", Method: http.MethodPost, Handler: e.pipelineCancel},
 		{Path: "/api/cicds/{pipelineID}/actions/rerun", Method: http.MethodPost, Handler: e.pipelineRerun},
@@ -427,6 +431,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/autotests/scenesets/{setID}", Method: http.MethodDelete, Handler: e.DeleteSceneSet},
 		{Path: "/api/autotests/scenesets/actions/drag", Method: http.MethodPut, Handler: e.DragSceneSet},
 		{Path: "/api/autotests/scenesets/actions/copy", Method: http.MethodPost, Handler: e.CopySceneSet},
+		{Path: "/api/autotests/scenesets/actions/generate-from-openapi", Method: http.MethodPost, Handler: e.GenerateSceneSetFromOpenAPI},
 
 		// migrate
 		{Path: "/api/autotests/actions/migrate-from-autotestv1", Method: http.MethodGet, Handler: e.MigrateFromAutoTestV1},
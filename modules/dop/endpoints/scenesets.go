@@ -231,3 +231,64 @@ func (e *Endpoints) CopySceneSet(ctx context.Context, r *http.Request, vars map[
 	}
 	return httpserver.OkResp(setId)
 }
+
+// GenerateSceneSetFromOpenAPI 根据 API 资料版本或上传的文档生成场景集
+func (e *Endpoints) GenerateSceneSetFromOpenAPI(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGenerateAutoTestSceneSet.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.SceneSetGenerateFromOpenAPIRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrGenerateAutoTestSceneSet.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+	if !identityInfo.IsInternalClient() {
+		// Authorize
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   identityInfo.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  req.ProjectID,
+			Resource: apistructs.SceneSetResource,
+			Action:   apistructs.CreateAction,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !access.Access {
+			return nil, apierrors.ErrGenerateAutoTestSceneSet.AccessDenied()
+		}
+	}
+
+	// 从 API 资料版本导出 OAS3 文档
+	if req.AssetID != "" {
+		orgID, err := user.GetOrgID(r)
+		if err != nil {
+			return apierrors.ErrGenerateAutoTestSceneSet.MissingParameter(apierrors.MissingOrgID).ToResp(), nil
+		}
+		if req.VersionID == 0 {
+			return apierrors.ErrGenerateAutoTestSceneSet.MissingParameter("versionID").ToResp(), nil
+		}
+		spec, apiError := e.assetSvc.DownloadSpecText(&apistructs.DownloadSpecTextReq{
+			OrgID:    orgID,
+			Identity: &identityInfo,
+			URIParams: &apistructs.DownloadSpecTextURIParams{
+				AssetID:   req.AssetID,
+				VersionID: req.VersionID,
+			},
+			QueryParams: &apistructs.DownloadSpecTextQueryParams{SpecProtocol: string(apistructs.APISpecProtocolOAS3Json)},
+		})
+		if apiError != nil {
+			return apiError.ToResp(), nil
+		}
+		req.Spec = string(spec)
+		req.SpecDiceFileUUID = ""
+	}
+
+	res, err := e.autotestV2.GenerateSceneSetFromOpenAPI(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(res)
+}
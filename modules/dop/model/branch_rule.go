@@ -15,8 +15,11 @@
 package model
 
 import (
+	"strings"
+
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/pkg/database/dbengine"
+	"github.com/erda-project/erda/pkg/strutil"
 )
 
 type BranchRule struct {
@@ -31,6 +34,13 @@ type BranchRule struct {
 	Desc              string //规则说明
 	Workspace         string `json:"workspace"`
 	ArtifactWorkspace string `json:"artifactWorkspace"`
+	MinApprovals      int    // 合并前至少需要的审批人数
+	RequiredApprovers string // 合并前必须审批的用户 ID, 逗号分隔
+	RequiredChecks    string // 合并前必须成功的 check-run 名称, 逗号分隔
+	// 合并前至少需要一位 CODEOWNERS 中的 owner 审批
+	RequireCodeOwnerApproval bool
+	MinCoverage              float64 // 总体行覆盖率下限
+	MinDiffCoverage          float64 // 新增代码行覆盖率下限
 }
 
 // TableName 设置模型对应数据库表名称
@@ -50,5 +60,24 @@ func (rule *BranchRule) ToApiData() *apistructs.BranchRule {
 		Desc:              rule.Desc,
 		Workspace:         rule.Workspace,
 		ArtifactWorkspace: rule.ArtifactWorkspace,
+		MergeProtection: apistructs.MergeProtection{
+			MinApprovals:      rule.MinApprovals,
+			RequiredApprovers: strutil.Split(rule.RequiredApprovers, ",", true),
+			RequiredChecks:    strutil.Split(rule.RequiredChecks, ",", true),
+
+			RequireCodeOwnerApproval: rule.RequireCodeOwnerApproval,
+			MinCoverage:              rule.MinCoverage,
+			MinDiffCoverage:          rule.MinDiffCoverage,
+		},
 	}
 }
+
+// SetMergeProtection 设置合并请求保护规则
+func (rule *BranchRule) SetMergeProtection(p apistructs.MergeProtection) {
+	rule.MinApprovals = p.MinApprovals
+	rule.RequiredApprovers = strings.Join(strutil.DedupSlice(p.RequiredApprovers, true), ",")
+	rule.RequiredChecks = strings.Join(strutil.DedupSlice(p.RequiredChecks, true), ",")
+	rule.RequireCodeOwnerApproval = p.RequireCodeOwnerApproval
+	rule.MinCoverage = p.MinCoverage
+	rule.MinDiffCoverage = p.MinDiffCoverage
+}
@@ -38,6 +38,9 @@ var (
 	UpdateAssetVersion     = err("ErrUpdateAssetVersion", "修改 API 资料版本失败")
 	DeleteAPIAssetVersion  = err("ErrDeleteAPIAssetVersion", "删除 API 资料详情失败")
 
+	CompareAPIAssetVersions = err("ErrCompareAPIAssetVersions", "比较 API 资料版本失败")
+	CheckAPICompatibility   = err("ErrCheckAPICompatibility", "检查 API 兼容性失败")
+
 	ValidateAPISpec        = err("ErrValidateAPISpec", "校验 API Spec 失败")
 	GetAPIAssetVersionSpec = err("GetAPIAssetVersionSpec", "查询 API 资料版本 Spec 失败")
 
@@ -131,6 +134,8 @@ var (
 	ErrRerunFailedPipeline    = err("ErrRerunFailedPipeline", "重试失败节点失败")
 	ErrRerunPipeline          = err("ErrRerunPipeline", "重试全流程失败")
 	ErrCreateCheckRun         = err("ErrCreateCheckRun", "创建流水线失败")
+	ErrGetCoverage            = err("ErrGetCoverage", "获取覆盖率失败")
+	ErrCheckCoverage          = err("ErrCheckCoverage", "检查覆盖率失败")
 
 	ErrUpdatePipelineDefinition = err("ErrUpdatePipelineDefinition", "修改流水线定义失败")
 
@@ -328,6 +333,8 @@ var (
 	ErrListAutoTestSceneSet   = err("ErrListAutoTestSceneSet", "获取自动化测试场景集列表失败")
 	ErrDragAutoTestSceneSet   = err("ErrDragAutoTestSceneSet", "拖动自动化测试场景集失败")
 
+	ErrGenerateAutoTestSceneSet = err("ErrGenerateAutoTestSceneSet", "根据 API 文档生成自动化测试场景集失败")
+
 	ErrCreateTicket = err("ErrCreateTicket", "创建工单失败")
 	ErrUpdateTicket = err("ErrUpdateTicket", "更新工单失败")
 	ErrDeleteTicket = err("ErrDeleteTicket", "删除工单失败")
@@ -0,0 +1,170 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assetsvc
+
+import (
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/swagger/oas3"
+)
+
+// CompareAPIAssetVersions 比较 API 资料两个版本的文档, 将变更区分为兼容和不兼容.
+// 未指定基准版本时与之前的最近一个版本比较, 没有之前的版本时返回空结果.
+func (svc *Service) CompareAPIAssetVersions(req *apistructs.CompareAPIAssetVersionsReq) (*oas3.DiffResult, *errorresp.APIError) {
+	if req.OrgID == 0 {
+		return nil, apierrors.CompareAPIAssetVersions.MissingParameter(apierrors.MissingOrgID)
+	}
+	if err := apistructs.ValidateAPIAssetID(req.AssetID); err != nil {
+		return nil, apierrors.CompareAPIAssetVersions.InvalidParameter(fmt.Errorf("assetID: %v", err))
+	}
+	if req.VersionID == 0 {
+		return nil, apierrors.CompareAPIAssetVersions.MissingParameter("versionID")
+	}
+
+	baseVersionID := req.BaseVersionID
+	if baseVersionID == 0 {
+		previous, err := previousVersion(req.OrgID, req.AssetID, req.VersionID)
+		if err != nil {
+			return nil, apierrors.CompareAPIAssetVersions.InternalError(err)
+		}
+		if previous == nil {
+			return &oas3.DiffResult{Changes: []*oas3.Change{}}, nil
+		}
+		baseVersionID = previous.ID
+	}
+
+	base, apiError := svc.loadVersionSwagger(req.OrgID, req.AssetID, baseVersionID)
+	if apiError != nil {
+		return nil, apiError
+	}
+	revision, apiError := svc.loadVersionSwagger(req.OrgID, req.AssetID, req.VersionID)
+	if apiError != nil {
+		return nil, apiError
+	}
+
+	return oas3.Diff(base, revision), nil
+}
+
+// CheckAPIAssetCompatibility 检查文档相对已发布版本的兼容性, 用于发布新版本前的流水线卡点.
+// 未指定基准版本时与 API 资料的当前版本比较, 资料还没有版本时返回空结果.
+// 开启 failOnBreaking 且存在不兼容变更时返回比较结果和错误, 流水线 action 可据此失败.
+func (svc *Service) CheckAPIAssetCompatibility(req *apistructs.CheckAPIAssetCompatibilityReq) (*oas3.DiffResult, *errorresp.APIError) {
+	if req.OrgID == 0 {
+		return nil, apierrors.CheckAPICompatibility.MissingParameter(apierrors.MissingOrgID)
+	}
+	if err := apistructs.ValidateAPIAssetID(req.AssetID); err != nil {
+		return nil, apierrors.CheckAPICompatibility.InvalidParameter(fmt.Errorf("assetID: %v", err))
+	}
+	if req.Body == nil {
+		return nil, apierrors.CheckAPICompatibility.MissingParameter("body")
+	}
+
+	// 复用创建版本时读取文档的逻辑
+	specReq := apistructs.APIAssetVersionCreateRequest{
+		OrgID:            req.OrgID,
+		APIAssetID:       req.AssetID,
+		SpecProtocol:     apistructs.APISpecProtocol(req.Body.SpecProtocol),
+		SpecDiceFileUUID: req.Body.SpecDiceFileUUID,
+		Spec:             req.Body.Spec,
+	}
+	if req.Identity != nil {
+		specReq.IdentityInfo = *req.Identity
+	}
+	if err := svc.readSpec(&specReq); err != nil {
+		return nil, apierrors.CheckAPICompatibility.InvalidParameter(err)
+	}
+	revision, err := parseSpec(&specReq.SpecProtocol, specReq.Spec)
+	if err != nil {
+		return nil, apierrors.CheckAPICompatibility.InvalidParameter(errors.Wrap(err, "swagger 文件不符合 OAS2/3 标准"))
+	}
+
+	baseVersionID := req.Body.BaseVersionID
+	if baseVersionID == 0 {
+		var asset apistructs.APIAssetsModel
+		if err := svc.FirstRecord(&asset, map[string]interface{}{
+			"org_id":   req.OrgID,
+			"asset_id": req.AssetID,
+		}); err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return nil, apierrors.CheckAPICompatibility.NotFound()
+			}
+			return nil, apierrors.CheckAPICompatibility.InternalError(err)
+		}
+		if asset.CurVersionID == 0 {
+			return &oas3.DiffResult{Changes: []*oas3.Change{}}, nil
+		}
+		baseVersionID = asset.CurVersionID
+	}
+
+	base, apiError := svc.loadVersionSwagger(req.OrgID, req.AssetID, baseVersionID)
+	if apiError != nil {
+		return nil, apiError
+	}
+
+	result := oas3.Diff(base, revision)
+	if req.Body.FailOnBreaking && result.HasBreaking() {
+		return result, apierrors.CheckAPICompatibility.InvalidState(
+			fmt.Sprintf("存在 %d 处不兼容变更", result.Breaking)).SetCtx(result)
+	}
+	return result, nil
+}
+
+// loadVersionSwagger 查询版本的文档并转换为 OAS3
+func (svc *Service) loadVersionSwagger(orgID uint64, assetID string, versionID uint64) (*openapi3.Swagger, *errorresp.APIError) {
+	spec, err := dbclient.GetAPIAssetVersionSpec(&apistructs.GetAPIAssetVersionReq{
+		OrgID: orgID,
+		URIParams: &apistructs.AssetVersionDetailURI{
+			AssetID:   assetID,
+			VersionID: versionID,
+		},
+	})
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.GetAPIAssetVersionSpec.NotFound()
+		}
+		return nil, apierrors.GetAPIAssetVersionSpec.InternalError(err)
+	}
+	protocol := apistructs.APISpecProtocol(spec.SpecProtocol)
+	swagger, err := parseSpec(&protocol, spec.Spec)
+	if err != nil {
+		logrus.Errorf("failed to parseSpec, assetID: %s, versionID: %d, err: %v", assetID, versionID, err)
+		return nil, apierrors.GetAPIAssetVersionSpec.InternalError(err)
+	}
+	return swagger, nil
+}
+
+// previousVersion 查询 versionID 之前创建的最近一个版本, 没有时返回 nil
+func previousVersion(orgID uint64, assetID string, versionID uint64) (*apistructs.APIAssetVersionsModel, error) {
+	var version apistructs.APIAssetVersionsModel
+	if err := dbclient.Sq().Where(map[string]interface{}{
+		"org_id":   orgID,
+		"asset_id": assetID,
+	}).Where("id < ?", versionID).Order("id DESC").First(&version).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &version, nil
+}
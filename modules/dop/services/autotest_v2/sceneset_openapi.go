@@ -0,0 +1,534 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/http/httputil"
+	"github.com/erda-project/erda/pkg/swagger"
+	"github.com/erda-project/erda/pkg/swagger/oas3"
+)
+
+const (
+	// openAPISceneMaxSteps 与 CreateAutoTestSceneStep 中一个场景下的步骤上限一致, 超过时拆分为多个场景
+	openAPISceneMaxSteps = 100
+	// openAPIDefaultScene 没有 tag 的接口归入的场景
+	openAPIDefaultScene = "default"
+
+	openAPIOutParamStatus = "status"
+	openAPIOutParamBody   = "body"
+)
+
+var (
+	openAPIMethods = []string{
+		http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodHead, http.MethodOptions, http.MethodTrace,
+	}
+	openAPINameReg = regexp.MustCompile("[^a-zA-Z\u4e00-\u9fa50-9_-]+")
+)
+
+// openAPIScene 由同一个 tag 下的接口生成的场景
+type openAPIScene struct {
+	Name        string
+	Description string
+	Steps       []openAPIStep
+}
+
+// openAPIStep 由一个 operation 生成的接口步骤
+type openAPIStep struct {
+	Name    string
+	APIInfo apistructs.APIInfoV2
+}
+
+// openAPIStepValue 接口步骤 value 的结构, 与前端 apiEditor 组件一致
+type openAPIStepValue struct {
+	APIInfo apistructs.APIInfoV2 `json:"apiSpec"`
+}
+
+// GenerateSceneSetFromOpenAPI 根据 OAS2/OAS3 文档生成场景集.
+// 每个 tag 生成一个场景, 每个 operation 生成一个接口步骤, 请求参数和请求体根据文档生成示例,
+// 并根据声明的成功响应生成状态码和响应体 json_schema 断言.
+func (svc *Service) GenerateSceneSetFromOpenAPI(req apistructs.SceneSetGenerateFromOpenAPIRequest) (*apistructs.SceneSetGenerateFromOpenAPIResponse, error) {
+	if req.SpaceID == 0 {
+		return nil, apierrors.ErrGenerateAutoTestSceneSet.MissingParameter("spaceID")
+	}
+
+	spec := req.Spec
+	if req.SpecDiceFileUUID != "" {
+		fr, err := svc.bdl.DownloadDiceFile(req.SpecDiceFileUUID)
+		if err != nil {
+			return nil, apierrors.ErrGenerateAutoTestSceneSet.InvalidParameter(fmt.Errorf("failed to get spec from file: %v", err))
+		}
+		defer fr.Close()
+		data, err := ioutil.ReadAll(fr)
+		if err != nil {
+			return nil, apierrors.ErrGenerateAutoTestSceneSet.InvalidParameter(fmt.Errorf("failed to read from file: %v", err))
+		}
+		spec = string(data)
+	}
+	if spec == "" {
+		return nil, apierrors.ErrGenerateAutoTestSceneSet.MissingParameter("spec")
+	}
+
+	v3, err := swagger.LoadFromData([]byte(spec))
+	if err != nil {
+		return nil, apierrors.ErrGenerateAutoTestSceneSet.InvalidParameter(errors.Wrap(err, "文档不符合 OAS2/3 标准"))
+	}
+	scenes := genOpenAPIScenes(v3)
+	if len(scenes) == 0 {
+		return nil, apierrors.ErrGenerateAutoTestSceneSet.InvalidParameter("文档中没有接口")
+	}
+
+	name := req.Name
+	if name == "" && v3.Info != nil {
+		name = v3.Info.Title
+	}
+	if name == "" {
+		name = "OpenAPI"
+	}
+	description := req.Description
+	if description == "" && v3.Info != nil {
+		description = v3.Info.Description
+	}
+
+	setID, err := svc.CreateSceneSet(apistructs.SceneSetRequest{
+		Name:         name,
+		SpaceID:      req.SpaceID,
+		Description:  description,
+		ProjectId:    req.ProjectID,
+		IdentityInfo: req.IdentityInfo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := apistructs.SceneSetGenerateFromOpenAPIResponse{SetID: setID}
+	sceneIDs, err := svc.createOpenAPIScenes(req, setID, scenes, &result)
+	if err != nil {
+		// 生成失败时删除已创建的场景集, 场景和步骤, 避免留下不完整的场景集
+		if cleanErr := svc.deleteOpenAPISceneSet(setID, sceneIDs); cleanErr != nil {
+			logrus.Errorf("failed to delete scene set generated from openapi, setID: %d, err: %v", setID, cleanErr)
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// createOpenAPIScenes 在场景集下创建场景和接口步骤, 返回已创建的场景 id, 出错时也返回已创建的部分
+func (svc *Service) createOpenAPIScenes(req apistructs.SceneSetGenerateFromOpenAPIRequest, setID uint64,
+	scenes []openAPIScene, result *apistructs.SceneSetGenerateFromOpenAPIResponse) ([]uint64, error) {
+	var sceneIDs []uint64
+	for _, scene := range scenes {
+		sceneID, err := svc.CreateAutotestScene(apistructs.AutotestSceneRequest{
+			AutoTestSceneParams: apistructs.AutoTestSceneParams{SpaceID: req.SpaceID},
+			Name:                scene.Name,
+			Description:         scene.Description,
+			SetID:               setID,
+			IdentityInfo:        req.IdentityInfo,
+		})
+		if err != nil {
+			return sceneIDs, err
+		}
+		sceneIDs = append(sceneIDs, sceneID)
+		result.SceneCount++
+
+		for _, step := range scene.Steps {
+			value, err := json.Marshal(openAPIStepValue{APIInfo: step.APIInfo})
+			if err != nil {
+				return sceneIDs, apierrors.ErrGenerateAutoTestSceneSet.InternalError(err)
+			}
+			if _, err := svc.CreateAutoTestSceneStep(apistructs.AutotestSceneRequest{
+				AutoTestSceneParams: apistructs.AutoTestSceneParams{SpaceID: req.SpaceID},
+				Name:                step.Name,
+				Value:               string(value),
+				SceneID:             sceneID,
+				Type:                apistructs.StepTypeAPI,
+				Target:              -1,
+				GroupID:             -1,
+				IdentityInfo:        req.IdentityInfo,
+			}); err != nil {
+				return sceneIDs, err
+			}
+			result.StepCount++
+		}
+	}
+	return sceneIDs, nil
+}
+
+// deleteOpenAPISceneSet 删除场景集及其下的场景, 场景的出入参和步骤
+func (svc *Service) deleteOpenAPISceneSet(setID uint64, sceneIDs []uint64) error {
+	set, err := svc.db.GetSceneSet(setID)
+	if err != nil {
+		return err
+	}
+	return svc.db.DeleteSceneSet(set, sceneIDs)
+}
+
+// genOpenAPIScenes 按 tag 将接口分组为场景, 场景按文档中 tag 的声明顺序排列, 接口按路径和方法排列
+func genOpenAPIScenes(v3 *openapi3.Swagger) []openAPIScene {
+	basePath := ""
+	if len(v3.Servers) > 0 {
+		if u, err := url.Parse(v3.Servers[0].URL); err == nil {
+			basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+
+	var (
+		tags         []string
+		descriptions = make(map[string]string)
+		steps        = make(map[string][]openAPIStep)
+	)
+	for _, tag := range v3.Tags {
+		if tag == nil {
+			continue
+		}
+		tags = append(tags, tag.Name)
+		descriptions[tag.Name] = tag.Description
+	}
+
+	paths := make([]string, 0, len(v3.Paths))
+	for path := range v3.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := v3.Paths[path]
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range openAPIMethods {
+			operation := pathItem.GetOperation(method)
+			if operation == nil {
+				continue
+			}
+			// path item 上声明的公共参数
+			for _, p := range pathItem.Parameters {
+				if p != nil && p.Value != nil && operation.Parameters.GetByInAndName(p.Value.In, p.Value.Name) == nil {
+					operation.Parameters = append(operation.Parameters, p)
+				}
+			}
+			if err := oas3.ExpandOperation(operation, v3); err != nil {
+				logrus.Warnf("failed to ExpandOperation, path: %s, method: %s, err: %v", path, method, err)
+			}
+
+			tag := openAPIDefaultScene
+			if len(operation.Tags) > 0 && operation.Tags[0] != "" {
+				tag = operation.Tags[0]
+			}
+			if _, ok := descriptions[tag]; !ok {
+				tags = append(tags, tag)
+				descriptions[tag] = ""
+			}
+			steps[tag] = append(steps[tag], genOpenAPIStep(basePath, path, method, operation))
+		}
+	}
+
+	var (
+		scenes []openAPIScene
+		names  = make(map[string]bool)
+	)
+	for _, tag := range tags {
+		tagSteps := steps[tag]
+		for i := 0; i < len(tagSteps); i += openAPISceneMaxSteps {
+			end := i + openAPISceneMaxSteps
+			if end > len(tagSteps) {
+				end = len(tagSteps)
+			}
+			name := openAPISceneName(tag, names)
+			names[name] = true
+			description := []rune(descriptions[tag])
+			if len(description) > descMaxLength {
+				description = description[:descMaxLength]
+			}
+			scenes = append(scenes, openAPIScene{
+				Name:        name,
+				Description: string(description),
+				Steps:       tagSteps[i:end],
+			})
+		}
+	}
+	return scenes
+}
+
+// openAPISceneName 场景名只能包含中文、英文、数字、中划线或下划线, 且在场景集内唯一
+func openAPISceneName(tag string, exists map[string]bool) string {
+	name := strings.Trim(openAPINameReg.ReplaceAllString(tag, "-"), "-")
+	if name == "" {
+		name = openAPIDefaultScene
+	}
+	if len([]rune(name)) > nameMaxLength-4 {
+		name = string([]rune(name)[:nameMaxLength-4])
+	}
+	if !exists[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		if n := name + "-" + strconv.Itoa(i); !exists[n] {
+			return n
+		}
+	}
+}
+
+// genOpenAPIStep 根据 operation 生成接口步骤
+func genOpenAPIStep(basePath, path, method string, operation *openapi3.Operation) openAPIStep {
+	name := operation.Summary
+	if name == "" {
+		name = operation.OperationID
+	}
+	if name == "" {
+		name = strings.ToLower(method) + strings.NewReplacer("/", "-", "{", "", "}", "").Replace(path)
+	}
+
+	info := apistructs.APIInfoV2{
+		Name:      name,
+		Method:    method,
+		Headers:   []apistructs.APIHeader{},
+		Params:    []apistructs.APIParam{},
+		Body:      apistructs.APIBody{Type: apistructs.APIBodyTypeNone},
+		OutParams: []apistructs.APIOutParam{},
+		Asserts:   []apistructs.APIAssert{},
+	}
+
+	// parameters
+	for _, ref := range operation.Parameters {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		p := ref.Value
+		value := parameterExample(p)
+		switch p.In {
+		case openapi3.ParameterInPath:
+			path = strings.Replace(path, "{"+p.Name+"}", url.PathEscape(value), -1)
+		case openapi3.ParameterInQuery:
+			info.Params = append(info.Params, apistructs.APIParam{Key: p.Name, Value: value, Desc: p.Description})
+		case openapi3.ParameterInHeader:
+			info.Headers = append(info.Headers, apistructs.APIHeader{Key: p.Name, Value: value, Desc: p.Description})
+		}
+	}
+	info.URL = basePath + path
+
+	// request body
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		if mediaType, media := pickMediaType(operation.RequestBody.Value.Content); media != nil {
+			info.Body = requestBodyExample(mediaType, media)
+		}
+	}
+
+	// asserts
+	info.OutParams, info.Asserts = responseAsserts(operation.Responses)
+
+	// 步骤名中的特殊字符会被删除, 先替换为中划线
+	return openAPIStep{Name: strings.Trim(openAPINameReg.ReplaceAllString(name, "-"), "-"), APIInfo: info}
+}
+
+// parameterExample 参数示例, 依次使用文档中的示例, 默认值, 枚举值, 没有时根据类型生成
+func parameterExample(p *openapi3.Parameter) string {
+	if p.Example != nil {
+		return fmt.Sprint(p.Example)
+	}
+	if p.Schema == nil || p.Schema.Value == nil {
+		return ""
+	}
+	schema := p.Schema.Value
+	switch {
+	case schema.Example != nil:
+		return fmt.Sprint(schema.Example)
+	case schema.Default != nil:
+		return fmt.Sprint(schema.Default)
+	case len(schema.Enum) > 0:
+		return fmt.Sprint(schema.Enum[0])
+	}
+	switch schema.Type {
+	case "integer", "number":
+		return "1"
+	case "boolean":
+		return "true"
+	}
+	// 路径参数不能为空
+	if p.In == openapi3.ParameterInPath {
+		return p.Name
+	}
+	return ""
+}
+
+// pickMediaType 优先选择 json, 其次是 form
+func pickMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, prefer := range []func(string) bool{
+		func(t string) bool { return t == string(httputil.ApplicationJson) },
+		func(t string) bool { return strings.HasSuffix(t, "+json") },
+		func(t string) bool { return t == string(httputil.URLEncodedFormMime) },
+	} {
+		for _, mediaType := range mediaTypes {
+			if prefer(mediaType) {
+				return mediaType, content[mediaType]
+			}
+		}
+	}
+	return mediaTypes[0], content[mediaTypes[0]]
+}
+
+// requestBodyExample 请求体示例, 文档中没有示例时使用 gen_example 根据 schema 生成
+func requestBodyExample(mediaType string, media *openapi3.MediaType) apistructs.APIBody {
+	if mediaType == string(httputil.URLEncodedFormMime) {
+		var params []apistructs.APIParam
+		if media.Schema != nil && media.Schema.Value != nil {
+			names := make([]string, 0, len(media.Schema.Value.Properties))
+			for name := range media.Schema.Value.Properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				property := media.Schema.Value.Properties[name]
+				if property == nil || property.Value == nil {
+					continue
+				}
+				oas3.GenExampleFromExpandedSchema(httputil.ApplicationJson, property.Value)
+				params = append(params, apistructs.APIParam{
+					Key:   name,
+					Value: fmt.Sprint(property.Value.Example),
+					Desc:  property.Value.Description,
+				})
+			}
+		}
+		return apistructs.APIBody{Type: apistructs.APIBodyTypeApplicationXWWWFormUrlencoded, Content: params}
+	}
+
+	bodyType := apistructs.APIBodyType(mediaType)
+	if mediaType == string(httputil.ApplicationJson) || strings.HasSuffix(mediaType, "+json") {
+		bodyType = apistructs.APIBodyTypeApplicationJSON2
+	}
+	var example interface{}
+	switch {
+	case media.Example != nil:
+		example = media.Example
+	case media.Schema != nil && media.Schema.Value != nil:
+		oas3.GenExampleFromExpandedSchema(httputil.ContentType(mediaType), media.Schema.Value)
+		example = media.Schema.Value.Example
+	}
+	content, ok := example.(string)
+	if !ok && example != nil {
+		data, _ := json.Marshal(example)
+		content = string(data)
+	}
+	return apistructs.APIBody{Type: bodyType, Content: content}
+}
+
+// responseAsserts 根据声明的成功响应生成状态码断言, 响应体为 json 时断言响应体符合 schema
+func responseAsserts(responses openapi3.Responses) ([]apistructs.APIOutParam, []apistructs.APIAssert) {
+	outParams := []apistructs.APIOutParam{{Key: openAPIOutParamStatus, Source: apistructs.APIOutParamSourceStatus}}
+	asserts := []apistructs.APIAssert{}
+
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	var response *openapi3.Response
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") || responses[code] == nil || responses[code].Value == nil {
+			continue
+		}
+		response = responses[code].Value
+		if _, err := strconv.Atoi(code); err == nil {
+			asserts = append(asserts, apistructs.APIAssert{Arg: openAPIOutParamStatus, Operator: "=", Value: code})
+		} else {
+			// 2XX
+			asserts = append(asserts,
+				apistructs.APIAssert{Arg: openAPIOutParamStatus, Operator: ">=", Value: "200"},
+				apistructs.APIAssert{Arg: openAPIOutParamStatus, Operator: "<", Value: "300"},
+			)
+		}
+		break
+	}
+	if response == nil {
+		// 只声明了 default 响应时不确定状态码
+		if ref := responses.Default(); ref != nil && ref.Value != nil {
+			response = ref.Value
+		}
+	}
+	if response == nil {
+		return outParams, asserts
+	}
+
+	media := response.Content.Get(string(httputil.ApplicationJson))
+	if media == nil || media.Schema == nil || media.Schema.Value == nil {
+		return outParams, asserts
+	}
+	schema, err := jsonSchemaFromOAS(media.Schema.Value)
+	if err != nil {
+		logrus.Warnf("failed to marshal response schema, err: %v", err)
+		return outParams, asserts
+	}
+	outParams = append(outParams, apistructs.APIOutParam{Key: openAPIOutParamBody, Source: apistructs.APIOutParamSourceBodyJson})
+	asserts = append(asserts, apistructs.APIAssert{Arg: openAPIOutParamBody, Operator: "json_schema", Value: string(schema)})
+	return outParams, asserts
+}
+
+// jsonSchemaFromOAS 将 OAS3 schema 转换为 json_schema 断言使用的 JSON Schema, nullable 转换为 type 中的 null
+func jsonSchemaFromOAS(schema *openapi3.Schema) ([]byte, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if nullable, ok := t["nullable"].(bool); ok {
+				delete(t, "nullable")
+				if typ, ok := t["type"].(string); ok && nullable {
+					t["type"] = []string{typ, "null"}
+				}
+			}
+			for _, child := range t {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range t {
+				walk(child)
+			}
+		}
+	}
+	walk(v)
+	return json.Marshal(v)
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/swagger"
+)
+
+const openAPITestSpec = `openapi: 3.0.0
+info:
+  title: petstore
+  version: 1.0.0
+servers:
+  - url: http://petstore.io/api/v1/
+tags:
+  - name: pet store!
+    description: pets
+  - name: order
+paths:
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      tags: [pet store!]
+      summary: get pet
+      responses:
+        '200':
+          description: ok
+  /pets:
+    post:
+      tags: [pet store!]
+      operationId: createPet
+      parameters:
+        - name: X-Trace
+          in: header
+          schema:
+            type: string
+            example: trace
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                  example: cat
+      responses:
+        '201':
+          description: created
+  /health:
+    get:
+      responses:
+        default:
+          description: ok
+  /users:
+    get:
+      tags: [user]
+      parameters:
+        - name: page
+          in: query
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: ok
+`
+
+func Test_genOpenAPIScenes(t *testing.T) {
+	bigSpec := func(n int) string {
+		var b strings.Builder
+		b.WriteString("openapi: 3.0.0\ninfo:\n  title: big\n  version: 1.0.0\npaths:\n")
+		for i := 0; i < n; i++ {
+			b.WriteString(fmt.Sprintf("  /items/%03d:\n    get:\n      tags: [big]\n      responses:\n        '200':\n          description: ok\n", i))
+		}
+		return b.String()
+	}
+
+	type step struct {
+		name, method, url string
+	}
+	tests := []struct {
+		name       string
+		spec       string
+		wantScenes []string
+		wantSteps  map[string][]step
+	}{
+		{
+			name: "group by tag",
+			spec: openAPITestSpec,
+			// 声明的 tag 在前, 其余按路径顺序; 没有接口的 tag 不生成场景
+			wantScenes: []string{"pet-store", "default", "user"},
+			wantSteps: map[string][]step{
+				"pet-store": {
+					{name: "createPet", method: "POST", url: "/api/v1/pets"},
+					{name: "get-pet", method: "GET", url: "/api/v1/pets/1"},
+				},
+				"default": {{name: "get-health", method: "GET", url: "/api/v1/health"}},
+				"user":    {{name: "get-users", method: "GET", url: "/api/v1/users"}},
+			},
+		},
+		{
+			name:       "split large scene",
+			spec:       bigSpec(openAPISceneMaxSteps + 1),
+			wantScenes: []string{"big", "big-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v3, err := swagger.LoadFromData([]byte(tt.spec))
+			assert.NoError(t, err)
+			scenes := genOpenAPIScenes(v3)
+
+			var names []string
+			for _, scene := range scenes {
+				names = append(names, scene.Name)
+				want, ok := tt.wantSteps[scene.Name]
+				if !ok {
+					continue
+				}
+				var got []step
+				for _, s := range scene.Steps {
+					got = append(got, step{name: s.Name, method: s.APIInfo.Method, url: s.APIInfo.URL})
+				}
+				assert.Equal(t, want, got, scene.Name)
+			}
+			assert.Equal(t, tt.wantScenes, names)
+		})
+	}
+
+	// 参数, 请求体和场景描述
+	v3, err := swagger.LoadFromData([]byte(openAPITestSpec))
+	assert.NoError(t, err)
+	scenes := genOpenAPIScenes(v3)
+	assert.Equal(t, "pets", scenes[0].Description)
+	create := scenes[0].Steps[0].APIInfo
+	assert.Equal(t, []apistructs.APIHeader{{Key: "X-Trace", Value: "trace"}}, create.Headers)
+	assert.Equal(t, apistructs.APIBodyTypeApplicationJSON2, create.Body.Type)
+	assert.JSONEq(t, `{"name":"cat"}`, create.Body.Content.(string))
+	assert.Equal(t, []apistructs.APIParam{{Key: "page", Value: "1"}}, scenes[2].Steps[0].APIInfo.Params)
+}
+
+func Test_responseAsserts(t *testing.T) {
+	jsonResponse := func() *openapi3.ResponseRef {
+		return &openapi3.ResponseRef{Value: openapi3.NewResponse().WithJSONSchema(openapi3.NewObjectSchema())}
+	}
+	emptyResponse := func() *openapi3.ResponseRef {
+		return &openapi3.ResponseRef{Value: openapi3.NewResponse()}
+	}
+	statusAssert := func(op, value string) apistructs.APIAssert {
+		return apistructs.APIAssert{Arg: openAPIOutParamStatus, Operator: op, Value: value}
+	}
+
+	tests := []struct {
+		name        string
+		responses   openapi3.Responses
+		wantAsserts []apistructs.APIAssert
+		wantBody    bool
+	}{
+		{
+			name:        "status and json body",
+			responses:   openapi3.Responses{"200": jsonResponse(), "400": jsonResponse()},
+			wantAsserts: []apistructs.APIAssert{statusAssert("=", "200")},
+			wantBody:    true,
+		},
+		{
+			name:        "first success status",
+			responses:   openapi3.Responses{"204": emptyResponse(), "201": emptyResponse()},
+			wantAsserts: []apistructs.APIAssert{statusAssert("=", "201")},
+		},
+		{
+			name:        "status range",
+			responses:   openapi3.Responses{"2XX": emptyResponse()},
+			wantAsserts: []apistructs.APIAssert{statusAssert(">=", "200"), statusAssert("<", "300")},
+		},
+		{
+			name:        "default response",
+			responses:   openapi3.Responses{"default": jsonResponse()},
+			wantAsserts: []apistructs.APIAssert{},
+			wantBody:    true,
+		},
+		{
+			name:        "no success response",
+			responses:   openapi3.Responses{"404": jsonResponse()},
+			wantAsserts: []apistructs.APIAssert{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outParams, asserts := responseAsserts(tt.responses)
+			assert.Equal(t, openAPIOutParamStatus, outParams[0].Key)
+			if !tt.wantBody {
+				assert.Equal(t, 1, len(outParams))
+				assert.Equal(t, tt.wantAsserts, asserts)
+				return
+			}
+			assert.Equal(t, 2, len(outParams))
+			assert.Equal(t, apistructs.APIOutParam{Key: openAPIOutParamBody, Source: apistructs.APIOutParamSourceBodyJson}, outParams[1])
+			assert.Equal(t, tt.wantAsserts, asserts[:len(asserts)-1])
+			bodyAssert := asserts[len(asserts)-1]
+			assert.Equal(t, openAPIOutParamBody, bodyAssert.Arg)
+			assert.Equal(t, "json_schema", bodyAssert.Operator)
+			assert.JSONEq(t, `{"type":"object"}`, bodyAssert.Value)
+		})
+	}
+}
+
+func Test_jsonSchemaFromOAS(t *testing.T) {
+	name := openapi3.NewStringSchema()
+	name.Nullable = true
+	tags := openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())
+	tags.Nullable = true
+	schema := openapi3.NewObjectSchema().WithProperty("name", name).WithPropertyRef("tags", &openapi3.SchemaRef{Value: tags})
+
+	data, err := jsonSchemaFromOAS(schema)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"object","properties":{"name":{"type":["string","null"]},"tags":{"type":["array","null"],"items":{"type":"string"}}}}`, string(data))
+}
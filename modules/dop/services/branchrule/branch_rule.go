@@ -109,6 +109,7 @@ func (branchRule *BranchRule) Update(request apistructs.UpdateBranchRuleRequest)
 	rule.Workspace = request.Workspace
 	rule.ArtifactWorkspace = request.ArtifactWorkspace
 	rule.NeedApproval = request.NeedApproval
+	rule.SetMergeProtection(request.MergeProtection)
 	err = branchRule.CheckRuleValid(&rule)
 	if err != nil {
 		return nil, err
@@ -132,6 +133,7 @@ func (branchRule *BranchRule) Create(request apistructs.CreateBranchRuleRequest)
 		NeedApproval:      request.NeedApproval,
 		Desc:              request.Desc,
 	}
+	rule.SetMergeProtection(request.MergeProtection)
 	err := branchRule.CheckRuleValid(&rule)
 	if err != nil {
 		return nil, err
@@ -173,6 +175,15 @@ func isRuleMatch(sourceRule, targetRule string) bool {
 }
 
 func (branchRule *BranchRule) CheckRuleValid(newBranchRule *model.BranchRule) error {
+	if newBranchRule.MinApprovals < 0 {
+		return fmt.Errorf("invalid minApprovals %d", newBranchRule.MinApprovals)
+	}
+	if newBranchRule.MinCoverage < 0 || newBranchRule.MinCoverage > 100 {
+		return fmt.Errorf("invalid minCoverage %v", newBranchRule.MinCoverage)
+	}
+	if newBranchRule.MinDiffCoverage < 0 || newBranchRule.MinDiffCoverage > 100 {
+		return fmt.Errorf("invalid minDiffCoverage %v", newBranchRule.MinDiffCoverage)
+	}
 	// check duplicate
 	currentRules, err := branchRule.Query(newBranchRule.ScopeType, newBranchRule.ScopeID)
 	if err != nil {
@@ -217,7 +228,9 @@ func (branchRule *BranchRule) GetAllValidBranchWorkspaces(appID int64, userID st
 	// project rule取部署信息 app rule取保护分支
 	for _, branch := range repoStats.Branches {
 		branchRule := diceworkspace.GetValidBranchByGitReference(branch, rules)
-		branchRule.IsProtect = diceworkspace.GetValidBranchByGitReference(branch, appRules).IsProtect
+		appBranchRule := diceworkspace.GetValidBranchByGitReference(branch, appRules)
+		branchRule.IsProtect = appBranchRule.IsProtect
+		branchRule.MergeProtection = appBranchRule.MergeProtection
 		result = append(result, branchRule)
 	}
 
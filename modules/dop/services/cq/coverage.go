@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/coverage"
+)
+
+const coverageCheckRunName = "coverage"
+
+// CoverageCheckRequest 根据流水线覆盖率报告检查 MR 覆盖率，阈值来自目标分支规则
+type CoverageCheckRequest struct {
+	MR         apistructs.MergeRequestInfo
+	PipelineID uint64
+	// MinCoverage 总体行覆盖率下限，百分比，0 表示不检查
+	MinCoverage float64
+	// MinDiffCoverage MR 新增代码行覆盖率下限，百分比，0 表示不检查
+	MinDiffCoverage float64
+}
+
+// CoverageCheckResult 覆盖率检查结果
+type CoverageCheckResult struct {
+	Total  coverage.Summary      `json:"total"`
+	Diff   coverage.DiffCoverage `json:"diff"`
+	Passed bool                  `json:"passed"`
+	// Reasons 未通过的原因
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// GetPipelineCoverage returns the coverage reported by the pipeline, reports of several tasks are merged.
+// Returns nil if the pipeline has no coverage report. The pipeline must belong to the application.
+func (cq *CQ) GetPipelineCoverage(appID int64, pipelineID uint64) (*coverage.Report, error) {
+	pipeline, err := cq.bdl.GetPipeline(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.ApplicationID != uint64(appID) {
+		return nil, fmt.Errorf("pipeline %d does not belong to application %d", pipelineID, appID)
+	}
+	reportSet, err := cq.bdl.GetPipelineReportSet(pipelineID, []string{string(apistructs.PipelineReportTypeCoverage)})
+	if err != nil {
+		return nil, err
+	}
+	var merged *coverage.Report
+	for _, r := range reportSet.Reports {
+		if r.Type != apistructs.PipelineReportTypeCoverage {
+			continue
+		}
+		// 完整报告存放在文件服务中，报告 meta 中只有汇总信息
+		uuid, ok := r.Meta[apistructs.CoverageReportMetaFileUUID].(string)
+		if !ok || uuid == "" {
+			continue
+		}
+		report, err := cq.downloadCoverageReport(uuid)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverage report of pipeline %d: %v", pipelineID, err)
+		}
+		if merged == nil {
+			merged = &coverage.Report{}
+		}
+		merged.Merge(report)
+	}
+	return merged, nil
+}
+
+func (cq *CQ) downloadCoverageReport(uuid string) (*coverage.Report, error) {
+	body, err := cq.bdl.DownloadDiceFile(uuid)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var report coverage.Report
+	if err := json.NewDecoder(body).Decode(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetMRDiffCoverage returns the coverage of lines added by the merge request.
+func (cq *CQ) GetMRDiffCoverage(mr apistructs.MergeRequestInfo, report *coverage.Report, userID string) (coverage.DiffCoverage, error) {
+	compare, err := cq.bdl.GetGittarCompare(mr.SourceSha, mr.TargetSha, mr.AppID, userID)
+	if err != nil {
+		return coverage.DiffCoverage{}, err
+	}
+	return report.DiffCoverage(coverage.ChangedLines(&compare.Diff)), nil
+}
+
+// CheckCoverage checks the coverage of the pipeline against the thresholds and
+// reports the result to the merge request as a check-run.
+func (cq *CQ) CheckCoverage(req CoverageCheckRequest, userID string) (*CoverageCheckResult, error) {
+	report, err := cq.GetPipelineCoverage(req.MR.AppID, req.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, fmt.Errorf("no coverage report found, pipelineID: %d", req.PipelineID)
+	}
+	diff, err := cq.GetMRDiffCoverage(req.MR, report, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := evaluateCoverage(report.Summary(), diff, req.MinCoverage, req.MinDiffCoverage)
+
+	now := time.Now()
+	checkRun := apistructs.CheckRun{
+		Name:        coverageCheckRunName,
+		MrID:        int64(req.MR.RepoMergeId),
+		Type:        "CI",
+		Commit:      req.MR.SourceSha,
+		PipelineID:  strconv.FormatUint(req.PipelineID, 10),
+		Status:      apistructs.CheckRunStatusCompleted,
+		Result:      apistructs.CheckRunResultSuccess,
+		Output:      result.output(),
+		CompletedAt: &now,
+	}
+	if !result.Passed {
+		checkRun.Result = apistructs.CheckRunResultFailure
+	}
+	if _, err := cq.bdl.CreateCheckRun(req.MR.AppID, checkRun, userID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func evaluateCoverage(total coverage.Summary, diff coverage.DiffCoverage, minCoverage, minDiffCoverage float64) *CoverageCheckResult {
+	result := &CoverageCheckResult{Total: total, Diff: diff, Passed: true}
+	if minCoverage > 0 && total.Rate < minCoverage {
+		result.Passed = false
+		result.Reasons = append(result.Reasons,
+			fmt.Sprintf("coverage %.2f%% is below the threshold %.2f%%", total.Rate, minCoverage))
+	}
+	// 没有新增可执行代码时不检查增量覆盖率
+	if minDiffCoverage > 0 && diff.Total > 0 && diff.Rate < minDiffCoverage {
+		result.Passed = false
+		result.Reasons = append(result.Reasons,
+			fmt.Sprintf("diff coverage %.2f%% is below the threshold %.2f%%", diff.Rate, minDiffCoverage))
+	}
+	return result
+}
+
+func (r *CoverageCheckResult) output() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "coverage: %.2f%% (%d/%d lines)\n", r.Total.Rate, r.Total.Covered, r.Total.Total)
+	fmt.Fprintf(&sb, "diff coverage: %.2f%% (%d/%d lines)\n", r.Diff.Rate, r.Diff.Covered, r.Diff.Total)
+	for _, reason := range r.Reasons {
+		sb.WriteString(reason + "\n")
+	}
+	for _, f := range r.Diff.Files {
+		if len(f.Uncovered) == 0 {
+			continue
+		}
+		lines := make([]string, 0, len(f.Uncovered))
+		for _, l := range f.Uncovered {
+			lines = append(lines, strconv.Itoa(l))
+		}
+		fmt.Fprintf(&sb, "%s: uncovered lines %s\n", f.Path, strings.Join(lines, ","))
+	}
+	return sb.String()
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/coverage"
+)
+
+func TestEvaluateCoverage(t *testing.T) {
+	total := coverage.Summary{Covered: 60, Total: 100, Rate: 60}
+	diff := coverage.DiffCoverage{
+		Summary: coverage.Summary{Covered: 1, Total: 4, Rate: 25},
+		Files:   []coverage.DiffFileCoverage{{Path: "calc/add.go", Uncovered: []int{3, 5, 8}}},
+	}
+
+	result := evaluateCoverage(total, diff, 0, 0)
+	assert.True(t, result.Passed)
+
+	result = evaluateCoverage(total, diff, 50, 80)
+	assert.False(t, result.Passed)
+	assert.Equal(t, 1, len(result.Reasons))
+	assert.Contains(t, result.output(), "calc/add.go: uncovered lines 3,5,8")
+
+	result = evaluateCoverage(total, diff, 70, 20)
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.Reasons[0], "below the threshold 70.00%")
+
+	// no executable lines added
+	result = evaluateCoverage(total, coverage.DiffCoverage{Summary: coverage.Summary{Rate: 100}}, 0, 80)
+	assert.True(t, result.Passed)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/erda-project/erda/modules/eventbox/server"
 	stypes "github.com/erda-project/erda/modules/eventbox/server/types"
 	"github.com/erda-project/erda/modules/eventbox/subscriber"
+	chatwebhooksubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/chatwebhook"
 	dingdingsubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/dingding"
 	dingdingworknoticesubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/dingding_worknotice"
 	emailsubscriber "github.com/erda-project/erda/modules/eventbox/subscriber/email"
@@ -98,6 +99,7 @@ func New() (Dispatcher, error) {
 	bundleS := bundle.New(bundle.WithCoreServices())
 	dingdingS := dingdingsubscriber.New(conf.Proxy())
 	dingdingWorknoticeS := dingdingworknoticesubscriber.New(conf.Proxy())
+	chatWebhookS := chatwebhooksubscriber.New(conf.Proxy())
 	mboxS := mbox.New(bundle.New(bundle.WithCoreServices()))
 	emailS := emailsubscriber.New(conf.SmtpHost(), conf.SmtpPort(), conf.SmtpUser(), conf.SmtpPassword(),
 		conf.SmtpDisplayUser(), conf.SmtpIsSSL(), conf.SMTPInsecureSkipVerify(), bundleS)
@@ -130,6 +132,7 @@ func New() (Dispatcher, error) {
 	dispatcher.RegisterSubscriber(httpS)
 	dispatcher.RegisterSubscriber(dingdingS)
 	dispatcher.RegisterSubscriber(dingdingWorknoticeS)
+	dispatcher.RegisterSubscriber(chatWebhookS)
 	dispatcher.RegisterSubscriber(smsS)
 	dispatcher.RegisterSubscriber(emailS)
 	dispatcher.RegisterSubscriber(vmsS)
@@ -42,6 +42,7 @@ const (
 	DINGDINGWorkNoticeOutput
 	MYSQLOutput
 	HTTPOutput
+	ChatWebhookOutput
 	LastType
 )
 
@@ -54,6 +55,7 @@ func infoTypeList() []InfoType {
 		DINGDINGWorkNoticeOutput,
 		MYSQLOutput,
 		HTTPOutput,
+		ChatWebhookOutput,
 	}
 }
 
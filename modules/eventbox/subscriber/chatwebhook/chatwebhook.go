@@ -0,0 +1,172 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chatwebhook 通过飞书、企业微信、Slack 等聊天工具的机器人 webhook 发送通知
+package chatwebhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/eventbox/monitor"
+	"github.com/erda-project/erda/modules/eventbox/subscriber"
+	"github.com/erda-project/erda/modules/eventbox/types"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+)
+
+var (
+	ErrSend            = errors.New("send chat webhook fail")
+	ErrBadURL          = errors.New("bad chat webhook URL")
+	ErrUnknownProvider = errors.New("unknown chat webhook provider")
+)
+
+// Message 渲染前的通用消息，Content 为 markdown
+type Message struct {
+	Title   string
+	Content string
+	At      At
+}
+
+// At 需要 @ 的人，各机器人按支持的方式渲染
+type At struct {
+	AtMobiles []string `json:"atMobiles"`
+	AtEmails  []string `json:"atEmails"`
+	IsAtAll   bool     `json:"isAtAll"`
+}
+
+type markdownLabel struct {
+	Title string `json:"title"`
+}
+
+type ChatWebhookSubscriber struct {
+	proxy string
+}
+
+func New(proxy string) subscriber.Subscriber {
+	return &ChatWebhookSubscriber{
+		proxy: proxy,
+	}
+}
+
+// dest: []apistructs.ChatWebhookTarget
+// labels: MARKDOWN: {"title": ""}, AT: {"atMobiles": [], "atEmails": [], "isAtAll": false}
+func (s *ChatWebhookSubscriber) Publish(dest string, content string, time int64, msg *types.Message) []error {
+	monitor.Notify(monitor.MonitorInfo{Tp: monitor.ChatWebhookOutput})
+	var targets []apistructs.ChatWebhookTarget
+	if err := json.Unmarshal([]byte(dest), &targets); err != nil {
+		return []error{errors.New("illegal dest")}
+	}
+	m, err := parseMessage(content, msg)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if err := s.send(target, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (s *ChatWebhookSubscriber) Status() interface{} {
+	return nil
+}
+
+func (s *ChatWebhookSubscriber) Name() string {
+	return "CHATWEBHOOK"
+}
+
+func parseMessage(content string, msg *types.Message) (*Message, error) {
+	m := &Message{Content: content}
+	// content 为序列化后的字符串
+	var contentS string
+	if err := json.Unmarshal([]byte(content), &contentS); err == nil {
+		m.Content = contentS
+	}
+	if md, ok := msg.Labels["/MARKDOWN"]; ok {
+		var label markdownLabel
+		if err := convertLabel(md, &label); err != nil {
+			return nil, errors.New("illegal [MARKDOWN] label value")
+		}
+		m.Title = label.Title
+	}
+	if at, ok := msg.Labels["/AT"]; ok {
+		if err := convertLabel(at, &m.At); err != nil {
+			return nil, errors.New("illegal [AT] label value")
+		}
+	}
+	return m, nil
+}
+
+func convertLabel(v interface{}, o interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(raw)).Decode(o)
+}
+
+func (s *ChatWebhookSubscriber) send(target apistructs.ChatWebhookTarget, m *Message) error {
+	renderer, ok := getRenderer(target.Type)
+	if !ok {
+		return errors.Wrap(ErrUnknownProvider, string(target.Type))
+	}
+	parsed, err := url.Parse(target.Receiver)
+	if err != nil || parsed.Host == "" {
+		return errors.Wrapf(ErrBadURL, "%s publish: %v", target.Type, target.Receiver)
+	}
+	bodies, err := renderer.Render(target.Target, m)
+	if err != nil {
+		return errors.Wrapf(ErrSend, "%s render: %v", target.Type, err)
+	}
+
+	opts := []httpclient.OpOption{httpclient.WithProxy(s.proxy), httpclient.WithDialerKeepAlive(30 * time.Second)}
+	if parsed.Scheme == "https" {
+		opts = append(opts, httpclient.WithHTTPS())
+	}
+	for _, body := range bodies {
+		var buf bytes.Buffer
+		resp, err := httpclient.New(opts...).
+			Post(parsed.Host).
+			Path(parsed.Path).
+			Params(parsed.Query()).
+			Header("Content-Type", "application/json;charset=utf-8").
+			JSONBody(body).Do().
+			Body(&buf)
+		if err != nil {
+			err = errors.Errorf("%s publish: %v, err: %v", target.Type, parsed.Host, err)
+			logrus.Error(err)
+			return errors.Wrap(ErrSend, err.Error())
+		}
+		if !resp.IsOK() {
+			err = errors.Errorf("%s publish: %v, httpcode: %d, body: %s", target.Type, parsed.Host, resp.StatusCode(), buf.String())
+			logrus.Error(err)
+			return errors.Wrap(ErrSend, err.Error())
+		}
+		if err := renderer.CheckResponse(buf.Bytes()); err != nil {
+			err = errors.Errorf("%s publish: %v, err: %v", target.Type, parsed.Host, err)
+			logrus.Error(err)
+			return errors.Wrap(ErrSend, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chatwebhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/eventbox/types"
+)
+
+func TestFeishuRenderer(t *testing.T) {
+	now = func() time.Time { return time.Unix(1599360473, 0) }
+	defer func() { now = time.Now }()
+
+	r := &feishuRenderer{}
+	bodies, err := r.Render(apistructs.Target{Secret: "secret"}, &Message{
+		Title:   "告警",
+		Content: "**cpu** high",
+		At:      At{AtEmails: []string{"a@erda.cloud"}, IsAtAll: true},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, bodies, 1)
+	msg := bodies[0].(feishuMessage)
+	assert.Equal(t, "1599360473", msg.Timestamp)
+	assert.Equal(t, feishuSign("1599360473", "secret"), msg.Sign)
+	assert.Equal(t, "告警", msg.Card.Header.Title.Content)
+	assert.Equal(t, "**cpu** high\n<at id=all></at> <at email=a@erda.cloud></at>", msg.Card.Elements[0].Text.Content)
+
+	assert.NoError(t, r.CheckResponse([]byte(`{"code":0,"msg":"success"}`)))
+	assert.NoError(t, r.CheckResponse([]byte(`{"StatusCode":0,"StatusMessage":"success"}`)))
+	assert.Error(t, r.CheckResponse([]byte(`{"code":19021,"msg":"sign match fail"}`)))
+}
+
+func TestWecomRenderer(t *testing.T) {
+	r := &wecomRenderer{}
+	bodies, err := r.Render(apistructs.Target{}, &Message{Title: "告警", Content: "**cpu** high"})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{wecomMessage{MsgType: "markdown", Markdown: &wecomMarkdown{Content: "### 告警\n**cpu** high"}}}, bodies)
+
+	bodies, err = r.Render(apistructs.Target{}, &Message{Content: "cpu high\ndetail", At: At{AtMobiles: []string{"1825718XXXX"}}})
+	assert.NoError(t, err)
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, wecomMessage{MsgType: "text", Text: &wecomText{Content: "cpu high", MentionedMobileList: []string{"1825718XXXX"}}}, bodies[1])
+
+	assert.NoError(t, r.CheckResponse([]byte(`{"errcode":0,"errmsg":"ok"}`)))
+	assert.Error(t, r.CheckResponse([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`)))
+	assert.Equal(t, "中", truncateBytes("中文", 4))
+}
+
+func TestSlackRenderer(t *testing.T) {
+	r := &slackRenderer{}
+	bodies, err := r.Render(apistructs.Target{}, &Message{
+		Title:   "告警",
+		Content: "## cpu\n**high** see [detail](https://erda.cloud)",
+		At:      At{IsAtAll: true},
+	})
+	assert.NoError(t, err)
+	msg := bodies[0].(slackMessage)
+	assert.Equal(t, "告警", msg.Text)
+	assert.Equal(t, "header", msg.Blocks[0].Type)
+	assert.Equal(t, "*cpu*\n*high* see <https://erda.cloud|detail>\n<!channel>", msg.Blocks[1].Text.Text)
+
+	assert.NoError(t, r.CheckResponse([]byte("ok")))
+	assert.Error(t, r.CheckResponse([]byte("invalid_payload")))
+}
+
+func TestChatWebhookSubscriber_Publish(t *testing.T) {
+	var received []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var v map[string]interface{}
+		json.Unmarshal(body, &v)
+		received = append(received, v)
+		if r.URL.Path == "/slack" {
+			w.Write([]byte("ok"))
+			return
+		}
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer srv.Close()
+
+	dest, _ := json.Marshal([]apistructs.ChatWebhookTarget{
+		{Type: apistructs.WeComNotifyTarget, Target: apistructs.Target{Receiver: srv.URL + "/wecom?key=xxx"}},
+		{Type: apistructs.SlackNotifyTarget, Target: apistructs.Target{Receiver: srv.URL + "/slack"}},
+		{Type: "unknown", Target: apistructs.Target{Receiver: srv.URL}},
+	})
+	content, _ := json.Marshal("**cpu** high")
+	s := New("")
+	errs := s.Publish(string(dest), string(content), time.Now().Unix(), &types.Message{
+		Labels: map[types.LabelKey]interface{}{
+			"/MARKDOWN": map[string]string{"title": "告警"},
+		},
+	})
+	assert.Len(t, errs, 1)
+	assert.Len(t, received, 2)
+	assert.Equal(t, "markdown", received[0]["msgtype"])
+	assert.Equal(t, "告警", received[1]["text"])
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chatwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+var now = time.Now
+
+// example feishu/lark card message:
+// {
+//     "timestamp": "1599360473",
+//     "sign": "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx=",
+//     "msg_type": "interactive",
+//     "card": {
+//         "header": {"title": {"tag": "plain_text", "content": "title"}},
+//         "elements": [{"tag": "div", "text": {"tag": "lark_md", "content": "**markdown** <at email=a@b.com></at>"}}]
+//     }
+// }
+type feishuText struct {
+	Tag     string `json:"tag"`
+	Content string `json:"content"`
+}
+
+type feishuHeader struct {
+	Title feishuText `json:"title"`
+}
+
+type feishuElement struct {
+	Tag  string     `json:"tag"`
+	Text feishuText `json:"text"`
+}
+
+type feishuCard struct {
+	Header   *feishuHeader   `json:"header,omitempty"`
+	Elements []feishuElement `json:"elements"`
+}
+
+type feishuMessage struct {
+	Timestamp string     `json:"timestamp,omitempty"`
+	Sign      string     `json:"sign,omitempty"`
+	MsgType   string     `json:"msg_type"`
+	Card      feishuCard `json:"card"`
+}
+
+type feishuRenderer struct{}
+
+func (r *feishuRenderer) Render(target apistructs.Target, m *Message) ([]interface{}, error) {
+	content := m.Content
+	var mentions []string
+	if m.At.IsAtAll {
+		mentions = append(mentions, "<at id=all></at>")
+	}
+	for _, email := range m.At.AtEmails {
+		mentions = append(mentions, fmt.Sprintf("<at email=%s></at>", email))
+	}
+	if len(mentions) > 0 {
+		content += "\n" + strings.Join(mentions, " ")
+	}
+
+	msg := feishuMessage{
+		MsgType: "interactive",
+		Card: feishuCard{
+			Elements: []feishuElement{{Tag: "div", Text: feishuText{Tag: "lark_md", Content: content}}},
+		},
+	}
+	if m.Title != "" {
+		msg.Card.Header = &feishuHeader{Title: feishuText{Tag: "plain_text", Content: m.Title}}
+	}
+	if target.Secret != "" {
+		timestamp := strconv.FormatInt(now().Unix(), 10)
+		msg.Timestamp = timestamp
+		msg.Sign = feishuSign(timestamp, target.Secret)
+	}
+	return []interface{}{msg}, nil
+}
+
+// feishuSign 使用 timestamp + "\n" + secret 作为密钥对空字符串签名
+func feishuSign(timestamp, secret string) string {
+	h := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (r *feishuRenderer) CheckResponse(body []byte) error {
+	var resp struct {
+		Code       *int   `json:"code"`
+		Msg        string `json:"msg"`
+		StatusCode *int   `json:"StatusCode"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return errors.Errorf("invalid response: %s", string(body))
+	}
+	if resp.Code != nil && *resp.Code != 0 {
+		return errors.Errorf("code: %d, msg: %s", *resp.Code, resp.Msg)
+	}
+	if resp.StatusCode != nil && *resp.StatusCode != 0 {
+		return errors.Errorf("StatusCode: %d, body: %s", *resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chatwebhook
+
+import (
+	"sync"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// Renderer 将通用消息渲染为各聊天工具机器人的请求
+type Renderer interface {
+	// Render 返回需要依次发送的请求体，例如企业微信 markdown 消息不支持 @ 手机号，需要额外发送一条文本消息
+	Render(target apistructs.Target, m *Message) ([]interface{}, error)
+	// CheckResponse 校验 http 200 时的响应内容
+	CheckResponse(body []byte) error
+}
+
+var (
+	renderersLock sync.RWMutex
+	renderers     = map[apistructs.NotifyTargetType]Renderer{}
+)
+
+// RegisterRenderer 注册通知组目标类型对应的 Renderer，已存在则覆盖
+func RegisterRenderer(typ apistructs.NotifyTargetType, r Renderer) {
+	renderersLock.Lock()
+	defer renderersLock.Unlock()
+	renderers[typ] = r
+}
+
+func getRenderer(typ apistructs.NotifyTargetType) (Renderer, bool) {
+	renderersLock.RLock()
+	defer renderersLock.RUnlock()
+	r, ok := renderers[typ]
+	return r, ok
+}
+
+func init() {
+	RegisterRenderer(apistructs.FeishuNotifyTarget, &feishuRenderer{})
+	RegisterRenderer(apistructs.WeComNotifyTarget, &wecomRenderer{})
+	RegisterRenderer(apistructs.SlackNotifyTarget, &slackRenderer{})
+}
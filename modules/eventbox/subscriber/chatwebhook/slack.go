@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chatwebhook
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// slackSectionMaxChars section 文本最长 3000 字符
+const slackSectionMaxChars = 3000
+
+var (
+	mdLinkRe    = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)\)`)
+	mdBoldRe    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdHeadingRe = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+)
+
+// example slack incoming webhook message:
+// {
+//     "text": "title",
+//     "blocks": [
+//         {"type": "header", "text": {"type": "plain_text", "text": "title"}},
+//         {"type": "section", "text": {"type": "mrkdwn", "text": "*mrkdwn* <https://erda.cloud|link>"}}
+//     ]
+// }
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text,omitempty"`
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slack 只能通过 member id @ 用户，这里仅支持 @channel
+type slackRenderer struct{}
+
+func (r *slackRenderer) Render(target apistructs.Target, m *Message) ([]interface{}, error) {
+	content := toSlackMrkdwn(m.Content)
+	if m.At.IsAtAll {
+		content += "\n<!channel>"
+	}
+
+	msg := slackMessage{Text: m.Title}
+	if msg.Text == "" {
+		msg.Text = strings.SplitN(strings.TrimSpace(m.Content), "\n", 2)[0]
+	}
+	if m.Title != "" {
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "header", Text: &slackText{Type: "plain_text", Text: m.Title}})
+	}
+	if runes := []rune(content); len(runes) > slackSectionMaxChars {
+		content = string(runes[:slackSectionMaxChars])
+	}
+	msg.Blocks = append(msg.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: content}})
+	return []interface{}{msg}, nil
+}
+
+func (r *slackRenderer) CheckResponse(body []byte) error {
+	if resp := strings.TrimSpace(string(body)); resp != "ok" {
+		return errors.Errorf("response: %s", resp)
+	}
+	return nil
+}
+
+// toSlackMrkdwn 将常用的 markdown 语法转换为 slack mrkdwn
+func toSlackMrkdwn(md string) string {
+	md = mdLinkRe.ReplaceAllString(md, "<$2|$1>")
+	md = mdBoldRe.ReplaceAllString(md, "*$1*")
+	md = mdHeadingRe.ReplaceAllString(md, "*$1*")
+	return md
+}
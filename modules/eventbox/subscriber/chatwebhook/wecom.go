@@ -0,0 +1,112 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chatwebhook
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// wecomMarkdownMaxBytes 企业微信 markdown 内容最长 4096 字节
+const wecomMarkdownMaxBytes = 4096
+
+// example wecom message:
+// {
+//     "msgtype": "markdown",
+//     "markdown": {"content": "### title\n**markdown**"}
+// }
+// markdown 消息不支持 @ 手机号，有需要时额外发送:
+// {
+//     "msgtype": "text",
+//     "text": {"content": "title", "mentioned_mobile_list": ["1825718XXXX", "@all"]}
+// }
+type wecomMarkdown struct {
+	Content string `json:"content"`
+}
+
+type wecomText struct {
+	Content             string   `json:"content"`
+	MentionedMobileList []string `json:"mentioned_mobile_list,omitempty"`
+}
+
+type wecomMessage struct {
+	MsgType  string         `json:"msgtype"`
+	Markdown *wecomMarkdown `json:"markdown,omitempty"`
+	Text     *wecomText     `json:"text,omitempty"`
+}
+
+type wecomRenderer struct{}
+
+func (r *wecomRenderer) Render(target apistructs.Target, m *Message) ([]interface{}, error) {
+	content := m.Content
+	if m.Title != "" {
+		content = "### " + m.Title + "\n" + content
+	}
+	content = truncateBytes(content, wecomMarkdownMaxBytes)
+	bodies := []interface{}{wecomMessage{
+		MsgType:  "markdown",
+		Markdown: &wecomMarkdown{Content: content},
+	}}
+
+	mobiles := append([]string{}, m.At.AtMobiles...)
+	if m.At.IsAtAll {
+		mobiles = append(mobiles, "@all")
+	}
+	if len(mobiles) > 0 {
+		// 文本消息内容不能为空
+		text := m.Title
+		if text == "" {
+			text = strings.SplitN(strings.TrimSpace(m.Content), "\n", 2)[0]
+		}
+		bodies = append(bodies, wecomMessage{
+			MsgType: "text",
+			Text:    &wecomText{Content: text, MentionedMobileList: mobiles},
+		})
+	}
+	return bodies, nil
+}
+
+func (r *wecomRenderer) CheckResponse(body []byte) error {
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return errors.Errorf("invalid response: %s", string(body))
+	}
+	if resp.ErrCode != 0 {
+		return errors.Errorf("errcode: %d, errmsg: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
+
+// truncateBytes 按字节截断，不截断多字节字符
+func truncateBytes(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	end := 0
+	for i := range s {
+		if i > max {
+			break
+		}
+		end = i
+	}
+	return s[:end]
+}
@@ -166,23 +166,7 @@ func (d *GroupSubscriber) Publish(dest string, content string, time int64, msg *
 				d.routeMessage(msg, &chr)
 			}
 		} else if channel.Name == "dingding" {
-			var atMobiles []string
-			if k, ok := channel.Params["atUserIDs"]; ok {
-				var users []apistructs.UserInfo
-				userIDs := strutil.Split(k, ",", true)
-				if len(userIDs) > 0 {
-					if r, err := d.bundle.ListUsers(apistructs.UserListRequest{UserIDs: userIDs, Plaintext: true}); err != nil {
-						logrus.Warnf("fail to fetch user, err: %v", err)
-					} else {
-						users = r.Users
-					}
-				}
-				for _, u := range users {
-					if u.Phone != "" {
-						atMobiles = append(atMobiles, u.Phone)
-					}
-				}
-			}
+			atMobiles, _ := d.atUsers(channel.Params)
 			var atMobilesTail string
 			if len(atMobiles) > 0 {
 				atMobilesTail = "\n\n"
@@ -238,9 +222,78 @@ func (d *GroupSubscriber) Publish(dest string, content string, time int64, msg *
 			}
 		}
 	}
+
+	// 飞书、企业微信、Slack 等机器人不依赖通知渠道配置, 通知组配置了即发送, 每次通知只发送一次
+	channel, ok := chatWebhookChannel(groupNotifyContent.Channels)
+	if !ok && len(groupDetail.ChatWebhookList) > 0 {
+		logrus.Warnf("skip chat webhooks of notify group %d, no chatwebhook or dingding channel template", groupDetail.ID)
+	}
+	if ok && len(groupDetail.ChatWebhookList) > 0 {
+		chr := *createHistoryRequest
+		chr.NotifySource.Params = channel.Params
+		chr.Channel = chatWebhookChannelName
+		atMobiles, atEmails := d.atUsers(channel.Params)
+		msg := &types.Message{
+			Content: template.Render(channel.Template, channel.Params),
+			Time:    time,
+			Labels: map[types.LabelKey]interface{}{
+				"CHATWEBHOOK": groupDetail.ChatWebhookList,
+				"MARKDOWN": map[string]string{
+					"title": template.Render(channel.Params["title"], channel.Params),
+				},
+				"AT": map[string]interface{}{
+					"atMobiles": atMobiles,
+					"atEmails":  atEmails,
+				},
+			},
+		}
+		d.routeMessage(msg, &chr)
+	}
 	return errs
 }
 
+// chatWebhookChannelName 聊天机器人专用的通知渠道, 未配置时复用钉钉的 markdown 模板
+const chatWebhookChannelName = "chatwebhook"
+
+// chatWebhookChannel 选择渲染聊天机器人消息的渠道模板: chatwebhook > dingding,
+// 邮件、短信等模板不适合发给机器人, 两者都没有时不发送
+func chatWebhookChannel(channels []apistructs.GroupNotifyChannel) (apistructs.GroupNotifyChannel, bool) {
+	for _, name := range []string{chatWebhookChannelName, "dingding"} {
+		for _, channel := range channels {
+			if channel.Name == name {
+				return channel, true
+			}
+		}
+	}
+	return apistructs.GroupNotifyChannel{}, false
+}
+
+// atUsers 获取 atUserIDs 参数中用户的手机号和邮箱
+func (d *GroupSubscriber) atUsers(params map[string]string) (atMobiles, atEmails []string) {
+	k, ok := params["atUserIDs"]
+	if !ok {
+		return
+	}
+	userIDs := strutil.Split(k, ",", true)
+	if len(userIDs) == 0 {
+		return
+	}
+	r, err := d.bundle.ListUsers(apistructs.UserListRequest{UserIDs: userIDs, Plaintext: true})
+	if err != nil {
+		logrus.Warnf("fail to fetch user, err: %v", err)
+		return
+	}
+	for _, u := range r.Users {
+		if u.Phone != "" {
+			atMobiles = append(atMobiles, u.Phone)
+		}
+		if u.Email != "" {
+			atEmails = append(atEmails, u.Email)
+		}
+	}
+	return
+}
+
 func (d *GroupSubscriber) Status() interface{} {
 	return nil
 }
@@ -0,0 +1,46 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package group
+
+import (
+	"testing"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func Test_chatWebhookChannel(t *testing.T) {
+	email := apistructs.GroupNotifyChannel{Name: "email", Template: "email"}
+	dingding := apistructs.GroupNotifyChannel{Name: "dingding", Template: "dingding"}
+	chat := apistructs.GroupNotifyChannel{Name: "chatwebhook", Template: "chatwebhook"}
+	tests := []struct {
+		name     string
+		channels []apistructs.GroupNotifyChannel
+		want     string
+		wantOk   bool
+	}{
+		{name: "no channels"},
+		{name: "chatwebhook channel first", channels: []apistructs.GroupNotifyChannel{email, dingding, chat}, want: "chatwebhook", wantOk: true},
+		{name: "fallback to dingding", channels: []apistructs.GroupNotifyChannel{email, dingding}, want: "dingding", wantOk: true},
+		{name: "no chat template", channels: []apistructs.GroupNotifyChannel{email}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := chatWebhookChannel(tt.channels)
+			if ok != tt.wantOk || got.Template != tt.want {
+				t.Errorf("chatWebhookChannel() = %v, %v, want %v, %v", got.Template, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
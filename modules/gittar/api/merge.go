@@ -349,6 +349,34 @@ func ReopenMR(ctx *webcontext.Context) {
 	ctx.Success(result)
 }
 
+func ApproveMR(ctx *webcontext.Context) {
+	id := ctx.ParamInt32("id", 0)
+	if id == 0 {
+		ctx.Abort(ERROR_ARG_ID)
+		return
+	}
+	result, err := ctx.Service.ApproveMR(ctx.Repository, ctx.User, id)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(result)
+}
+
+func UnapproveMR(ctx *webcontext.Context) {
+	id := ctx.ParamInt32("id", 0)
+	if id == 0 {
+		ctx.Abort(ERROR_ARG_ID)
+		return
+	}
+	result, err := ctx.Service.UnapproveMR(ctx.Repository, ctx.User, id)
+	if err != nil {
+		ctx.Abort(err)
+		return
+	}
+	ctx.Success(result)
+}
+
 func QueryNotes(ctx *webcontext.Context) {
 	id := ctx.ParamInt32("id", 0)
 	if id == 0 {
@@ -999,6 +999,12 @@ func GetRepoStats(context *webcontext.Context) {
 		context.Abort(err)
 		return
 	}
+	repo, err := context.Service.GetRepoById(repository.ID)
+	if err != nil {
+		context.Abort(err)
+		return
+	}
+	stats["mergeStrategy"] = repo.MergeStrategy
 	context.Success(stats)
 }
 
@@ -1028,6 +1034,22 @@ func SetLocked(context *webcontext.Context) {
 	context.Success(result)
 }
 
+// SetMergeStrategy 设置仓库合并策略
+func SetMergeStrategy(context *webcontext.Context) {
+	var request apistructs.RepoMergeStrategyRequest
+	err := context.BindJSON(&request)
+	if err != nil {
+		context.Abort(err)
+		return
+	}
+	result, err := context.Service.SetMergeStrategy(context.Repository, context.User, &request)
+	if err != nil {
+		context.Abort(err)
+		return
+	}
+	context.Success(result)
+}
+
 // GetArchive 打包下载
 func GetArchive(ctx *webcontext.Context) {
 	fileName := ctx.Param("*")
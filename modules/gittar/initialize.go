@@ -169,6 +169,7 @@ func addApiRoutes(g *echo.Group) {
 	g.DELETE("/branches/*", webcontext.WrapHandler(api.DeleteRepoBranch))
 	g.PUT("/branch/default/*", webcontext.WrapHandler(api.SetRepoDefaultBranch))
 	g.POST("/locked", webcontext.WrapHandler(api.SetLocked))
+	g.POST("/merge-strategy", webcontext.WrapHandler(api.SetMergeStrategy))
 	g.GET("/stats/*", webcontext.WrapHandler(api.GetRepoStats))
 	g.GET("/stats", webcontext.WrapHandler(api.GetRepoStats))
 	g.GET("/tags", webcontext.WrapHandler(api.GetRepoTags))
@@ -195,6 +196,8 @@ func addApiRoutes(g *echo.Group) {
 	g.POST("/merge-requests/:id/merge", webcontext.WrapHandler(api.Merge))
 	g.POST("/merge-requests/:id/close", webcontext.WrapHandler(api.CloseMR))
 	g.POST("/merge-requests/:id/reopen", webcontext.WrapHandler(api.ReopenMR))
+	g.POST("/merge-requests/:id/approve", webcontext.WrapHandler(api.ApproveMR))
+	g.POST("/merge-requests/:id/unapprove", webcontext.WrapHandler(api.UnapproveMR))
 	g.GET("/merge-requests/:id/notes", webcontext.WrapHandler(api.QueryNotes))
 	g.POST("/merge-requests/:id/notes", webcontext.WrapHandler(api.CreateNotes))
 	g.POST("/check-runs", webcontext.WrapHandler(api.CreateCheckRun))
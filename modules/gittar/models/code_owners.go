@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/gittar/pkg/gitmodule"
+)
+
+const codeOwnersMemberPageSize = 100
+
+// GetCodeOwners 根据目标分支的 CODEOWNERS 和变更文件计算 owner 的用户 ID, 不包含合并请求作者
+func (svc *Service) GetCodeOwners(repo *gitmodule.Repository, sourceCommit, targetCommit *gitmodule.Commit, authorId string) ([]string, error) {
+	codeOwners, err := targetCommit.GetCodeOwners()
+	if err != nil || codeOwners == nil {
+		return nil, err
+	}
+	paths, err := repo.GetChangedFilePaths(sourceCommit, targetCommit)
+	if err != nil {
+		return nil, err
+	}
+	owners := codeOwners.OwnersOf(paths)
+	if len(owners) == 0 {
+		return nil, nil
+	}
+	members, err := svc.listAppMembers(repo.ApplicationId)
+	if err != nil {
+		return nil, err
+	}
+	var userIds []string
+	for _, userId := range resolveCodeOwners(owners, members) {
+		if userId != authorId {
+			userIds = append(userIds, userId)
+		}
+	}
+	return userIds, nil
+}
+
+func (svc *Service) listAppMembers(appId int64) ([]apistructs.Member, error) {
+	var members []apistructs.Member
+	for pageNo := 1; ; pageNo++ {
+		page, err := svc.bundle.GetMembers(apistructs.MemberListRequest{
+			ScopeType: apistructs.AppScope,
+			ScopeID:   appId,
+			PageNo:    pageNo,
+			PageSize:  codeOwnersMemberPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, page.List...)
+		if len(page.List) < codeOwnersMemberPageSize || len(members) >= page.Total {
+			return members, nil
+		}
+	}
+}
+
+// resolveCodeOwners 将 CODEOWNERS 中的 owner 转换为应用成员的用户 ID:
+// @name 匹配用户名, 含 @ 的匹配邮箱, 其余视为用户 ID; 非应用成员忽略
+func resolveCodeOwners(owners []string, members []apistructs.Member) []string {
+	var userIds []string
+	seen := make(map[string]bool)
+	for _, owner := range owners {
+		for _, member := range members {
+			var matched bool
+			switch {
+			case strings.HasPrefix(owner, "@"):
+				matched = member.Name == strings.TrimPrefix(owner, "@")
+			case strings.Contains(owner, "@"):
+				matched = strings.EqualFold(member.Email, owner)
+			default:
+				matched = member.UserID == owner
+			}
+			if matched && !seen[member.UserID] {
+				seen[member.UserID] = true
+				userIds = append(userIds, member.UserID)
+			}
+		}
+	}
+	return userIds
+}
+
+// refreshCodeOwners 按合并请求当前的源分支和目标分支重新计算 owner, 失败时保留原值
+func (svc *Service) refreshCodeOwners(repo *gitmodule.Repository, mergeRequest *MergeRequest) string {
+	sourceCommit, err := repo.GetCommit(mergeRequest.SourceSha)
+	if err != nil {
+		logrus.Warnf("failed to get source commit of merge request %d, err: %v", mergeRequest.ID, err)
+		return mergeRequest.CodeOwners
+	}
+	targetCommit, err := repo.GetBranchCommit(mergeRequest.TargetBranch)
+	if err != nil {
+		logrus.Warnf("failed to get target commit of merge request %d, err: %v", mergeRequest.ID, err)
+		return mergeRequest.CodeOwners
+	}
+	codeOwners, err := svc.GetCodeOwners(repo, sourceCommit, targetCommit, mergeRequest.AuthorId)
+	if err != nil {
+		logrus.Warnf("failed to get code owners of merge request %d, err: %v", mergeRequest.ID, err)
+		return mergeRequest.CodeOwners
+	}
+	return strings.Join(codeOwners, ",")
+}
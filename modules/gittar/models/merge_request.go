@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/gorm"
@@ -27,6 +28,7 @@ import (
 	"github.com/erda-project/erda/modules/gittar/pkg/gitmodule"
 	"github.com/erda-project/erda/modules/gittar/uc"
 	"github.com/erda-project/erda/modules/pkg/diceworkspace"
+	"github.com/erda-project/erda/pkg/strutil"
 )
 
 var (
@@ -40,6 +42,27 @@ var (
 	MERGE_STATUS_NO_CONFLICT = "no_conflict"
 )
 
+// MergeStrategy decides how the source branch is merged into the target branch
+type MergeStrategy string
+
+var (
+	// MergeStrategyMerge creates a merge commit
+	MergeStrategyMerge MergeStrategy = "merge"
+	// MergeStrategySquash squashes all commits into one commit on top of the target branch
+	MergeStrategySquash MergeStrategy = "squash"
+	// MergeStrategyRebase replays commits on top of the target branch and fast-forward
+	MergeStrategyRebase MergeStrategy = "rebase"
+)
+
+func (s MergeStrategy) Valid() bool {
+	switch s {
+	case MergeStrategyMerge, MergeStrategySquash, MergeStrategyRebase:
+		return true
+	default:
+		return false
+	}
+}
+
 type BatchSearchMrRequest struct {
 	Conditions []BatchSearchMrCondition `json:"conditions"`
 }
@@ -64,6 +87,8 @@ type MergeQueryCondition struct {
 type MergeOptions struct {
 	RemoveSourceBranch bool   `json:"removeSourceBranch"`
 	CommitMessage      string `json:"CommitMessage"`
+	// MergeStrategy merge, squash or rebase, default is the repo's merge strategy
+	MergeStrategy MergeStrategy `json:"mergeStrategy"`
 }
 
 //MergeRequest model
@@ -88,8 +113,9 @@ type MergeRequest struct {
 	UpdatedAt          *time.Time
 	MergeAt            *time.Time
 	CloseAt            *time.Time
-	Score              int `gorm:"size:150;index:idx_score"`
-	ScoreNum           int `gorm:"size:150;index:idx_score_num"`
+	Score              int    `gorm:"size:150;index:idx_score"`
+	ScoreNum           int    `gorm:"size:150;index:idx_score_num"`
+	CodeOwners         string `gorm:"size:1024"` // 逗号分隔的用户 ID
 }
 
 type MrCheckRun struct {
@@ -123,6 +149,7 @@ func (mergeRequest *MergeRequest) ToInfo(repo *gitmodule.Repository) *apistructs
 	result.AppID = repo.ApplicationId
 	result.Score = mergeRequest.Score
 	result.ScoreNum = mergeRequest.ScoreNum
+	result.CodeOwners = strutil.Split(mergeRequest.CodeOwners, ",", true)
 
 	if mergeRequest.SourceBranch != "" && mergeRequest.TargetBranch != "" {
 		result.DefaultCommitMessage = fmt.Sprintf("Merge branch '%s' into '%s'", mergeRequest.SourceBranch, mergeRequest.TargetBranch)
@@ -189,6 +216,15 @@ func (svc *Service) CreateMergeRequest(repo *gitmodule.Repository, user *User, i
 		return nil, err
 	}
 
+	// CODEOWNERS 中的 owner 自动作为评审人, 未指定 assignee 时分配给第一位 owner
+	codeOwners, err := svc.GetCodeOwners(repo, sourceCommit, targetCommit, user.Id)
+	if err != nil {
+		logrus.Warnf("failed to get code owners, repo: %s, err: %v", repo.Path, err)
+	}
+	if info.AssigneeId == "" && len(codeOwners) > 0 {
+		info.AssigneeId = codeOwners[0]
+	}
+
 	mergeRequest := MergeRequest{
 		RepoID:             repo.ID,
 		Title:              info.Title,
@@ -202,6 +238,7 @@ func (svc *Service) CreateMergeRequest(repo *gitmodule.Repository, user *User, i
 		TargetSha:          targetCommit.ID,
 		RemoveSourceBranch: info.RemoveSourceBranch,
 		RepoMergeId:        lastMr.RepoMergeId + 1,
+		CodeOwners:         strings.Join(codeOwners, ","),
 	}
 	err = svc.db.Create(&mergeRequest).Error
 	if err != nil {
@@ -297,6 +334,10 @@ func (svc *Service) GetMergeRequestDetail(repo *gitmodule.Repository, mergeId in
 		}
 	}
 	result := mergeRequest.ToInfo(repo)
+	result.ApproverIds, err = svc.GetMRApproverIds(mergeRequest.ID)
+	if err != nil {
+		return nil, err
+	}
 	result.IsCheckRunValid, err = svc.IsCheckRunsValid(repo, mergeRequest.ID)
 	return result, err
 }
@@ -311,6 +352,12 @@ func (svc *Service) SyncMergeRequest(repo *gitmodule.Repository, branch string,
 	for _, mergeRequest := range mergeRequests {
 		flag := (mergeRequest.SourceSha != commitID)
 		mergeRequest.SourceSha = commitID
+		if flag {
+			mergeRequest.CodeOwners = svc.refreshCodeOwners(repo, &mergeRequest)
+			if err := svc.resetMRApprovals(mergeRequest.ID); err != nil {
+				return err
+			}
+		}
 		err := svc.db.Save(&mergeRequest).Error
 		if err != nil {
 			return err
@@ -438,6 +485,11 @@ func (svc *Service) Merge(repo *gitmodule.Repository, user *User, mergeId int, m
 		return nil, errors.New("has conflict")
 	}
 
+	err = svc.checkMergeProtection(repo, &mergeRequest)
+	if err != nil {
+		return nil, err
+	}
+
 	if repo.IsProtectBranch(mergeRequest.TargetBranch) ||
 		(repo.IsProtectBranch(mergeRequest.SourceBranch) && mergeRequest.RemoveSourceBranch) {
 		err = svc.CheckPermission(repo, user, PermissionPushProtectBranch, nil)
@@ -446,15 +498,31 @@ func (svc *Service) Merge(repo *gitmodule.Repository, user *User, mergeId int, m
 		}
 	}
 
+	strategy, err := svc.getMergeStrategy(repo, mergeOptions.MergeStrategy)
+	if err != nil {
+		return nil, err
+	}
+
 	if mergeOptions.CommitMessage == "" {
 		mergeOptions.CommitMessage = fmt.Sprintf("Merge branch '%s' into '%s'", mergeRequest.SourceBranch, mergeRequest.TargetBranch)
+		if strategy == MergeStrategySquash {
+			mergeOptions.CommitMessage = mergeRequest.Title
+		}
 	}
 	_, err = repo.GetBranchCommit(mergeRequest.SourceBranch)
 	if err != nil {
 		return nil, err
 	}
 
-	commit, err := repo.Merge(mergeRequest.SourceBranch, mergeRequest.TargetBranch, user.ToGitSignature(), mergeOptions.CommitMessage)
+	var commit *gitmodule.Commit
+	switch strategy {
+	case MergeStrategySquash:
+		commit, err = repo.Squash(mergeRequest.SourceBranch, mergeRequest.TargetBranch, user.ToGitSignature(), mergeOptions.CommitMessage)
+	case MergeStrategyRebase:
+		commit, err = repo.Rebase(mergeRequest.SourceBranch, mergeRequest.TargetBranch, user.ToGitSignature())
+	default:
+		commit, err = repo.Merge(mergeRequest.SourceBranch, mergeRequest.TargetBranch, user.ToGitSignature(), mergeOptions.CommitMessage)
+	}
 
 	now := time.Now()
 	if err == nil {
@@ -477,6 +545,28 @@ func (svc *Service) Merge(repo *gitmodule.Repository, user *User, mergeId int, m
 	return commit, nil
 }
 
+// getMergeStrategy returns the strategy used to merge, the repo's merge strategy is enforced if set
+func (svc *Service) getMergeStrategy(repo *gitmodule.Repository, strategy MergeStrategy) (MergeStrategy, error) {
+	if strategy != "" && !strategy.Valid() {
+		return "", fmt.Errorf("invalid merge strategy: %s", strategy)
+	}
+	repoModel, err := svc.GetRepoById(repo.ID)
+	if err != nil {
+		return "", err
+	}
+	repoStrategy := MergeStrategy(repoModel.MergeStrategy)
+	if repoStrategy == "" {
+		if strategy == "" {
+			return MergeStrategyMerge, nil
+		}
+		return strategy, nil
+	}
+	if strategy != "" && strategy != repoStrategy {
+		return "", fmt.Errorf("merge strategy %s is not allowed, repo only allows %s", strategy, repoStrategy)
+	}
+	return repoStrategy, nil
+}
+
 func (svc *Service) CloseMR(repo *gitmodule.Repository, user *User, mergeId int) (*apistructs.MergeRequestInfo, error) {
 	var mergeRequest MergeRequest
 	err := svc.db.Where("repo_id=? and repo_merge_id=?", repo.ID, mergeId).First(&mergeRequest).Error
@@ -0,0 +1,192 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/gittar/pkg/gitmodule"
+	"github.com/erda-project/erda/modules/pkg/diceworkspace"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// MergeRequestApproval 合并请求审批记录
+type MergeRequestApproval struct {
+	ID        int64
+	RepoID    int64  `gorm:"index:idx_repo_id"`
+	MergeID   int64  `gorm:"index:idx_merge_id"` // MergeRequest.ID
+	UserID    string `gorm:"size:150"`
+	CreatedAt time.Time
+}
+
+// ApproveMR 审批通过合并请求, 作者不能审批自己的合并请求
+func (svc *Service) ApproveMR(repo *gitmodule.Repository, user *User, mergeId int) (*apistructs.MergeRequestInfo, error) {
+	var mergeRequest MergeRequest
+	err := svc.db.Where("repo_id=? and repo_merge_id=?", repo.ID, mergeId).First(&mergeRequest).Error
+	if err != nil {
+		return nil, err
+	}
+	if mergeRequest.State != MERGE_REQUEST_OPEN {
+		return nil, errors.New(mergeRequest.State + " 状态无法审批")
+	}
+	if mergeRequest.AuthorId == user.Id {
+		return nil, errors.New("can not approve your own merge request")
+	}
+	err = svc.CheckPermission(repo, user, PermissionApproveMR, getMrUserRole(mergeRequest, user.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	var count int
+	err = svc.db.Model(&MergeRequestApproval{}).
+		Where("merge_id=? and user_id=?", mergeRequest.ID, user.Id).Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		err = svc.db.Create(&MergeRequestApproval{
+			RepoID:    repo.ID,
+			MergeID:   mergeRequest.ID,
+			UserID:    user.Id,
+			CreatedAt: time.Now(),
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return svc.GetMergeRequestDetail(repo, mergeId)
+}
+
+// UnapproveMR 撤销审批
+func (svc *Service) UnapproveMR(repo *gitmodule.Repository, user *User, mergeId int) (*apistructs.MergeRequestInfo, error) {
+	var mergeRequest MergeRequest
+	err := svc.db.Where("repo_id=? and repo_merge_id=?", repo.ID, mergeId).First(&mergeRequest).Error
+	if err != nil {
+		return nil, err
+	}
+	if mergeRequest.State != MERGE_REQUEST_OPEN {
+		return nil, errors.New(mergeRequest.State + " 状态无法撤销审批")
+	}
+	err = svc.db.Where("merge_id=? and user_id=?", mergeRequest.ID, user.Id).Delete(&MergeRequestApproval{}).Error
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetMergeRequestDetail(repo, mergeId)
+}
+
+// resetMRApprovals 源分支有新提交时清空审批, 需要重新审批
+func (svc *Service) resetMRApprovals(mergeRequestID int64) error {
+	return svc.db.Where("merge_id=?", mergeRequestID).Delete(&MergeRequestApproval{}).Error
+}
+
+// GetMRApproverIds 返回已审批的用户 ID, 按审批时间排序
+func (svc *Service) GetMRApproverIds(mergeRequestID int64) ([]string, error) {
+	var approvals []MergeRequestApproval
+	err := svc.db.Where("merge_id=?", mergeRequestID).Order("id").Find(&approvals).Error
+	if err != nil {
+		return nil, err
+	}
+	approverIds := make([]string, 0, len(approvals))
+	for _, approval := range approvals {
+		approverIds = append(approverIds, approval.UserID)
+	}
+	return approverIds, nil
+}
+
+// checkMergeProtection 校验目标分支规则中的审批人数、必须审批人以及必须成功的 check-run
+func (svc *Service) checkMergeProtection(repo *gitmodule.Repository, mergeRequest *MergeRequest) error {
+	rules, err := svc.bundle.GetAppBranchRules(uint64(repo.ApplicationId))
+	if err != nil {
+		return fmt.Errorf("failed to get branch rules: %v", err)
+	}
+	protection := diceworkspace.GetValidBranchByGitReference(mergeRequest.TargetBranch, rules).MergeProtection
+	if protection.IsEmpty() {
+		return nil
+	}
+
+	approverIds, err := svc.GetMRApproverIds(mergeRequest.ID)
+	if err != nil {
+		return err
+	}
+	var checkRuns []CheckRun
+	if len(protection.RequiredChecks) > 0 {
+		err = svc.db.Where("repo_id=? and mr_id=? and commit=?", repo.ID, mergeRequest.RepoMergeId, mergeRequest.SourceSha).
+			Order("id").Find(&checkRuns).Error
+		if err != nil {
+			return err
+		}
+	}
+	var codeOwners []string
+	if protection.RequireCodeOwnerApproval {
+		codeOwners = strutil.Split(svc.refreshCodeOwners(repo, mergeRequest), ",", true)
+	}
+	return validateMergeProtection(protection, mergeRequest.AuthorId, approverIds, codeOwners, checkRuns)
+}
+
+func validateMergeProtection(protection apistructs.MergeProtection, authorId string, approverIds, codeOwners []string,
+	checkRuns []CheckRun) error {
+	approved := make(map[string]bool, len(approverIds))
+	for _, id := range approverIds {
+		approved[id] = true
+	}
+	if len(approved) < protection.MinApprovals {
+		return fmt.Errorf("at least %d approvals are required, got %d", protection.MinApprovals, len(approved))
+	}
+
+	var missingApprovers []string
+	for _, id := range protection.RequiredApprovers {
+		// 作者无法审批自己的合并请求, 跳过
+		if id != authorId && !approved[id] {
+			missingApprovers = append(missingApprovers, id)
+		}
+	}
+	if len(missingApprovers) > 0 {
+		return fmt.Errorf("approvals from required reviewers are missing: %s", strings.Join(missingApprovers, ","))
+	}
+
+	if protection.RequireCodeOwnerApproval && len(codeOwners) > 0 {
+		var ownerApproved bool
+		for _, id := range codeOwners {
+			if approved[id] {
+				ownerApproved = true
+				break
+			}
+		}
+		if !ownerApproved {
+			return fmt.Errorf("approval from code owners is required: %s", strings.Join(codeOwners, ","))
+		}
+	}
+
+	// 同名 check-run 以最新一次为准
+	latest := make(map[string]CheckRun, len(checkRuns))
+	for _, run := range checkRuns {
+		latest[run.Name] = run
+	}
+	var failedChecks []string
+	for _, name := range protection.RequiredChecks {
+		run, ok := latest[name]
+		if !ok || run.Status != apistructs.CheckRunStatusCompleted || run.Result != apistructs.CheckRunResultSuccess {
+			failedChecks = append(failedChecks, name)
+		}
+	}
+	if len(failedChecks) > 0 {
+		return fmt.Errorf("required checks have not succeeded: %s", strings.Join(failedChecks, ","))
+	}
+	return nil
+}
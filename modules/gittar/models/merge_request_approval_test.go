@@ -0,0 +1,112 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func Test_validateMergeProtection(t *testing.T) {
+	success := CheckRun{Name: "test", Status: apistructs.CheckRunStatusCompleted, Result: apistructs.CheckRunResultSuccess}
+	failure := CheckRun{Name: "test", Status: apistructs.CheckRunStatusCompleted, Result: apistructs.CheckRunResultFailure}
+	running := CheckRun{Name: "test", Status: apistructs.CheckRunStatusInProgress}
+	tests := []struct {
+		name        string
+		protection  apistructs.MergeProtection
+		approverIds []string
+		codeOwners  []string
+		checkRuns   []CheckRun
+		wantErr     bool
+	}{
+		{
+			name: "no protection",
+		},
+		{
+			name:        "enough approvals",
+			protection:  apistructs.MergeProtection{MinApprovals: 2},
+			approverIds: []string{"1", "2"},
+		},
+		{
+			name:        "duplicate approvals are counted once",
+			protection:  apistructs.MergeProtection{MinApprovals: 2},
+			approverIds: []string{"1", "1"},
+			wantErr:     true,
+		},
+		{
+			name:        "missing required approver",
+			protection:  apistructs.MergeProtection{RequiredApprovers: []string{"1", "3"}},
+			approverIds: []string{"1", "2"},
+			wantErr:     true,
+		},
+		{
+			name:       "author in required approvers is skipped",
+			protection: apistructs.MergeProtection{RequiredApprovers: []string{"author"}},
+		},
+		{
+			name:        "code owner approved",
+			protection:  apistructs.MergeProtection{RequireCodeOwnerApproval: true},
+			approverIds: []string{"2"},
+			codeOwners:  []string{"1", "2"},
+		},
+		{
+			name:        "code owner not approved",
+			protection:  apistructs.MergeProtection{RequireCodeOwnerApproval: true},
+			approverIds: []string{"3"},
+			codeOwners:  []string{"1", "2"},
+			wantErr:     true,
+		},
+		{
+			name:       "no code owners",
+			protection: apistructs.MergeProtection{RequireCodeOwnerApproval: true},
+		},
+		{
+			name:       "required check succeeded",
+			protection: apistructs.MergeProtection{RequiredChecks: []string{"test"}},
+			checkRuns:  []CheckRun{success},
+		},
+		{
+			name:       "required check missing",
+			protection: apistructs.MergeProtection{RequiredChecks: []string{"test"}},
+			wantErr:    true,
+		},
+		{
+			name:       "latest check run failed",
+			protection: apistructs.MergeProtection{RequiredChecks: []string{"test"}},
+			checkRuns:  []CheckRun{success, failure},
+			wantErr:    true,
+		},
+		{
+			name:       "latest check run in progress",
+			protection: apistructs.MergeProtection{RequiredChecks: []string{"test"}},
+			checkRuns:  []CheckRun{success, running},
+			wantErr:    true,
+		},
+		{
+			name:       "latest check run succeeded after failure",
+			protection: apistructs.MergeProtection{RequiredChecks: []string{"test"}},
+			checkRuns:  []CheckRun{failure, success},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMergeProtection(tt.protection, "author", tt.approverIds, tt.codeOwners, tt.checkRuns)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMergeProtection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ package models
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path"
 	"strconv"
@@ -45,6 +46,8 @@ type Repo struct {
 	Size        int64
 	IsExternal  bool
 	Config      string
+	// MergeStrategy enforced merge strategy of merge requests, empty means any strategy is allowed
+	MergeStrategy string `gorm:"size:50"`
 }
 
 func (Repo) TableName() string {
@@ -141,6 +144,21 @@ func (svc *Service) SetLocked(repo *gitmodule.Repository, user *User, info *apis
 	return info, nil
 }
 
+func (svc *Service) SetMergeStrategy(repo *gitmodule.Repository, user *User, info *apistructs.RepoMergeStrategyRequest) (*apistructs.RepoMergeStrategyRequest, error) {
+	if err := svc.CheckPermission(repo, user, PermissionRepoSetting, nil); err != nil {
+		return nil, err
+	}
+	if info.MergeStrategy != "" && !MergeStrategy(info.MergeStrategy).Valid() {
+		return nil, fmt.Errorf("invalid merge strategy: %s", info.MergeStrategy)
+	}
+
+	err := svc.db.Table("dice_repos").Where("id = ?", repo.ID).Update("merge_strategy", info.MergeStrategy).Error
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
 func (svc *Service) DeleteRepo(repo *Repo) error {
 	repoPath := repo.DiskPath()
 	logrus.Infof("remove gitRepo %v", repoPath)
@@ -0,0 +1,140 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitmodule
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// CodeOwnersPaths CODEOWNERS 文件的查找路径, 使用第一个存在的文件
+var CodeOwnersPaths = []string{"CODEOWNERS", ".erda/CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwnersRule CODEOWNERS 中的一行规则
+type CodeOwnersRule struct {
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// CodeOwners 按 gitignore 风格匹配文件路径, 后出现的规则优先
+//
+//	# comment
+//	*                @default-owner
+//	/docs/           doc@example.com
+//	modules/**/*.go  @alice @bob
+type CodeOwners struct {
+	Rules []*CodeOwnersRule
+}
+
+// ParseCodeOwners 解析 CODEOWNERS 文件
+func ParseCodeOwners(r io.Reader) (*CodeOwners, error) {
+	codeOwners := &CodeOwners{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := compileCodeOwnersPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %s: %v", lineNo, fields[0], err)
+		}
+		codeOwners.Rules = append(codeOwners.Rules, &CodeOwnersRule{
+			Pattern: fields[0],
+			Owners:  fields[1:],
+			re:      re,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return codeOwners, nil
+}
+
+// Match 返回文件路径对应的 owner, 未匹配时返回 nil
+func (co *CodeOwners) Match(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(co.Rules) - 1; i >= 0; i-- {
+		if co.Rules[i].re.MatchString(path) {
+			return co.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// OwnersOf 返回一组文件路径对应的 owner, 按出现顺序去重
+func (co *CodeOwners) OwnersOf(paths []string) []string {
+	var owners []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		for _, owner := range co.Match(path) {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// compileCodeOwnersPattern 将 gitignore 风格的 pattern 转换为正则:
+// 以 / 开头或中间含 / 的 pattern 从仓库根目录匹配, 否则匹配任意层级;
+// 匹配到目录时同时匹配目录下的所有文件
+func compileCodeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// **/ 匹配零或多级目录
+					i++
+					expr.WriteString("(?:.*/)?")
+				} else {
+					expr.WriteString(".*")
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("(?:/.*)?$")
+	return regexp.Compile(expr.String())
+}
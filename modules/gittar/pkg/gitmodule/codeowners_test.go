@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitmodule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOwners(t *testing.T) {
+	codeOwners, err := ParseCodeOwners(strings.NewReader(`
+# default owners
+*                   @default
+/docs/              doc@erda.cloud
+apistructs          @api   # inline comment
+modules/**/*.go     @alice @bob
+/modules/gittar/    @gittar
+*.md
+`))
+	assert.NoError(t, err)
+	assert.Len(t, codeOwners.Rules, 6)
+
+	cases := map[string][]string{
+		"main.go":                      {"@default"},
+		"docs/index.html":              {"doc@erda.cloud"},
+		"sub/docs/index.html":          {"@default"},
+		"apistructs/gittar.go":         {"@api"},
+		"modules/apistructs/a.go":      {"@alice", "@bob"},
+		"modules/dop/a.go":             {"@alice", "@bob"},
+		"modules/dop/model/a.go":       {"@alice", "@bob"},
+		"modules/gittar/models/mr.go":  {"@gittar"},
+		"modules/gittar/README.md":     {},
+		"modules/dop/conf/config.yaml": {"@default"},
+	}
+	for path, owners := range cases {
+		assert.Equal(t, owners, codeOwners.Match(path), path)
+	}
+
+	assert.Equal(t, []string{"@default", "@alice", "@bob", "@gittar"},
+		codeOwners.OwnersOf([]string{"main.go", "modules/dop/a.go", "modules/dop/b.go", "modules/gittar/api/a.go"}))
+}
+
+func TestParseCodeOwners_InvalidPattern(t *testing.T) {
+	_, err := ParseCodeOwners(strings.NewReader("/ @root"))
+	assert.Error(t, err)
+}
@@ -194,6 +194,22 @@ func (c *Commit) GetFilesChangedSinceCommit(pastCommit string) ([]string, error)
 	return c.Repo.getFilesChanged(pastCommit, c.ID)
 }
 
+// GetCodeOwners 读取 commit 中的 CODEOWNERS 文件, 不存在时返回 nil
+func (c *Commit) GetCodeOwners() (*CodeOwners, error) {
+	for _, path := range CodeOwnersPaths {
+		entry, err := c.GetTreeEntryByPath(path)
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		rd, err := entry.Blob().Data()
+		if err != nil {
+			return nil, err
+		}
+		return ParseCodeOwners(rd)
+	}
+	return nil, nil
+}
+
 func (c *Commit) GetSubModules() (*objectCache, error) {
 	if c.submoduleCache != nil {
 		return c.submoduleCache, nil
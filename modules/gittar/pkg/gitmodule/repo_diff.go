@@ -184,6 +184,58 @@ func (repo *Repository) GetDiffFile(newCommit *Commit, oldCommit *Commit, oldPat
 
 }
 
+// GetChangedFilePaths 返回 newCommit 相对于与 oldCommit 合并基点的变更文件路径, 重命名时同时包含新旧路径
+func (repo *Repository) GetChangedFilePaths(newCommit *Commit, oldCommit *Commit) ([]string, error) {
+	baseCommit, err := repo.GetMergeBase(newCommit, oldCommit)
+	if err != nil {
+		return nil, err
+	}
+	rawRepo, err := repo.GetRawRepo()
+	if err != nil {
+		return nil, err
+	}
+	oidOld, _ := git.NewOid(baseCommit.TreeSha)
+	treeOld, err := rawRepo.LookupTree(oidOld)
+	if err != nil {
+		return nil, err
+	}
+	oidNew, _ := git.NewOid(newCommit.TreeSha)
+	treeNew, err := rawRepo.LookupTree(oidNew)
+	if err != nil {
+		return nil, err
+	}
+	options, _ := git.DefaultDiffOptions()
+	diff, err := rawRepo.DiffTreeToTree(treeOld, treeNew, &options)
+	if err != nil {
+		return nil, err
+	}
+	defer diff.Free()
+	findOptions, err := git.DefaultDiffFindOptions()
+	if err != nil {
+		return nil, err
+	}
+	err = diff.FindSimilar(&findOptions)
+	if err != nil {
+		return nil, err
+	}
+	num, err := diff.NumDeltas()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for i := 0; i < num; i++ {
+		delta, err := diff.Delta(i)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, delta.NewFile.Path)
+		if delta.OldFile.Path != delta.NewFile.Path {
+			paths = append(paths, delta.OldFile.Path)
+		}
+	}
+	return paths, nil
+}
+
 func (repo *Repository) GetDiff(newCommit *Commit, oldCommit *Commit) (*Diff, error) {
 	return repo.GetDiffWithOptions(newCommit, oldCommit, NewDefaultDiffOptions().SetShowAll(true))
 }
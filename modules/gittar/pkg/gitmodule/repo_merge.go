@@ -18,6 +18,8 @@ package gitmodule
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	git "github.com/libgit2/git2go/v30"
 )
@@ -228,3 +230,161 @@ func (repo *Repository) Merge(ourBranch string, theirBranch string, signature *S
 	return repo.GetCommit(newOid.String())
 
 }
+
+// Squash merges ourBranch into theirBranch with a single commit, the new commit has only one parent.
+func (repo *Repository) Squash(ourBranch string, theirBranch string, signature *Signature, message string) (*Commit, error) {
+	info, err := repo.getMergeInfo(ourBranch, theirBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	rawRepo, err := repo.GetRawRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := git.DefaultMergeOptions()
+	if err != nil {
+		return nil, err
+	}
+	index, err := rawRepo.MergeTrees(info.BaseTree, info.OurTree, info.TheirTree, &options)
+	if err != nil {
+		return nil, err
+	}
+	if index.HasConflicts() {
+		return nil, errors.New("has conflict")
+	}
+	newTreeOid, err := index.WriteTreeTo(rawRepo)
+	if err != nil {
+		return nil, err
+	}
+	newTree, err := rawRepo.LookupTree(newTreeOid)
+	if err != nil {
+		return nil, err
+	}
+
+	parentCommit, err := rawRepo.LookupCommit(info.TheirCommit.Git2Oid())
+	if err != nil {
+		return nil, err
+	}
+
+	sig := &git.Signature{
+		Name:  signature.Name,
+		Email: signature.Email,
+		When:  signature.When,
+	}
+	newOid, err := rawRepo.CreateCommit(BRANCH_PREFIX+theirBranch, sig, sig, message, newTree, parentCommit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetCommit(newOid.String())
+}
+
+// Rebase replays commits of ourBranch on top of theirBranch, then fast-forward theirBranch to the last replayed commit.
+// Only first-parent history of ourBranch is replayed, a merge commit is replayed as its change against the first parent,
+// so conflict resolutions in merge commits are kept. Authors of the replayed commits are kept.
+func (repo *Repository) Rebase(ourBranch string, theirBranch string, signature *Signature) (*Commit, error) {
+	info, err := repo.getMergeInfo(ourBranch, theirBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	rawRepo, err := repo.GetRawRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	// theirBranch not changed since ourBranch created, just fast-forward
+	if info.BaseCommit.ID == info.TheirCommit.ID {
+		if err := updateBranch(rawRepo, theirBranch, info.TheirCommit.ID, info.OurCommit.Git2Oid(), "rebase: fast-forward"); err != nil {
+			return nil, err
+		}
+		return info.OurCommit, nil
+	}
+
+	stdout, err := NewCommand("rev-list", "--reverse", "--first-parent", info.BaseCommit.ID+".."+info.OurCommit.ID).RunInDirBytes(repo.DiskPath())
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := git.DefaultMergeOptions()
+	if err != nil {
+		return nil, err
+	}
+	sig := &git.Signature{
+		Name:  signature.Name,
+		Email: signature.Email,
+		When:  signature.When,
+	}
+
+	current, err := rawRepo.LookupCommit(info.TheirCommit.Git2Oid())
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range strings.Fields(string(stdout)) {
+		oid, err := git.NewOid(id)
+		if err != nil {
+			return nil, err
+		}
+		commit, err := rawRepo.LookupCommit(oid)
+		if err != nil {
+			return nil, err
+		}
+		commitTree, err := commit.Tree()
+		if err != nil {
+			return nil, err
+		}
+		parentTree, err := commit.Parent(0).Tree()
+		if err != nil {
+			return nil, err
+		}
+		currentTree, err := current.Tree()
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := rawRepo.MergeTrees(parentTree, currentTree, commitTree, &options)
+		if err != nil {
+			return nil, err
+		}
+		if index.HasConflicts() {
+			return nil, fmt.Errorf("has conflict when rebase commit %s", id)
+		}
+		newTreeOid, err := index.WriteTreeTo(rawRepo)
+		if err != nil {
+			return nil, err
+		}
+		newTree, err := rawRepo.LookupTree(newTreeOid)
+		if err != nil {
+			return nil, err
+		}
+
+		newOid, err := rawRepo.CreateCommit("", commit.Author(), sig, commit.Message(), newTree, current)
+		if err != nil {
+			return nil, err
+		}
+		current, err = rawRepo.LookupCommit(newOid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := updateBranch(rawRepo, theirBranch, info.TheirCommit.ID, current.Id(), "rebase: "+ourBranch); err != nil {
+		return nil, err
+	}
+	return repo.GetCommit(current.Id().String())
+}
+
+// updateBranch moves branch to target only if the branch still points to oldCommitID,
+// libgit2 checks the old value again when writing the reference, so concurrent pushes are not overwritten.
+func updateBranch(rawRepo *git.Repository, branch string, oldCommitID string, target *git.Oid, msg string) error {
+	ref, err := rawRepo.References.Lookup(BRANCH_PREFIX + branch)
+	if err != nil {
+		return err
+	}
+	if ref.Target() == nil || ref.Target().String() != oldCommitID {
+		return fmt.Errorf("branch %s has been updated, please retry", branch)
+	}
+	_, err = ref.SetTarget(target, msg)
+	return err
+}
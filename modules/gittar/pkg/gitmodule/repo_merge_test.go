@@ -0,0 +1,221 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !codeanalysis
+
+package gitmodule
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/libgit2/git2go/v30"
+)
+
+func createMergeTestRepo(t *testing.T) (*Repository, *git.Repository) {
+	rootPath, err := ioutil.TempDir("", "merge")
+	checkFatal(t, err)
+	rawRepo, err := git.InitRepository(filepath.Join(rootPath, "repo.git"), true)
+	checkFatal(t, err)
+	repo, err := OpenRepository(rootPath, "repo.git")
+	checkFatal(t, err)
+	return repo, rawRepo
+}
+
+func cleanupMergeTestRepo(t *testing.T, repo *Repository, rawRepo *git.Repository) {
+	rawRepo.Free()
+	checkFatal(t, os.RemoveAll(repo.Root()))
+}
+
+func testSignature(name string) *git.Signature {
+	return &git.Signature{
+		Name:  name,
+		Email: name + "@erda.cloud",
+		When:  time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+// commitFiles creates a commit whose tree contains exactly the given files
+func commitFiles(t *testing.T, rawRepo *git.Repository, author string, files map[string]string, msg string, parents ...*git.Commit) *git.Commit {
+	builder, err := rawRepo.TreeBuilder()
+	checkFatal(t, err)
+	defer builder.Free()
+	for name, content := range files {
+		blobOid, err := rawRepo.CreateBlobFromBuffer([]byte(content))
+		checkFatal(t, err)
+		checkFatal(t, builder.Insert(name, blobOid, git.FilemodeBlob))
+	}
+	treeOid, err := builder.Write()
+	checkFatal(t, err)
+	tree, err := rawRepo.LookupTree(treeOid)
+	checkFatal(t, err)
+	sig := testSignature(author)
+	oid, err := rawRepo.CreateCommit("", sig, sig, msg, tree, parents...)
+	checkFatal(t, err)
+	commit, err := rawRepo.LookupCommit(oid)
+	checkFatal(t, err)
+	return commit
+}
+
+func setBranch(t *testing.T, rawRepo *git.Repository, branch string, commit *git.Commit) {
+	_, err := rawRepo.References.Create(BRANCH_PREFIX+branch, commit.Id(), true, "")
+	checkFatal(t, err)
+}
+
+func lookupBranch(t *testing.T, rawRepo *git.Repository, branch string) *git.Commit {
+	ref, err := rawRepo.References.Lookup(BRANCH_PREFIX + branch)
+	checkFatal(t, err)
+	commit, err := rawRepo.LookupCommit(ref.Target())
+	checkFatal(t, err)
+	return commit
+}
+
+func assertFiles(t *testing.T, rawRepo *git.Repository, commit *git.Commit, files map[string]string) {
+	tree, err := commit.Tree()
+	checkFatal(t, err)
+	if int(tree.EntryCount()) != len(files) {
+		t.Fatalf("tree has %d entries, want %d", tree.EntryCount(), len(files))
+	}
+	for name, content := range files {
+		entry, err := tree.EntryByPath(name)
+		checkFatal(t, err)
+		blob, err := rawRepo.LookupBlob(entry.Id)
+		checkFatal(t, err)
+		if string(blob.Contents()) != content {
+			t.Fatalf("file %s is %q, want %q", name, blob.Contents(), content)
+		}
+	}
+}
+
+func TestRepository_Squash(t *testing.T) {
+	repo, rawRepo := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(t, repo, rawRepo)
+
+	base := commitFiles(t, rawRepo, "dev", map[string]string{"a.txt": "a"}, "init")
+	master := commitFiles(t, rawRepo, "dev", map[string]string{"a.txt": "a", "c.txt": "c"}, "add c", base)
+	f1 := commitFiles(t, rawRepo, "alice", map[string]string{"a.txt": "a", "b.txt": "b"}, "add b", base)
+	f2 := commitFiles(t, rawRepo, "alice", map[string]string{"a.txt": "a2", "b.txt": "b"}, "update a", f1)
+	setBranch(t, rawRepo, "master", master)
+	setBranch(t, rawRepo, "feature", f2)
+
+	result, err := repo.Squash("feature", "master", &Signature{Name: "merger", Email: "merger@erda.cloud", When: time.Now()}, "squash feature")
+	checkFatal(t, err)
+
+	head := lookupBranch(t, rawRepo, "master")
+	if head.Id().String() != result.ID {
+		t.Fatalf("master is %s, want %s", head.Id(), result.ID)
+	}
+	if head.ParentCount() != 1 || head.Parent(0).Id().String() != master.Id().String() {
+		t.Fatalf("squash commit should have the only parent %s", master.Id())
+	}
+	assertFiles(t, rawRepo, head, map[string]string{"a.txt": "a2", "b.txt": "b", "c.txt": "c"})
+}
+
+func TestRepository_Rebase(t *testing.T) {
+	repo, rawRepo := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(t, repo, rawRepo)
+
+	base := commitFiles(t, rawRepo, "dev", map[string]string{"a.txt": "a"}, "init")
+	master := commitFiles(t, rawRepo, "dev", map[string]string{"a.txt": "a", "c.txt": "c"}, "add c", base)
+	f1 := commitFiles(t, rawRepo, "alice", map[string]string{"a.txt": "a", "b.txt": "b"}, "add b", base)
+	f2 := commitFiles(t, rawRepo, "bob", map[string]string{"a.txt": "a2", "b.txt": "b"}, "update a", f1)
+	setBranch(t, rawRepo, "master", master)
+	setBranch(t, rawRepo, "feature", f2)
+
+	result, err := repo.Rebase("feature", "master", &Signature{Name: "merger", Email: "merger@erda.cloud", When: time.Now()})
+	checkFatal(t, err)
+
+	head := lookupBranch(t, rawRepo, "master")
+	if head.Id().String() != result.ID {
+		t.Fatalf("master is %s, want %s", head.Id(), result.ID)
+	}
+	assertFiles(t, rawRepo, head, map[string]string{"a.txt": "a2", "b.txt": "b", "c.txt": "c"})
+	if head.Author().Name != "bob" || head.Message() != "update a" {
+		t.Fatalf("last replayed commit is %s by %s", head.Message(), head.Author().Name)
+	}
+	first := head.Parent(0)
+	if first.Author().Name != "alice" || first.Message() != "add b" || first.ParentCount() != 1 {
+		t.Fatalf("first replayed commit is %s by %s", first.Message(), first.Author().Name)
+	}
+	if first.Parent(0).Id().String() != master.Id().String() {
+		t.Fatalf("replayed commits should be on top of %s", master.Id())
+	}
+	// 源分支不变
+	if lookupBranch(t, rawRepo, "feature").Id().String() != f2.Id().String() {
+		t.Fatalf("feature branch should not be changed")
+	}
+}
+
+func TestRepository_RebaseFastForward(t *testing.T) {
+	repo, rawRepo := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(t, repo, rawRepo)
+
+	base := commitFiles(t, rawRepo, "dev", map[string]string{"a.txt": "a"}, "init")
+	f1 := commitFiles(t, rawRepo, "alice", map[string]string{"a.txt": "a", "b.txt": "b"}, "add b", base)
+	setBranch(t, rawRepo, "master", base)
+	setBranch(t, rawRepo, "feature", f1)
+
+	result, err := repo.Rebase("feature", "master", &Signature{Name: "merger", Email: "merger@erda.cloud", When: time.Now()})
+	checkFatal(t, err)
+	if result.ID != f1.Id().String() || lookupBranch(t, rawRepo, "master").Id().String() != f1.Id().String() {
+		t.Fatalf("master should be fast-forwarded to %s", f1.Id())
+	}
+}
+
+func TestRepository_RebaseKeepsMergeResolution(t *testing.T) {
+	repo, rawRepo := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(t, repo, rawRepo)
+
+	base := commitFiles(t, rawRepo, "dev", map[string]string{"a.txt": "base"}, "init")
+	master := commitFiles(t, rawRepo, "dev", map[string]string{"a.txt": "base", "c.txt": "c"}, "add c", base)
+	// feature 合入了另一个有冲突的分支, 冲突在合并提交中解决
+	other := commitFiles(t, rawRepo, "bob", map[string]string{"a.txt": "other"}, "other change", base)
+	f1 := commitFiles(t, rawRepo, "alice", map[string]string{"a.txt": "feature"}, "feature change", base)
+	merged := commitFiles(t, rawRepo, "alice", map[string]string{"a.txt": "resolved"}, "merge other", f1, other)
+	setBranch(t, rawRepo, "master", master)
+	setBranch(t, rawRepo, "feature", merged)
+
+	result, err := repo.Rebase("feature", "master", &Signature{Name: "merger", Email: "merger@erda.cloud", When: time.Now()})
+	checkFatal(t, err)
+
+	head := lookupBranch(t, rawRepo, "master")
+	if head.Id().String() != result.ID {
+		t.Fatalf("master is %s, want %s", head.Id(), result.ID)
+	}
+	assertFiles(t, rawRepo, head, map[string]string{"a.txt": "resolved", "c.txt": "c"})
+	if head.ParentCount() != 1 || head.Parent(0).Parent(0).Id().String() != master.Id().String() {
+		t.Fatalf("rebased history should be linear on top of %s", master.Id())
+	}
+}
+
+func TestRepository_RebaseConflict(t *testing.T) {
+	repo, rawRepo := createMergeTestRepo(t)
+	defer cleanupMergeTestRepo(t, repo, rawRepo)
+
+	base := commitFiles(t, rawRepo, "dev", map[string]string{"a.txt": "base"}, "init")
+	master := commitFiles(t, rawRepo, "dev", map[string]string{"a.txt": "master"}, "master change", base)
+	f1 := commitFiles(t, rawRepo, "alice", map[string]string{"a.txt": "feature"}, "feature change", base)
+	setBranch(t, rawRepo, "master", master)
+	setBranch(t, rawRepo, "feature", f1)
+
+	if _, err := repo.Rebase("feature", "master", &Signature{Name: "merger", Email: "merger@erda.cloud", When: time.Now()}); err == nil {
+		t.Fatalf("rebase should fail with conflict")
+	}
+	if lookupBranch(t, rawRepo, "master").Id().String() != master.Id().String() {
+		t.Fatalf("master should not be changed when rebase failed")
+	}
+}
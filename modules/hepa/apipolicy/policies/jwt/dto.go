@@ -0,0 +1,140 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+)
+
+const (
+	DEFAULT_TOKEN_HEADER = "Authorization"
+	BEARER_PREFIX        = "Bearer"
+)
+
+var supportedAlgorithms = map[string]bool{
+	"HS256": true,
+	"HS384": true,
+	"HS512": true,
+	"RS256": true,
+	"RS384": true,
+	"RS512": true,
+	"ES256": true,
+	"ES384": true,
+	"ES512": true,
+}
+
+var headerRegex = regexp.MustCompile(`^[0-9a-zA-Z-]+$`)
+
+// StaticKey 静态配置的验签密钥，HS* 使用共享密钥，RS*/ES* 使用 PEM 格式公钥
+type StaticKey struct {
+	Kid       string `json:"kid"`
+	Algorithm string `json:"algorithm"`
+	Key       string `json:"key"`
+}
+
+// ClaimHeader 将 token 中的 claim 转发为上游请求头
+type ClaimHeader struct {
+	Claim  string `json:"claim"`
+	Header string `json:"header"`
+}
+
+type PolicyDto struct {
+	apipolicy.BaseDto
+	JwksUri         string        `json:"jwksUri"`
+	JwksCacheTTL    int64         `json:"jwksCacheTTL"`
+	StaticKeys      []StaticKey   `json:"staticKeys,omitempty"`
+	Algorithms      []string      `json:"algorithms"`
+	Issuer          string        `json:"issuer"`
+	Audiences       []string      `json:"audiences,omitempty"`
+	ClockSkew       int64         `json:"clockSkew"`
+	TokenHeader     string        `json:"tokenHeader"`
+	ClaimsToHeaders []ClaimHeader `json:"claimsToHeaders,omitempty"`
+	ErrStatus       int64         `json:"errStatus"`
+}
+
+func (dto PolicyDto) IsValidDto() (bool, string) {
+	if !dto.Switch {
+		return true, ""
+	}
+	if dto.JwksUri == "" && len(dto.StaticKeys) == 0 {
+		return false, "JWKS地址和静态密钥至少需要填写一项"
+	}
+	if dto.JwksUri != "" && !strings.HasPrefix(dto.JwksUri, "http://") && !strings.HasPrefix(dto.JwksUri, "https://") {
+		return false, fmt.Sprintf("JWKS地址不合法:%s", dto.JwksUri)
+	}
+	if dto.JwksCacheTTL < 0 {
+		return false, "JWKS缓存时间不能小于0"
+	}
+	if len(dto.Algorithms) == 0 {
+		return false, "签名算法不能为空"
+	}
+	for _, alg := range dto.Algorithms {
+		if !supportedAlgorithms[alg] {
+			return false, fmt.Sprintf("不支持的签名算法:%s", alg)
+		}
+	}
+	kids := map[string]bool{}
+	for _, key := range dto.StaticKeys {
+		if !supportedAlgorithms[key.Algorithm] {
+			return false, fmt.Sprintf("不支持的签名算法:%s", key.Algorithm)
+		}
+		if key.Key == "" {
+			return false, "静态密钥不能为空"
+		}
+		if _, err := parseStaticKey(key); err != nil {
+			return false, fmt.Sprintf("%s算法需要填写PEM格式的公钥", key.Algorithm)
+		}
+		if kids[key.Kid] {
+			return false, fmt.Sprintf("静态密钥kid重复:%s", key.Kid)
+		}
+		kids[key.Kid] = true
+	}
+	if dto.ClockSkew < 0 {
+		return false, "时钟偏差不能小于0"
+	}
+	if dto.TokenHeader != "" && !headerRegex.MatchString(dto.TokenHeader) {
+		return false, fmt.Sprintf("token请求头名称不合法:%s", dto.TokenHeader)
+	}
+	headers := map[string]bool{}
+	for _, item := range dto.ClaimsToHeaders {
+		if item.Claim == "" {
+			return false, "转发的claim名称不能为空"
+		}
+		if !headerRegex.MatchString(item.Header) {
+			return false, fmt.Sprintf("转发的请求头名称不合法:%s", item.Header)
+		}
+		lower := strings.ToLower(item.Header)
+		if headers[lower] {
+			return false, fmt.Sprintf("转发的请求头名称重复:%s", item.Header)
+		}
+		headers[lower] = true
+	}
+	// ingress 的外部认证只会透传 401 和 403
+	if dto.ErrStatus != 401 && dto.ErrStatus != 403 {
+		return false, "校验失败状态码只支持401或403"
+	}
+	return true, ""
+}
+
+func (dto PolicyDto) tokenHeader() string {
+	if dto.TokenHeader == "" {
+		return DEFAULT_TOKEN_HEADER
+	}
+	return dto.TokenHeader
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+)
+
+func publicKeyPEM(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func generateRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func generateECKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestPolicyDto_IsValidDto(t *testing.T) {
+	rsaPEM := publicKeyPEM(t, &generateRSAKey(t).PublicKey)
+	ecPEM := publicKeyPEM(t, &generateECKey(t).PublicKey)
+	validDto := func() PolicyDto {
+		return PolicyDto{
+			BaseDto:      apipolicy.BaseDto{Switch: true},
+			JwksUri:      "https://issuer.example.com/.well-known/jwks.json",
+			JwksCacheTTL: 300,
+			StaticKeys: []StaticKey{
+				{Kid: "hs", Algorithm: "HS256", Key: "secret"},
+				{Kid: "rs", Algorithm: "RS256", Key: rsaPEM},
+				{Kid: "es", Algorithm: "ES256", Key: ecPEM},
+			},
+			Algorithms:      []string{"RS256", "HS256", "ES256"},
+			Issuer:          "https://issuer.example.com",
+			Audiences:       []string{"api"},
+			ClockSkew:       60,
+			ClaimsToHeaders: []ClaimHeader{{Claim: "sub", Header: "X-User-Id"}},
+			ErrStatus:       401,
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(dto *PolicyDto)
+		want   bool
+	}{
+		{"valid", func(dto *PolicyDto) {}, true},
+		{"switch off", func(dto *PolicyDto) { dto.Switch = false; dto.JwksUri = ""; dto.StaticKeys = nil }, true},
+		{"jwks only", func(dto *PolicyDto) { dto.StaticKeys = nil }, true},
+		{"static keys only", func(dto *PolicyDto) { dto.JwksUri = "" }, true},
+		{"no key source", func(dto *PolicyDto) { dto.JwksUri = ""; dto.StaticKeys = nil }, false},
+		{"invalid jwks uri", func(dto *PolicyDto) { dto.JwksUri = "ftp://issuer" }, false},
+		{"negative jwks ttl", func(dto *PolicyDto) { dto.JwksCacheTTL = -1 }, false},
+		{"no algorithms", func(dto *PolicyDto) { dto.Algorithms = nil }, false},
+		{"unsupported algorithm", func(dto *PolicyDto) { dto.Algorithms = []string{"none"} }, false},
+		{"unsupported key algorithm", func(dto *PolicyDto) { dto.StaticKeys[0].Algorithm = "PS256" }, false},
+		{"empty key", func(dto *PolicyDto) { dto.StaticKeys[0].Key = "" }, false},
+		{"rsa without pem", func(dto *PolicyDto) { dto.StaticKeys[1].Key = "secret" }, false},
+		{"ec with rsa pem", func(dto *PolicyDto) { dto.StaticKeys[2].Key = rsaPEM }, false},
+		{"duplicate kid", func(dto *PolicyDto) { dto.StaticKeys[1].Kid = "hs" }, false},
+		{"negative clock skew", func(dto *PolicyDto) { dto.ClockSkew = -1 }, false},
+		{"invalid token header", func(dto *PolicyDto) { dto.TokenHeader = "X Token" }, false},
+		{"custom token header", func(dto *PolicyDto) { dto.TokenHeader = "X-Token" }, true},
+		{"empty claim", func(dto *PolicyDto) { dto.ClaimsToHeaders[0].Claim = "" }, false},
+		{"invalid claim header", func(dto *PolicyDto) { dto.ClaimsToHeaders[0].Header = "X User" }, false},
+		{"duplicate claim header", func(dto *PolicyDto) {
+			dto.ClaimsToHeaders = append(dto.ClaimsToHeaders, ClaimHeader{Claim: "tenant", Header: "x-user-id"})
+		}, false},
+		{"forbidden status", func(dto *PolicyDto) { dto.ErrStatus = 403 }, true},
+		{"unsupported status", func(dto *PolicyDto) { dto.ErrStatus = 400 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dto := validDto()
+			tt.modify(&dto)
+			if got, msg := dto.IsValidDto(); got != tt.want {
+				t.Errorf("IsValidDto() = %v, msg %s, want %v", got, msg, tt.want)
+			}
+		})
+	}
+}
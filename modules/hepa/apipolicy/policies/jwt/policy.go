@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+	"github.com/erda-project/erda/modules/hepa/config"
+	"github.com/erda-project/erda/modules/hepa/repository/orm"
+	"github.com/erda-project/erda/pkg/discover"
+)
+
+const (
+	POLICY_NAME           = "safety-jwt"
+	VERIFY_PATH           = "/api/gateway/policies/jwt/verify/"
+	AUTH_URL              = "nginx.ingress.kubernetes.io/auth-url"
+	AUTH_METHOD           = "nginx.ingress.kubernetes.io/auth-method"
+	AUTH_RESPONSE_HEADERS = "nginx.ingress.kubernetes.io/auth-response-headers"
+)
+
+type Policy struct {
+	apipolicy.BasePolicy
+}
+
+func (policy Policy) CreateDefaultConfig(ctx map[string]interface{}) apipolicy.PolicyDto {
+	dto := &PolicyDto{
+		JwksCacheTTL: 300,
+		Algorithms:   []string{"RS256"},
+		ClockSkew:    60,
+		TokenHeader:  DEFAULT_TOKEN_HEADER,
+		ErrStatus:    401,
+	}
+	dto.Switch = false
+	return dto
+}
+
+func (policy Policy) UnmarshalConfig(config []byte) (apipolicy.PolicyDto, error, string) {
+	policyDto := &PolicyDto{}
+	err := json.Unmarshal(config, policyDto)
+	if err != nil {
+		return nil, errors.Wrapf(err, "json parse config failed, config:%s", config), "Invalid config"
+	}
+	ok, msg := policyDto.IsValidDto()
+	if !ok {
+		return nil, errors.Errorf("invalid policy dto, msg:%s", msg), msg
+	}
+	return policyDto, nil, ""
+}
+
+// verifyAddr ingress 外部认证回调 hepa 的地址，ingress 无法通过服务发现地址访问 hepa 时需要单独配置
+func verifyAddr() string {
+	addr := config.ServerConf.JwtVerifyAddr
+	if addr == "" {
+		addr = discover.Hepa()
+	}
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	return strings.TrimSuffix(addr, "/")
+}
+
+// buildAnnotations 通过 ingress 外部认证调用 hepa 校验 token，并把 claim 请求头转发给上游
+func (policy Policy) buildAnnotations(dto *PolicyDto, addr, zoneId string) map[string]*string {
+	annotations := map[string]*string{
+		AUTH_URL:              nil,
+		AUTH_METHOD:           nil,
+		AUTH_RESPONSE_HEADERS: nil,
+	}
+	if !dto.Switch {
+		return annotations
+	}
+	url := addr + VERIFY_PATH + zoneId
+	method := "GET"
+	annotations[AUTH_URL] = &url
+	annotations[AUTH_METHOD] = &method
+	if len(dto.ClaimsToHeaders) > 0 {
+		var headers []string
+		for _, item := range dto.ClaimsToHeaders {
+			headers = append(headers, item.Header)
+		}
+		responseHeaders := strings.Join(headers, ",")
+		annotations[AUTH_RESPONSE_HEADERS] = &responseHeaders
+	}
+	return annotations
+}
+
+func (policy Policy) ParseConfig(dto apipolicy.PolicyDto, ctx map[string]interface{}) (apipolicy.PolicyConfig, error) {
+	res := apipolicy.PolicyConfig{}
+	policyDto, ok := dto.(*PolicyDto)
+	if !ok {
+		return res, errors.Errorf("invalid config:%+v", dto)
+	}
+	value, ok := ctx[apipolicy.CTX_ZONE]
+	if !ok {
+		return res, errors.Errorf("get identify failed:%+v", ctx)
+	}
+	zone, ok := value.(*orm.GatewayZone)
+	if !ok {
+		return res, errors.Errorf("convert failed:%+v", value)
+	}
+	res.IngressAnnotation = &apipolicy.IngressAnnotation{
+		Annotation: policy.buildAnnotations(policyDto, verifyAddr(), zone.Id),
+	}
+	return res, nil
+}
+
+func init() {
+	err := apipolicy.RegisterPolicyEngine(POLICY_NAME, &Policy{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"testing"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+)
+
+func TestPolicy_buildAnnotations(t *testing.T) {
+	dto := &PolicyDto{
+		BaseDto:    apipolicy.BaseDto{Switch: true},
+		StaticKeys: []StaticKey{{Algorithm: "HS256", Key: "secret"}},
+		Algorithms: []string{"HS256"},
+		ClaimsToHeaders: []ClaimHeader{
+			{Claim: "sub", Header: "X-User-Id"},
+			{Claim: "tenant", Header: "X-Tenant"},
+		},
+		ErrStatus: 401,
+	}
+	annotations := Policy{}.buildAnnotations(dto, "http://hepa:8080", "zone-1")
+	if got := annotations[AUTH_URL]; got == nil || *got != "http://hepa:8080/api/gateway/policies/jwt/verify/zone-1" {
+		t.Errorf("auth-url = %v", got)
+	}
+	if got := annotations[AUTH_METHOD]; got == nil || *got != "GET" {
+		t.Errorf("auth-method = %v", got)
+	}
+	if got := annotations[AUTH_RESPONSE_HEADERS]; got == nil || *got != "X-User-Id,X-Tenant" {
+		t.Errorf("auth-response-headers = %v", got)
+	}
+
+	dto.ClaimsToHeaders = nil
+	annotations = Policy{}.buildAnnotations(dto, "http://hepa:8080", "zone-1")
+	if v, ok := annotations[AUTH_RESPONSE_HEADERS]; !ok || v != nil {
+		t.Error("auth-response-headers should be removed when no claim is forwarded")
+	}
+
+	dto.Switch = false
+	annotations = Policy{}.buildAnnotations(dto, "http://hepa:8080", "zone-1")
+	for _, name := range []string{AUTH_URL, AUTH_METHOD, AUTH_RESPONSE_HEADERS} {
+		if v, ok := annotations[name]; !ok || v != nil {
+			t.Errorf("%s should be removed when policy is off", name)
+		}
+	}
+}
@@ -0,0 +1,533 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+	"github.com/erda-project/erda/modules/hepa/repository/orm"
+	db "github.com/erda-project/erda/modules/hepa/repository/service"
+)
+
+const (
+	// 策略配置在校验接口中的缓存时间，策略更新后最多延迟这么久生效
+	policyCacheTTL = 10 * time.Second
+	// token 的 kid 在 JWKS 中找不到时，两次重新拉取的最小间隔
+	jwksMinRefreshInterval = 10 * time.Second
+)
+
+// verifyKey 验签密钥，algorithm 为空时按密钥类型匹配签名算法
+type verifyKey struct {
+	kid       string
+	algorithm string
+	key       interface{}
+}
+
+func parseStaticKey(key StaticKey) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(key.Algorithm, "HS"):
+		return []byte(key.Key), nil
+	case strings.HasPrefix(key.Algorithm, "RS"):
+		return jwt.ParseRSAPublicKeyFromPEM([]byte(key.Key))
+	case strings.HasPrefix(key.Algorithm, "ES"):
+		return jwt.ParseECPublicKeyFromPEM([]byte(key.Key))
+	}
+	return nil, errors.Errorf("unsupported algorithm:%s", key.Algorithm)
+}
+
+// matches 密钥类型必须和 token 的签名算法一致，避免用公钥作为 HS 共享密钥的算法混淆攻击
+func (key verifyKey) matches(alg, kid string) bool {
+	if kid != "" && key.kid != kid {
+		return false
+	}
+	if key.algorithm != "" && key.algorithm != alg {
+		return false
+	}
+	switch key.key.(type) {
+	case []byte:
+		return strings.HasPrefix(alg, "HS")
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS")
+	case *ecdsa.PublicKey:
+		return strings.HasPrefix(alg, "ES")
+	}
+	return false
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+func (jwk jsonWebKey) publicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 {
+			return nil, errors.Errorf("invalid rsa exponent, kid:%s", jwk.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve:%s, kid:%s", jwk.Crv, jwk.Kid)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.Errorf("invalid ec point, kid:%s", jwk.Kid)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, errors.Errorf("unsupported key type:%s, kid:%s", jwk.Kty, jwk.Kid)
+}
+
+// parseJwks 解析 JWKS，跳过加密用途和不支持类型的密钥
+func parseJwks(body []byte) ([]verifyKey, error) {
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	err := json.Unmarshal(body, &set)
+	if err != nil {
+		return nil, errors.Wrapf(err, "json parse jwks failed, body:%s", body)
+	}
+	var keys []verifyKey
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Warnf("skip jwk, err:%+v", err)
+			continue
+		}
+		keys = append(keys, verifyKey{
+			kid:       jwk.Kid,
+			algorithm: jwk.Alg,
+			key:       key,
+		})
+	}
+	return keys, nil
+}
+
+type jwksEntry struct {
+	keys      []verifyKey
+	fetchedAt time.Time
+}
+
+type jwksCache struct {
+	sync.Mutex
+	client  *http.Client
+	entries map[string]*jwksEntry
+}
+
+func (cache *jwksCache) fetch(uri string) ([]verifyKey, error) {
+	resp, err := cache.client.Get(uri)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetch jwks failed, uri:%s, code:%d, body:%s", uri, resp.StatusCode, body)
+	}
+	return parseJwks(body)
+}
+
+// get 返回缓存的 JWKS，超过 ttl 或要求刷新时重新拉取，拉取失败时继续使用旧的密钥
+func (cache *jwksCache) get(uri string, ttl time.Duration, refresh bool) ([]verifyKey, error) {
+	cache.Lock()
+	defer cache.Unlock()
+	entry, ok := cache.entries[uri]
+	now := time.Now()
+	if ok {
+		age := now.Sub(entry.fetchedAt)
+		if (!refresh && age < ttl) || (refresh && age < jwksMinRefreshInterval) {
+			return entry.keys, nil
+		}
+	}
+	keys, err := cache.fetch(uri)
+	if err != nil {
+		if ok {
+			log.Errorf("refresh jwks failed, use cached keys, err:%+v", err)
+			return entry.keys, nil
+		}
+		return nil, err
+	}
+	cache.entries[uri] = &jwksEntry{
+		keys:      keys,
+		fetchedAt: now,
+	}
+	return keys, nil
+}
+
+var defaultJwksCache = &jwksCache{
+	client:  &http.Client{Timeout: 5 * time.Second},
+	entries: map[string]*jwksEntry{},
+}
+
+// verifier 按策略配置校验 token
+type verifier struct {
+	dto        *PolicyDto
+	staticKeys []verifyKey
+	// jwks 获取 JWKS 中的密钥，refresh 为 true 时要求重新拉取
+	jwks func(refresh bool) ([]verifyKey, error)
+	now  func() time.Time
+}
+
+func newVerifier(dto *PolicyDto) (*verifier, error) {
+	v := &verifier{
+		dto: dto,
+		now: time.Now,
+	}
+	for _, key := range dto.StaticKeys {
+		parsed, err := parseStaticKey(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse static key failed, kid:%s", key.Kid)
+		}
+		v.staticKeys = append(v.staticKeys, verifyKey{
+			kid:       key.Kid,
+			algorithm: key.Algorithm,
+			key:       parsed,
+		})
+	}
+	if dto.JwksUri != "" {
+		ttl := time.Duration(dto.JwksCacheTTL) * time.Second
+		v.jwks = func(refresh bool) ([]verifyKey, error) {
+			return defaultJwksCache.get(dto.JwksUri, ttl, refresh)
+		}
+	}
+	return v, nil
+}
+
+func findKey(keys []verifyKey, alg, kid string) interface{} {
+	for _, key := range keys {
+		if key.matches(alg, kid) {
+			return key.key
+		}
+	}
+	return nil
+}
+
+func (v *verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+	kid, _ := token.Header["kid"].(string)
+	if key := findKey(v.staticKeys, alg, kid); key != nil {
+		return key, nil
+	}
+	if v.jwks == nil {
+		return nil, errors.Errorf("no key matches, alg:%s, kid:%s", alg, kid)
+	}
+	keys, err := v.jwks(false)
+	if err != nil {
+		return nil, err
+	}
+	if key := findKey(keys, alg, kid); key != nil {
+		return key, nil
+	}
+	// 签发方轮换密钥后，新的 kid 需要重新拉取 JWKS
+	keys, err = v.jwks(true)
+	if err != nil {
+		return nil, err
+	}
+	if key := findKey(keys, alg, kid); key != nil {
+		return key, nil
+	}
+	return nil, errors.Errorf("no key matches, alg:%s, kid:%s", alg, kid)
+}
+
+func numberClaim(claims jwt.MapClaims, name string) (int64, bool, error) {
+	value, ok := claims[name]
+	if !ok {
+		return 0, false, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, true, errors.Errorf("claim %s is not a number", name)
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return 0, true, errors.Wrapf(err, "claim %s is not a number", name)
+	}
+	return int64(f), true, nil
+}
+
+func (v *verifier) validateClaims(claims jwt.MapClaims) error {
+	now := v.now().Unix()
+	exp, ok, err := numberClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("token has no exp claim")
+	}
+	if now > exp+v.dto.ClockSkew {
+		return errors.New("token is expired")
+	}
+	nbf, ok, err := numberClaim(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now+v.dto.ClockSkew < nbf {
+		return errors.New("token is not valid yet")
+	}
+	if v.dto.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.dto.Issuer {
+			return errors.Errorf("invalid issuer:%s", iss)
+		}
+	}
+	if len(v.dto.Audiences) > 0 {
+		var auds []string
+		switch aud := claims["aud"].(type) {
+		case string:
+			auds = []string{aud}
+		case []interface{}:
+			for _, item := range aud {
+				if s, ok := item.(string); ok {
+					auds = append(auds, s)
+				}
+			}
+		}
+		for _, aud := range auds {
+			for _, allowed := range v.dto.Audiences {
+				if aud == allowed {
+					return nil
+				}
+			}
+		}
+		return errors.Errorf("invalid audience:%v", claims["aud"])
+	}
+	return nil
+}
+
+func (v *verifier) verify(token string) (jwt.MapClaims, error) {
+	parser := &jwt.Parser{
+		ValidMethods:         v.dto.Algorithms,
+		UseJSONNumber:        true,
+		SkipClaimsValidation: true,
+	}
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(token, claims, v.keyFunc)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = v.validateClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// claimHeaders 生成需要转发给上游的请求头，claim 不存在时返回空值，ingress 会据此移除客户端伪造的同名请求头
+func (v *verifier) claimHeaders(claims jwt.MapClaims) map[string]string {
+	headers := map[string]string{}
+	for _, item := range v.dto.ClaimsToHeaders {
+		var value string
+		switch claim := claims[item.Claim].(type) {
+		case nil:
+		case string:
+			value = claim
+		case json.Number:
+			value = claim.String()
+		case bool:
+			value = strconv.FormatBool(claim)
+		default:
+			data, err := json.Marshal(claim)
+			if err == nil {
+				value = string(data)
+			}
+		}
+		headers[item.Header] = value
+	}
+	return headers
+}
+
+func extractToken(r *http.Request, header string) string {
+	value := strings.TrimSpace(r.Header.Get(header))
+	if len(value) > len(BEARER_PREFIX) && strings.EqualFold(value[:len(BEARER_PREFIX)], BEARER_PREFIX) &&
+		(value[len(BEARER_PREFIX)] == ' ' || value[len(BEARER_PREFIX)] == '\t') {
+		return strings.TrimSpace(value[len(BEARER_PREFIX):])
+	}
+	if strings.EqualFold(header, DEFAULT_TOKEN_HEADER) {
+		return ""
+	}
+	return value
+}
+
+type cachedVerifier struct {
+	verifier *verifier
+	loadedAt time.Time
+}
+
+var (
+	verifierLock sync.Mutex
+	verifiers    = map[string]*cachedVerifier{}
+)
+
+// loadPolicy 读取 zone 生效的 jwt 策略，zone 未单独配置时使用所属流量入口的配置
+func loadPolicy(zoneId string) (*PolicyDto, error) {
+	zoneDb, err := db.NewGatewayZoneServiceImpl()
+	if err != nil {
+		return nil, err
+	}
+	zone, err := zoneDb.GetById(zoneId)
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		return nil, errors.Errorf("zone not found, id:%s", zoneId)
+	}
+	var packageId string
+	apiDb, err := db.NewGatewayPackageApiServiceImpl()
+	if err != nil {
+		return nil, err
+	}
+	api, err := apiDb.GetByAny(&orm.GatewayPackageApi{ZoneId: zoneId})
+	if err != nil {
+		return nil, err
+	}
+	if api != nil {
+		packageId = api.PackageId
+	} else {
+		packDb, err := db.NewGatewayPackageServiceImpl()
+		if err != nil {
+			return nil, err
+		}
+		pack, err := packDb.GetByAny(&orm.GatewayPackage{ZoneId: zoneId})
+		if err != nil {
+			return nil, err
+		}
+		if pack != nil {
+			packageId = pack.Id
+		}
+	}
+	engine, err := apipolicy.GetPolicyEngine(POLICY_NAME)
+	if err != nil {
+		return nil, err
+	}
+	dto, err := engine.GetConfig(POLICY_NAME, packageId, zone, nil)
+	if err != nil {
+		return nil, err
+	}
+	policyDto, ok := dto.(*PolicyDto)
+	if !ok {
+		return nil, errors.Errorf("invalid config:%+v", dto)
+	}
+	return policyDto, nil
+}
+
+func getVerifier(zoneId string) (*verifier, error) {
+	verifierLock.Lock()
+	defer verifierLock.Unlock()
+	if cached, ok := verifiers[zoneId]; ok && time.Since(cached.loadedAt) < policyCacheTTL {
+		return cached.verifier, nil
+	}
+	dto, err := loadPolicy(zoneId)
+	if err != nil {
+		return nil, err
+	}
+	var v *verifier
+	if dto.Switch {
+		v, err = newVerifier(dto)
+		if err != nil {
+			return nil, err
+		}
+	}
+	verifiers[zoneId] = &cachedVerifier{
+		verifier: v,
+		loadedAt: time.Now(),
+	}
+	return v, nil
+}
+
+// Verify 供 ingress 外部认证调用，校验通过时返回需要转发给上游的 claim 请求头
+func Verify(zoneId string, r *http.Request) (map[string]string, int) {
+	// 放行 CORS 预检请求
+	if r.Header.Get("X-Original-Method") == http.MethodOptions {
+		return nil, http.StatusOK
+	}
+	v, err := getVerifier(zoneId)
+	if err != nil {
+		log.Errorf("load jwt policy failed, zoneId:%s, err:%+v", zoneId, err)
+		return nil, http.StatusInternalServerError
+	}
+	if v == nil {
+		return nil, http.StatusOK
+	}
+	token := extractToken(r, v.dto.tokenHeader())
+	if token == "" {
+		return nil, int(v.dto.ErrStatus)
+	}
+	claims, err := v.verify(token)
+	if err != nil {
+		log.Debugf("verify jwt failed, zoneId:%s, err:%s", zoneId, err)
+		return nil, int(v.dto.ErrStatus)
+	}
+	return v.claimHeaders(claims), http.StatusOK
+}
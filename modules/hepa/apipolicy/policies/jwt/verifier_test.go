@@ -0,0 +1,227 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+)
+
+var testNow = time.Unix(1600000000, 0)
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func TestVerifier_verify(t *testing.T) {
+	rsaKey := generateRSAKey(t)
+	otherRSAKey := generateRSAKey(t)
+	ecKey := generateECKey(t)
+	rsaPEM := publicKeyPEM(t, &rsaKey.PublicKey)
+	dto := &PolicyDto{
+		BaseDto: apipolicy.BaseDto{Switch: true},
+		StaticKeys: []StaticKey{
+			{Kid: "hs", Algorithm: "HS256", Key: "secret"},
+			{Kid: "rs", Algorithm: "RS256", Key: rsaPEM},
+			{Kid: "es", Algorithm: "ES256", Key: publicKeyPEM(t, &ecKey.PublicKey)},
+		},
+		Algorithms: []string{"HS256", "RS256", "ES256"},
+		Issuer:     "issuer",
+		Audiences:  []string{"api", "web"},
+		ClockSkew:  60,
+		ErrStatus:  401,
+	}
+	v, err := newVerifier(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return testNow }
+	claims := func(modify func(claims jwt.MapClaims)) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"iss": "issuer",
+			"aud": "api",
+			"sub": "user-1",
+			"exp": testNow.Unix() + 300,
+			"nbf": testNow.Unix() - 10,
+		}
+		if modify != nil {
+			modify(claims)
+		}
+		return claims
+	}
+	tests := []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{"hs256", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(nil)), true},
+		{"rs256", sign(t, jwt.SigningMethodRS256, "rs", rsaKey, claims(nil)), true},
+		{"es256", sign(t, jwt.SigningMethodES256, "es", ecKey, claims(nil)), true},
+		{"no kid", sign(t, jwt.SigningMethodRS256, "", rsaKey, claims(nil)), true},
+		{"wrong secret", sign(t, jwt.SigningMethodHS256, "hs", []byte("other"), claims(nil)), false},
+		{"wrong rsa key", sign(t, jwt.SigningMethodRS256, "rs", otherRSAKey, claims(nil)), false},
+		{"unknown kid", sign(t, jwt.SigningMethodRS256, "unknown", rsaKey, claims(nil)), false},
+		{"kid of other algorithm", sign(t, jwt.SigningMethodHS256, "rs", []byte(rsaPEM), claims(nil)), false},
+		{"algorithm not allowed", sign(t, jwt.SigningMethodRS512, "", rsaKey, claims(nil)), false},
+		{"expired", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { c["exp"] = testNow.Unix() - 61 })), false},
+		{"expired within clock skew", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { c["exp"] = testNow.Unix() - 30 })), true},
+		{"no exp", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { delete(c, "exp") })), false},
+		{"exp not a number", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { c["exp"] = "tomorrow" })), false},
+		{"not valid yet", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { c["nbf"] = testNow.Unix() + 61 })), false},
+		{"wrong issuer", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { c["iss"] = "other" })), false},
+		{"no issuer", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { delete(c, "iss") })), false},
+		{"audience list", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { c["aud"] = []string{"other", "web"} })), true},
+		{"wrong audience", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { c["aud"] = []string{"other"} })), false},
+		{"no audience", sign(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims(func(c jwt.MapClaims) { delete(c, "aud") })), false},
+		{"malformed", "not-a-token", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.verify(tt.token)
+			if (err == nil) != tt.want {
+				t.Errorf("verify() err = %v, want valid %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifier_jwks(t *testing.T) {
+	rsaKey := generateRSAKey(t)
+	ecKey := generateECKey(t)
+	keys := []jsonWebKey{
+		{Kty: "RSA", Kid: "rs-1", Alg: "RS256", Use: "sig", N: encodeBigInt(rsaKey.N), E: encodeBigInt(big.NewInt(int64(rsaKey.E)))},
+		{Kty: "RSA", Kid: "enc", Use: "enc", N: encodeBigInt(rsaKey.N), E: encodeBigInt(big.NewInt(int64(rsaKey.E)))},
+		{Kty: "oct", Kid: "oct"},
+	}
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(rw).Encode(map[string]interface{}{"keys": keys})
+	}))
+	defer server.Close()
+
+	cache := &jwksCache{client: server.Client(), entries: map[string]*jwksEntry{}}
+	dto := &PolicyDto{
+		BaseDto:    apipolicy.BaseDto{Switch: true},
+		JwksUri:    server.URL,
+		Algorithms: []string{"RS256", "ES256"},
+		ErrStatus:  401,
+	}
+	v, err := newVerifier(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.jwks = func(refresh bool) ([]verifyKey, error) {
+		return cache.get(server.URL, time.Hour, refresh)
+	}
+	claims := jwt.MapClaims{"exp": time.Now().Unix() + 300}
+
+	if _, err := v.verify(sign(t, jwt.SigningMethodRS256, "rs-1", rsaKey, claims)); err != nil {
+		t.Fatalf("token signed by jwks key should be valid, err %v", err)
+	}
+	if _, err := v.verify(sign(t, jwt.SigningMethodRS256, "rs-1", rsaKey, claims)); err != nil || fetches != 1 {
+		t.Fatalf("jwks should be cached, fetches %d, err %v", fetches, err)
+	}
+	if _, err := v.verify(sign(t, jwt.SigningMethodRS256, "enc", rsaKey, claims)); err == nil {
+		t.Error("encryption key should not be used to verify signature")
+	}
+
+	// 签发方轮换出新的 kid 后重新拉取 JWKS
+	keys = append(keys, jsonWebKey{Kty: "EC", Kid: "es-2", Crv: "P-256", X: encodeBigInt(ecKey.X), Y: encodeBigInt(ecKey.Y)})
+	cache.entries[server.URL].fetchedAt = time.Now().Add(-jwksMinRefreshInterval)
+	fetches = 0
+	if _, err := v.verify(sign(t, jwt.SigningMethodES256, "es-2", ecKey, claims)); err != nil || fetches != 1 {
+		t.Errorf("rotated key should be fetched, fetches %d, err %v", fetches, err)
+	}
+	// 短时间内未知 kid 不会反复拉取
+	fetches = 0
+	if _, err := v.verify(sign(t, jwt.SigningMethodES256, "unknown", ecKey, claims)); err == nil || fetches != 0 {
+		t.Errorf("unknown kid should not trigger refetch within min interval, fetches %d, err %v", fetches, err)
+	}
+}
+
+func TestVerifier_claimHeaders(t *testing.T) {
+	v := &verifier{dto: &PolicyDto{
+		ClaimsToHeaders: []ClaimHeader{
+			{Claim: "sub", Header: "X-User-Id"},
+			{Claim: "level", Header: "X-Level"},
+			{Claim: "admin", Header: "X-Admin"},
+			{Claim: "roles", Header: "X-Roles"},
+			{Claim: "tenant", Header: "X-Tenant"},
+		},
+	}}
+	claims := jwt.MapClaims{
+		"sub":   "user-1",
+		"level": json.Number("3"),
+		"admin": true,
+		"roles": []interface{}{"a", "b"},
+	}
+	want := map[string]string{
+		"X-User-Id": "user-1",
+		"X-Level":   "3",
+		"X-Admin":   "true",
+		"X-Roles":   `["a","b"]`,
+		"X-Tenant":  "",
+	}
+	if got := v.claimHeaders(claims); !reflect.DeepEqual(got, want) {
+		t.Errorf("claimHeaders() = %v, want %v", got, want)
+	}
+}
+
+func Test_extractToken(t *testing.T) {
+	tests := []struct {
+		header string
+		name   string
+		value  string
+		want   string
+	}{
+		{"Authorization", "Authorization", "Bearer abc", "abc"},
+		{"Authorization", "Authorization", "bearer  abc ", "abc"},
+		{"Authorization", "Authorization", "Basic abc", ""},
+		{"Authorization", "Authorization", "abc", ""},
+		{"X-Token", "X-Token", "abc", "abc"},
+		{"X-Token", "X-Token", "Bearer abc", "abc"},
+		{"X-Token", "Authorization", "Bearer abc", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(tt.name, tt.value)
+		if got := extractToken(r, tt.header); got != tt.want {
+			t.Errorf("extractToken(%s: %s) = %s, want %s", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+)
+
+type LimitBy string
+
+const (
+	LIMIT_BY_CONSUMER LimitBy = "consumer"
+	LIMIT_BY_API      LimitBy = "api"
+	LIMIT_BY_IP       LimitBy = "ip"
+	LIMIT_BY_HEADER   LimitBy = "header"
+)
+
+var headerRegex = regexp.MustCompile(`^[0-9a-zA-Z-_]+$`)
+
+// QuotaRule 一条配额规则，各时间窗口独立计数，均为滑动窗口
+type QuotaRule struct {
+	LimitBy    LimitBy `json:"limitBy"`
+	HeaderName string  `json:"headerName,omitempty"`
+	// 仅对指定的调用方生效，为空时对所有调用方分别计数
+	ConsumerIds []string `json:"consumerIds,omitempty"`
+	Second      int64    `json:"second,omitempty"`
+	Minute      int64    `json:"minute,omitempty"`
+	Hour        int64    `json:"hour,omitempty"`
+	Day         int64    `json:"day,omitempty"`
+	// 允许在窗口配额之外额外突发的请求数
+	Burst int64 `json:"burst,omitempty"`
+}
+
+type PolicyDto struct {
+	apipolicy.BaseDto
+	Rules             []QuotaRule `json:"rules"`
+	HideClientHeaders bool        `json:"hideClientHeaders"`
+	RefuseCode        int64       `json:"refuseCode"`
+	RefuseResponse    string      `json:"refuseResponse"`
+}
+
+type window struct {
+	Name  string
+	Size  int64
+	Limit int64
+}
+
+// windows 按窗口从短到长排列已配置配额的时间窗口，Size 单位为秒
+func (rule QuotaRule) windows() []window {
+	var windows []window
+	for _, w := range []window{
+		{"Second", 1, rule.Second},
+		{"Minute", 60, rule.Minute},
+		{"Hour", 3600, rule.Hour},
+		{"Day", 86400, rule.Day},
+	} {
+		if w.Limit > 0 {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+func (rule QuotaRule) isValid() (bool, string) {
+	switch rule.LimitBy {
+	case LIMIT_BY_CONSUMER, LIMIT_BY_API, LIMIT_BY_IP:
+	case LIMIT_BY_HEADER:
+		if !headerRegex.MatchString(rule.HeaderName) {
+			return false, fmt.Sprintf("请求头名称不合法:%s", rule.HeaderName)
+		}
+	default:
+		return false, fmt.Sprintf("限流维度非法:%s", rule.LimitBy)
+	}
+	if len(rule.ConsumerIds) > 0 && rule.LimitBy != LIMIT_BY_CONSUMER {
+		return false, "只有按调用方限流时才能指定调用方"
+	}
+	if rule.Second < 0 || rule.Minute < 0 || rule.Hour < 0 || rule.Day < 0 {
+		return false, "限流配额不能小于0"
+	}
+	if len(rule.windows()) == 0 {
+		return false, "至少需要配置一个时间窗口的限流配额"
+	}
+	// 较大的窗口配额不能小于较小的窗口配额
+	var last int64
+	for _, w := range rule.windows() {
+		if w.Limit < last {
+			return false, "较长时间窗口的配额不能小于较短时间窗口的配额"
+		}
+		last = w.Limit
+	}
+	if rule.Burst < 0 {
+		return false, "突发请求数不能小于0"
+	}
+	return true, ""
+}
+
+func (dto PolicyDto) IsValidDto() (bool, string) {
+	if !dto.Switch {
+		return true, ""
+	}
+	if len(dto.Rules) == 0 {
+		return false, "至少需要配置一条配额规则"
+	}
+	for _, rule := range dto.Rules {
+		if ok, msg := rule.isValid(); !ok {
+			return false, msg
+		}
+	}
+	if dto.RefuseCode < 400 || dto.RefuseCode >= 600 {
+		return false, fmt.Sprintf("拒绝状态码非法: %d", dto.RefuseCode)
+	}
+	return true, ""
+}
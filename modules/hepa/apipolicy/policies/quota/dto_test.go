@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+)
+
+func TestQuotaRule_isValid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule QuotaRule
+		want bool
+	}{
+		{"limit by ip", QuotaRule{LimitBy: LIMIT_BY_IP, Second: 10}, true},
+		{"limit by api", QuotaRule{LimitBy: LIMIT_BY_API, Minute: 100}, true},
+		{"invalid limit by", QuotaRule{LimitBy: "path", Second: 10}, false},
+		{"valid header", QuotaRule{LimitBy: LIMIT_BY_HEADER, HeaderName: "X-Tenant-Id", Second: 10}, true},
+		{"invalid header", QuotaRule{LimitBy: LIMIT_BY_HEADER, HeaderName: "X Tenant", Second: 10}, false},
+		{"empty header", QuotaRule{LimitBy: LIMIT_BY_HEADER, Second: 10}, false},
+		{"consumers with consumer limit", QuotaRule{LimitBy: LIMIT_BY_CONSUMER, ConsumerIds: []string{"1"}, Day: 10}, true},
+		{"consumers with ip limit", QuotaRule{LimitBy: LIMIT_BY_IP, ConsumerIds: []string{"1"}, Day: 10}, false},
+		{"negative limit", QuotaRule{LimitBy: LIMIT_BY_IP, Second: -1, Minute: 10}, false},
+		{"no window", QuotaRule{LimitBy: LIMIT_BY_IP}, false},
+		{"increasing windows", QuotaRule{LimitBy: LIMIT_BY_IP, Second: 10, Hour: 100, Day: 1000}, true},
+		{"decreasing windows", QuotaRule{LimitBy: LIMIT_BY_IP, Second: 100, Minute: 10}, false},
+		{"burst", QuotaRule{LimitBy: LIMIT_BY_IP, Second: 10, Burst: 5}, true},
+		{"negative burst", QuotaRule{LimitBy: LIMIT_BY_IP, Second: 10, Burst: -1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, msg := tt.rule.isValid(); got != tt.want {
+				t.Errorf("isValid() = %v, want %v, msg: %s", got, tt.want, msg)
+			}
+		})
+	}
+}
+
+func TestPolicyDto_IsValidDto(t *testing.T) {
+	on := apipolicy.BaseDto{Switch: true}
+	tests := []struct {
+		name string
+		dto  PolicyDto
+		want bool
+	}{
+		{"switch off", PolicyDto{}, true},
+		{"no rules", PolicyDto{BaseDto: on, RefuseCode: 429}, false},
+		{"invalid rule", PolicyDto{BaseDto: on, RefuseCode: 429, Rules: []QuotaRule{{LimitBy: LIMIT_BY_IP}}}, false},
+		{"invalid refuse code", PolicyDto{BaseDto: on, RefuseCode: 200, Rules: []QuotaRule{{LimitBy: LIMIT_BY_IP, Second: 1}}}, false},
+		{"valid", PolicyDto{BaseDto: on, RefuseCode: 429, Rules: []QuotaRule{{LimitBy: LIMIT_BY_IP, Second: 1}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, msg := tt.dto.IsValidDto(); got != tt.want {
+				t.Errorf("IsValidDto() = %v, want %v, msg: %s", got, tt.want, msg)
+			}
+		})
+	}
+}
+
+func TestQuotaRule_windows(t *testing.T) {
+	got := QuotaRule{Minute: 60, Second: 10, Day: 1000}.windows()
+	want := []window{{"Second", 1, 10}, {"Minute", 60, 60}, {"Day", 86400, 1000}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("windows() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+	"github.com/erda-project/erda/modules/hepa/kong"
+	kongDto "github.com/erda-project/erda/modules/hepa/kong/dto"
+	"github.com/erda-project/erda/modules/hepa/repository/orm"
+	db "github.com/erda-project/erda/modules/hepa/repository/service"
+)
+
+const (
+	// 滑动窗口计数由 kong 自带的 post-function 插件执行生成的 lua 脚本完成，
+	// kong 需要配置 untrusted_lua = on 才能访问计数用的 shared dict
+	PLUGIN_NAME = "post-function"
+	CATEGORY    = "safety"
+)
+
+// 计数复用 kong rate-limiting 插件的 shared dict，计数在每个 kong 节点上独立进行
+const scriptTemplate = `local prefix = {{lua .Prefix}}
+local rules = {
+{{- range .Rules}}
+  {
+    limit_by = {{lua .LimitBy}},
+    header_name = {{lua .HeaderName}},
+    consumers = {{if .Consumers}}{ {{- range .Consumers}} [{{lua .}}] = true,{{end}} }{{else}}nil{{end}},
+    burst = {{.Burst}},
+    windows = {
+{{- range .Windows}}
+      { name = {{lua .Name}}, size = {{.Size}}, limit = {{.Limit}} },
+{{- end}}
+    },
+  },
+{{- end}}
+}
+local refuse_code = {{.RefuseCode}}
+local refuse_response = {{lua .RefuseResponse}}
+local hide_client_headers = {{.HideClientHeaders}}
+local counters = ngx.shared.kong_rate_limiting_counters
+
+-- 未识别到调用方或缺少请求头时按客户端 ip 计数，指定了调用方的规则只对这些调用方生效
+local function identifier(rule, consumer)
+  if rule.limit_by == "consumer" then
+    if rule.consumers then
+      return consumer and rule.consumers[consumer.id] and consumer.id
+    end
+    if consumer then
+      return consumer.id
+    end
+  elseif rule.limit_by == "api" then
+    local service = kong.router.get_service()
+    if service then
+      return service.id
+    end
+  elseif rule.limit_by == "header" then
+    local value = kong.request.get_header(rule.header_name)
+    if value then
+      return value
+    end
+  end
+  return kong.client.get_forwarded_ip()
+end
+
+return function()
+  local now = ngx.now()
+  local consumer = kong.client.get_consumer()
+  local retry_after = 0
+  local limits = {}
+  local remaining = {}
+  local keys = {}
+  for i, rule in ipairs(rules) do
+    local id = identifier(rule, consumer)
+    if id then
+      for _, w in ipairs(rule.windows) do
+        local index = math.floor(now / w.size)
+        local key = prefix .. i .. ":" .. w.size .. ":" .. id .. ":"
+        local prev = counters:get(key .. (index - 1)) or 0
+        local curr = counters:get(key .. index) or 0
+        local elapsed = now - index * w.size
+        -- 上一个固定窗口按仍处于滑动窗口内的比例计入
+        local used = prev * (w.size - elapsed) / w.size + curr
+        local threshold = w.limit + rule.burst
+        if used + 1 > threshold then
+          local wait
+          if curr + 1 <= threshold then
+            -- 当前窗口内上一个窗口的计数衰减到足够小即可放行
+            wait = w.size - elapsed - (threshold - curr - 1) * w.size / prev
+          else
+            -- 需要等到下一个窗口，当前窗口的计数衰减到足够小
+            wait = w.size - elapsed + w.size * (1 - (threshold - 1) / curr)
+          end
+          retry_after = math.max(retry_after, math.ceil(wait))
+        else
+          keys[#keys + 1] = { key .. index, w.size * 2 }
+        end
+        local left = math.max(0, math.floor(w.limit - used - 1))
+        if not remaining[w.name] or left < remaining[w.name] then
+          limits[w.name] = w.limit
+          remaining[w.name] = left
+        end
+      end
+    end
+  end
+  if not hide_client_headers then
+    for name, left in pairs(remaining) do
+      kong.response.set_header("X-RateLimit-Limit-" .. name, limits[name])
+      kong.response.set_header("X-RateLimit-Remaining-" .. name, left)
+    end
+  end
+  if retry_after > 0 then
+    return kong.response.exit(refuse_code, refuse_response, { ["Retry-After"] = retry_after })
+  end
+  for _, item in ipairs(keys) do
+    counters:incr(item[1], 1, 0, item[2])
+  end
+end
+`
+
+var script = template.Must(template.New("quota").Funcs(template.FuncMap{"lua": luaString}).Parse(scriptTemplate))
+
+type scriptRule struct {
+	LimitBy    string
+	HeaderName string
+	Consumers  []string
+	Burst      int64
+	Windows    []window
+}
+
+type scriptData struct {
+	Prefix            string
+	Rules             []scriptRule
+	RefuseCode        int64
+	RefuseResponse    string
+	HideClientHeaders bool
+}
+
+// luaString 生成 lua 字符串字面量，字母数字和少量符号以外的字节都按 \ddd 转义
+func luaString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || strings.IndexByte(" _-.,:/", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "\\%03d", c)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+type Policy struct {
+	apipolicy.BasePolicy
+}
+
+func (policy Policy) CreateDefaultConfig(ctx map[string]interface{}) apipolicy.PolicyDto {
+	dto := &PolicyDto{
+		Rules:          []QuotaRule{},
+		RefuseCode:     429,
+		RefuseResponse: "API rate limit exceeded",
+	}
+	dto.Switch = false
+	return dto
+}
+
+func (policy Policy) UnmarshalConfig(config []byte) (apipolicy.PolicyDto, error, string) {
+	policyDto := &PolicyDto{}
+	err := json.Unmarshal(config, policyDto)
+	if err != nil {
+		return nil, errors.Wrapf(err, "json parse config failed, config:%s", config), "Invalid config"
+	}
+	ok, msg := policyDto.IsValidDto()
+	if !ok {
+		return nil, errors.Errorf("invalid policy dto, msg:%s", msg), msg
+	}
+	return policyDto, nil, ""
+}
+
+// kongConsumerIds 将 hepa 的调用方 id 转换为 kong consumer id，并确认调用方属于当前环境
+func (policy Policy) kongConsumerIds(ids []string, zone *orm.GatewayZone) ([]string, error) {
+	consumerDb, err := db.NewGatewayConsumerServiceImpl()
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, id := range ids {
+		consumer, err := consumerDb.GetById(id)
+		if err != nil {
+			return nil, err
+		}
+		if consumer == nil {
+			return nil, errors.Errorf("consumer not found, id:%s", id)
+		}
+		if consumer.ProjectId != zone.DiceProjectId || consumer.Env != zone.DiceEnv {
+			return nil, errors.Errorf("consumer %s not belong to project %s env %s",
+				consumer.ConsumerName, zone.DiceProjectId, zone.DiceEnv)
+		}
+		res = append(res, consumer.ConsumerId)
+	}
+	return res, nil
+}
+
+// buildScript 生成按规则计数的 lua 脚本，consumers 为每条规则对应的 kong consumer id
+func (policy Policy) buildScript(dto *PolicyDto, zoneId string, consumers [][]string) (string, error) {
+	data := scriptData{
+		Prefix:            "quota:" + zoneId + ":",
+		RefuseCode:        dto.RefuseCode,
+		RefuseResponse:    dto.RefuseResponse,
+		HideClientHeaders: dto.HideClientHeaders,
+	}
+	for i, rule := range dto.Rules {
+		data.Rules = append(data.Rules, scriptRule{
+			LimitBy:    string(rule.LimitBy),
+			HeaderName: rule.HeaderName,
+			Consumers:  consumers[i],
+			Burst:      rule.Burst,
+			Windows:    rule.windows(),
+		})
+	}
+	var b strings.Builder
+	err := script.Execute(&b, data)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return b.String(), nil
+}
+
+// buildPluginReq 生成未启用的插件模板，由 zone 的 domain-policy 按 api 启用
+func (policy Policy) buildPluginReq(dto *PolicyDto, zone *orm.GatewayZone) (*kongDto.KongPluginReqDto, error) {
+	consumers := make([][]string, len(dto.Rules))
+	for i, rule := range dto.Rules {
+		if len(rule.ConsumerIds) == 0 {
+			continue
+		}
+		ids, err := policy.kongConsumerIds(rule.ConsumerIds, zone)
+		if err != nil {
+			return nil, err
+		}
+		consumers[i] = ids
+	}
+	code, err := policy.buildScript(dto, zone.Id, consumers)
+	if err != nil {
+		return nil, err
+	}
+	disable := false
+	return &kongDto.KongPluginReqDto{
+		Name: PLUGIN_NAME,
+		Config: map[string]interface{}{
+			"access": []string{code},
+		},
+		Enabled: &disable,
+	}, nil
+}
+
+func (policy Policy) ParseConfig(dto apipolicy.PolicyDto, ctx map[string]interface{}) (apipolicy.PolicyConfig, error) {
+	res := apipolicy.PolicyConfig{}
+	policyDto, ok := dto.(*PolicyDto)
+	if !ok {
+		return res, errors.Errorf("invalid config:%+v", dto)
+	}
+	value, ok := ctx[apipolicy.CTX_KONG_ADAPTER]
+	if !ok {
+		return res, errors.Errorf("get identify failed:%+v", ctx)
+	}
+	adapter, ok := value.(kong.KongAdapter)
+	if !ok {
+		return res, errors.Errorf("convert failed:%+v", value)
+	}
+	value, ok = ctx[apipolicy.CTX_ZONE]
+	if !ok {
+		return res, errors.Errorf("get identify failed:%+v", ctx)
+	}
+	zone, ok := value.(*orm.GatewayZone)
+	if !ok {
+		return res, errors.Errorf("convert failed:%+v", value)
+	}
+	policyDb, _ := db.NewGatewayPolicyServiceImpl()
+	exist, err := policyDb.GetByAny(&orm.GatewayPolicy{
+		ZoneId:     zone.Id,
+		PluginName: PLUGIN_NAME,
+		Category:   CATEGORY,
+	})
+	if err != nil {
+		return res, err
+	}
+	if !policyDto.Switch {
+		if exist != nil {
+			err = adapter.RemovePlugin(exist.PluginId)
+			if err != nil {
+				return res, err
+			}
+			_ = policyDb.DeleteById(exist.Id)
+			res.KongPolicyChange = true
+		}
+		return res, nil
+	}
+	req, err := policy.buildPluginReq(policyDto, zone)
+	if err != nil {
+		return res, err
+	}
+	// 配置变更时原地更新插件模板，已绑定的 domain-policy 不受影响
+	if exist != nil {
+		req.Id = exist.PluginId
+		resp, err := adapter.CreateOrUpdatePluginById(req)
+		if err != nil {
+			return res, err
+		}
+		configByte, err := json.Marshal(resp.Config)
+		if err != nil {
+			return res, err
+		}
+		exist.Config = configByte
+		err = policyDb.Update(exist)
+		if err != nil {
+			return res, err
+		}
+		return res, nil
+	}
+	resp, err := adapter.AddPlugin(req)
+	if err != nil {
+		return res, err
+	}
+	if resp == nil {
+		return res, errors.Errorf("kong plugin %s not enabled", PLUGIN_NAME)
+	}
+	configByte, err := json.Marshal(resp.Config)
+	if err != nil {
+		return res, err
+	}
+	err = policyDb.Insert(&orm.GatewayPolicy{
+		ZoneId:     zone.Id,
+		PluginName: PLUGIN_NAME,
+		Category:   CATEGORY,
+		PluginId:   resp.Id,
+		Config:     configByte,
+		Enabled:    1,
+	})
+	if err != nil {
+		return res, err
+	}
+	res.KongPolicyChange = true
+	return res, nil
+}
+
+func init() {
+	err := apipolicy.RegisterPolicyEngine("safety-quota", &Policy{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_luaString(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"X-Tenant-Id", `"X-Tenant-Id"`},
+		{`say "hi"`, `"say \034hi\034"`},
+		{"a\nb\\", `"a\010b\092"`},
+		{"限流", `"\233\153\144\230\181\129"`},
+	}
+	for _, tt := range tests {
+		if got := luaString(tt.value); got != tt.want {
+			t.Errorf("luaString(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPolicy_buildScript(t *testing.T) {
+	dto := &PolicyDto{
+		Rules: []QuotaRule{
+			{LimitBy: LIMIT_BY_HEADER, HeaderName: "X-Tenant-Id", Second: 10, Day: 1000, Burst: 5},
+			{LimitBy: LIMIT_BY_CONSUMER, ConsumerIds: []string{"c1"}, Minute: 60},
+		},
+		RefuseCode:     429,
+		RefuseResponse: "API rate limit exceeded",
+	}
+	code, err := Policy{}.buildScript(dto, "z1", [][]string{nil, {"kong-consumer"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`local prefix = "quota:z1:"`,
+		`    limit_by = "header",
+    header_name = "X-Tenant-Id",
+    consumers = nil,
+    burst = 5,
+    windows = {
+      { name = "Second", size = 1, limit = 10 },
+      { name = "Day", size = 86400, limit = 1000 },
+    },`,
+		`    limit_by = "consumer",
+    header_name = "",
+    consumers = { ["kong-consumer"] = true, },
+    burst = 0,
+    windows = {
+      { name = "Minute", size = 60, limit = 60 },
+    },`,
+		`local refuse_code = 429`,
+		`local refuse_response = "API rate limit exceeded"`,
+		`local hide_client_headers = false`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("buildScript() missing:\n%s\ngot:\n%s", want, code)
+		}
+	}
+}
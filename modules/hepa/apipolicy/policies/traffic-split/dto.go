@@ -0,0 +1,171 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trafficsplit
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+)
+
+const (
+	// kong target 的权重范围是 0-1000
+	WEIGHT_SCALE = 10
+	// ingress 命中请求头/cookie 规则后设置该请求头，kong 按它匹配灰度路由
+	SPLIT_HEADER = "X-Erda-Traffic-Split"
+)
+
+var (
+	headerRegex = regexp.MustCompile(`^[0-9a-zA-Z-]+$`)
+	// cookie 名称会拼接成 nginx 变量名，不能包含 - 和 .
+	cookieRegex = regexp.MustCompile(`^[0-9a-zA-Z_]+$`)
+	valueRegex  = regexp.MustCompile(`^[0-9a-zA-Z_.-]*$`)
+)
+
+// MatchRule 请求头或 cookie 匹配规则，Value 为空时只要带上该请求头/cookie 即命中
+type MatchRule struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PolicyDto struct {
+	apipolicy.BaseDto
+	// 灰度流量转发的目标服务
+	RuntimeServiceId string `json:"runtimeServiceId"`
+	// 按比例转发到灰度服务的流量百分比，0-100
+	Weight int64      `json:"weight"`
+	Header *MatchRule `json:"header,omitempty"`
+	Cookie *MatchRule `json:"cookie,omitempty"`
+}
+
+func (dto PolicyDto) IsValidDto() (bool, string) {
+	if !dto.Switch {
+		return true, ""
+	}
+	if dto.RuntimeServiceId == "" {
+		return false, "灰度服务不能为空"
+	}
+	if dto.Weight < 0 || dto.Weight > 100 {
+		return false, "灰度流量比例需要在0到100之间"
+	}
+	if dto.Header != nil && !headerRegex.MatchString(dto.Header.Name) {
+		return false, fmt.Sprintf("请求头名称不合法:%s", dto.Header.Name)
+	}
+	if dto.Header != nil && !valueRegex.MatchString(dto.Header.Value) {
+		return false, fmt.Sprintf("请求头匹配值不合法:%s", dto.Header.Value)
+	}
+	if dto.Cookie != nil && !cookieRegex.MatchString(dto.Cookie.Name) {
+		return false, fmt.Sprintf("cookie名称不合法:%s", dto.Cookie.Name)
+	}
+	if dto.Cookie != nil && !valueRegex.MatchString(dto.Cookie.Value) {
+		return false, fmt.Sprintf("cookie匹配值不合法:%s", dto.Cookie.Value)
+	}
+	if dto.Weight == 0 && !dto.hasMatchRule() {
+		return false, "灰度流量比例为0时需要配置请求头或cookie匹配规则"
+	}
+	return true, ""
+}
+
+func (dto PolicyDto) hasMatchRule() bool {
+	return dto.Header != nil || dto.Cookie != nil
+}
+
+func matchCondition(variable string, rule *MatchRule) string {
+	if rule.Value == "" {
+		return fmt.Sprintf(`%s != ""`, variable)
+	}
+	return fmt.Sprintf(`%s = "%s"`, variable, rule.Value)
+}
+
+// buildLocationSnippet 命中请求头/cookie 规则的请求带上 SPLIT_HEADER，未命中时清除客户端伪造的同名请求头
+func buildLocationSnippet(dto *PolicyDto, zoneId string) string {
+	if !dto.Switch || !dto.hasMatchRule() {
+		return ""
+	}
+	snippet := "set $traffic_split \"\";\n"
+	if dto.Header != nil {
+		variable := "$http_" + strings.Replace(strings.ToLower(dto.Header.Name), "-", "_", -1)
+		snippet += fmt.Sprintf("if (%s) {\n  set $traffic_split \"%s\";\n}\n", matchCondition(variable, dto.Header), zoneId)
+	}
+	if dto.Cookie != nil {
+		variable := "$cookie_" + dto.Cookie.Name
+		snippet += fmt.Sprintf("if (%s) {\n  set $traffic_split \"%s\";\n}\n", matchCondition(variable, dto.Cookie), zoneId)
+	}
+	snippet += fmt.Sprintf("more_set_input_headers \"%s: $traffic_split\";\n", SPLIT_HEADER)
+	return snippet
+}
+
+// parseTarget 将 http://host:port/path 或 host:port 形式的地址转换为 host 和端口
+func parseTarget(addr string) (string, int, error) {
+	if addr == "" {
+		return "", 0, errors.New("empty address")
+	}
+	port := 80
+	hostPort := addr
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil || u.Host == "" {
+			return "", 0, errors.Errorf("invalid address %s", addr)
+		}
+		hostPort = u.Host
+		if u.Scheme == "https" {
+			port = 443
+		}
+	}
+	host, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		// 没有端口
+		return hostPort, port, nil
+	}
+	port, err = strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, errors.Errorf("invalid port of address %s", addr)
+	}
+	return host, port, nil
+}
+
+func targetOf(addr string) (string, error) {
+	host, port, err := parseTarget(addr)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+// buildTargets 计算主服务与灰度服务的 kong target 权重
+func buildTargets(primary, canary string, weight int64) (map[string]int64, error) {
+	primaryTarget, err := targetOf(primary)
+	if err != nil {
+		return nil, err
+	}
+	canaryTarget, err := targetOf(canary)
+	if err != nil {
+		return nil, err
+	}
+	if primaryTarget == canaryTarget {
+		return nil, errors.Errorf("canary target %s is the same as primary", canaryTarget)
+	}
+	return map[string]int64{
+		primaryTarget: (100 - weight) * WEIGHT_SCALE,
+		canaryTarget:  weight * WEIGHT_SCALE,
+	}, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trafficsplit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+)
+
+func TestPolicyDto_IsValidDto(t *testing.T) {
+	tests := []struct {
+		name string
+		dto  PolicyDto
+		want bool
+	}{
+		{"switch off", PolicyDto{}, true},
+		{"no runtime service", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, Weight: 10}, false},
+		{"negative weight", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, RuntimeServiceId: "1", Weight: -1}, false},
+		{"weight over 100", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, RuntimeServiceId: "1", Weight: 101}, false},
+		{"zero weight without rule", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, RuntimeServiceId: "1"}, false},
+		{"zero weight with header", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, RuntimeServiceId: "1",
+			Header: &MatchRule{Name: "X-Canary", Value: "always"}}, true},
+		{"invalid header name", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, RuntimeServiceId: "1", Weight: 10,
+			Header: &MatchRule{Name: "X Canary"}}, false},
+		{"invalid header value", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, RuntimeServiceId: "1", Weight: 10,
+			Header: &MatchRule{Name: "X-Canary", Value: `a"; set $x 1`}}, false},
+		{"invalid cookie name", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, RuntimeServiceId: "1",
+			Cookie: &MatchRule{Name: "canary-user"}}, false},
+		{"valid cookie", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, RuntimeServiceId: "1",
+			Cookie: &MatchRule{Name: "canary_user", Value: "1"}}, true},
+		{"valid", PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, RuntimeServiceId: "1", Weight: 20}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, msg := tt.dto.IsValidDto(); got != tt.want {
+				t.Errorf("IsValidDto() = %v, want %v, msg: %s", got, tt.want, msg)
+			}
+		})
+	}
+}
+
+func Test_parseTarget(t *testing.T) {
+	tests := []struct {
+		addr     string
+		wantHost string
+		wantPort int
+		wantErr  bool
+	}{
+		{"http://foo.svc:8080/api", "foo.svc", 8080, false},
+		{"http://foo.svc", "foo.svc", 80, false},
+		{"https://foo.svc/api", "foo.svc", 443, false},
+		{"10.0.0.1:9090", "10.0.0.1", 9090, false},
+		{"http://foo.svc:abc", "", 0, true},
+		{"", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			host, port, err := parseTarget(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if host != tt.wantHost || port != tt.wantPort {
+				t.Errorf("parseTarget() = %s:%d, want %s:%d", host, port, tt.wantHost, tt.wantPort)
+			}
+		})
+	}
+}
+
+func Test_buildTargets(t *testing.T) {
+	targets, err := buildTargets("http://foo.svc:8080/api", "bar.svc:8080", 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{"foo.svc:8080": 800, "bar.svc:8080": 200}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("buildTargets() = %v, want %v", targets, want)
+	}
+	if _, err = buildTargets("http://foo.svc:8080", "foo.svc:8080", 20); err == nil {
+		t.Error("same primary and canary target should fail")
+	}
+}
+
+func TestUpstreamName(t *testing.T) {
+	if got := UpstreamName("abc"); got != "traffic-split-abc" {
+		t.Errorf("UpstreamName() = %s", got)
+	}
+}
+
+func Test_buildLocationSnippet(t *testing.T) {
+	if got := buildLocationSnippet(&PolicyDto{BaseDto: apipolicy.BaseDto{Switch: true}, Weight: 10}, "z1"); got != "" {
+		t.Errorf("buildLocationSnippet() without rule = %q, want empty", got)
+	}
+	dto := &PolicyDto{
+		BaseDto: apipolicy.BaseDto{Switch: true},
+		Header:  &MatchRule{Name: "X-Canary", Value: "always"},
+		Cookie:  &MatchRule{Name: "canary"},
+	}
+	want := "set $traffic_split \"\";\n" +
+		"if ($http_x_canary = \"always\") {\n  set $traffic_split \"z1\";\n}\n" +
+		"if ($cookie_canary != \"\") {\n  set $traffic_split \"z1\";\n}\n" +
+		"more_set_input_headers \"X-Erda-Traffic-Split: $traffic_split\";\n"
+	if got := buildLocationSnippet(dto, "z1"); got != want {
+		t.Errorf("buildLocationSnippet() = %q, want %q", got, want)
+	}
+	dto.Switch = false
+	if got := buildLocationSnippet(dto, "z1"); got != "" {
+		t.Errorf("buildLocationSnippet() switch off = %q, want empty", got)
+	}
+}
@@ -0,0 +1,403 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trafficsplit
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+	gw "github.com/erda-project/erda/modules/hepa/gateway/dto"
+	"github.com/erda-project/erda/modules/hepa/kong"
+	kongDto "github.com/erda-project/erda/modules/hepa/kong/dto"
+	"github.com/erda-project/erda/modules/hepa/repository/orm"
+	db "github.com/erda-project/erda/modules/hepa/repository/service"
+)
+
+const UPSTREAM_PREFIX = "traffic-split-"
+
+// 路由级插件，灰度路由需要和原路由保持一致
+var routePlugins = []string{"path-variable", "set-route-info"}
+
+type Policy struct {
+	apipolicy.BasePolicy
+}
+
+// UpstreamName 灰度分流使用的 kong upstream 名称，kong service 的 host 指向它即按权重分流；
+// 按请求头/cookie 分流时的灰度 kong service 和 route 也使用该名称
+func UpstreamName(zoneId string) string {
+	return UPSTREAM_PREFIX + zoneId
+}
+
+// Enabled 判断 api 所在 zone 是否开启了灰度分流
+func Enabled(adapter kong.KongAdapter, zoneId string) (bool, error) {
+	upstream, err := adapter.GetUpstream(UpstreamName(zoneId))
+	if err != nil {
+		return false, err
+	}
+	return upstream != nil, nil
+}
+
+// KeepUpstream 重建 kong service 后，让已开启灰度分流的 service 继续指向 upstream
+func KeepUpstream(adapter kong.KongAdapter, zoneId, serviceId string) error {
+	enabled, err := Enabled(adapter, zoneId)
+	if err != nil || !enabled {
+		return err
+	}
+	_, err = adapter.UpdateService(&kongDto.KongServiceReqDto{
+		ServiceId: serviceId,
+		Host:      UpstreamName(zoneId),
+	})
+	return err
+}
+
+// KeepCanaryRoute 原路由更新后，同步灰度路由的匹配条件和路由级插件
+func KeepCanaryRoute(adapter kong.KongAdapter, zoneId, routeId string) error {
+	canary, err := adapter.GetRoute(UpstreamName(zoneId))
+	if err != nil || canary == nil {
+		return err
+	}
+	return syncCanaryRoute(adapter, zoneId, routeId, canary.Service.Id)
+}
+
+// Release 删除 api 时清理灰度分流的 upstream 和灰度路由
+func Release(adapter kong.KongAdapter, zoneId string) error {
+	err := releaseCanary(adapter, zoneId)
+	if err != nil {
+		return err
+	}
+	return adapter.DeleteUpstream(UpstreamName(zoneId))
+}
+
+// buildCanaryRouteReq 复制原路由的匹配条件，并要求请求带有值为 zoneId 的 SPLIT_HEADER
+func buildCanaryRouteReq(primary *kongDto.KongRouteRespDto, zoneId, serviceId string) *kongDto.KongRouteReqDto {
+	name := UpstreamName(zoneId)
+	return &kongDto.KongRouteReqDto{
+		Name:          name,
+		Protocols:     primary.Protocols,
+		Methods:       primary.Methods,
+		Hosts:         primary.Hosts,
+		Paths:         primary.Paths,
+		Headers:       map[string][]string{SPLIT_HEADER: {zoneId}},
+		Service:       &kongDto.Service{Id: serviceId},
+		RegexPriority: primary.RegexPriority,
+		RouteId:       name,
+	}
+}
+
+// buildCanaryServiceReq 灰度 service 沿用原 service 的路径，地址指向灰度服务
+func buildCanaryServiceReq(primary *orm.GatewayService, canaryAddr, zoneId string) (*kongDto.KongServiceReqDto, error) {
+	host, port, err := parseTarget(canaryAddr)
+	if err != nil {
+		return nil, err
+	}
+	protocol := "http"
+	if strings.HasPrefix(canaryAddr, "https://") {
+		protocol = "https"
+	}
+	name := UpstreamName(zoneId)
+	retries := 0
+	return &kongDto.KongServiceReqDto{
+		Name:           name,
+		Protocol:       protocol,
+		Host:           host,
+		Port:           port,
+		Path:           primary.Path,
+		Retries:        &retries,
+		ConnectTimeout: 5000,
+		ReadTimeout:    60000,
+		WriteTimeout:   60000,
+		ServiceId:      name,
+	}, nil
+}
+
+func syncCanaryRoute(adapter kong.KongAdapter, zoneId, routeId, serviceId string) error {
+	primary, err := adapter.GetRoute(routeId)
+	if err != nil {
+		return err
+	}
+	if primary == nil {
+		return errors.Errorf("kong route %s not found", routeId)
+	}
+	canary, err := adapter.CreateOrUpdateRoute(buildCanaryRouteReq(primary, zoneId, serviceId))
+	if err != nil {
+		return err
+	}
+	for _, name := range routePlugins {
+		plugin, err := adapter.GetPlugin(&kongDto.KongPluginReqDto{Name: name, RouteId: routeId})
+		if err != nil {
+			return err
+		}
+		if plugin == nil {
+			err = adapter.DeletePluginIfExist(&kongDto.KongPluginReqDto{Name: name, RouteId: canary.Id})
+		} else {
+			_, err = adapter.CreateOrUpdatePlugin(&kongDto.KongPluginReqDto{
+				Name:    name,
+				RouteId: canary.Id,
+				Config:  plugin.Config,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func syncCanary(adapter kong.KongAdapter, zoneId, apiId, canaryAddr string, primary *orm.GatewayService) error {
+	routeDb, err := db.NewGatewayRouteServiceImpl()
+	if err != nil {
+		return err
+	}
+	route, err := routeDb.GetByApiId(apiId)
+	if err != nil {
+		return err
+	}
+	if route == nil {
+		return errors.Errorf("kong route of api %s not found", apiId)
+	}
+	req, err := buildCanaryServiceReq(primary, canaryAddr, zoneId)
+	if err != nil {
+		return err
+	}
+	service, err := adapter.CreateOrUpdateService(req)
+	if err != nil {
+		return err
+	}
+	return syncCanaryRoute(adapter, zoneId, route.RouteId, service.Id)
+}
+
+// releaseCanary 删除灰度路由及其 service，路由上的插件由 kong 级联删除
+func releaseCanary(adapter kong.KongAdapter, zoneId string) error {
+	name := UpstreamName(zoneId)
+	err := adapter.DeleteRoute(name)
+	if err != nil {
+		return err
+	}
+	return adapter.DeleteService(name)
+}
+
+func (policy Policy) CreateDefaultConfig(ctx map[string]interface{}) apipolicy.PolicyDto {
+	dto := &PolicyDto{}
+	dto.Switch = false
+	return dto
+}
+
+func (policy Policy) UnmarshalConfig(config []byte) (apipolicy.PolicyDto, error, string) {
+	policyDto := &PolicyDto{}
+	err := json.Unmarshal(config, policyDto)
+	if err != nil {
+		return nil, errors.Wrapf(err, "json parse config failed, config:%s", config), "Invalid config"
+	}
+	ok, msg := policyDto.IsValidDto()
+	if !ok {
+		return nil, errors.Errorf("invalid policy dto, msg:%s", msg), msg
+	}
+	return policyDto, nil, ""
+}
+
+func (policy Policy) NeedSerialUpdate() bool {
+	return true
+}
+
+func (policy Policy) disable(adapter kong.KongAdapter, zone *orm.GatewayZone, api *orm.GatewayPackageApi) error {
+	err := releaseCanary(adapter, zone.Id)
+	if err != nil {
+		return err
+	}
+	enabled, err := Enabled(adapter, zone.Id)
+	if err != nil || !enabled {
+		return err
+	}
+	serviceDb, err := db.NewGatewayServiceServiceImpl()
+	if err != nil {
+		return err
+	}
+	service, err := serviceDb.GetByApiId(api.Id)
+	if err != nil {
+		return err
+	}
+	if service != nil {
+		host, port, err := parseTarget(api.RedirectAddr)
+		if err != nil {
+			return err
+		}
+		_, err = adapter.UpdateService(&kongDto.KongServiceReqDto{
+			ServiceId: service.ServiceId,
+			Host:      host,
+			Port:      port,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return Release(adapter, zone.Id)
+}
+
+func (policy Policy) syncTargets(adapter kong.KongAdapter, upstreamName string, targets map[string]int64) error {
+	upstream, err := adapter.GetUpstream(upstreamName)
+	if err != nil {
+		return err
+	}
+	if upstream == nil {
+		upstream, err = adapter.CreateUpstream(&kongDto.KongUpstreamDto{Name: upstreamName})
+		if err != nil {
+			return err
+		}
+	}
+	status, err := adapter.GetUpstreamStatus(upstream.Id)
+	if err != nil {
+		return err
+	}
+	for _, target := range status.Data {
+		if weight, ok := targets[target.Target]; ok && weight > 0 {
+			continue
+		}
+		err = adapter.DeleteUpstreamTarget(upstream.Id, target.Target)
+		if err != nil {
+			return err
+		}
+	}
+	for target, weight := range targets {
+		// 权重为0时 kong 会使用默认权重，直接不添加该 target
+		if weight == 0 {
+			continue
+		}
+		_, err = adapter.AddUpstreamTarget(upstream.Id, &kongDto.KongTargetDto{
+			Target: target,
+			Weight: weight,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (policy Policy) ParseConfig(dto apipolicy.PolicyDto, ctx map[string]interface{}) (apipolicy.PolicyConfig, error) {
+	res := apipolicy.PolicyConfig{}
+	policyDto, ok := dto.(*PolicyDto)
+	if !ok {
+		return res, errors.Errorf("invalid config:%+v", dto)
+	}
+	value, ok := ctx[apipolicy.CTX_KONG_ADAPTER]
+	if !ok {
+		return res, errors.Errorf("get identify failed:%+v", ctx)
+	}
+	adapter, ok := value.(kong.KongAdapter)
+	if !ok {
+		return res, errors.Errorf("convert failed:%+v", value)
+	}
+	value, ok = ctx[apipolicy.CTX_ZONE]
+	if !ok {
+		return res, errors.Errorf("get identify failed:%+v", ctx)
+	}
+	zone, ok := value.(*orm.GatewayZone)
+	if !ok {
+		return res, errors.Errorf("convert failed:%+v", value)
+	}
+	// 灰度分流只作用在单个 api 上
+	if zone.Type != db.ZONE_TYPE_PACKAGE_API {
+		if policyDto.Switch {
+			return res, errors.Errorf("traffic split only supports package api, zone:%s", zone.Id)
+		}
+		return res, nil
+	}
+	packageApiDb, err := db.NewGatewayPackageApiServiceImpl()
+	if err != nil {
+		return res, err
+	}
+	api, err := packageApiDb.GetByAny(&orm.GatewayPackageApi{ZoneId: zone.Id})
+	if err != nil {
+		return res, err
+	}
+	if api == nil {
+		return res, errors.Errorf("package api of zone %s not found", zone.Id)
+	}
+	locationSnippet := buildLocationSnippet(policyDto, zone.Id)
+	res.IngressAnnotation = &apipolicy.IngressAnnotation{
+		LocationSnippet: &locationSnippet,
+	}
+	if !policyDto.Switch {
+		res.AnnotationReset = true
+		return res, policy.disable(adapter, zone, api)
+	}
+	if api.RedirectType != gw.RT_URL {
+		return res, errors.Errorf("traffic split only supports api redirect to url, api:%s", api.Id)
+	}
+	runtimeDb, err := db.NewGatewayRuntimeServiceServiceImpl()
+	if err != nil {
+		return res, err
+	}
+	runtimeService, err := runtimeDb.Get(policyDto.RuntimeServiceId)
+	if err != nil {
+		return res, err
+	}
+	if runtimeService == nil {
+		return res, errors.Errorf("runtime service not found, id:%s", policyDto.RuntimeServiceId)
+	}
+	if runtimeService.ProjectId != zone.DiceProjectId || runtimeService.Workspace != zone.DiceEnv {
+		return res, errors.Errorf("runtime service %s not belong to project %s env %s",
+			runtimeService.Id, zone.DiceProjectId, zone.DiceEnv)
+	}
+	if runtimeService.InnerAddress == "" {
+		return res, errors.Errorf("runtime service %s has no inner address", runtimeService.Id)
+	}
+	targets, err := buildTargets(api.RedirectAddr, runtimeService.InnerAddress, policyDto.Weight)
+	if err != nil {
+		return res, err
+	}
+	serviceDb, err := db.NewGatewayServiceServiceImpl()
+	if err != nil {
+		return res, err
+	}
+	service, err := serviceDb.GetByApiId(api.Id)
+	if err != nil {
+		return res, err
+	}
+	if service == nil {
+		return res, errors.Errorf("kong service of api %s not found", api.Id)
+	}
+	upstreamName := UpstreamName(zone.Id)
+	err = policy.syncTargets(adapter, upstreamName, targets)
+	if err != nil {
+		return res, err
+	}
+	_, err = adapter.UpdateService(&kongDto.KongServiceReqDto{
+		ServiceId: service.ServiceId,
+		Host:      upstreamName,
+	})
+	if err != nil {
+		return res, err
+	}
+	if policyDto.hasMatchRule() {
+		err = syncCanary(adapter, zone.Id, api.Id, runtimeService.InnerAddress, service)
+	} else {
+		err = releaseCanary(adapter, zone.Id)
+	}
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func init() {
+	err := apipolicy.RegisterPolicyEngine("traffic-split", &Policy{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trafficsplit
+
+import (
+	"reflect"
+	"testing"
+
+	kongDto "github.com/erda-project/erda/modules/hepa/kong/dto"
+	"github.com/erda-project/erda/modules/hepa/repository/orm"
+)
+
+func Test_buildCanaryRouteReq(t *testing.T) {
+	primary := &kongDto.KongRouteRespDto{
+		Id:            "r1",
+		Protocols:     []string{"http", "https"},
+		Methods:       []string{"GET"},
+		Hosts:         []string{"foo.example.com"},
+		Paths:         []string{"/api/foo"},
+		RegexPriority: 2,
+	}
+	req := buildCanaryRouteReq(primary, "z1", "s1")
+	want := &kongDto.KongRouteReqDto{
+		Name:          "traffic-split-z1",
+		Protocols:     []string{"http", "https"},
+		Methods:       []string{"GET"},
+		Hosts:         []string{"foo.example.com"},
+		Paths:         []string{"/api/foo"},
+		Headers:       map[string][]string{SPLIT_HEADER: {"z1"}},
+		Service:       &kongDto.Service{Id: "s1"},
+		RegexPriority: 2,
+		RouteId:       "traffic-split-z1",
+	}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("buildCanaryRouteReq() = %+v, want %+v", req, want)
+	}
+}
+
+func Test_buildCanaryServiceReq(t *testing.T) {
+	req, err := buildCanaryServiceReq(&orm.GatewayService{Protocol: "https", Path: "/v1"}, "bar.svc:8080", "z1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.ServiceId != "traffic-split-z1" || req.Name != "traffic-split-z1" {
+		t.Errorf("unexpected service name: %+v", req)
+	}
+	if req.Protocol != "http" || req.Host != "bar.svc" || req.Port != 8080 || req.Path != "/v1" {
+		t.Errorf("unexpected service address: %+v", req)
+	}
+	if _, err = buildCanaryServiceReq(&orm.GatewayService{}, "", "z1"); err == nil {
+		t.Error("buildCanaryServiceReq() with empty address should fail")
+	}
+}
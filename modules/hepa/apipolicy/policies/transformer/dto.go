@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transformer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+)
+
+var (
+	headerRegex = regexp.MustCompile(`^[0-9a-zA-Z-_]+$`)
+	fieldRegex  = regexp.MustCompile(`^[0-9a-zA-Z-_\.\[\]]+$`)
+)
+
+type KeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Transform 对同一类字段的变换，按 remove、rename、replace、add 的顺序执行
+type Transform struct {
+	Remove []string `json:"remove,omitempty"`
+	Rename []Rename `json:"rename,omitempty"`
+	// 字段存在时替换
+	Replace []KeyValue `json:"replace,omitempty"`
+	// 字段不存在时添加
+	Add []KeyValue `json:"add,omitempty"`
+}
+
+type RequestTransform struct {
+	Headers     Transform `json:"headers"`
+	Querystring Transform `json:"querystring"`
+	Body        Transform `json:"body"`
+}
+
+type ResponseTransform struct {
+	Headers Transform `json:"headers"`
+	Body    Transform `json:"body"`
+}
+
+type PolicyDto struct {
+	apipolicy.BaseDto
+	Request  RequestTransform  `json:"request"`
+	Response ResponseTransform `json:"response"`
+}
+
+func (t Transform) isEmpty() bool {
+	return len(t.Remove) == 0 && len(t.Rename) == 0 && len(t.Replace) == 0 && len(t.Add) == 0
+}
+
+func (t Transform) isValid(name string, regex *regexp.Regexp) (bool, string) {
+	var keys []string
+	keys = append(keys, t.Remove...)
+	for _, item := range t.Rename {
+		keys = append(keys, item.From, item.To)
+	}
+	for _, item := range t.Replace {
+		keys = append(keys, item.Key)
+	}
+	for _, item := range t.Add {
+		keys = append(keys, item.Key)
+	}
+	for _, key := range keys {
+		if !regex.MatchString(key) {
+			return false, fmt.Sprintf("%s名称不合法:%s", name, key)
+		}
+	}
+	// kong 以第一个冒号分隔字段名和值，值中可以包含冒号
+	var values []KeyValue
+	values = append(values, t.Replace...)
+	values = append(values, t.Add...)
+	for _, item := range values {
+		if strings.ContainsAny(item.Value, "\r\n") {
+			return false, fmt.Sprintf("%s的值不能包含换行:%s", name, item.Key)
+		}
+	}
+	return true, ""
+}
+
+func (req RequestTransform) isEmpty() bool {
+	return req.Headers.isEmpty() && req.Querystring.isEmpty() && req.Body.isEmpty()
+}
+
+func (resp ResponseTransform) isEmpty() bool {
+	return resp.Headers.isEmpty() && resp.Body.isEmpty()
+}
+
+func (dto PolicyDto) IsValidDto() (bool, string) {
+	if !dto.Switch {
+		return true, ""
+	}
+	if dto.Request.isEmpty() && dto.Response.isEmpty() {
+		return false, "至少需要配置一条转换规则"
+	}
+	checks := []struct {
+		transform Transform
+		name      string
+		regex     *regexp.Regexp
+	}{
+		{dto.Request.Headers, "请求头", headerRegex},
+		{dto.Request.Querystring, "请求参数", fieldRegex},
+		{dto.Request.Body, "请求体字段", fieldRegex},
+		{dto.Response.Headers, "应答头", headerRegex},
+		{dto.Response.Body, "应答体字段", fieldRegex},
+	}
+	for _, check := range checks {
+		if ok, msg := check.transform.isValid(check.name, check.regex); !ok {
+			return false, msg
+		}
+	}
+	return true, ""
+}
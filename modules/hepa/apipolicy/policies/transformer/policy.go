@@ -0,0 +1,230 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transformer
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/modules/hepa/apipolicy"
+	"github.com/erda-project/erda/modules/hepa/kong"
+	kongDto "github.com/erda-project/erda/modules/hepa/kong/dto"
+	"github.com/erda-project/erda/modules/hepa/repository/orm"
+	db "github.com/erda-project/erda/modules/hepa/repository/service"
+)
+
+const (
+	REQUEST_PLUGIN  = "request-transformer"
+	RESPONSE_PLUGIN = "response-transformer"
+)
+
+type Policy struct {
+	apipolicy.BasePolicy
+}
+
+func (policy Policy) CreateDefaultConfig(ctx map[string]interface{}) apipolicy.PolicyDto {
+	dto := &PolicyDto{}
+	dto.Switch = false
+	return dto
+}
+
+func (policy Policy) UnmarshalConfig(config []byte) (apipolicy.PolicyDto, error, string) {
+	policyDto := &PolicyDto{}
+	err := json.Unmarshal(config, policyDto)
+	if err != nil {
+		return nil, errors.Wrapf(err, "json parse config failed, config:%s", config), "Invalid config"
+	}
+	ok, msg := policyDto.IsValidDto()
+	if !ok {
+		return nil, errors.Errorf("invalid policy dto, msg:%s", msg), msg
+	}
+	return policyDto, nil, ""
+}
+
+func toKeyValues(items []KeyValue) []string {
+	res := []string{}
+	for _, item := range items {
+		res = append(res, item.Key+":"+item.Value)
+	}
+	return res
+}
+
+func toRenames(items []Rename) []string {
+	res := []string{}
+	for _, item := range items {
+		res = append(res, item.From+":"+item.To)
+	}
+	return res
+}
+
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
+
+// buildConfig 生成 kong request-transformer/response-transformer 的配置，fields 的 key 为 kong 配置中的字段类型
+func buildConfig(fields map[string]Transform) map[string]interface{} {
+	remove := map[string]interface{}{}
+	rename := map[string]interface{}{}
+	replace := map[string]interface{}{}
+	add := map[string]interface{}{}
+	for field, transform := range fields {
+		remove[field] = nonNil(transform.Remove)
+		replace[field] = toKeyValues(transform.Replace)
+		add[field] = toKeyValues(transform.Add)
+		rename[field] = toRenames(transform.Rename)
+	}
+	return map[string]interface{}{
+		"remove":  remove,
+		"rename":  rename,
+		"replace": replace,
+		"add":     add,
+	}
+}
+
+// buildRequestPluginReq 生成未启用的插件模板，由 zone 的 domain-policy 按 api 启用
+func (policy Policy) buildRequestPluginReq(dto *PolicyDto) *kongDto.KongPluginReqDto {
+	disable := false
+	return &kongDto.KongPluginReqDto{
+		Name: REQUEST_PLUGIN,
+		Config: buildConfig(map[string]Transform{
+			"headers":     dto.Request.Headers,
+			"querystring": dto.Request.Querystring,
+			"body":        dto.Request.Body,
+		}),
+		Enabled: &disable,
+	}
+}
+
+func (policy Policy) buildResponsePluginReq(dto *PolicyDto) *kongDto.KongPluginReqDto {
+	disable := false
+	return &kongDto.KongPluginReqDto{
+		Name: RESPONSE_PLUGIN,
+		Config: buildConfig(map[string]Transform{
+			"headers": dto.Response.Headers,
+			"json":    dto.Response.Body,
+		}),
+		Enabled: &disable,
+	}
+}
+
+// applyPlugin 创建、更新或删除 zone 上的 kong 插件，req 为 nil 时删除
+func (policy Policy) applyPlugin(adapter kong.KongAdapter, zone *orm.GatewayZone, pluginName string, req *kongDto.KongPluginReqDto) (bool, error) {
+	policyDb, _ := db.NewGatewayPolicyServiceImpl()
+	exist, err := policyDb.GetByAny(&orm.GatewayPolicy{
+		ZoneId:     zone.Id,
+		PluginName: pluginName,
+	})
+	if err != nil {
+		return false, err
+	}
+	if req == nil {
+		if exist == nil {
+			return false, nil
+		}
+		err = adapter.RemovePlugin(exist.PluginId)
+		if err != nil {
+			return false, err
+		}
+		_ = policyDb.DeleteById(exist.Id)
+		return true, nil
+	}
+	if exist != nil {
+		req.Id = exist.PluginId
+		resp, err := adapter.CreateOrUpdatePluginById(req)
+		if err != nil {
+			return false, err
+		}
+		configByte, err := json.Marshal(resp.Config)
+		if err != nil {
+			return false, err
+		}
+		exist.Config = configByte
+		return false, policyDb.Update(exist)
+	}
+	resp, err := adapter.AddPlugin(req)
+	if err != nil {
+		return false, err
+	}
+	configByte, err := json.Marshal(resp.Config)
+	if err != nil {
+		return false, err
+	}
+	policyDao := &orm.GatewayPolicy{
+		ZoneId:     zone.Id,
+		PluginName: pluginName,
+		Category:   "transform",
+		PluginId:   resp.Id,
+		Config:     configByte,
+		Enabled:    1,
+	}
+	err = policyDb.Insert(policyDao)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (policy Policy) ParseConfig(dto apipolicy.PolicyDto, ctx map[string]interface{}) (apipolicy.PolicyConfig, error) {
+	res := apipolicy.PolicyConfig{}
+	policyDto, ok := dto.(*PolicyDto)
+	if !ok {
+		return res, errors.Errorf("invalid config:%+v", dto)
+	}
+	value, ok := ctx[apipolicy.CTX_KONG_ADAPTER]
+	if !ok {
+		return res, errors.Errorf("get identify failed:%+v", ctx)
+	}
+	adapter, ok := value.(kong.KongAdapter)
+	if !ok {
+		return res, errors.Errorf("convert failed:%+v", value)
+	}
+	value, ok = ctx[apipolicy.CTX_ZONE]
+	if !ok {
+		return res, errors.Errorf("get identify failed:%+v", ctx)
+	}
+	zone, ok := value.(*orm.GatewayZone)
+	if !ok {
+		return res, errors.Errorf("convert failed:%+v", value)
+	}
+	var reqPlugin, respPlugin *kongDto.KongPluginReqDto
+	if policyDto.Switch && !policyDto.Request.isEmpty() {
+		reqPlugin = policy.buildRequestPluginReq(policyDto)
+	}
+	if policyDto.Switch && !policyDto.Response.isEmpty() {
+		respPlugin = policy.buildResponsePluginReq(policyDto)
+	}
+	changed, err := policy.applyPlugin(adapter, zone, REQUEST_PLUGIN, reqPlugin)
+	if err != nil {
+		return res, err
+	}
+	res.KongPolicyChange = changed
+	changed, err = policy.applyPlugin(adapter, zone, RESPONSE_PLUGIN, respPlugin)
+	if err != nil {
+		return res, err
+	}
+	res.KongPolicyChange = res.KongPolicyChange || changed
+	return res, nil
+}
+
+func init() {
+	err := apipolicy.RegisterPolicyEngine("transformer", &Policy{})
+	if err != nil {
+		panic(err)
+	}
+}
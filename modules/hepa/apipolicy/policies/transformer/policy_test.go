@@ -0,0 +1,99 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transformer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_buildConfig(t *testing.T) {
+	config := buildConfig(map[string]Transform{
+		"headers": {
+			Remove:  []string{"X-Debug"},
+			Rename:  []Rename{{From: "X-Old", To: "X-New"}},
+			Replace: []KeyValue{{Key: "Host", Value: "a.com:8080"}},
+			Add:     []KeyValue{{Key: "X-From", Value: "gateway"}},
+		},
+		"json": {
+			Remove: []string{"password"},
+			Rename: []Rename{{From: "user_name", To: "userName"}},
+		},
+	})
+	want := map[string]interface{}{
+		"remove": map[string]interface{}{
+			"headers": []string{"X-Debug"},
+			"json":    []string{"password"},
+		},
+		"rename": map[string]interface{}{
+			"headers": []string{"X-Old:X-New"},
+			"json":    []string{"user_name:userName"},
+		},
+		"replace": map[string]interface{}{
+			"headers": []string{"Host:a.com:8080"},
+			"json":    []string{},
+		},
+		"add": map[string]interface{}{
+			"headers": []string{"X-From:gateway"},
+			"json":    []string{},
+		},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("buildConfig() = %v, want %v", config, want)
+	}
+}
+
+func TestPolicy_buildPluginReq(t *testing.T) {
+	dto := &PolicyDto{}
+	dto.Request.Querystring.Add = []KeyValue{{Key: "from", Value: "gateway"}}
+	dto.Response.Headers.Remove = []string{"Server"}
+	dto.Response.Body.Rename = []Rename{{From: "data.user_name", To: "data.userName"}}
+	policy := Policy{}
+	reqPlugin := policy.buildRequestPluginReq(dto)
+	respPlugin := policy.buildResponsePluginReq(dto)
+	if reqPlugin.Name != REQUEST_PLUGIN || respPlugin.Name != RESPONSE_PLUGIN {
+		t.Errorf("unexpected plugin name: %s, %s", reqPlugin.Name, respPlugin.Name)
+	}
+	if reqPlugin.Enabled == nil || *reqPlugin.Enabled || respPlugin.Enabled == nil || *respPlugin.Enabled {
+		t.Error("plugin templates should be disabled and enabled per api by domain-policy")
+	}
+	if reqPlugin.RouteId != "" || reqPlugin.ServiceId != "" {
+		t.Error("plugin templates should not bind route or service")
+	}
+	add := reqPlugin.Config["add"].(map[string]interface{})
+	if !reflect.DeepEqual(add["querystring"], []string{"from:gateway"}) {
+		t.Errorf("unexpected querystring add: %v", add["querystring"])
+	}
+	rename := respPlugin.Config["rename"].(map[string]interface{})
+	if !reflect.DeepEqual(rename["json"], []string{"data.user_name:data.userName"}) {
+		t.Errorf("unexpected json rename: %v", rename["json"])
+	}
+}
+
+func TestPolicyDto_IsValidDto(t *testing.T) {
+	dto := PolicyDto{}
+	dto.Switch = true
+	if ok, _ := dto.IsValidDto(); ok {
+		t.Error("empty transform should be invalid")
+	}
+	dto.Response.Body.Rename = []Rename{{From: "user_name", To: "userName"}}
+	if ok, msg := dto.IsValidDto(); !ok {
+		t.Errorf("response body rename should be valid, msg: %s", msg)
+	}
+	dto.Response.Body.Rename = []Rename{{From: "user name", To: "userName"}}
+	if ok, _ := dto.IsValidDto(); ok {
+		t.Error("invalid field name should be rejected")
+	}
+}
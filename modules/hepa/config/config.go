@@ -63,4 +63,5 @@ type ServerConfig struct {
 	TenantGroupKey           string   `default:"58dcbf490ef3"`
 	CenterDomainNameKeepList []string `default:"collector,gittar,hepa,openapi,soldier,uc,dice,uc-adaptor,nexus-sys,sonar-sys"`
 	EdgeDomainNameKeepList   []string `default:"soldier,nexus-sys"`
+	JwtVerifyAddr            string   `default:""`
 }
@@ -39,6 +39,7 @@ var KEYAUTH_CONFIG map[string]interface{}
 var OAUTH2_CONFIG map[string]interface{}
 var SIGNAUTH_CONFIG map[string]interface{}
 var HMACAUTH_CONFIG map[string]interface{}
+var JWT_CONFIG map[string]interface{}
 
 type OpenapiRule struct {
 	Region          RuleRegion
@@ -87,4 +88,10 @@ func init() {
 	HMACAUTH_CONFIG["validate_request_body"] = true
 	HMACAUTH_CONFIG["enforce_headers"] = []string{"date", "request-line"}
 	HMACAUTH_CONFIG["algorithms"] = []string{"hmac-sha256", "hmac-sha384", "hmac-sha512"}
+	JWT_CONFIG = map[string]interface{}{}
+	JWT_CONFIG["key_claim_name"] = "iss"
+	JWT_CONFIG["claims_to_verify"] = []string{"exp", "nbf"}
+	JWT_CONFIG["header_names"] = []string{"authorization"}
+	// kong 默认从 jwt 参数读取 token，会被记录到访问日志和 Referer 中，只允许通过请求头传递
+	JWT_CONFIG["uri_param_names"] = []string{}
 }
@@ -23,6 +23,7 @@ const (
 	AT_SIGN_AUTH  = "sign-auth"
 	AT_HMAC_AUTH  = "hmac-auth"
 	AT_ALIYUN_APP = "aliyun-app"
+	AT_JWT        = "jwt"
 )
 
 // AclType
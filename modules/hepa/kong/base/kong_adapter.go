@@ -153,6 +153,31 @@ func (impl *KongAdapterImpl) CreateOrUpdateRoute(req *KongRouteReqDto) (*KongRou
 	return nil, errors.Errorf("CreateOrUpdateRoute failed: code[%d] msg[%s]", code, body)
 }
 
+func (impl *KongAdapterImpl) GetRoute(nameOrId string) (*KongRouteRespDto, error) {
+	if impl == nil {
+		return nil, errors.New("kong can't be attached")
+	}
+	if nameOrId == "" {
+		return nil, errors.New(ERR_INVALID_ARG)
+	}
+	code, body, err := util.DoCommonRequest(impl.Client, "GET", impl.KongAddr+RouteRoot+nameOrId, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "request failed")
+	}
+	if code == 404 {
+		return nil, nil
+	}
+	if code == 200 {
+		respDto := &KongRouteRespDto{}
+		err = json.Unmarshal(body, respDto)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unmarshal body failed, body:%s", body)
+		}
+		return respDto, nil
+	}
+	return nil, errors.Errorf("GetRoute failed: code[%d] msg[%s]", code, body)
+}
+
 func (impl *KongAdapterImpl) TouchRouteOAuthMethod(id string) error {
 	if impl == nil {
 		return errors.New("kong can't be attached")
@@ -237,6 +262,30 @@ func (impl *KongAdapterImpl) CreateOrUpdateService(req *KongServiceReqDto) (*Kon
 	return nil, errors.Errorf("CreateOrUpdateService failed: code[%d] msg[%s]", code, body)
 }
 
+// UpdateService 只更新请求中设置的字段
+func (impl *KongAdapterImpl) UpdateService(req *KongServiceReqDto) (*KongServiceRespDto, error) {
+	if impl == nil {
+		return nil, errors.New("kong can't be attached")
+	}
+	if req == nil || req.ServiceId == "" {
+		return nil, errors.New(ERR_INVALID_ARG)
+	}
+	url := impl.KongAddr + ServiceRoot + req.ServiceId
+	code, body, err := util.DoCommonRequest(impl.Client, "PATCH", url, req)
+	if err != nil {
+		return nil, errors.Wrap(err, "request failed")
+	}
+	if code == 201 || code == 200 {
+		respDto := &KongServiceRespDto{}
+		err = json.Unmarshal(body, respDto)
+		if err != nil {
+			return nil, errors.Wrapf(err, "json unmarshal failed [%s]", body)
+		}
+		return respDto, nil
+	}
+	return nil, errors.Errorf("UpdateService failed: code[%d] msg[%s]", code, body)
+}
+
 func (impl *KongAdapterImpl) DeleteService(id string) error {
 	if impl == nil {
 		return errors.New("kong can't be attached")
@@ -626,6 +675,49 @@ func (impl *KongAdapterImpl) CreateUpstream(req *KongUpstreamDto) (*KongUpstream
 	return nil, errors.Errorf("CreateUpstream failed: code[%d] msg[%s]", code, body)
 }
 
+// GetUpstream 按 id 或名称查询 upstream，不存在时返回 nil
+func (impl *KongAdapterImpl) GetUpstream(nameOrId string) (*KongUpstreamDto, error) {
+	if impl == nil {
+		return nil, errors.New("kong can't be attached")
+	}
+	if nameOrId == "" {
+		return nil, errors.New(ERR_INVALID_ARG)
+	}
+	code, body, err := util.DoCommonRequest(impl.Client, "GET", impl.KongAddr+UpstreamRoot+nameOrId, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "request failed")
+	}
+	if code == 404 {
+		return nil, nil
+	}
+	if code == 200 {
+		respDto := &KongUpstreamDto{}
+		err = json.Unmarshal(body, respDto)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unmarshal body failed, body:%s", body)
+		}
+		return respDto, nil
+	}
+	return nil, errors.Errorf("GetUpstream failed: code[%d] msg[%s]", code, body)
+}
+
+func (impl *KongAdapterImpl) DeleteUpstream(nameOrId string) error {
+	if impl == nil {
+		return errors.New("kong can't be attached")
+	}
+	if nameOrId == "" {
+		return errors.New(ERR_INVALID_ARG)
+	}
+	code, body, err := util.DoCommonRequest(impl.Client, "DELETE", impl.KongAddr+UpstreamRoot+nameOrId, nil)
+	if err != nil {
+		return errors.Wrap(err, "request failed")
+	}
+	if code == 204 || code == 404 {
+		return nil
+	}
+	return errors.Errorf("DeleteUpstream failed: code[%d] msg[%s]", code, body)
+}
+
 func (impl *KongAdapterImpl) GetUpstreamStatus(upstreamId string) (*KongUpstreamStatusRespDto, error) {
 	if impl == nil {
 		return nil, errors.New("kong can't be attached")
@@ -78,6 +78,8 @@ type KongCredentialDto struct {
 	Secret string `json:"secret,omitempty"`
 	// hmac-auth
 	Username string `json:"username,omitempty"`
+	// jwt
+	Algorithm string `json:"algorithm,omitempty"`
 }
 
 func (dto *KongCredentialDto) ToHmacReq() {
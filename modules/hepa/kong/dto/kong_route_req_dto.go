@@ -24,6 +24,8 @@ type KongObj struct {
 }
 
 type KongRouteReqDto struct {
+	// 选填，路由名称，可以代替路由id用于更新和删除
+	Name string `json:"name,omitempty"`
 	// 协议列表，默认["http", "https"]
 	Protocols []string `json:"protocols,omitempty"`
 	// 以下三个参数至少需要填一个
@@ -33,6 +35,8 @@ type KongRouteReqDto struct {
 	Hosts []string `json:"hosts,omitempty"`
 	// 3、路径列表
 	Paths []string `json:"paths,omitempty"`
+	// 选填，按请求头匹配
+	Headers map[string][]string `json:"headers,omitempty"`
 	// 选填，当通过路径之一匹配路由时，从上游请求URL中去除匹配的前缀。
 	StripPath *bool `json:"strip_path,omitempty"`
 	// 选填，当通过主机域名中的一个匹配路由时，在上游请求报头中使用请求主机头。
@@ -16,6 +16,7 @@ package dto
 
 type KongRouteRespDto struct {
 	Id        string   `json:"id"`
+	Name      string   `json:"name"`
 	CreatedAt int64    `json:"created_at"`
 	UpdatedAt int64    `json:"updated_at"`
 	Protocols []string `json:"protocols"`
@@ -23,6 +24,8 @@ type KongRouteRespDto struct {
 	Hosts     []string `json:"hosts"`
 	Paths     []string `json:"paths"`
 	Service   Service  `json:"service"`
+	// 正则匹配优先级
+	RegexPriority int `json:"regex_priority"`
 }
 
 type KongRoutesRespDto struct {
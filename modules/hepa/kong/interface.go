@@ -25,9 +25,11 @@ type KongAdapter interface {
 	CreateConsumer(req *KongConsumerReqDto) (*KongConsumerRespDto, error)
 	DeleteConsumer(string) error
 	CreateOrUpdateRoute(req *KongRouteReqDto) (*KongRouteRespDto, error)
+	GetRoute(string) (*KongRouteRespDto, error)
 	DeleteRoute(string) error
 	UpdateRoute(req *KongRouteReqDto) (*KongRouteRespDto, error)
 	CreateOrUpdateService(req *KongServiceReqDto) (*KongServiceRespDto, error)
+	UpdateService(req *KongServiceReqDto) (*KongServiceRespDto, error)
 	DeleteService(string) error
 	DeletePluginIfExist(req *KongPluginReqDto) error
 	CreateOrUpdatePlugin(req *KongPluginReqDto) (*KongPluginRespDto, error)
@@ -42,6 +44,8 @@ type KongAdapter interface {
 	GetCredentialList(string, string) (*KongCredentialListDto, error)
 	CreateAclGroup(string, string) error
 	CreateUpstream(req *KongUpstreamDto) (*KongUpstreamDto, error)
+	GetUpstream(string) (*KongUpstreamDto, error)
+	DeleteUpstream(string) error
 	GetUpstreamStatus(string) (*KongUpstreamStatusRespDto, error)
 	AddUpstreamTarget(string, *KongTargetDto) (*KongTargetDto, error)
 	DeleteUpstreamTarget(string, string) error
@@ -18,6 +18,7 @@ import (
 	logs "github.com/erda-project/erda-infra/base/logs"
 	servicehub "github.com/erda-project/erda-infra/base/servicehub"
 	transport "github.com/erda-project/erda-infra/pkg/transport"
+	"github.com/erda-project/erda-infra/providers/httpserver"
 	pb "github.com/erda-project/erda-proto-go/core/hepa/api_policy/pb"
 	"github.com/erda-project/erda/modules/hepa/common"
 	"github.com/erda-project/erda/modules/hepa/services/api_policy/impl"
@@ -36,7 +37,8 @@ type provider struct {
 	Log              logs.Logger
 	Register         transport.Register
 	apiPolicyService *apiPolicyService
-	Perm             perm.Interface `autowired:"permission"`
+	Perm             perm.Interface    `autowired:"permission"`
+	HttpServer       httpserver.Router `autowired:"http-server"`
 }
 
 func (p *provider) Init(ctx servicehub.Context) error {
@@ -60,6 +62,7 @@ func (p *provider) Init(ctx servicehub.Context) error {
 			perm.Method(policyService.SetPolicy, perm.ScopeOrg, "org", perm.ActionGet, perm.OrgIDValue()),
 		), common.AccessLogWrap(common.AccessLog))
 	}
+	p.initRoutes(p.HttpServer)
 	return nil
 }
 
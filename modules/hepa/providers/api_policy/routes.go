@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api_policy
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda-infra/providers/httpserver"
+	"github.com/erda-project/erda/modules/hepa/apipolicy/policies/jwt"
+	"github.com/erda-project/erda/modules/hepa/services/api_policy"
+	"github.com/erda-project/erda/modules/monitor/common/permission"
+	api "github.com/erda-project/erda/pkg/common/httpapi"
+)
+
+type trafficSplitWeightReq struct {
+	PackageId string `json:"packageId" validate:"required"`
+	ApiId     string `json:"apiId" validate:"required"`
+	Weight    int64  `json:"weight"`
+}
+
+func (p *provider) initRoutes(routes httpserver.Router) {
+	// 调整灰度分流比例，不需要重新提交整个策略配置
+	routes.PUT("/api/gateway/openapi/policies/traffic-split/weight", p.setTrafficSplitWeight, permission.Intercepter(
+		permission.ScopeOrg, permission.OrgIDFromHeader(),
+		"org", permission.ActionUpdate,
+	))
+	// 供 ingress 外部认证回调，不经过 openapi 鉴权
+	routes.GET(jwt.VERIFY_PATH+":zoneId", p.verifyJwt)
+}
+
+func (p *provider) setTrafficSplitWeight(r *http.Request, params trafficSplitWeightReq) interface{} {
+	service := api_policy.Service.Clone(r.Context())
+	config, err := service.SetTrafficSplitWeight(api.OrgID(r), params.PackageId, params.ApiId, params.Weight)
+	if errors.Cause(err) == api_policy.ErrPackageForbidden {
+		return api.Errors.AccessDenied()
+	}
+	if err != nil {
+		return api.Errors.InvalidParameter(errors.Cause(err))
+	}
+	return api.Success(config)
+}
+
+func (p *provider) verifyJwt(rw http.ResponseWriter, r *http.Request, params struct {
+	ZoneId string `param:"zoneId"`
+}) {
+	headers, status := jwt.Verify(params.ZoneId, r)
+	for name, value := range headers {
+		rw.Header().Set(name, value)
+	}
+	rw.WriteHeader(status)
+}
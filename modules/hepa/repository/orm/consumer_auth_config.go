@@ -24,8 +24,10 @@ const (
 	KEYAUTH      = "key-auth"
 	SIGNAUTH     = "sign-auth"
 	HMACAUTH     = "hmac-auth"
+	JWTAUTH      = "jwt"
 	KeyAuthTips  = "请将appKey带在名为appKey的url参数或者名为X-App-Key的请求头上"
 	SignAuthTips = "请将appKey带在名为appKey的url参数上，将参数签名串带在名为sign的url参数上"
+	JwtAuthTips  = "请使用secret签发token，iss字段填写key，并以Bearer方式带在Authorization请求头上"
 )
 
 type AuthItem struct {
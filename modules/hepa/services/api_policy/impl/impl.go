@@ -34,8 +34,12 @@ import (
 	_ "github.com/erda-project/erda/modules/hepa/apipolicy/policies/csrf"
 	_ "github.com/erda-project/erda/modules/hepa/apipolicy/policies/custom"
 	_ "github.com/erda-project/erda/modules/hepa/apipolicy/policies/ip"
+	_ "github.com/erda-project/erda/modules/hepa/apipolicy/policies/jwt"
 	_ "github.com/erda-project/erda/modules/hepa/apipolicy/policies/proxy"
+	_ "github.com/erda-project/erda/modules/hepa/apipolicy/policies/quota"
 	_ "github.com/erda-project/erda/modules/hepa/apipolicy/policies/server-guard"
+	trafficsplit "github.com/erda-project/erda/modules/hepa/apipolicy/policies/traffic-split"
+	_ "github.com/erda-project/erda/modules/hepa/apipolicy/policies/transformer"
 	_ "github.com/erda-project/erda/modules/hepa/apipolicy/policies/waf"
 	"github.com/erda-project/erda/modules/hepa/bundle"
 	"github.com/erda-project/erda/modules/hepa/common"
@@ -55,6 +59,7 @@ import (
 
 const (
 	mutexBucketSize = 512
+	TRAFFIC_SPLIT   = "traffic-split"
 )
 
 var azMutex []*sync.Mutex
@@ -992,3 +997,44 @@ func (impl GatewayApiPolicyServiceImpl) SetPolicyConfig(category, packageId, pac
 	result = dto
 	return
 }
+
+// SetTrafficSplitWeight 调整已开启的灰度分流的流量比例
+func (impl GatewayApiPolicyServiceImpl) SetTrafficSplitWeight(orgId, packageId, packageApiId string, weight int64) (interface{}, error) {
+	if packageId == "" || packageApiId == "" {
+		return nil, errors.New("packageId and apiId are required")
+	}
+	pack, err := impl.packageDb.Get(packageId)
+	if err != nil {
+		return nil, err
+	}
+	if pack == nil {
+		return nil, errors.Errorf("package %s not found", packageId)
+	}
+	if pack.DiceOrgId != orgId {
+		return nil, errors.Wrapf(api_policy.ErrPackageForbidden, "package:%s, org:%s", packageId, orgId)
+	}
+	api, err := impl.packageApiDb.Get(packageApiId)
+	if err != nil {
+		return nil, err
+	}
+	if api == nil || api.PackageId != packageId {
+		return nil, errors.Errorf("api %s not found in package %s", packageApiId, packageId)
+	}
+	current, err := impl.GetPolicyConfig(TRAFFIC_SPLIT, packageId, packageApiId)
+	if err != nil {
+		return nil, err
+	}
+	policyDto, ok := current.(*trafficsplit.PolicyDto)
+	if !ok || !policyDto.Switch || policyDto.IsGlobal() {
+		return nil, errors.Errorf("traffic split of api %s is not enabled", packageApiId)
+	}
+	policyDto.Weight = weight
+	if ok, msg := policyDto.IsValidDto(); !ok {
+		return nil, errors.New(msg)
+	}
+	config, err := json.Marshal(policyDto)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return impl.SetPolicyConfig(TRAFFIC_SPLIT, packageId, packageApiId, config)
+}
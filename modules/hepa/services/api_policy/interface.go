@@ -17,6 +17,8 @@ package api_policy
 import (
 	"context"
 
+	"github.com/pkg/errors"
+
 	"github.com/erda-project/erda/modules/hepa/apipolicy"
 	"github.com/erda-project/erda/modules/hepa/repository/orm"
 	"github.com/erda-project/erda/modules/hepa/repository/service"
@@ -24,11 +26,15 @@ import (
 
 var Service GatewayApiPolicyService
 
+// ErrPackageForbidden 操作的产品包不属于当前企业
+var ErrPackageForbidden = errors.New("package not belong to org")
+
 type GatewayApiPolicyService interface {
 	Clone(context.Context) GatewayApiPolicyService
 	SetPackageDefaultPolicyConfig(category, packageId string, az *orm.GatewayAzInfo, config []byte, helper ...*service.SessionHelper) (string, error)
 	GetPolicyConfig(category, packageId, packageApiId string) (interface{}, error)
 	SetPolicyConfig(category, packageId, packageApiId string, config []byte) (interface{}, error)
+	SetTrafficSplitWeight(orgId, packageId, packageApiId string, weight int64) (interface{}, error)
 	RefreshZoneIngress(zone orm.GatewayZone, az orm.GatewayAzInfo) error
 	SetZonePolicyConfig(zone *orm.GatewayZone, category string, config []byte, helper *service.SessionHelper, needDeployTag ...bool) (apipolicy.PolicyDto, string, error)
 	SetZoneDefaultPolicyConfig(packageId string, zone *orm.GatewayZone, az *orm.GatewayAzInfo, session ...*service.SessionHelper) (map[string]*string, *string, *service.SessionHelper, error)
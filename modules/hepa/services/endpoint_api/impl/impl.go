@@ -29,6 +29,7 @@ import (
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/hepa/apipolicy"
+	trafficsplit "github.com/erda-project/erda/modules/hepa/apipolicy/policies/traffic-split"
 	"github.com/erda-project/erda/modules/hepa/bundle"
 	"github.com/erda-project/erda/modules/hepa/common"
 	"github.com/erda-project/erda/modules/hepa/common/util"
@@ -226,6 +227,8 @@ func (impl GatewayOpenapiServiceImpl) createAuthRule(authType string, pack *orm.
 			return nil, errors.New("hmac-auth plugin is not enabled")
 		}
 		authRule.Config = gw.HMACAUTH_CONFIG
+	case gw.AT_JWT:
+		authRule.Config = gw.JWT_CONFIG
 	case gw.AT_ALIYUN_APP:
 		authRule.Config = nil
 		authRule.NotKongPlugin = true
@@ -715,6 +718,10 @@ func (impl GatewayOpenapiServiceImpl) UpdatePackage(id string, dto *gw.PackageDt
 			if err != nil {
 				return
 			}
+			err = (*impl.consumerBiz).EnsurePackageCredentials(id, dto.AuthType)
+			if err != nil {
+				return
+			}
 		}
 	}
 	if dao.AclType != dto.AclType {
@@ -1818,6 +1825,19 @@ func (impl GatewayOpenapiServiceImpl) UpdatePackageApi(packageId, apiId string,
 		}
 		dto.ProjectId = pack.DiceProjectId
 		dto.Env = pack.DiceEnv
+		// 灰度分流的主服务 target 来自转发地址，开启期间不允许修改
+		if dao.RedirectType == gw.RT_URL && dao.ZoneId != "" &&
+			(updateDao.RedirectType != gw.RT_URL || dto.RedirectAddr != dao.RedirectAddr) {
+			var splitting bool
+			splitting, err = trafficsplit.Enabled(kongAdapter, dao.ZoneId)
+			if err != nil {
+				return
+			}
+			if splitting {
+				err = errors.Errorf("traffic split of api %s is enabled, disable it before changing redirect addr", apiId)
+				return
+			}
+		}
 		if updateDao.RedirectType == gw.RT_URL {
 			updateDao.RuntimeServiceId = ""
 			updateDao.DiceApp = ""
@@ -1827,6 +1847,12 @@ func (impl GatewayOpenapiServiceImpl) UpdatePackageApi(packageId, apiId string,
 				if err != nil {
 					return
 				}
+				if dao.ZoneId != "" {
+					err = trafficsplit.KeepUpstream(kongAdapter, dao.ZoneId, serviceId)
+					if err != nil {
+						return
+					}
+				}
 				dto.ServiceId = serviceId
 				routeId, err = impl.touchKongRoute(kongAdapter, dto, apiId)
 				if err != nil {
@@ -1849,6 +1875,12 @@ func (impl GatewayOpenapiServiceImpl) UpdatePackageApi(packageId, apiId string,
 			if err != nil {
 				return
 			}
+			if dao.ZoneId != "" {
+				err = trafficsplit.KeepCanaryRoute(kongAdapter, dao.ZoneId, dto.RouteId)
+				if err != nil {
+					return
+				}
+			}
 		} else if updateDao.RedirectType == gw.RT_SERVICE {
 			var runtimeService *orm.GatewayRuntimeService
 			if dao.RedirectType == gw.RT_URL {
@@ -2021,6 +2053,12 @@ func (impl *GatewayOpenapiServiceImpl) DeletePackageApi(packageId, apiId string)
 		if err != nil {
 			return
 		}
+		if dao.ZoneId != "" {
+			err = trafficsplit.Release(kongAdapter, dao.ZoneId)
+			if err != nil {
+				return
+			}
+		}
 	}
 	if dao.ZoneId != "" {
 		err = (*impl.zoneBiz).DeleteZone(dao.ZoneId)
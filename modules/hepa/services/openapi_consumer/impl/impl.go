@@ -114,11 +114,18 @@ func (impl GatewayOpenapiConsumerServiceImpl) getCredentialList(kongAdapter kong
 			Data: []kongDto.KongCredentialDto{},
 		}
 	}
+	jCredentials, err := kongAdapter.GetCredentialList(consumerId, orm.JWTAUTH)
+	if err != nil {
+		jCredentials = &kongDto.KongCredentialListDto{
+			Data: []kongDto.KongCredentialDto{},
+		}
+	}
 	return map[string]kongDto.KongCredentialListDto{
 		orm.KEYAUTH:  *kCredentials,
 		orm.OAUTH2:   *oCredentials,
 		orm.SIGNAUTH: *sCredentials,
 		orm.HMACAUTH: *hCredentials,
+		orm.JWTAUTH:  *jCredentials,
 	}, nil
 }
 
@@ -597,6 +604,11 @@ func (impl GatewayOpenapiConsumerServiceImpl) GetConsumerCredentials(id string)
 					AuthType: orm.HMACAUTH,
 					AuthData: credentialListMap[orm.HMACAUTH],
 				},
+				{
+					AuthTips: orm.JwtAuthTips,
+					AuthType: orm.JWTAUTH,
+					AuthData: credentialListMap[orm.JWTAUTH],
+				},
 			},
 		},
 	}
@@ -759,6 +771,11 @@ func (impl GatewayOpenapiConsumerServiceImpl) UpdateConsumerCredentials(id strin
 					AuthType: orm.HMACAUTH,
 					AuthData: credentialListMap[orm.HMACAUTH],
 				},
+				{
+					AuthTips: orm.JwtAuthTips,
+					AuthType: orm.JWTAUTH,
+					AuthData: credentialListMap[orm.JWTAUTH],
+				},
 			},
 		},
 	}
@@ -1233,11 +1250,86 @@ func (impl GatewayOpenapiConsumerServiceImpl) GetConsumerAcls(id string) (result
 	return
 }
 
+// newJwtCredential 生成独立的 jwt 签发密钥，不复用其他鉴权方式的凭证，已存在 jwt 凭证时返回 nil
+func newJwtCredential(credentials map[string]kongDto.KongCredentialListDto) (*kongDto.KongCredentialDto, error) {
+	if len(credentials[orm.JWTAUTH].Data) > 0 {
+		return nil, nil
+	}
+	credential := &kongDto.KongCredentialDto{
+		Algorithm: "HS256",
+	}
+	var err error
+	credential.Key, err = util.GenUniqueId()
+	if err != nil {
+		return nil, err
+	}
+	credential.Secret, err = util.GenUniqueId()
+	if err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// ensureJwtCredential 消费者被授权访问 jwt 鉴权的流量入口时才创建 jwt 凭证
+func (impl GatewayOpenapiConsumerServiceImpl) ensureJwtCredential(consumer *orm.GatewayConsumer) error {
+	kongInfo, err := impl.kongDb.GetKongInfo(&orm.GatewayKongInfo{
+		Az:        consumer.Az,
+		ProjectId: consumer.ProjectId,
+		Env:       consumer.Env,
+	})
+	if err != nil {
+		return err
+	}
+	kongAdapter := kong.NewKongAdapter(kongInfo.KongAddr)
+	credentialListMap, err := impl.getCredentialList(kongAdapter, consumer.ConsumerId)
+	if err != nil {
+		return err
+	}
+	credential, err := newJwtCredential(credentialListMap)
+	if err != nil || credential == nil {
+		return err
+	}
+	_, err = impl.createCredential(kongAdapter, orm.JWTAUTH, consumer.ConsumerId, credential)
+	return err
+}
+
+func (impl GatewayOpenapiConsumerServiceImpl) ensureCredentials(authType string, consumers []orm.GatewayConsumer) error {
+	if authType != gw.AT_JWT {
+		return nil
+	}
+	for i := range consumers {
+		err := impl.ensureJwtCredential(&consumers[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsurePackageCredentials 流量入口切换鉴权方式后，为已授权的消费者补齐对应凭证
+func (impl GatewayOpenapiConsumerServiceImpl) EnsurePackageCredentials(packageId, authType string) error {
+	consumers, err := impl.GetConsumersOfPackage(packageId)
+	if err != nil {
+		return err
+	}
+	return impl.ensureCredentials(authType, consumers)
+}
+
 func (impl GatewayOpenapiConsumerServiceImpl) updatePackageAclRules(packageId string) error {
 	consumers, err := impl.GetConsumersOfPackage(packageId)
 	if err != nil {
 		return err
 	}
+	pack, err := impl.packageDb.Get(packageId)
+	if err != nil {
+		return err
+	}
+	if pack != nil {
+		err = impl.ensureCredentials(pack.AuthType, consumers)
+		if err != nil {
+			return err
+		}
+	}
 	var buffer bytes.Buffer
 	for _, consumer := range consumers {
 		if buffer.Len() > 0 {
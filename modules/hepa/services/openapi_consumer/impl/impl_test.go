@@ -0,0 +1,45 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impl
+
+import (
+	"testing"
+
+	kongDto "github.com/erda-project/erda/modules/hepa/kong/dto"
+	"github.com/erda-project/erda/modules/hepa/repository/orm"
+)
+
+func Test_newJwtCredential(t *testing.T) {
+	exists, err := newJwtCredential(map[string]kongDto.KongCredentialListDto{
+		orm.JWTAUTH: {Data: []kongDto.KongCredentialDto{{Key: "k"}}},
+	})
+	if err != nil || exists != nil {
+		t.Errorf("existing jwt credential should be kept, got %+v, err %v", exists, err)
+	}
+
+	signAuth := kongDto.KongCredentialDto{Key: "key", Secret: "secret"}
+	generated, err := newJwtCredential(map[string]kongDto.KongCredentialListDto{
+		orm.SIGNAUTH: {Data: []kongDto.KongCredentialDto{signAuth}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generated.Key == "" || generated.Secret == "" || generated.Key == generated.Secret || generated.Algorithm != "HS256" {
+		t.Errorf("key/secret should be generated, got %+v", generated)
+	}
+	if generated.Key == signAuth.Key || generated.Secret == signAuth.Secret {
+		t.Errorf("sign-auth key/secret should not be reused, got %+v", generated)
+	}
+}
@@ -42,6 +42,7 @@ type GatewayOpenapiConsumerService interface {
 	GetKongConsumerName(consumer *orm.GatewayConsumer) string
 	GetPackageAcls(string) ([]dto.PackageAclInfoDto, error)
 	UpdatePackageAcls(string, *dto.PackageAclsDto) (bool, error)
+	EnsurePackageCredentials(packageId, authType string) error
 	GetPackageApiAcls(string, string) ([]dto.PackageAclInfoDto, error)
 	UpdatePackageApiAcls(string, string, *dto.PackageAclsDto) (bool, error)
 }
@@ -16,6 +16,9 @@
 package conf
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/erda-project/erda/pkg/envconf"
 	"github.com/erda-project/erda/pkg/kms/kmstypes"
 )
@@ -26,6 +29,8 @@ type Conf struct {
 	Debug         bool               `env:"DEBUG" default:"false"`
 	KmsStoreKind  kmstypes.StoreKind `env:"KMS_STORE_KIND" default:"ETCD"`
 	EtcdEndpoints string             `env:"ETCD_ENDPOINTS" required:"false"`
+
+	KeyRotationCheckInterval time.Duration `env:"KEY_ROTATION_CHECK_INTERVAL" default:"10m"`
 }
 
 var cfg Conf
@@ -39,6 +44,11 @@ func Load() {
 			panic("missing env ETCD_ENDPOINTS while KMS_STORE_KIND is ETCD")
 		}
 	}
+
+	// used as ticker interval, which panics if not positive
+	if cfg.KeyRotationCheckInterval <= 0 {
+		panic(fmt.Sprintf("invalid env KEY_ROTATION_CHECK_INTERVAL: %s, must be positive", cfg.KeyRotationCheckInterval))
+	}
 }
 
 // ListenAddr return ListenAddr option.
@@ -58,3 +68,8 @@ func KmsStoreKind() kmstypes.StoreKind {
 func EtcdEndpoints() string {
 	return cfg.EtcdEndpoints
 }
+
+// KeyRotationCheckInterval 检查用户主密钥是否需要自动轮转的间隔
+func KeyRotationCheckInterval() time.Duration {
+	return cfg.KeyRotationCheckInterval
+}
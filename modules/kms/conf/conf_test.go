@@ -17,6 +17,7 @@ package conf
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -31,6 +32,22 @@ func TestLoad(t *testing.T) {
 	normalLoad(t)
 }
 
+func TestLoad_InvalidKeyRotationCheckInterval(t *testing.T) {
+	_ = os.Setenv(envKeyKmsStoreKind, kmstypes.StoreKind_ETCD.String())
+	_ = os.Setenv(envKeyEtcdEndpoints, "fake")
+	_ = os.Setenv(envKeyKeyRotationCheckInterval, "0s")
+	defer func() {
+		_ = os.Unsetenv(envKeyKmsStoreKind)
+		_ = os.Unsetenv(envKeyEtcdEndpoints)
+		_ = os.Unsetenv(envKeyKeyRotationCheckInterval)
+	}()
+
+	assert.Panics(t, Load)
+
+	_ = os.Setenv(envKeyKeyRotationCheckInterval, "-1m")
+	assert.Panics(t, Load)
+}
+
 func shouldLoadPanic(t *testing.T) {
 	defer func() { recover() }()
 	// panic logic
@@ -42,6 +59,8 @@ func shouldLoadPanic(t *testing.T) {
 const (
 	envKeyKmsStoreKind  = "KMS_STORE_KIND"
 	envKeyEtcdEndpoints = "ETCD_ENDPOINTS"
+
+	envKeyKeyRotationCheckInterval = "KEY_ROTATION_CHECK_INTERVAL"
 )
 
 func normalLoad(t *testing.T) {
@@ -55,4 +74,5 @@ func normalLoad(t *testing.T) {
 	assert.Equal(t, EtcdEndpoints(), "fake")
 	assert.Equal(t, ListenAddr(), ":3082")
 	assert.False(t, Debug())
+	assert.Equal(t, KeyRotationCheckInterval(), 10*time.Minute)
 }
@@ -30,6 +30,19 @@ var (
 	ErrDescribeKey      = err("ErrDescribeKey", "查询用户主密钥失败")
 )
 
+var (
+	ErrGetPublicKey      = err("ErrGetPublicKey", "获取公钥失败")
+	ErrAsymmetricDecrypt = err("ErrAsymmetricDecrypt", "非对称解密失败")
+	ErrSign              = err("ErrSign", "签名失败")
+	ErrVerify            = err("ErrVerify", "验签失败")
+)
+
+var (
+	ErrUpdateRotationPolicy = err("ErrUpdateRotationPolicy", "更新密钥轮转策略失败")
+	ErrReEncrypt            = err("ErrReEncrypt", "重新加密失败")
+	ErrSeal                 = err("ErrSeal", "信封加密失败")
+)
+
 func err(template, defaultValue string) *errorresp.APIError {
 	return errorresp.New(errorresp.WithTemplateMessage(template, defaultValue))
 }
@@ -57,6 +57,13 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/kms/decrypt", Method: http.MethodPost, Handler: e.KmsDecrypt},
 		{Path: "/api/kms/generate-data-key", Method: http.MethodPost, Handler: e.KmsGenerateDataKey},
 		{Path: "/api/kms/rotate-key-version", Method: http.MethodPost, Handler: e.KmsRotateKeyVersion},
+		{Path: "/api/kms/update-rotation-policy", Method: http.MethodPost, Handler: e.KmsUpdateRotationPolicy},
+		{Path: "/api/kms/re-encrypt", Method: http.MethodPost, Handler: e.KmsReEncrypt},
+		{Path: "/api/kms/seal", Method: http.MethodPost, Handler: e.KmsSeal},
 		{Path: "/api/kms/describe-key", Method: http.MethodGet, Handler: e.KmsRotateKeyVersion},
+		{Path: "/api/kms/get-public-key", Method: http.MethodPost, Handler: e.KmsGetPublicKey},
+		{Path: "/api/kms/asymmetric-decrypt", Method: http.MethodPost, Handler: e.KmsAsymmetricDecrypt},
+		{Path: "/api/kms/sign", Method: http.MethodPost, Handler: e.KmsSign},
+		{Path: "/api/kms/verify", Method: http.MethodPost, Handler: e.KmsVerify},
 	}
 }
@@ -16,10 +16,12 @@ package endpoints
 
 import (
 	"context"
+	"encoding/base64"
 	"net/http"
 
 	"github.com/erda-project/erda/modules/kms/endpoints/apierrors"
 	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/kms/envelope"
 	"github.com/erda-project/erda/pkg/kms/kmstypes"
 )
 
@@ -94,3 +96,77 @@ func (e *Endpoints) KmsRotateKeyVersion(ctx context.Context, r *http.Request, va
 
 	return httpserver.OkResp(rotateResp)
 }
+
+func (e *Endpoints) KmsUpdateRotationPolicy(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.UpdateRotationPolicyRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	plugin, err := e.getPluginByKeyID(req.KeyID)
+	if err != nil {
+		return apierrors.ErrUpdateRotationPolicy.InvalidParameter(err).ToResp(), nil
+	}
+	updateResp, err := plugin.UpdateRotationPolicy(ctx, &req)
+	if err != nil {
+		return apierrors.ErrUpdateRotationPolicy.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(updateResp)
+}
+
+func (e *Endpoints) KmsReEncrypt(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.ReEncryptRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	plugin, err := e.getPluginByKeyID(req.KeyID)
+	if err != nil {
+		return apierrors.ErrReEncrypt.InvalidParameter(err).ToResp(), nil
+	}
+	reEncryptResp, err := plugin.ReEncrypt(ctx, &req)
+	if err != nil {
+		return apierrors.ErrReEncrypt.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(reEncryptResp)
+}
+
+// KmsSeal seals a secret by envelope encryption, the sealed value is saved into config-center by caller
+// and resolved by secret://<keyID>/<name> references in dice.yml at deploy time.
+func (e *Endpoints) KmsSeal(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.SealRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	plugin, err := e.getPluginByKeyID(req.KeyID)
+	if err != nil {
+		return apierrors.ErrSeal.InvalidParameter(err).ToResp(), nil
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(req.PlaintextBase64)
+	if err != nil {
+		return apierrors.ErrSeal.InvalidParameter(err).ToResp(), nil
+	}
+	sealed, err := envelope.Seal(pluginDataKeyService{ctx: ctx, plugin: plugin}, req.KeyID, plaintext)
+	if err != nil {
+		return apierrors.ErrSeal.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(kmstypes.SealResponse{KeyID: req.KeyID, Sealed: sealed})
+}
+
+// pluginDataKeyService adapts kms plugin to envelope.DataKeyService
+type pluginDataKeyService struct {
+	ctx    context.Context
+	plugin kmstypes.Plugin
+}
+
+func (s pluginDataKeyService) GenerateDataKey(req *kmstypes.GenerateDataKeyRequest) (*kmstypes.GenerateDataKeyResponse, error) {
+	return s.plugin.GenerateDataKey(s.ctx, req)
+}
+
+func (s pluginDataKeyService) Decrypt(req *kmstypes.DecryptRequest) (*kmstypes.DecryptResponse, error) {
+	return s.plugin.Decrypt(s.ctx, req)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/envelope"
+	"github.com/erda-project/erda/pkg/kms/kmscrypto"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// fakePlugin encrypts DEK with a fixed CMK, only for test
+type fakePlugin struct {
+	kmstypes.Plugin
+	cmk []byte
+}
+
+func (p *fakePlugin) GenerateDataKey(ctx context.Context, req *kmstypes.GenerateDataKeyRequest) (*kmstypes.GenerateDataKeyResponse, error) {
+	dek, err := kmscrypto.GenerateAes256Key()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := kmscrypto.AesGcmEncrypt(p.cmk, dek, []byte(req.KeyID))
+	if err != nil {
+		return nil, err
+	}
+	return &kmstypes.GenerateDataKeyResponse{
+		KeyID:            req.KeyID,
+		CiphertextBase64: base64.StdEncoding.EncodeToString(ciphertext),
+		PlaintextBase64:  base64.StdEncoding.EncodeToString(dek),
+	}, nil
+}
+
+func (p *fakePlugin) Decrypt(ctx context.Context, req *kmstypes.DecryptRequest) (*kmstypes.DecryptResponse, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(req.CiphertextBase64)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := kmscrypto.AesGcmDecrypt(p.cmk, ciphertext, []byte(req.KeyID))
+	if err != nil {
+		return nil, err
+	}
+	return &kmstypes.DecryptResponse{PlaintextBase64: base64.StdEncoding.EncodeToString(dek)}, nil
+}
+
+func TestPluginDataKeyService_Seal(t *testing.T) {
+	cmk, err := kmscrypto.GenerateAes256Key()
+	assert.NoError(t, err)
+	svc := pluginDataKeyService{ctx: context.Background(), plugin: &fakePlugin{cmk: cmk}}
+
+	sealed, err := envelope.Seal(svc, "key-a", []byte("p@ssw0rd"))
+	assert.NoError(t, err)
+	assert.True(t, envelope.IsSealed(sealed))
+	assert.NotContains(t, sealed, "p@ssw0rd")
+
+	plaintext, err := envelope.Open(svc, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "p@ssw0rd", string(plaintext))
+}
+
+func TestSealRequest_ValidateRequest(t *testing.T) {
+	assert.Error(t, (&kmstypes.SealRequest{PlaintextBase64: "aGVsbG8="}).ValidateRequest())
+	assert.Error(t, (&kmstypes.SealRequest{KeyID: "key-a"}).ValidateRequest())
+	assert.Error(t, (&kmstypes.SealRequest{KeyID: "key-a", PlaintextBase64: "not base64"}).ValidateRequest())
+	assert.NoError(t, (&kmstypes.SealRequest{KeyID: "key-a", PlaintextBase64: "aGVsbG8="}).ValidateRequest())
+}
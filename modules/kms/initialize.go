@@ -16,6 +16,8 @@
 package kms
 
 import (
+	"context"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/modules/kms/conf"
@@ -43,6 +45,9 @@ func Initialize() error {
 
 	ep := endpoints.New(endpoints.WithKmsManager(kmsMgr))
 
+	// 自动轮转用户主密钥
+	go rotateKeysPeriodically(context.Background(), kmsMgr)
+
 	server := httpserver.New(conf.ListenAddr())
 	server.RegisterEndpoint(ep.Routes())
 
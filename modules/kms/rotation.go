@@ -0,0 +1,84 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/modules/kms/conf"
+	"github.com/erda-project/erda/pkg/dlock"
+	"github.com/erda-project/erda/pkg/kms"
+)
+
+const keyRotationLockKey = "/dice/kms/key-rotation"
+
+// rotateKeysPeriodically 定期自动轮转到期的用户主密钥, 多实例时只有持有分布式锁的实例执行
+func rotateKeysPeriodically(ctx context.Context, kmsMgr *kms.Manager) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		lockCtx, cancel := context.WithCancel(ctx)
+		lock, err := dlock.New(keyRotationLockKey, func() { cancel() }, dlock.WithTTL(30))
+		if err != nil {
+			logrus.Errorf("failed to create key rotation lock, err: %v", err)
+			cancel()
+			time.Sleep(conf.KeyRotationCheckInterval())
+			continue
+		}
+		if err := lock.Lock(lockCtx); err != nil {
+			logrus.Errorf("failed to lock key rotation, err: %v", err)
+			_ = lock.Close()
+			cancel()
+			time.Sleep(conf.KeyRotationCheckInterval())
+			continue
+		}
+		logrus.Infof("key rotation lock acquired, begin to rotate keys periodically")
+
+		rotateKeysUntilDone(lockCtx, kmsMgr)
+
+		if err := lock.UnlockAndClose(); err != nil {
+			logrus.Errorf("failed to unlock key rotation, err: %v", err)
+		}
+		cancel()
+	}
+}
+
+// rotateKeysUntilDone 持有锁期间每隔 KeyRotationCheckInterval 轮转一次, 锁丢失或 ctx 结束时返回
+func rotateKeysUntilDone(ctx context.Context, kmsMgr *kms.Manager) {
+	ticker := time.NewTicker(conf.KeyRotationCheckInterval())
+	defer ticker.Stop()
+	for {
+		rotated, err := kmsMgr.RotateDueKeys(ctx, conf.KmsStoreKind(), time.Now())
+		if err != nil {
+			logrus.Errorf("failed to rotate keys automatically, err: %v", err)
+		}
+		if len(rotated) > 0 {
+			logrus.Infof("rotated %d keys automatically", len(rotated))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
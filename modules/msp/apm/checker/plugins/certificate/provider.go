@@ -15,7 +15,14 @@
 package certificate
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/erda-project/erda-infra/base/logs"
 	"github.com/erda-project/erda-infra/base/servicehub"
@@ -23,7 +30,11 @@ import (
 	"github.com/erda-project/erda/modules/msp/apm/checker/plugins"
 )
 
-type config struct{}
+type config struct {
+	Timeout time.Duration `file:"timeout" default:"5s"`
+	// ExpireDays report outage if the certificate will expire within the days
+	ExpireDays int `file:"expire_days" default:"30"`
+}
 
 // +provider
 type provider struct {
@@ -34,11 +45,190 @@ type provider struct {
 func (p *provider) Init(ctx servicehub.Context) error { return nil }
 
 func (p *provider) Validate(c *pb.Checker) error {
-	return nil
+	_, err := p.New(c)
+	return err
 }
 
 func (p *provider) New(c *pb.Checker) (plugins.Handler, error) {
-	return nil, fmt.Errorf("TODO ...")
+	// address
+	addr, host, err := parseAddress(c.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// server name for SNI and hostname verification
+	serverName := c.Config["server_name"]
+	if len(serverName) <= 0 {
+		serverName = host
+	}
+
+	// timeout
+	var timeout time.Duration
+	if val := c.Config["timeout"]; len(val) > 0 {
+		timeout, err = time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %s", err)
+		}
+	}
+	if timeout <= 0 {
+		timeout = p.Cfg.Timeout
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	// expire days
+	expireDays := p.Cfg.ExpireDays
+	if val := c.Config["expire_days"]; len(val) > 0 {
+		expireDays, err = strconv.Atoi(val)
+		if err != nil || expireDays < 0 {
+			return nil, fmt.Errorf("invalid expire_days: %q", val)
+		}
+	}
+
+	return &certHandler{
+		tags:       c.Tags,
+		addr:       addr,
+		serverName: serverName,
+		timeout:    timeout,
+		expireDays: expireDays,
+	}, nil
+}
+
+// parseAddress returns host:port and host from config "url" or "address", the default port is 443.
+func parseAddress(cfg map[string]string) (addr, host string, err error) {
+	addr = cfg["address"]
+	if urlstr := cfg["url"]; len(addr) <= 0 && len(urlstr) > 0 {
+		u, err := url.Parse(urlstr)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid url: %s", err)
+		}
+		addr = u.Host
+	}
+	if len(addr) <= 0 {
+		return "", "", fmt.Errorf("url or address must not be empty")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, "443"
+	}
+	if len(host) <= 0 {
+		return "", "", fmt.Errorf("host must not be empty")
+	}
+	return net.JoinHostPort(host, port), host, nil
+}
+
+type certHandler struct {
+	tags       map[string]string
+	addr       string
+	serverName string
+	timeout    time.Duration
+	expireDays int
+}
+
+// certInfo is the check result of the peer certificate.
+type certInfo struct {
+	expireDays    int
+	notAfter      time.Time
+	chainValid    bool
+	chainError    string
+	hostnameMatch bool
+}
+
+func (h *certHandler) Do(ctx plugins.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	tags, fields := make(map[string]string), make(map[string]interface{})
+	for k, v := range h.tags {
+		tags[k] = v
+	}
+	tags["url"] = "https://" + h.addr
+	tags["server_name"] = h.serverName
+	fields["retry"] = 0 // for compatibility
+
+	start := time.Now()
+	info, err := h.inspect()
+	fields["latency"] = time.Now().Sub(start).Milliseconds()
+	if err == nil {
+		fields["expire_days"] = info.expireDays
+		fields["not_after"] = info.notAfter.Unix()
+		fields["chain_valid"] = info.chainValid
+		fields["hostname_match"] = info.hostnameMatch
+		err = h.verify(info)
+	}
+	if err != nil {
+		fields["code"] = 601
+		fields["message"] = err.Error()
+		tags["status"] = "2"                 // for compatibility
+		tags["status_name"] = "Major Outage" // for compatibility
+	} else {
+		fields["code"] = 200
+		tags["status"] = "1"                // for compatibility
+		tags["status_name"] = "Operational" // for compatibility
+	}
+
+	ctx.Report(&plugins.Metric{
+		Name:   "status_page",
+		Tags:   tags,
+		Fields: fields,
+	})
+	return nil
+}
+
+// inspect dials the address and checks the peer certificates.
+// The verification is done manually, so the details can be reported even if the certificate is invalid.
+func (h *certHandler) inspect() (*certInfo, error) {
+	dialer := &net.Dialer{Timeout: h.timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", h.addr, &tls.Config{
+		ServerName:         h.serverName,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) <= 0 {
+		return nil, fmt.Errorf("no peer certificate")
+	}
+	leaf := certs[0]
+	info := &certInfo{
+		notAfter:      leaf.NotAfter,
+		expireDays:    int(math.Floor(time.Until(leaf.NotAfter).Hours() / 24)),
+		hostnameMatch: leaf.VerifyHostname(h.serverName) == nil,
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Intermediates: intermediates}); err != nil {
+		info.chainError = err.Error()
+	} else {
+		info.chainValid = true
+	}
+	return info, nil
+}
+
+func (h *certHandler) verify(info *certInfo) error {
+	if info.expireDays < 0 {
+		return fmt.Errorf("certificate expired at %s", info.notAfter.Format(time.RFC3339))
+	}
+	if !info.chainValid {
+		return fmt.Errorf("invalid certificate chain: %s", info.chainError)
+	}
+	if !info.hostnameMatch {
+		return fmt.Errorf("certificate does not match hostname %q", h.serverName)
+	}
+	if info.expireDays < h.expireDays {
+		return fmt.Errorf("certificate will expire in %d days at %s", info.expireDays, info.notAfter.Format(time.RFC3339))
+	}
+	return nil
 }
 
 func init() {
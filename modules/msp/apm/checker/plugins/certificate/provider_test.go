@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/erda-project/erda-proto-go/msp/apm/checker/pb"
+	"github.com/erda-project/erda/modules/msp/apm/checker/plugins"
+)
+
+type mockContext struct {
+	context.Context
+	metrics []*plugins.Metric
+}
+
+func (c *mockContext) Report(m ...*plugins.Metric) error {
+	c.metrics = append(c.metrics, m...)
+	return nil
+}
+
+func Test_parseAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]string
+		wantAddr string
+		wantErr  bool
+	}{
+		{"url", map[string]string{"url": "https://erda.cloud/path"}, "erda.cloud:443", false},
+		{"url with port", map[string]string{"url": "https://erda.cloud:8443"}, "erda.cloud:8443", false},
+		{"address", map[string]string{"address": "erda.cloud"}, "erda.cloud:443", false},
+		{"empty", map[string]string{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, _, err := parseAddress(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if addr != tt.wantAddr {
+				t.Errorf("parseAddress() got = %v, want %v", addr, tt.wantAddr)
+			}
+		})
+	}
+}
+
+func Test_certHandler_Do(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	p := &provider{Cfg: &config{Timeout: time.Second, ExpireDays: 30}}
+	h, err := p.New(&pb.Checker{Config: map[string]string{"url": srv.URL, "server_name": "example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := &mockContext{Context: context.Background()}
+	if err := h.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(ctx.metrics) != 1 {
+		t.Fatalf("got %d metrics, want 1", len(ctx.metrics))
+	}
+	m := ctx.metrics[0]
+	// the test certificate is self-signed for example.com
+	if m.Fields["chain_valid"] != false {
+		t.Errorf("chain_valid got %v, want false", m.Fields["chain_valid"])
+	}
+	if m.Fields["hostname_match"] != true {
+		t.Errorf("hostname_match got %v, want true", m.Fields["hostname_match"])
+	}
+	if days, ok := m.Fields["expire_days"].(int); !ok || days <= 0 {
+		t.Errorf("invalid expire_days %v", m.Fields["expire_days"])
+	}
+	if m.Tags["status"] != "2" {
+		t.Errorf("got status %s, want 2", m.Tags["status"])
+	}
+}
@@ -15,7 +15,12 @@
 package dns
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/erda-project/erda-infra/base/logs"
 	"github.com/erda-project/erda-infra/base/servicehub"
@@ -23,7 +28,19 @@ import (
 	"github.com/erda-project/erda/modules/msp/apm/checker/plugins"
 )
 
-type config struct{}
+// supported record types
+const (
+	recordA     = "A"
+	recordAAAA  = "AAAA"
+	recordCNAME = "CNAME"
+	recordMX    = "MX"
+	recordNS    = "NS"
+	recordTXT   = "TXT"
+)
+
+type config struct {
+	Timeout time.Duration `file:"timeout" default:"5s"`
+}
 
 // +provider
 type provider struct {
@@ -34,11 +51,207 @@ type provider struct {
 func (p *provider) Init(ctx servicehub.Context) error { return nil }
 
 func (p *provider) Validate(c *pb.Checker) error {
-	return nil
+	_, err := p.New(c)
+	return err
 }
 
 func (p *provider) New(c *pb.Checker) (plugins.Handler, error) {
-	return nil, fmt.Errorf("TODO ...")
+	// domain
+	domain := strings.TrimSpace(c.Config["domain"])
+	if len(domain) <= 0 {
+		return nil, fmt.Errorf("domain must not be empty")
+	}
+
+	// record type
+	recordType := strings.ToUpper(c.Config["record_type"])
+	if len(recordType) <= 0 {
+		recordType = recordA
+	}
+	switch recordType {
+	case recordA, recordAAAA, recordCNAME, recordMX, recordNS, recordTXT:
+	default:
+		return nil, fmt.Errorf("invalid record_type: %s", recordType)
+	}
+
+	// resolver server
+	server := c.Config["server"]
+	if len(server) > 0 {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+	}
+
+	// timeout
+	var timeout time.Duration
+	if val := c.Config["timeout"]; len(val) > 0 {
+		var err error
+		timeout, err = time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %s", err)
+		}
+	}
+	if timeout <= 0 {
+		timeout = p.Cfg.Timeout
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	// expected answers, split by comma
+	var expects []string
+	for _, item := range strings.Split(c.Config["expect"], ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			expects = append(expects, normalizeAnswer(item))
+		}
+	}
+
+	h := &dnsHandler{
+		tags:       c.Tags,
+		domain:     domain,
+		recordType: recordType,
+		server:     server,
+		expects:    expects,
+		timeout:    timeout,
+		resolver:   net.DefaultResolver,
+	}
+	if len(server) > 0 {
+		h.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				d := net.Dialer{Timeout: timeout}
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+	return h, nil
+}
+
+type dnsHandler struct {
+	tags       map[string]string
+	domain     string
+	recordType string
+	server     string
+	expects    []string
+	timeout    time.Duration
+	resolver   *net.Resolver
+}
+
+func (h *dnsHandler) Do(ctx plugins.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	tags, fields := make(map[string]string), make(map[string]interface{})
+	for k, v := range h.tags {
+		tags[k] = v
+	}
+	tags["url"] = "dns://" + h.domain
+	tags["record_type"] = h.recordType
+	if len(h.server) > 0 {
+		tags["server"] = h.server
+	}
+	fields["retry"] = 0 // for compatibility
+
+	start := time.Now()
+	answers, err := h.lookup(ctx)
+	fields["latency"] = time.Now().Sub(start).Milliseconds()
+	if err == nil {
+		fields["answers"] = strings.Join(answers, ",")
+		err = h.match(answers)
+	}
+	if err != nil {
+		fields["code"] = 601
+		fields["message"] = err.Error()
+		tags["status"] = "2"                 // for compatibility
+		tags["status_name"] = "Major Outage" // for compatibility
+	} else {
+		fields["code"] = 200
+		tags["status"] = "1"                // for compatibility
+		tags["status_name"] = "Operational" // for compatibility
+	}
+
+	ctx.Report(&plugins.Metric{
+		Name:   "status_page",
+		Tags:   tags,
+		Fields: fields,
+	})
+	return nil
+}
+
+// lookup returns the sorted answers of the record type.
+func (h *dnsHandler) lookup(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var answers []string
+	switch h.recordType {
+	case recordA, recordAAAA:
+		network := "ip4"
+		if h.recordType == recordAAAA {
+			network = "ip6"
+		}
+		ips, err := h.resolver.LookupIP(ctx, network, h.domain)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+	case recordCNAME:
+		cname, err := h.resolver.LookupCNAME(ctx, h.domain)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, normalizeAnswer(cname))
+	case recordMX:
+		mxs, err := h.resolver.LookupMX(ctx, h.domain)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			answers = append(answers, normalizeAnswer(mx.Host))
+		}
+	case recordNS:
+		nss, err := h.resolver.LookupNS(ctx, h.domain)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			answers = append(answers, normalizeAnswer(ns.Host))
+		}
+	case recordTXT:
+		txts, err := h.resolver.LookupTXT(ctx, h.domain)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, txts...)
+	}
+	if len(answers) <= 0 {
+		return nil, fmt.Errorf("no %s record found", h.recordType)
+	}
+	sort.Strings(answers)
+	return answers, nil
+}
+
+// match checks all expected answers are returned.
+func (h *dnsHandler) match(answers []string) error {
+	set := make(map[string]struct{}, len(answers))
+	for _, answer := range answers {
+		set[answer] = struct{}{}
+	}
+	for _, expect := range h.expects {
+		if _, ok := set[expect]; !ok {
+			return fmt.Errorf("expected answer %q not found in [%s]", expect, strings.Join(answers, ","))
+		}
+	}
+	return nil
+}
+
+// normalizeAnswer removes the trailing dot of domain names.
+func normalizeAnswer(answer string) string {
+	return strings.TrimSuffix(answer, ".")
 }
 
 func init() {
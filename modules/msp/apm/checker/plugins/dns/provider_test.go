@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erda-project/erda-proto-go/msp/apm/checker/pb"
+)
+
+func Test_provider_New(t *testing.T) {
+	p := &provider{Cfg: &config{Timeout: time.Second}}
+	tests := []struct {
+		name       string
+		config     map[string]string
+		wantErr    bool
+		wantServer string
+		wantExpect []string
+	}{
+		{"default", map[string]string{"domain": "erda.cloud"}, false, "", nil},
+		{"server without port", map[string]string{"domain": "erda.cloud", "server": "8.8.8.8"}, false, "8.8.8.8:53", nil},
+		{"expects", map[string]string{"domain": "erda.cloud", "record_type": "cname", "expect": "a.erda.cloud., b.erda.cloud"}, false, "", []string{"a.erda.cloud", "b.erda.cloud"}},
+		{"empty domain", map[string]string{}, true, "", nil},
+		{"invalid record type", map[string]string{"domain": "erda.cloud", "record_type": "SRV"}, true, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := p.New(&pb.Checker{Config: tt.config})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			dh := h.(*dnsHandler)
+			if dh.server != tt.wantServer {
+				t.Errorf("server got %q, want %q", dh.server, tt.wantServer)
+			}
+			if len(dh.expects) != len(tt.wantExpect) {
+				t.Fatalf("expects got %v, want %v", dh.expects, tt.wantExpect)
+			}
+			for i := range dh.expects {
+				if dh.expects[i] != tt.wantExpect[i] {
+					t.Errorf("expects got %v, want %v", dh.expects, tt.wantExpect)
+				}
+			}
+		})
+	}
+}
+
+func Test_dnsHandler_match(t *testing.T) {
+	h := &dnsHandler{expects: []string{"1.1.1.1"}}
+	if err := h.match([]string{"1.1.1.1", "2.2.2.2"}); err != nil {
+		t.Errorf("match() unexpected error: %v", err)
+	}
+	if err := h.match([]string{"2.2.2.2"}); err == nil {
+		t.Errorf("match() expected error")
+	}
+}
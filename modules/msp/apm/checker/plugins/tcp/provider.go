@@ -16,6 +16,10 @@ package tcp
 
 import (
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/erda-project/erda-infra/base/logs"
 	"github.com/erda-project/erda-infra/base/servicehub"
@@ -23,7 +27,11 @@ import (
 	"github.com/erda-project/erda/modules/msp/apm/checker/plugins"
 )
 
-type config struct{}
+const maxResponseSize = 4096
+
+type config struct {
+	Timeout time.Duration `file:"timeout" default:"5s"`
+}
 
 // +provider
 type provider struct {
@@ -34,11 +42,144 @@ type provider struct {
 func (p *provider) Init(ctx servicehub.Context) error { return nil }
 
 func (p *provider) Validate(c *pb.Checker) error {
-	return nil
+	_, err := parseAddress(c.Config)
+	return err
 }
 
 func (p *provider) New(c *pb.Checker) (plugins.Handler, error) {
-	return nil, fmt.Errorf("TODO ...")
+	// address
+	addr, err := parseAddress(c.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// timeout
+	var timeout time.Duration
+	if val := c.Config["timeout"]; len(val) > 0 {
+		timeout, err = time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %s", err)
+		}
+	}
+	if timeout <= 0 {
+		timeout = p.Cfg.Timeout
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &tcpHandler{
+		tags:    c.Tags,
+		addr:    addr,
+		send:    c.Config["send"],
+		expect:  c.Config["expect"],
+		timeout: timeout,
+	}, nil
+}
+
+// parseAddress returns host:port from config, "address" is preferred, otherwise "host" and "port" are used.
+func parseAddress(cfg map[string]string) (string, error) {
+	addr := cfg["address"]
+	if len(addr) <= 0 {
+		host, port := cfg["host"], cfg["port"]
+		if len(host) <= 0 {
+			return "", fmt.Errorf("host must not be empty")
+		}
+		addr = net.JoinHostPort(host, port)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address: %s", err)
+	}
+	if len(host) <= 0 {
+		return "", fmt.Errorf("host must not be empty")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return "", fmt.Errorf("invalid port: %q", port)
+	}
+	return addr, nil
+}
+
+type tcpHandler struct {
+	tags    map[string]string
+	addr    string
+	send    string
+	expect  string
+	timeout time.Duration
+}
+
+func (h *tcpHandler) Do(ctx plugins.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	tags, fields := make(map[string]string), make(map[string]interface{})
+	for k, v := range h.tags {
+		tags[k] = v
+	}
+	tags["url"] = "tcp://" + h.addr
+	fields["retry"] = 0 // for compatibility
+
+	start := time.Now()
+	err := h.check(ctx)
+	fields["latency"] = time.Now().Sub(start).Milliseconds()
+	if err != nil {
+		fields["code"] = 601
+		fields["message"] = err.Error()
+		tags["status"] = "2"                 // for compatibility
+		tags["status_name"] = "Major Outage" // for compatibility
+	} else {
+		fields["code"] = 200
+		tags["status"] = "1"                // for compatibility
+		tags["status_name"] = "Operational" // for compatibility
+	}
+
+	ctx.Report(&plugins.Metric{
+		Name:   "status_page",
+		Tags:   tags,
+		Fields: fields,
+	})
+	return nil
+}
+
+// check dials the address, sends payload and matches response if need.
+func (h *tcpHandler) check(ctx plugins.Context) error {
+	dialer := &net.Dialer{Timeout: h.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", h.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(h.send) <= 0 && len(h.expect) <= 0 {
+		return nil
+	}
+	conn.SetDeadline(time.Now().Add(h.timeout))
+
+	if len(h.send) > 0 {
+		if _, err := conn.Write([]byte(h.send)); err != nil {
+			return fmt.Errorf("failed to send: %s", err)
+		}
+	}
+	if len(h.expect) <= 0 {
+		return nil
+	}
+
+	// read until matched, EOF, timeout or buffer is full
+	var buf []byte
+	tmp := make([]byte, 512)
+	for len(buf) < maxResponseSize {
+		n, err := conn.Read(tmp)
+		buf = append(buf, tmp[:n]...)
+		if strings.Contains(string(buf), h.expect) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("response not matched %q: %s", h.expect, err)
+		}
+	}
+	return fmt.Errorf("response not matched %q", h.expect)
 }
 
 func init() {
@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/erda-project/erda-proto-go/msp/apm/checker/pb"
+	"github.com/erda-project/erda/modules/msp/apm/checker/plugins"
+)
+
+type mockContext struct {
+	context.Context
+	metrics []*plugins.Metric
+}
+
+func (c *mockContext) Report(m ...*plugins.Metric) error {
+	c.metrics = append(c.metrics, m...)
+	return nil
+}
+
+func startEchoServer(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 512)
+				n, _ := conn.Read(buf)
+				conn.Write(buf[:n])
+			}()
+		}
+	}()
+	return l
+}
+
+func Test_provider_Validate(t *testing.T) {
+	p := &provider{Cfg: &config{}}
+	tests := []struct {
+		name    string
+		config  map[string]string
+		wantErr bool
+	}{
+		{"address", map[string]string{"address": "127.0.0.1:3306"}, false},
+		{"host and port", map[string]string{"host": "localhost", "port": "6379"}, false},
+		{"empty", map[string]string{}, true},
+		{"no port", map[string]string{"host": "localhost"}, true},
+		{"invalid port", map[string]string{"address": "localhost:99999"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.Validate(&pb.Checker{Config: tt.config}); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_tcpHandler_Do(t *testing.T) {
+	l := startEchoServer(t)
+	defer l.Close()
+
+	p := &provider{Cfg: &config{Timeout: time.Second}}
+	tests := []struct {
+		name   string
+		config map[string]string
+		status string
+	}{
+		{"connect", map[string]string{"address": l.Addr().String()}, "1"},
+		{"matched", map[string]string{"address": l.Addr().String(), "send": "PING", "expect": "PING"}, "1"},
+		{"not matched", map[string]string{"address": l.Addr().String(), "send": "PING", "expect": "PONG", "timeout": "100ms"}, "2"},
+		{"refused", map[string]string{"address": "127.0.0.1:1"}, "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := p.New(&pb.Checker{Config: tt.config, Tags: map[string]string{"metric": "1"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := &mockContext{Context: context.Background()}
+			if err := h.Do(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if len(ctx.metrics) != 1 {
+				t.Fatalf("got %d metrics, want 1", len(ctx.metrics))
+			}
+			m := ctx.metrics[0]
+			if m.Tags["status"] != tt.status {
+				t.Errorf("got status %s, want %s, message: %v", m.Tags["status"], tt.status, m.Fields["message"])
+			}
+			if m.Tags["metric"] != "1" {
+				t.Errorf("checker tags not reported")
+			}
+		})
+	}
+}
@@ -306,6 +306,10 @@ const props3 string = `}
       {
         "label": "不属于",
         "value": "not_belong"
+      },
+      {
+        "label": "符合 JSON Schema",
+        "value": "json_schema"
       }
     ]
   },
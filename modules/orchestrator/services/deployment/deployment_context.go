@@ -955,7 +955,10 @@ func (fsm *DeployFSMContext) generateDeployServiceRequest(group *apistructs.Serv
 	for k, v := range obj.Envs {
 		groupEnv[k] = v
 	}
-	var configNamespace string
+	var (
+		configNamespace string
+		envconfigs      map[string]string
+	)
 	for _, w := range fsm.App.Workspaces {
 		if w.Workspace == runtime.Workspace {
 			configNamespace = w.ConfigNamespace
@@ -964,7 +967,8 @@ func (fsm *DeployFSMContext) generateDeployServiceRequest(group *apistructs.Serv
 	}
 	if len(configNamespace) > 0 {
 		// get configs from config-center
-		envconfigs, fileconfigs, err := fsm.bdl.FetchDeploymentConfig(configNamespace)
+		var fileconfigs map[string]string
+		envconfigs, fileconfigs, err = fsm.bdl.FetchDeploymentConfig(configNamespace)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1005,6 +1009,12 @@ func (fsm *DeployFSMContext) generateDeployServiceRequest(group *apistructs.Serv
 		}
 
 	}
+	// resolve secret:// references in envs, only sealed values are passed to scheduler
+	secretEnvs, err := resolveSecretEnvs(obj, envconfigs, fsm.bdl.KMSDataKeyService())
+	if err != nil {
+		return nil, nil, err
+	}
+	group.SecretEnvs = secretEnvs
 	group.DiceYml = *obj
 	return usedAddonInsMap, usedAddonTenantMap, nil
 }
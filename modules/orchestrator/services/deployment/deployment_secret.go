@@ -0,0 +1,77 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/pkg/kms/envelope"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+// resolveSecretEnvs 解析 envs 中的 secret://<keyID>/<name> 引用.
+// <name> 对应配置中心中的一条配置, 其值为 CMK <keyID> 下的 KMS 信封密文.
+// 部署时通过 KMS 解密校验密文可用, 然后将引用和信封密文从明文 envs 中移除, 只返回密文,
+// 由 executor 以 k8s Secret 的方式注入, 明文不会落入 servicegroup 和 release 等元数据.
+// 返回值 map[servicename]map[envname]sealed
+func resolveSecretEnvs(obj *diceyml.Object, configs map[string]string, svc envelope.DataKeyService) (map[string]map[string]string, error) {
+	secretEnvs := make(map[string]map[string]string)
+	opened := make(map[string]struct{})
+	for serviceName, service := range obj.Services {
+		for k, v := range service.Envs {
+			if envelope.IsSealed(v) {
+				// 信封密文只通过 secret:// 引用使用, 不作为明文 env 注入
+				delete(service.Envs, k)
+				continue
+			}
+			if !diceyml.IsSecretRef(v) {
+				continue
+			}
+			ref, err := diceyml.ParseSecretRef(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "service: %s, env: %s", serviceName, k)
+			}
+			sealed, ok := configs[ref.Name]
+			if !ok {
+				return nil, errors.Errorf("service: %s, env: %s, secret %s not found in config-center", serviceName, k, ref.Name)
+			}
+			s, err := envelope.Parse(sealed)
+			if err != nil {
+				return nil, errors.Wrapf(err, "service: %s, env: %s, secret %s", serviceName, k, ref.Name)
+			}
+			if s.KeyID != ref.KeyID {
+				return nil, errors.Errorf("service: %s, env: %s, secret %s is not sealed by key %s", serviceName, k, ref.Name, ref.KeyID)
+			}
+			if _, ok := opened[sealed]; !ok {
+				if _, err := s.Open(svc); err != nil {
+					return nil, errors.Wrapf(err, "service: %s, env: %s, failed to open secret %s", serviceName, k, ref.Name)
+				}
+				opened[sealed] = struct{}{}
+			}
+			if secretEnvs[serviceName] == nil {
+				secretEnvs[serviceName] = make(map[string]string)
+			}
+			secretEnvs[serviceName][k] = sealed
+			delete(service.Envs, k)
+		}
+	}
+	// global envs 已经展开到各 service 中, 这里移除以免 scheduler 再次展开
+	for k, v := range obj.Envs {
+		if envelope.IsSealed(v) || diceyml.IsSecretRef(v) {
+			delete(obj.Envs, k)
+		}
+	}
+	return secretEnvs, nil
+}
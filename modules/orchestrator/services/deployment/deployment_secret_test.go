@@ -0,0 +1,85 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/envelope"
+	"github.com/erda-project/erda/pkg/kms/kmscrypto"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+// plainDataKeyService 不加密 DEK, 仅用于测试
+type plainDataKeyService struct{}
+
+func (plainDataKeyService) GenerateDataKey(req *kmstypes.GenerateDataKeyRequest) (*kmstypes.GenerateDataKeyResponse, error) {
+	dek, err := kmscrypto.GenerateAes256Key()
+	if err != nil {
+		return nil, err
+	}
+	dekBase64 := base64.StdEncoding.EncodeToString(dek)
+	return &kmstypes.GenerateDataKeyResponse{KeyID: req.KeyID, CiphertextBase64: dekBase64, PlaintextBase64: dekBase64}, nil
+}
+
+func (plainDataKeyService) Decrypt(req *kmstypes.DecryptRequest) (*kmstypes.DecryptResponse, error) {
+	return &kmstypes.DecryptResponse{PlaintextBase64: req.CiphertextBase64}, nil
+}
+
+func TestResolveSecretEnvs(t *testing.T) {
+	svc := plainDataKeyService{}
+	sealed, err := envelope.Seal(svc, "key-a", []byte("p@ssw0rd"))
+	assert.NoError(t, err)
+	configs := map[string]string{"db.password": sealed, "PLAIN": "v"}
+
+	newObj := func(ref string) *diceyml.Object {
+		return &diceyml.Object{
+			Envs: map[string]string{"GLOBAL_PASSWORD": ref},
+			Services: diceyml.Services{
+				"web": &diceyml.Service{Envs: map[string]string{
+					"GLOBAL_PASSWORD": ref,
+					"DB_PASSWORD":     ref,
+					"db.password":     sealed,
+					"PLAIN":           "v",
+				}},
+				"worker": &diceyml.Service{Envs: map[string]string{"PLAIN": "v"}},
+			},
+		}
+	}
+
+	obj := newObj("secret://key-a/db.password")
+	secretEnvs, err := resolveSecretEnvs(obj, configs, svc)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"web": {"GLOBAL_PASSWORD": sealed, "DB_PASSWORD": sealed},
+	}, secretEnvs)
+	assert.Equal(t, diceyml.EnvMap{"PLAIN": "v"}, obj.Services["web"].Envs)
+	assert.Equal(t, diceyml.EnvMap{"PLAIN": "v"}, obj.Services["worker"].Envs)
+	assert.Empty(t, obj.Envs)
+
+	for _, ref := range []string{
+		"secret://key-a/not-exist",     // not found in config-center
+		"secret://key-a/PLAIN",         // not sealed
+		"secret://key-b/db.password",   // sealed by another key
+		"secret://key-a/db.password/x", // invalid reference
+	} {
+		_, err := resolveSecretEnvs(newObj(ref), configs, svc)
+		assert.Error(t, err, ref)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverage_report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/erda-project/erda-infra/base/servicehub"
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/aop"
+	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
+	"github.com/erda-project/erda/pkg/coverage"
+)
+
+const (
+	// metaKeyCoverage 任务输出的覆盖率，值为 coverage.Report 的 json
+	metaKeyCoverage = "coverage"
+	// metaKeyCoverageFormat + metaKeyCoverageContent 任务直接输出的原始覆盖率报告，由插件解析
+	metaKeyCoverageFormat  = "coverageFormat"
+	metaKeyCoverageContent = "coverageContent"
+)
+
+// +provider
+type provider struct {
+	aoptypes.TaskBaseTunePoint
+}
+
+func (p *provider) Name() string { return "coverage-report" }
+
+func (p *provider) Handle(ctx *aoptypes.TuneContext) error {
+	metadata := ctx.SDK.Task.Result.Metadata
+	if metadata == nil {
+		return nil
+	}
+
+	var (
+		report          *coverage.Report
+		format, content string
+	)
+	for _, v := range metadata {
+		switch v.Name {
+		case metaKeyCoverage:
+			report = &coverage.Report{}
+			if err := json.Unmarshal([]byte(v.Value), report); err != nil {
+				return fmt.Errorf("unmarshal coverage report error: %v", err)
+			}
+		case metaKeyCoverageFormat:
+			format = v.Value
+		case metaKeyCoverageContent:
+			content = v.Value
+		}
+	}
+	if report == nil && format != "" && content != "" {
+		var err error
+		report, err = coverage.Parse(coverage.Format(format), strings.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("parse %s coverage report error: %v", format, err)
+		}
+	}
+	if report == nil {
+		return nil
+	}
+
+	// 逐行的覆盖率数据体积较大，存放在文件服务中，报告 meta 中只保存汇总信息
+	file, err := uploadReport(ctx, report)
+	if err != nil {
+		return fmt.Errorf("upload coverage report error: %v", err)
+	}
+
+	_, err = ctx.SDK.Report.Create(apistructs.PipelineReportCreateRequest{
+		PipelineID: ctx.SDK.Pipeline.ID,
+		Type:       apistructs.PipelineReportTypeCoverage,
+		Meta: apistructs.PipelineReportMeta{
+			"taskId":                              ctx.SDK.Task.ID,
+			"taskName":                            ctx.SDK.Task.Name,
+			"summary":                             report.Summary(),
+			"packages":                            report.Packages(),
+			"files":                               report.FileSummaries(),
+			apistructs.CoverageReportMetaFileUUID: file.UUID,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func uploadReport(ctx *aoptypes.TuneContext, report *coverage.Report) (*apistructs.File, error) {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.SDK.Bundle.UploadFile(apistructs.FileUploadRequest{
+		FileNameWithExt: fmt.Sprintf("coverage-%d-%d.json", ctx.SDK.Pipeline.ID, ctx.SDK.Task.ID),
+		ByteSize:        int64(len(b)),
+		FileReader:      ioutil.NopCloser(bytes.NewReader(b)),
+		From:            "pipeline-coverage",
+		Creator:         ctx.SDK.Pipeline.GetRunUserID(),
+	})
+}
+
+func (p *provider) Init(ctx servicehub.Context) error {
+	err := aop.RegisterTunePoint(p)
+	if err != nil {
+		panic(err)
+	}
+	return nil
+}
+
+func init() {
+	servicehub.Register(aop.NewProviderNameByPluginName(&provider{}), &servicehub.Spec{
+		Creator: func() servicehub.Provider {
+			return &provider{}
+		},
+	})
+}
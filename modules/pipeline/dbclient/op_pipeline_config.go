@@ -30,12 +30,24 @@ var defaultAPITestActionExecutor = spec.PipelineConfig{
 	},
 }
 
+// defaultMemoryActionExecutor provide in-process memory action-executor
+var defaultMemoryActionExecutor = spec.PipelineConfig{
+	Type: spec.PipelineConfigTypeActionExecutor,
+	Value: spec.ActionExecutorConfig{
+		Kind:    string(spec.PipelineTaskExecutorKindMemory),
+		Name:    spec.PipelineTaskExecutorNameMemoryDefault.String(),
+		Options: nil,
+	},
+}
+
 func (client *Client) ListPipelineConfigsOfActionExecutor() (configs []spec.PipelineConfig, cfgChan chan spec.ActionExecutorConfig, err error) {
 	if err := client.Find(&configs, spec.PipelineConfig{Type: spec.PipelineConfigTypeActionExecutor}); err != nil {
 		return nil, nil, err
 	}
 	// add default api-test action executor
 	configs = append(configs, defaultAPITestActionExecutor)
+	// add default memory action executor
+	configs = append(configs, defaultMemoryActionExecutor)
 	cfgChan = make(chan spec.ActionExecutorConfig, 100)
 	for _, c := range configs {
 		var r spec.ActionExecutorConfig
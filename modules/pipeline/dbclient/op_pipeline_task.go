@@ -181,6 +181,19 @@ func (client *Client) UpdatePipelineTask(id uint64, task *spec.PipelineTask, ops
 	}
 }
 
+// CompareAndUpdatePipelineTask updates the task only if its status in db is still expected,
+// returns false if the status has been changed by others.
+func (client *Client) CompareAndUpdatePipelineTask(id uint64, expected apistructs.PipelineStatus, task *spec.PipelineTask, ops ...SessionOption) (bool, error) {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	affectedRows, err := session.ID(id).Where("status = ?", expected).AllCols().Update(task)
+	if err != nil {
+		return false, err
+	}
+	return affectedRows > 0, nil
+}
+
 func (client *Client) UpdatePipelineTaskStatus(id uint64, status apistructs.PipelineStatus, ops ...SessionOption) error {
 	session := client.NewSession(ops...)
 	defer session.Close()
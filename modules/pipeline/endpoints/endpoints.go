@@ -23,6 +23,7 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/dbclient"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler"
 	"github.com/erda-project/erda/modules/pipeline/pkg/clusterinfo"
+	"github.com/erda-project/erda/modules/pipeline/providers/trigger"
 	"github.com/erda-project/erda/modules/pipeline/services/actionagentsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/appsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/buildartifactsvc"
@@ -50,6 +51,7 @@ type Endpoints struct {
 	extMarketSvc     *extmarketsvc.ExtMarketSvc
 	reportSvc        *reportsvc.ReportSvc
 	queueManage      *queuemanage.QueueManage
+	triggerSvc       *trigger.TriggerService
 
 	dbClient           *dbclient.Client
 	queryStringDecoder *schema.Decoder
@@ -142,6 +144,12 @@ func WithQueueManage(qm *queuemanage.QueueManage) Option {
 	}
 }
 
+func WithTriggerService(svc *trigger.TriggerService) Option {
+	return func(e *Endpoints) {
+		e.triggerSvc = svc
+	}
+}
+
 func WithQueryStringDecoder(decoder *schema.Decoder) Option {
 	return func(e *Endpoints) {
 		e.queryStringDecoder = decoder
@@ -215,6 +223,9 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		// platform callback
 		{Path: "/api/pipelines/actions/callback", Method: http.MethodPost, Handler: e.pipelineCallback},
 
+		// incoming webhook, authenticated by signed url and payload signature
+		{Path: "/api/pipeline-webhooks/{token}", Method: http.MethodPost, Handler: e.pipelineWebhookTrigger},
+
 		// daemon
 		{Path: "/_daemon/reload-action-executor-config", Method: http.MethodGet, Handler: e.reloadActionExecutorConfig},
 		{Path: "/_daemon/crond/actions/reload", Method: http.MethodGet, Handler: e.crondReload},
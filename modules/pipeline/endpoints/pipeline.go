@@ -24,6 +24,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/conf"
 	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
 	"github.com/erda-project/erda/modules/pkg/user"
 	"github.com/erda-project/erda/pkg/http/httpserver"
@@ -140,6 +141,16 @@ func (e *Endpoints) pipelineDetail(ctx context.Context, r *http.Request, vars ma
 		return errorresp.ErrResp(err)
 	}
 
+	if !req.SimplePipelineBaseResult && e.triggerSvc != nil {
+		webhookURLPath, err := e.triggerSvc.GetWebhookURLPath(detailDTO.Source, detailDTO.YmlName)
+		if err != nil {
+			return errorresp.ErrResp(err)
+		}
+		if webhookURLPath != "" {
+			detailDTO.WebhookURL = conf.OpenAPIPublicURL() + webhookURLPath
+		}
+	}
+
 	return httpserver.OkResp(detailDTO)
 }
 
@@ -0,0 +1,52 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/modules/pipeline/providers/trigger"
+	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// maxWebhookPayloadSize 限制 webhook 请求体大小
+const maxWebhookPayloadSize = 1 << 20
+
+func (e *Endpoints) pipelineWebhookTrigger(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		return apierrors.ErrTriggerWebhook.InvalidParameter(err).ToResp(), nil
+	}
+
+	// 鉴权: 签名 url + payload 签名
+	pipelineIDs, err := e.triggerSvc.RunPipelineByWebhook(ctx, vars["token"], r.Header.Get(trigger.WebhookSignatureHeader), body)
+	if err != nil {
+		if len(pipelineIDs) > 0 {
+			logrus.Errorf("failed to create some of pipelines by webhook, created pipelineIDs: %v, err: %v", pipelineIDs, err)
+		}
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(pipelineIDs)
+}
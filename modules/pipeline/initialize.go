@@ -189,6 +189,7 @@ func (p *provider) do() error {
 		endpoints.WithPipelineSvc(pipelineSvc),
 		endpoints.WithReportSvc(reportSvc),
 		endpoints.WithQueueManage(queueManage),
+		endpoints.WithTriggerService(p.TriggerService),
 		endpoints.WithReconciler(r),
 	)
 
@@ -16,6 +16,11 @@ package memory
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/actionexecutor/types"
@@ -28,16 +33,36 @@ const (
 
 func init() {
 	types.Register(Kind, func(name types.Name, options map[string]string) (types.ActionExecutor, error) {
-		return &Memory{
-			name:    name,
-			options: options,
-		}, nil
+		return New(name, options), nil
 	})
 }
 
+// Memory runs tasks in-process, it keeps all job states in memory,
+// so pipelines can be reconciled without scheduler or kubernetes.
 type Memory struct {
 	name    types.Name
 	options map[string]string
+
+	lock sync.RWMutex
+	jobs map[string]*job
+}
+
+// job is the in-memory state of a task.
+type job struct {
+	status    apistructs.PipelineStatus
+	desc      string
+	cancel    context.CancelFunc
+	timeBegin time.Time
+	timeEnd   time.Time
+}
+
+// New returns a memory action executor.
+func New(name types.Name, options map[string]string) *Memory {
+	return &Memory{
+		name:    name,
+		options: options,
+		jobs:    make(map[string]*job),
+	}
 }
 
 func (m *Memory) Kind() types.Kind {
@@ -49,37 +74,179 @@ func (m *Memory) Name() types.Name {
 }
 
 func (m *Memory) Exist(ctx context.Context, action *spec.PipelineTask) (bool, bool, error) {
-	panic("implement me")
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	j, ok := m.jobs[makeJobID(action)]
+	if !ok {
+		return false, false, nil
+	}
+	return true, j.status != apistructs.PipelineStatusCreated, nil
 }
 
 func (m *Memory) Create(ctx context.Context, action *spec.PipelineTask) (interface{}, error) {
-	panic("implement me")
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	jobID := makeJobID(action)
+	if _, ok := m.jobs[jobID]; ok {
+		return nil, nil
+	}
+	m.jobs[jobID] = &job{status: apistructs.PipelineStatusCreated}
+	return nil, nil
 }
 
 func (m *Memory) Start(ctx context.Context, action *spec.PipelineTask) (interface{}, error) {
-	panic("implement me")
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	jobID := makeJobID(action)
+	j, ok := m.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("job not created, jobID: %s", jobID)
+	}
+	if j.status != apistructs.PipelineStatusCreated {
+		return nil, nil
+	}
+
+	run := getRunner(action.Type)
+	if run == nil {
+		run = defaultRunner
+	}
+
+	var (
+		runCtx context.Context
+		cancel context.CancelFunc
+	)
+	if action.Extra.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(context.Background(), action.Extra.Timeout)
+	} else {
+		runCtx, cancel = context.WithCancel(context.Background())
+	}
+	j.status = apistructs.PipelineStatusRunning
+	j.cancel = cancel
+	j.timeBegin = time.Now()
+
+	// run with a copy of task, avoid data race with reconciler
+	task := *action
+	go func() {
+		defer cancel()
+		err := run(runCtx, &task)
+		m.finish(runCtx, jobID, err)
+	}()
+
+	return nil, nil
+}
+
+// finish updates job's end status according to the run result.
+func (m *Memory) finish(ctx context.Context, jobID string, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	j, ok := m.jobs[jobID]
+	if !ok || j.status.IsEndStatus() {
+		return
+	}
+	j.timeEnd = time.Now()
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		j.status = apistructs.PipelineStatusTimeout
+	case err != nil:
+		j.status = apistructs.PipelineStatusFailed
+		j.desc = err.Error()
+	default:
+		j.status = apistructs.PipelineStatusSuccess
+	}
+	logrus.Debugf("memory executor: job %s finished, status: %s", jobID, j.status)
 }
 
 func (m *Memory) Update(ctx context.Context, action *spec.PipelineTask) (interface{}, error) {
-	panic("implement me")
+	return nil, nil
 }
 
 func (m *Memory) Status(ctx context.Context, action *spec.PipelineTask) (apistructs.PipelineStatusDesc, error) {
-	panic("implement me")
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	j, ok := m.jobs[makeJobID(action)]
+	if !ok {
+		return apistructs.PipelineStatusDesc{Status: apistructs.PipelineStatusAnalyzed}, nil
+	}
+	return apistructs.PipelineStatusDesc{Status: j.status, Desc: j.desc}, nil
 }
 
 func (m *Memory) Inspect(ctx context.Context, action *spec.PipelineTask) (apistructs.TaskInspect, error) {
-	panic("implement me")
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	jobID := makeJobID(action)
+	j, ok := m.jobs[jobID]
+	if !ok {
+		return apistructs.TaskInspect{}, fmt.Errorf("job not found, jobID: %s", jobID)
+	}
+	desc := fmt.Sprintf("jobID: %s, status: %s", jobID, j.status)
+	if !j.timeBegin.IsZero() {
+		desc += fmt.Sprintf(", timeBegin: %s", j.timeBegin.Format(time.RFC3339))
+	}
+	if !j.timeEnd.IsZero() {
+		desc += fmt.Sprintf(", timeEnd: %s", j.timeEnd.Format(time.RFC3339))
+	}
+	if j.desc != "" {
+		desc += fmt.Sprintf(", desc: %s", j.desc)
+	}
+	return apistructs.TaskInspect{Desc: desc}, nil
 }
 
 func (m *Memory) Cancel(ctx context.Context, action *spec.PipelineTask) (interface{}, error) {
-	panic("implement me")
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	j, ok := m.jobs[makeJobID(action)]
+	if !ok || j.status.IsEndStatus() {
+		return nil, nil
+	}
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.status = apistructs.PipelineStatusStopByUser
+	j.timeEnd = time.Now()
+	return nil, nil
 }
 
 func (m *Memory) Remove(ctx context.Context, action *spec.PipelineTask) (interface{}, error) {
-	panic("implement me")
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.remove(makeJobID(action))
+	return nil, nil
 }
 
 func (m *Memory) BatchDelete(ctx context.Context, actions []*spec.PipelineTask) (interface{}, error) {
-	panic("implement me")
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, action := range actions {
+		m.remove(makeJobID(action))
+	}
+	return nil, nil
+}
+
+// remove must be called with lock held.
+func (m *Memory) remove(jobID string) {
+	j, ok := m.jobs[jobID]
+	if !ok {
+		return
+	}
+	if j.cancel != nil {
+		j.cancel()
+	}
+	delete(m.jobs, jobID)
+}
+
+// makeJobID use task uuid if exists, otherwise use pipelineID and taskID.
+func makeJobID(action *spec.PipelineTask) string {
+	if action.Extra.UUID != "" {
+		return action.Extra.UUID
+	}
+	return fmt.Sprintf("pipeline-%d-task-%d-%s", action.PipelineID, action.ID, action.Name)
 }
@@ -0,0 +1,138 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func waitEndStatus(t *testing.T, m *Memory, task *spec.PipelineTask) apistructs.PipelineStatus {
+	for i := 0; i < 100; i++ {
+		desc, err := m.Status(context.Background(), task)
+		assert.NoError(t, err)
+		if desc.Status.IsEndStatus() {
+			return desc.Status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("task %s not end", task.Name)
+	return ""
+}
+
+func TestMemory_Lifecycle(t *testing.T) {
+	RegisterRunner("memory-test-success", func(ctx context.Context, task *spec.PipelineTask) error {
+		return nil
+	})
+	defer UnregisterRunner("memory-test-success")
+
+	m := New("memory", nil)
+	ctx := context.Background()
+	task := &spec.PipelineTask{ID: 1, PipelineID: 1, Name: "a", Type: "memory-test-success"}
+
+	created, started, err := m.Exist(ctx, task)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.False(t, started)
+
+	_, err = m.Start(ctx, task)
+	assert.Error(t, err)
+
+	_, err = m.Create(ctx, task)
+	assert.NoError(t, err)
+	created, started, err = m.Exist(ctx, task)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.False(t, started)
+
+	_, err = m.Start(ctx, task)
+	assert.NoError(t, err)
+	assert.Equal(t, apistructs.PipelineStatusSuccess, waitEndStatus(t, m, task))
+
+	inspect, err := m.Inspect(ctx, task)
+	assert.NoError(t, err)
+	assert.Contains(t, inspect.Desc, "Success")
+
+	_, err = m.Remove(ctx, task)
+	assert.NoError(t, err)
+	created, _, _ = m.Exist(ctx, task)
+	assert.False(t, created)
+}
+
+func TestMemory_RunnerFailed(t *testing.T) {
+	RegisterRunner("memory-test-failed", func(ctx context.Context, task *spec.PipelineTask) error {
+		return fmt.Errorf("failed")
+	})
+	defer UnregisterRunner("memory-test-failed")
+
+	m := New("memory", nil)
+	ctx := context.Background()
+	task := &spec.PipelineTask{ID: 2, PipelineID: 1, Name: "b", Type: "memory-test-failed"}
+	_, _ = m.Create(ctx, task)
+	_, _ = m.Start(ctx, task)
+	assert.Equal(t, apistructs.PipelineStatusFailed, waitEndStatus(t, m, task))
+
+	desc, _ := m.Status(ctx, task)
+	assert.Equal(t, "failed", desc.Desc)
+}
+
+func TestMemory_RunnerNotRegistered(t *testing.T) {
+	m := New("memory", nil)
+	ctx := context.Background()
+	task := &spec.PipelineTask{ID: 5, PipelineID: 1, Name: "e", Type: "memory-test-unknown"}
+	_, _ = m.Create(ctx, task)
+	_, _ = m.Start(ctx, task)
+	assert.Equal(t, apistructs.PipelineStatusFailed, waitEndStatus(t, m, task))
+
+	desc, _ := m.Status(ctx, task)
+	assert.Contains(t, desc.Desc, "not supported")
+}
+
+func TestMemory_CancelAndTimeout(t *testing.T) {
+	RegisterRunner("memory-test-block", func(ctx context.Context, task *spec.PipelineTask) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	defer UnregisterRunner("memory-test-block")
+
+	m := New("memory", nil)
+	ctx := context.Background()
+
+	task := &spec.PipelineTask{ID: 3, PipelineID: 1, Name: "c", Type: "memory-test-block"}
+	_, _ = m.Create(ctx, task)
+	_, _ = m.Start(ctx, task)
+	desc, _ := m.Status(ctx, task)
+	assert.Equal(t, apistructs.PipelineStatusRunning, desc.Status)
+	_, err := m.Cancel(ctx, task)
+	assert.NoError(t, err)
+	assert.Equal(t, apistructs.PipelineStatusStopByUser, waitEndStatus(t, m, task))
+
+	timeoutTask := &spec.PipelineTask{ID: 4, PipelineID: 1, Name: "d", Type: "memory-test-block"}
+	timeoutTask.Extra.Timeout = 10 * time.Millisecond
+	_, _ = m.Create(ctx, timeoutTask)
+	_, _ = m.Start(ctx, timeoutTask)
+	assert.Equal(t, apistructs.PipelineStatusTimeout, waitEndStatus(t, m, timeoutTask))
+
+	_, err = m.BatchDelete(ctx, []*spec.PipelineTask{task, timeoutTask})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(m.jobs))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+// RunFunc runs a task in-process, return error means task failed.
+// The ctx is canceled when task is canceled, removed or timeout.
+type RunFunc func(ctx context.Context, task *spec.PipelineTask) error
+
+var (
+	runnersLock sync.RWMutex
+	runners     = map[string]RunFunc{}
+)
+
+// RegisterRunner registers a run function for the action type, e.g. custom-script, git-checkout.
+// Registering an existing action type will overwrite it.
+func RegisterRunner(actionType string, run RunFunc) {
+	runnersLock.Lock()
+	defer runnersLock.Unlock()
+	runners[actionType] = run
+}
+
+// UnregisterRunner removes the run function of the action type.
+func UnregisterRunner(actionType string) {
+	runnersLock.Lock()
+	defer runnersLock.Unlock()
+	delete(runners, actionType)
+}
+
+func getRunner(actionType string) RunFunc {
+	runnersLock.RLock()
+	defer runnersLock.RUnlock()
+	return runners[actionType]
+}
+
+// defaultRunner is used when no runner registered for the action type, the task fails.
+func defaultRunner(ctx context.Context, task *spec.PipelineTask) error {
+	return fmt.Errorf("action type %q is not supported by memory executor, please register a runner", task.Type)
+}
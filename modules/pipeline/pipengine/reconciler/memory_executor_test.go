@@ -0,0 +1,135 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/dbclient"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/actionexecutor/plugins/memory"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/queue/throttler"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/taskrun"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+// popAllThrottler 不做并发限制
+type popAllThrottler struct {
+	throttler.Throttler
+}
+
+func (t *popAllThrottler) PopPending(key string) (bool, []throttler.PopDetail) {
+	return true, nil
+}
+
+// memoryTaskStore 代替数据库保存 task
+type memoryTaskStore struct {
+	lock  sync.Mutex
+	tasks map[uint64]spec.PipelineTask
+}
+
+func (s *memoryTaskStore) get(id uint64) spec.PipelineTask {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.tasks[id]
+}
+
+func (s *memoryTaskStore) set(task spec.PipelineTask) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.tasks[task.ID] = task
+}
+
+func patchTaskStore(store *memoryTaskStore) func() {
+	var client *dbclient.Client
+	patches := []*monkey.PatchGuard{
+		monkey.PatchInstanceMethod(reflect.TypeOf(client), "GetPipelineTask", func(client *dbclient.Client, id interface{}) (spec.PipelineTask, error) {
+			return store.get(id.(uint64)), nil
+		}),
+		monkey.PatchInstanceMethod(reflect.TypeOf(client), "GetPipelineStatus", func(client *dbclient.Client, id uint64, ops ...dbclient.SessionOption) (apistructs.PipelineStatus, error) {
+			return apistructs.PipelineStatusRunning, nil
+		}),
+		monkey.PatchInstanceMethod(reflect.TypeOf(client), "UpdatePipelineTaskResult", func(client *dbclient.Client, id uint64, result apistructs.PipelineTaskResult) error {
+			task := store.get(id)
+			task.Result = result
+			store.set(task)
+			return nil
+		}),
+		monkey.PatchInstanceMethod(reflect.TypeOf(&taskrun.TaskRun{}), "Update", func(tr *taskrun.TaskRun) {
+			store.set(*tr.Task)
+		}),
+	}
+	return func() {
+		for _, patch := range patches {
+			patch.Unpatch()
+		}
+	}
+}
+
+func TestReconcileTaskWithMemoryExecutor(t *testing.T) {
+	memory.RegisterRunner("reconciler-test-success", func(ctx context.Context, task *spec.PipelineTask) error {
+		return nil
+	})
+	defer memory.UnregisterRunner("reconciler-test-success")
+	memory.RegisterRunner("reconciler-test-failed", func(ctx context.Context, task *spec.PipelineTask) error {
+		return fmt.Errorf("failed")
+	})
+	defer memory.UnregisterRunner("reconciler-test-failed")
+
+	store := &memoryTaskStore{tasks: make(map[uint64]spec.PipelineTask)}
+	defer patchTaskStore(store)()
+
+	tests := []struct {
+		name       string
+		actionType string
+		wantStatus apistructs.PipelineStatus
+	}{
+		{name: "success", actionType: "reconciler-test-success", wantStatus: apistructs.PipelineStatusSuccess},
+		{name: "failed", actionType: "reconciler-test-failed", wantStatus: apistructs.PipelineStatusFailed},
+		{name: "not supported", actionType: "reconciler-test-unknown", wantStatus: apistructs.PipelineStatusFailed},
+	}
+	executor := memory.New("memory", nil)
+	p := &spec.Pipeline{PipelineBase: spec.PipelineBase{ID: 1}}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &spec.PipelineTask{
+				ID:         uint64(i + 1),
+				PipelineID: p.ID,
+				Name:       tt.name,
+				Type:       tt.actionType,
+				Status:     apistructs.PipelineStatusBorn,
+			}
+			task.Extra.UUID = fmt.Sprintf("reconciler-test-%d", task.ID)
+			task.Extra.Timeout = -1
+			store.set(*task)
+
+			pExitCh, pExitChCancel := context.WithCancel(context.Background())
+			defer pExitChCancel()
+			tr := taskrun.New(context.Background(), task, pExitCh.Done(), pExitChCancel, &popAllThrottler{},
+				executor, p, nil, nil, nil, nil, nil)
+
+			assert.NoError(t, reconcileTask(tr))
+			assert.Equal(t, tt.wantStatus, tr.Task.Status)
+			assert.Equal(t, tt.wantStatus, store.get(task.ID).Status)
+		})
+	}
+}
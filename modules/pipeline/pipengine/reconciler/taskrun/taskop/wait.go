@@ -17,6 +17,7 @@ package taskop
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -25,8 +26,11 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
 	"github.com/erda-project/erda/modules/pipeline/commonutil/costtimeutil"
 	"github.com/erda-project/erda/modules/pipeline/conf"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/actionexecutor"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/actionexecutor/types"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/rlog"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/taskrun"
+	"github.com/erda-project/erda/modules/pipeline/spec"
 	"github.com/erda-project/erda/pkg/loop"
 )
 
@@ -66,9 +70,7 @@ func (w *wait) Processing() (interface{}, error) {
 		}
 	}()
 
-	var (
-		data interface{}
-	)
+	var data interface{}
 
 	err := loop.New(loop.WithDeclineRatio(1.5), loop.WithDeclineLimit(time.Second*10)).Do(func() (abort bool, err error) {
 		if w.QuitWaitTimeout {
@@ -105,6 +107,7 @@ func (w *wait) WhenDone(data interface{}) error {
 	}
 	endStatus := data.(apistructs.PipelineStatusDesc).Status
 	if endStatus.IsFailedStatus() {
+		w.cancelMatrixSiblings(endStatus)
 		if inspect, err := w.Executor.Inspect(w.Ctx, w.Task); err != nil {
 			logrus.Errorf("failed to inspect task, pipelineID:%d, taskID: %d, err: %v", w.P.ID, w.Task.ID, err)
 		} else {
@@ -121,6 +124,76 @@ func (w *wait) WhenDone(data interface{}) error {
 	return nil
 }
 
+// cancelMatrixSiblings 开启 fail_fast 的 matrix 任务失败时，取消同组内未结束的任务（包括尚未开始的任务）
+func (w *wait) cancelMatrixSiblings(endStatus apistructs.PipelineStatus) {
+	action := w.Task.Extra.Action
+	if action.MatrixGroup == "" || !action.MatrixFailFast || endStatus == apistructs.PipelineStatusNoNeedBySystem {
+		return
+	}
+	// 同组任务可能同时失败，先持久化自身终态，其他任务拉取列表时即不会再取消本任务
+	if err := w.DBClient.UpdatePipelineTaskStatus(w.Task.ID, endStatus); err != nil {
+		logrus.Errorf("[alert] reconciler: pipelineID: %d, task %q failed to update status for matrix fail_fast, err: %v",
+			w.P.ID, w.Task.Name, err)
+		return
+	}
+	tasks, err := w.DBClient.ListPipelineTasksByPipelineID(w.P.ID)
+	if err != nil {
+		logrus.Errorf("[alert] reconciler: pipelineID: %d, task %q failed to list tasks for matrix fail_fast, err: %v",
+			w.P.ID, w.Task.Name, err)
+		return
+	}
+	for _, sibling := range matrixSiblingsToCancel(w.Task, tasks) {
+		oldStatus := sibling.Status
+		// 先置为终态，同组任务的 taskRun 拉取最新状态后即可退出
+		sibling.Status = apistructs.PipelineStatusNoNeedBySystem
+		sibling.TimeEnd = time.Now()
+		sibling.Result.Errors = sibling.Result.AppendError(&apistructs.PipelineTaskErrResponse{
+			Msg: fmt.Sprintf("cancelled because matrix task %q failed", w.Task.Name),
+		})
+		// 仅当状态未被其他任务（同时失败的同组任务或自身 taskRun）修改时才取消
+		updated, err := w.DBClient.CompareAndUpdatePipelineTask(sibling.ID, oldStatus, sibling)
+		if err != nil {
+			logrus.Errorf("[alert] reconciler: pipelineID: %d, task %q failed to cancel by matrix fail_fast, err: %v",
+				w.P.ID, sibling.Name, err)
+			continue
+		}
+		if !updated {
+			continue
+		}
+		// 已创建的任务需要停止 executor 中的 job
+		if oldStatus != apistructs.PipelineStatusCreated && oldStatus != apistructs.PipelineStatusQueue &&
+			oldStatus != apistructs.PipelineStatusRunning {
+			continue
+		}
+		executor, err := actionexecutor.GetManager().Get(types.Name(sibling.Extra.ExecutorName))
+		if err != nil {
+			logrus.Errorf("[alert] reconciler: pipelineID: %d, task %q failed to get executor for matrix fail_fast, err: %v",
+				w.P.ID, sibling.Name, err)
+			continue
+		}
+		if _, err := executor.Cancel(w.Ctx, sibling); err != nil {
+			logrus.Errorf("[alert] reconciler: pipelineID: %d, task %q failed to cancel by matrix fail_fast, err: %v",
+				w.P.ID, sibling.Name, err)
+		}
+	}
+}
+
+// matrixSiblingsToCancel 返回同一 matrix 组内尚未结束的其他任务
+func matrixSiblingsToCancel(failed *spec.PipelineTask, tasks []spec.PipelineTask) []*spec.PipelineTask {
+	var siblings []*spec.PipelineTask
+	for i := range tasks {
+		task := &tasks[i]
+		if task.ID == failed.ID || task.Extra.Action.MatrixGroup != failed.Extra.Action.MatrixGroup {
+			continue
+		}
+		if task.Status.IsEndStatus() {
+			continue
+		}
+		siblings = append(siblings, task)
+	}
+	return siblings
+}
+
 func (w *wait) WhenLogicError(err error) error {
 	w.Task.Status = apistructs.PipelineStatusError
 	return nil
@@ -137,6 +210,7 @@ func (w *wait) WhenTimeout() error {
 	}
 
 	w.QuitWaitTimeout = true
+	w.cancelMatrixSiblings(apistructs.PipelineStatusTimeout)
 	w.Task.Status = apistructs.PipelineStatusTimeout
 	w.Task.TimeEnd = time.Now()
 	w.Task.CostTimeSec = int64(w.Task.TimeEnd.Sub(w.Task.TimeBegin).Seconds())
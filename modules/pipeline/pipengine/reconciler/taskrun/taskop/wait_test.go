@@ -0,0 +1,50 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+func Test_matrixSiblingsToCancel(t *testing.T) {
+	newTask := func(id uint64, group pipelineyml.ActionAlias, status apistructs.PipelineStatus) spec.PipelineTask {
+		task := spec.PipelineTask{ID: id, Name: "build", Status: status}
+		task.Extra.Action.MatrixGroup = group
+		return task
+	}
+	failed := newTask(1, "build", apistructs.PipelineStatusFailed)
+	tasks := []spec.PipelineTask{
+		failed,
+		newTask(2, "build", apistructs.PipelineStatusRunning),
+		newTask(3, "build", apistructs.PipelineStatusQueue),
+		newTask(4, "build", apistructs.PipelineStatusAnalyzed),
+		newTask(5, "build", apistructs.PipelineStatusSuccess),
+		newTask(6, "build", apistructs.PipelineStatusFailed),
+		newTask(7, "test", apistructs.PipelineStatusRunning),
+		newTask(8, "", apistructs.PipelineStatusBorn),
+	}
+
+	var ids []uint64
+	for _, task := range matrixSiblingsToCancel(&failed, tasks) {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []uint64{2, 3, 4}, ids)
+}
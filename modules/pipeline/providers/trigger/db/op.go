@@ -18,6 +18,7 @@ import (
 	"github.com/erda-project/erda-infra/providers/mysqlxorm"
 	_ "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
 	"github.com/erda-project/erda-proto-go/core/pipeline/trigger/pb"
+	"github.com/erda-project/erda/apistructs"
 )
 
 type Client struct {
@@ -79,6 +80,17 @@ func (client *Client) GetPipelineTriggerByID(pipelineDefinitionID uint64, ops ..
 	return triggers, nil
 }
 
+func (client *Client) ListPipelineTriggersBySource(pipelineSource apistructs.PipelineSource, pipelineYmlName, event string, ops ...mysqlxorm.SessionOption) ([]PipelineTrigger, error) {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	var triggers []PipelineTrigger
+	if err := session.Where("pipeline_source = ? AND pipeline_yml_name = ? AND event = ?", pipelineSource, pipelineYmlName, event).Find(&triggers); err != nil {
+		return nil, err
+	}
+	return triggers, nil
+}
+
 func FilterByEvent(triggers []PipelineTrigger, Filter map[string]string) ([]PipelineTrigger, error) {
 	var filterTriggers []PipelineTrigger
 	for _, trigger := range triggers {
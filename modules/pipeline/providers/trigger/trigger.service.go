@@ -119,6 +119,22 @@ func (s *TriggerService) RegisterTriggerHandler(definition definition.PipelineDe
 		return err
 	}
 
+	if yml.Spec() != nil && yml.Spec().On != nil && yml.Spec().On.Webhook != nil {
+		// 沿用已有的 token, 保证定义更新后 webhook 地址不变
+		var token string
+		for _, oldPipelineTrigger := range oldPipelineTriggers {
+			if oldPipelineTrigger.Event == WebhookEvent {
+				token = oldPipelineTrigger.Filter[webhookTokenFilterKey]
+			}
+		}
+		if token == "" {
+			if token, err = newWebhookToken(); err != nil {
+				return err
+			}
+		}
+		newPipelineTriggerMap[WebhookEvent] = map[string]string{webhookTokenFilterKey: token}
+	}
+
 	txSession := s.triggerDbClient.NewSession()
 	for _, oldPipelineTrigger := range oldPipelineTriggers {
 		err := s.triggerDbClient.DeletePipelineTrigger(oldPipelineTrigger.ID, mysqlxorm.WithSession(txSession))
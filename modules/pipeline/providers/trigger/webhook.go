@@ -0,0 +1,203 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	pb "github.com/erda-project/erda-proto-go/core/pipeline/trigger/pb"
+	"github.com/erda-project/erda/apistructs"
+	triggerDb "github.com/erda-project/erda/modules/pipeline/providers/trigger/db"
+	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+const (
+	// WebhookEvent is the trigger event of on.webhook
+	WebhookEvent = "webhook"
+	// WebhookSignatureHeader carries the HMAC-SHA256 signature of the payload, format: sha256=<hex>
+	WebhookSignatureHeader = "X-Erda-Webhook-Signature"
+
+	webhookTokenFilterKey  = "token"
+	webhookSignaturePrefix = "sha256="
+	webhookTokenLength     = 32
+)
+
+// newWebhookToken returns a random token used in the url of pipeline definition,
+// the token is kept when the definition is updated.
+func newWebhookToken() (string, error) {
+	b := make([]byte, webhookTokenLength/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// WebhookURLPath returns the url path which external systems post payloads to.
+func WebhookURLPath(token string) string {
+	return fmt.Sprintf("/api/pipeline-webhooks/%s", token)
+}
+
+// GetWebhookURLPath returns the webhook url path of the pipeline yml, empty if on.webhook not declared.
+func (s *TriggerService) GetWebhookURLPath(pipelineSource apistructs.PipelineSource, pipelineYmlName string) (string, error) {
+	triggers, err := s.triggerDbClient.ListPipelineTriggersBySource(pipelineSource, pipelineYmlName, WebhookEvent)
+	if err != nil {
+		return "", err
+	}
+	for _, trigger := range triggers {
+		if token := trigger.Filter[webhookTokenFilterKey]; token != "" {
+			return WebhookURLPath(token), nil
+		}
+	}
+	return "", nil
+}
+
+// SignWebhookPayload returns the signature of payload, the same as the value of WebhookSignatureHeader.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifyWebhookSignature(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, webhookSignaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(SignWebhookPayload(secret, body)), []byte(signature))
+}
+
+// RunPipelineByWebhook validates the payload of signed url and starts the pipelines.
+// All matched pipelines are validated before any of them is created, so an invalid one doesn't leave others started.
+func (s *TriggerService) RunPipelineByWebhook(ctx context.Context, token, signature string, body []byte) ([]uint64, error) {
+	pipelineTriggers, err := s.triggerDbClient.ListPipelineTriggers(&pb.PipelineTriggerRequest{
+		EventName: WebhookEvent,
+		Label:     map[string]string{webhookTokenFilterKey: token},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pipelineTriggers) == 0 {
+		return nil, apierrors.ErrTriggerWebhook.NotFound()
+	}
+
+	var createRequests []*apistructs.PipelineCreateRequestV2
+	for _, trigger := range GetUniqueTrigger(pipelineTriggers) {
+		createRequest, err := s.makeWebhookCreateRequest(trigger, signature, body)
+		if err != nil {
+			return nil, err
+		}
+		if createRequest != nil {
+			createRequests = append(createRequests, createRequest)
+		}
+	}
+
+	var pipelineIDs []uint64
+	var errs []string
+	for _, createRequest := range createRequests {
+		pipeline, err := s.pipelineSvc.CreateV2(createRequest)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", createRequest.PipelineYmlName, err))
+			continue
+		}
+		pipelineIDs = append(pipelineIDs, pipeline.ID)
+	}
+	if len(errs) > 0 {
+		return pipelineIDs, errors.Errorf("failed to create pipelines by webhook, %s", strings.Join(errs, "; "))
+	}
+	return pipelineIDs, nil
+}
+
+// makeWebhookCreateRequest verifies the signature and renders the run params of the pipeline triggered,
+// returns nil if the pipeline doesn't declare on.webhook any more.
+func (s *TriggerService) makeWebhookCreateRequest(trigger triggerDb.PipelineTrigger, signature string, body []byte) (*apistructs.PipelineCreateRequestV2, error) {
+	pipelineDefinition, err := s.definitionDbClient.GetPipelineDefinitionByNameAndSource(trigger.PipelineSource, trigger.PipelineYmlName)
+	if err != nil {
+		return nil, err
+	}
+	if pipelineDefinition == nil || pipelineDefinition.PipelineYml == "" || pipelineDefinition.Extra.CreateRequest == nil {
+		return nil, nil
+	}
+
+	yml, err := pipelineyml.New([]byte(pipelineDefinition.PipelineYml))
+	if err != nil {
+		return nil, err
+	}
+	if yml.Spec().On == nil || yml.Spec().On.Webhook == nil {
+		return nil, nil
+	}
+	createRequest := pipelineDefinition.Extra.CreateRequest
+	webhook := yml.Spec().On.Webhook
+	secret, err := s.getWebhookSecret(pipelineDefinition.PipelineSource, createRequest.ConfigManageNamespaces, webhook.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if !verifyWebhookSignature(secret, body, signature) {
+		return nil, apierrors.ErrTriggerWebhook.AccessDenied()
+	}
+	var payload interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, apierrors.ErrTriggerWebhook.InvalidParameter(errors.Errorf("invalid json payload: %v", err))
+		}
+	}
+	params, err := pipelineyml.RenderWebhookParams(webhook.Params, payload)
+	if err != nil {
+		return nil, apierrors.ErrTriggerWebhook.InvalidParameter(err)
+	}
+	var runParams apistructs.PipelineRunParams
+	for name, value := range params {
+		runParams = append(runParams, apistructs.PipelineRunParam{Name: name, Value: value})
+	}
+
+	return &apistructs.PipelineCreateRequestV2{
+		PipelineYml:            pipelineDefinition.PipelineYml,
+		ClusterName:            createRequest.ClusterName,
+		PipelineYmlName:        pipelineDefinition.PipelineYmlName,
+		PipelineSource:         pipelineDefinition.PipelineSource,
+		Labels:                 createRequest.Labels,
+		NormalLabels:           createRequest.NormalLabels,
+		ConfigManageNamespaces: createRequest.ConfigManageNamespaces,
+		RunParams:              runParams,
+		AutoRunAtOnce:          true,
+		AutoStartCron:          false,
+		ForceRun:               false,
+		IdentityInfo:           createRequest.IdentityInfo,
+	}, nil
+}
+
+// getWebhookSecret gets the value of config referenced by on.webhook.secret from config namespaces of the pipeline.
+func (s *TriggerService) getWebhookSecret(pipelineSource apistructs.PipelineSource, namespaces []string, secretRef string) (string, error) {
+	key, ok := pipelineyml.WebhookSecretConfigKey(secretRef)
+	if !ok {
+		return "", apierrors.ErrTriggerWebhook.InvalidState("on.webhook.secret must reference a config")
+	}
+	secret, err := s.pipelineSvc.GetCmsConfig(pipelineSource, namespaces, key)
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", apierrors.ErrTriggerWebhook.InvalidState(fmt.Sprintf("config %s referenced by on.webhook.secret not found", key))
+	}
+	return secret, nil
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookToken(t *testing.T) {
+	token, err := newWebhookToken()
+	assert.NoError(t, err)
+	assert.Len(t, token, webhookTokenLength)
+	another, err := newWebhookToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, another)
+	assert.Equal(t, "/api/pipeline-webhooks/"+token, WebhookURLPath(token))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"version":"1.0.0"}`)
+	signature := SignWebhookPayload("secret", body)
+	assert.True(t, verifyWebhookSignature("secret", body, signature))
+	assert.False(t, verifyWebhookSignature("another", body, signature))
+	assert.False(t, verifyWebhookSignature("secret", []byte(`{}`), signature))
+	assert.False(t, verifyWebhookSignature("secret", body, signature[len(webhookSignaturePrefix):]))
+	assert.False(t, verifyWebhookSignature("secret", body, ""))
+}
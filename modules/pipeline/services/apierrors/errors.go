@@ -93,6 +93,8 @@ var (
 
 	ErrCallback = err("ErrCallback", "回调平台失败")
 
+	ErrTriggerWebhook = err("ErrTriggerWebhook", "Webhook 触发流水线失败")
+
 	ErrDownloadActionAgent = err("ErrDownloadActionAgent", "下载 Action Agent 失败")
 	ErrValidateActionAgent = err("ErrValidateActionAgent", "校验 Action Agent 失败")
 
@@ -248,6 +248,28 @@ func (s *PipelineSvc) FetchSecrets(p *spec.Pipeline) (secrets, cmsDiceFiles map[
 	return secrets, cmsDiceFiles, holdOnKeys, encryptSecretKeys, nil
 }
 
+// GetCmsConfig 获取配置管理中 key 对应的值, 与 FetchSecrets 一致, 后面的命名空间覆盖前面的
+func (s *PipelineSvc) GetCmsConfig(pipelineSource apistructs.PipelineSource, namespaces []string, key string) (string, error) {
+	var value string
+	for _, ns := range namespaces {
+		configs, err := s.cmsService.GetCmsNsConfigs(apis.WithInternalClientContext(context.Background(), "pipeline"),
+			&pb.CmsNsConfigsGetRequest{
+				Ns:             ns,
+				PipelineSource: pipelineSource.String(),
+				Keys:           []*pb.PipelineCmsConfigKey{{Key: key, Decrypt: true}},
+			})
+		if err != nil {
+			return "", err
+		}
+		for _, c := range configs.Data {
+			if c.Key == key {
+				value = c.Value
+			}
+		}
+	}
+	return value, nil
+}
+
 // AddRegistryLabel Support third-party docker registry
 func AddRegistryLabel(r map[string]string, clusterInfo apistructs.ClusterInfoData) map[string]string {
 	r[secretKeyDockerArtifactRegistry] = httpclientutil.RmProto(clusterInfo.Get(apistructs.REGISTRY_ADDR))
@@ -162,7 +162,8 @@ var (
 	PipelineTaskExecutorNameEmpty            PipelineTaskExecutorName = ""
 	PipelineTaskExecutorNameSchedulerDefault PipelineTaskExecutorName = "scheduler"
 	PipelineTaskExecutorNameAPITestDefault   PipelineTaskExecutorName = "api-test"
-	PipelineTaskExecutorNameList                                      = []PipelineTaskExecutorName{PipelineTaskExecutorNameEmpty, PipelineTaskExecutorNameSchedulerDefault, PipelineTaskExecutorNameAPITestDefault}
+	PipelineTaskExecutorNameMemoryDefault    PipelineTaskExecutorName = "memory"
+	PipelineTaskExecutorNameList                                      = []PipelineTaskExecutorName{PipelineTaskExecutorNameEmpty, PipelineTaskExecutorNameSchedulerDefault, PipelineTaskExecutorNameAPITestDefault, PipelineTaskExecutorNameMemoryDefault}
 )
 
 func (that PipelineTaskExecutorName) Check() bool {
@@ -31,6 +31,9 @@ type Conf struct {
 	TraceLogEnv string `env:"TRACELOGENV" default:"TERMINUS_DEFINE_TAG"`
 	// PlaceHolderImage Image used to occupy the seat when disassembling the service deployment
 	PlaceHolderImage string `env:"PLACEHOLDER_IMAGE" default:"registry.cn-hangzhou.aliyuncs.com/terminus/busybox"`
+	// GrpcHealthProbeImage Image providing /bin/grpc_health_probe and cp, used to inject the probe binary for grpc health check,
+	// if empty, grpc_health_probe must be provided by the service image
+	GrpcHealthProbeImage string `env:"GRPC_HEALTH_PROBE_IMAGE"`
 
 	KafkaBrokers        string `env:"BOOTSTRAP_SERVERS"`
 	KafkaContainerTopic string `env:"CMDB_CONTAINER_TOPIC"`
@@ -78,6 +81,11 @@ func PlaceHolderImage() string {
 	return cfg.PlaceHolderImage
 }
 
+// GrpcHealthProbeImage return cfg.GrpcHealthProbeImage
+func GrpcHealthProbeImage() string {
+	return cfg.GrpcHealthProbeImage
+}
+
 func KafkaBrokers() string {
 	return cfg.KafkaBrokers
 }
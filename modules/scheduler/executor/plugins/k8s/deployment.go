@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/conf"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8sapi"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/toleration"
 	"github.com/erda-project/erda/pkg/parser/diceyml"
@@ -156,6 +157,13 @@ func (k *Kubernetes) AddContainersEnv(containers []apiv1.Container, service *api
 		}
 	}
 
+	// Secret envs sealed by KMS, injected by k8s secret
+	secretEnvs, err := k.CreateOrUpdateSecretEnvs(ns, serviceName, service)
+	if err != nil {
+		return err
+	}
+	envs = append(envs, secretEnvs...)
+
 	addEnv := func(svc *apistructs.Service, envs *[]apiv1.EnvVar, useClusterIP bool) error {
 		var err error
 		// use SHORT dns, service's name is equal to SHORT dns
@@ -427,6 +435,9 @@ func (k *Kubernetes) newDeployment(service *apistructs.Service, sg *apistructs.S
 			},
 		},
 	}
+	if service.Autoscaling != nil {
+		deployment.Spec.Replicas = autoscalingReplicas(service, nil)
+	}
 
 	if v := k.options["FORCE_BLUE_GREEN_DEPLOY"]; v != "true" &&
 		(strutil.ToUpper(service.Env["DICE_WORKSPACE"]) == apistructs.DevWorkspace.String() ||
@@ -521,6 +532,7 @@ func (k *Kubernetes) newDeployment(service *apistructs.Service, sg *apistructs.S
 
 	k.AddPodMountVolume(service, &deployment.Spec.Template.Spec, secretvolmounts, secretvolumes)
 	k.AddSpotEmptyDir(&deployment.Spec.Template.Spec)
+	InjectGrpcHealthProbe(&deployment.Spec.Template.Spec, conf.GrpcHealthProbeImage())
 
 	logrus.Debugf("show k8s deployment, name: %s, deployment: %+v", deploymentName, deployment)
 	return deployment, nil
@@ -809,6 +821,10 @@ func (k *Kubernetes) scaleDeployment(sg *apistructs.ServiceGroup) error {
 		return getErr
 	}
 
+	if err = k.checkManualScale(ns, deploymentName, &scalingService, deploy.Spec.Replicas); err != nil {
+		return err
+	}
+
 	deploy.Spec.Replicas = func(i int32) *int32 { return &i }(int32(scalingService.Scale))
 
 	// only support one container on Erda currently
@@ -15,12 +15,30 @@
 package k8s
 
 import (
+	"fmt"
+	"strconv"
+
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/erda-project/erda/apistructs"
 )
 
+// GrpcHealthProbeCmd is the command used by grpc health check, which implements the grpc health checking protocol,
+// see also https://github.com/grpc-ecosystem/grpc-health-probe.
+// k8s(< 1.24) does not support grpc probe natively, the binary is injected by an init container
+// when GRPC_HEALTH_PROBE_IMAGE is configured, otherwise it should be provided by the service image
+const GrpcHealthProbeCmd = "grpc_health_probe"
+
+const (
+	grpcHealthProbeName = "grpc-health-probe"
+	// grpcHealthProbeDir the shared dir which the injected grpc_health_probe is copied to
+	grpcHealthProbeDir = "/erda-grpc-health-probe"
+	// grpcHealthProbeImageCmd the binary path in GRPC_HEALTH_PROBE_IMAGE
+	grpcHealthProbeImageCmd = "/bin/grpc_health_probe"
+)
+
 func (k *Kubernetes) NewHealthcheckProbe(service *apistructs.Service) *apiv1.Probe {
 	return FillHealthCheckProbe(service)
 }
@@ -35,11 +53,70 @@ func SetHealthCheck(container *apiv1.Container, service *apistructs.Service) {
 		readinessprobe.FailureThreshold = 3
 		readinessprobe.PeriodSeconds = 10
 		readinessprobe.InitialDelaySeconds = 10
+		// initial delay and thresholds configured in tcp or grpc health check take precedence
+		initialDelay, failureThreshold, successThreshold := probeThresholds(service.NewHealthCheck)
+		if initialDelay > 0 {
+			readinessprobe.InitialDelaySeconds = int32(initialDelay)
+		}
+		if failureThreshold > 0 {
+			readinessprobe.FailureThreshold = int32(failureThreshold)
+		}
+		if successThreshold > 0 {
+			readinessprobe.SuccessThreshold = int32(successThreshold)
+		}
 	}
 	container.ReadinessProbe = readinessprobe
 
 }
 
+// InjectGrpcHealthProbe copy grpc_health_probe into the business container by an init container,
+// and make the grpc health check probes use it, do nothing if image is empty
+func InjectGrpcHealthProbe(podSpec *apiv1.PodSpec, image string) {
+	if image == "" || len(podSpec.Containers) == 0 {
+		return
+	}
+	container := &podSpec.Containers[0]
+	probes := []*apiv1.Probe{container.LivenessProbe, container.ReadinessProbe}
+	var injected bool
+	for _, probe := range probes {
+		if probe == nil || probe.Exec == nil || len(probe.Exec.Command) == 0 ||
+			probe.Exec.Command[0] != GrpcHealthProbeCmd {
+			continue
+		}
+		probe.Exec.Command[0] = grpcHealthProbeDir + "/" + GrpcHealthProbeCmd
+		injected = true
+	}
+	if !injected {
+		return
+	}
+
+	mount := apiv1.VolumeMount{
+		Name:      grpcHealthProbeName,
+		MountPath: grpcHealthProbeDir,
+	}
+	podSpec.Volumes = append(podSpec.Volumes, apiv1.Volume{
+		Name:         grpcHealthProbeName,
+		VolumeSource: apiv1.VolumeSource{EmptyDir: &apiv1.EmptyDirVolumeSource{}},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, mount)
+	podSpec.InitContainers = append(podSpec.InitContainers, apiv1.Container{
+		Name:  grpcHealthProbeName,
+		Image: image,
+		Resources: apiv1.ResourceRequirements{
+			Requests: apiv1.ResourceList{
+				apiv1.ResourceCPU:    resource.MustParse("10m"),
+				apiv1.ResourceMemory: resource.MustParse("16Mi"),
+			},
+			Limits: apiv1.ResourceList{
+				apiv1.ResourceCPU:    resource.MustParse("100m"),
+				apiv1.ResourceMemory: resource.MustParse("16Mi"),
+			},
+		},
+		Command:      []string{"cp", grpcHealthProbeImageCmd, grpcHealthProbeDir + "/" + GrpcHealthProbeCmd},
+		VolumeMounts: []apiv1.VolumeMount{mount},
+	})
+}
+
 // FillHealthCheckProbe Fill out k8s probe based on service
 func FillHealthCheckProbe(service *apistructs.Service) *apiv1.Probe {
 	var (
@@ -48,7 +125,8 @@ func FillHealthCheckProbe(service *apistructs.Service) *apiv1.Probe {
 		oldHC = service.HealthCheck
 	)
 
-	if newHC != nil && (newHC.ExecHealthCheck != nil || newHC.HttpHealthCheck != nil) {
+	if newHC != nil && (newHC.ExecHealthCheck != nil || newHC.HttpHealthCheck != nil ||
+		newHC.TcpHealthCheck != nil || newHC.GrpcHealthCheck != nil) {
 		probe = NewHealthCheck(newHC)
 	} else if oldHC != nil {
 		probe = OldHealthCheck(oldHC)
@@ -87,7 +165,8 @@ func DefaultHealthCheck(service *apistructs.Service) *apiv1.Probe {
 
 // NewHealthCheck Configure the new version of Dice health check
 func NewHealthCheck(hc *apistructs.NewHealthCheck) *apiv1.Probe {
-	if hc == nil || (hc.HttpHealthCheck == nil && hc.ExecHealthCheck == nil) {
+	if hc == nil || (hc.HttpHealthCheck == nil && hc.ExecHealthCheck == nil &&
+		hc.TcpHealthCheck == nil && hc.GrpcHealthCheck == nil) {
 		return nil
 	}
 
@@ -111,10 +190,56 @@ func NewHealthCheck(hc *apistructs.NewHealthCheck) *apiv1.Probe {
 		if times := int32(execCheck.Duration) / 15; times > probe.FailureThreshold {
 			probe.FailureThreshold = times
 		}
+	} else if hc.TcpHealthCheck != nil {
+		tcpCheck := hc.TcpHealthCheck
+		probe.TCPSocket = &apiv1.TCPSocketAction{
+			Port: intstr.FromInt(tcpCheck.Port),
+		}
+		setProbeThresholds(probe, tcpCheck.Duration, tcpCheck.InitialDelay, tcpCheck.FailureThreshold)
+	} else if hc.GrpcHealthCheck != nil {
+		grpcCheck := hc.GrpcHealthCheck
+		// k8s does not support grpc probe natively yet, check by grpc_health_probe, see InjectGrpcHealthProbe
+		cmd := []string{GrpcHealthProbeCmd, "-addr=127.0.0.1:" + strconv.Itoa(grpcCheck.Port),
+			fmt.Sprintf("-rpc-timeout=%ds", probe.TimeoutSeconds)}
+		if grpcCheck.Service != "" {
+			cmd = append(cmd, "-service="+grpcCheck.Service)
+		}
+		probe.Exec = &apiv1.ExecAction{
+			Command: cmd,
+		}
+		setProbeThresholds(probe, grpcCheck.Duration, grpcCheck.InitialDelay, grpcCheck.FailureThreshold)
 	}
 	return probe
 }
 
+func setProbeThresholds(probe *apiv1.Probe, duration, initialDelay, failureThreshold int) {
+	if times := int32(duration) / 15; times > probe.FailureThreshold {
+		probe.FailureThreshold = times
+	}
+	// explicit failure threshold overrides the one calculated by duration
+	if failureThreshold > 0 {
+		probe.FailureThreshold = int32(failureThreshold)
+	}
+	if initialDelay > 0 {
+		probe.InitialDelaySeconds = int32(initialDelay)
+	}
+}
+
+// probeThresholds returns initial delay and thresholds configured in tcp or grpc health check,
+// zero means not configured
+func probeThresholds(hc *apistructs.NewHealthCheck) (initialDelay, failureThreshold, successThreshold int) {
+	if hc == nil || hc.HttpHealthCheck != nil || hc.ExecHealthCheck != nil {
+		return
+	}
+	if hc.TcpHealthCheck != nil {
+		return hc.TcpHealthCheck.InitialDelay, hc.TcpHealthCheck.FailureThreshold, hc.TcpHealthCheck.SuccessThreshold
+	}
+	if hc.GrpcHealthCheck != nil {
+		return hc.GrpcHealthCheck.InitialDelay, hc.GrpcHealthCheck.FailureThreshold, hc.GrpcHealthCheck.SuccessThreshold
+	}
+	return
+}
+
 // OldHealthCheck Compatible with Dice old version health detection
 func OldHealthCheck(hc *apistructs.HealthCheck) *apiv1.Probe {
 	if hc == nil {
@@ -86,4 +86,84 @@ func TestFillHealthCheckProbe(t *testing.T) {
 	assert.NotNil(t, probe)
 	assert.Equal(t, []string{"sh", "-c", service.NewHealthCheck.ExecHealthCheck.Cmd}, probe.Exec.Command)
 	assert.Equal(t, int32(service.NewHealthCheck.ExecHealthCheck.Duration/15), probe.FailureThreshold)
+
+	// new hc tcp
+	service.NewHealthCheck = &apistructs.NewHealthCheck{
+		TcpHealthCheck: &apistructs.TcpHealthCheck{
+			Port:         8080,
+			Duration:     1000,
+			InitialDelay: 30,
+		},
+	}
+	probe = FillHealthCheckProbe(service)
+	assert.NotNil(t, probe)
+	assert.Equal(t, 8080, probe.TCPSocket.Port.IntValue())
+	assert.Equal(t, int32(service.NewHealthCheck.TcpHealthCheck.Duration/15), probe.FailureThreshold)
+	assert.Equal(t, int32(30), probe.InitialDelaySeconds)
+
+	// new hc grpc
+	service.NewHealthCheck = &apistructs.NewHealthCheck{
+		GrpcHealthCheck: &apistructs.GrpcHealthCheck{
+			Port:             9090,
+			Service:          "helloworld.Greeter",
+			Duration:         1000,
+			FailureThreshold: 5,
+		},
+	}
+	probe = FillHealthCheckProbe(service)
+	assert.NotNil(t, probe)
+	assert.Equal(t, []string{GrpcHealthProbeCmd, "-addr=127.0.0.1:9090", "-rpc-timeout=10s", "-service=helloworld.Greeter"}, probe.Exec.Command)
+	assert.Equal(t, int32(5), probe.FailureThreshold)
+}
+
+func TestSetHealthCheck(t *testing.T) {
+	service := &apistructs.Service{
+		NewHealthCheck: &apistructs.NewHealthCheck{
+			GrpcHealthCheck: &apistructs.GrpcHealthCheck{
+				Port:             9090,
+				InitialDelay:     20,
+				SuccessThreshold: 2,
+			},
+		},
+	}
+	container := &corev1.Container{}
+	SetHealthCheck(container, service)
+	assert.Equal(t, int32(20), container.LivenessProbe.InitialDelaySeconds)
+	assert.Equal(t, int32(20), container.ReadinessProbe.InitialDelaySeconds)
+	assert.Equal(t, int32(3), container.ReadinessProbe.FailureThreshold)
+	assert.Equal(t, int32(2), container.ReadinessProbe.SuccessThreshold)
+}
+
+func TestInjectGrpcHealthProbe(t *testing.T) {
+	service := &apistructs.Service{
+		NewHealthCheck: &apistructs.NewHealthCheck{
+			GrpcHealthCheck: &apistructs.GrpcHealthCheck{Port: 9090},
+		},
+	}
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "svc"}}}
+	SetHealthCheck(&podSpec.Containers[0], service)
+
+	// image not configured, use grpc_health_probe provided by the service image
+	InjectGrpcHealthProbe(podSpec, "")
+	assert.Equal(t, GrpcHealthProbeCmd, podSpec.Containers[0].LivenessProbe.Exec.Command[0])
+	assert.Equal(t, 0, len(podSpec.InitContainers))
+
+	InjectGrpcHealthProbe(podSpec, "grpc-health-probe:v0.4.6")
+	container := podSpec.Containers[0]
+	assert.Equal(t, "/erda-grpc-health-probe/grpc_health_probe", container.LivenessProbe.Exec.Command[0])
+	assert.Equal(t, "/erda-grpc-health-probe/grpc_health_probe", container.ReadinessProbe.Exec.Command[0])
+	assert.Equal(t, 1, len(container.VolumeMounts))
+	assert.Equal(t, 1, len(podSpec.Volumes))
+	assert.Equal(t, 1, len(podSpec.InitContainers))
+	assert.Equal(t, "grpc-health-probe:v0.4.6", podSpec.InitContainers[0].Image)
+	assert.Equal(t, container.VolumeMounts, podSpec.InitContainers[0].VolumeMounts)
+
+	// not grpc health check
+	podSpec = &corev1.PodSpec{Containers: []corev1.Container{{Name: "svc"}}}
+	SetHealthCheck(&podSpec.Containers[0], &apistructs.Service{
+		NewHealthCheck: &apistructs.NewHealthCheck{TcpHealthCheck: &apistructs.TcpHealthCheck{Port: 8080}},
+	})
+	InjectGrpcHealthProbe(podSpec, "grpc-health-probe:v0.4.6")
+	assert.Equal(t, 0, len(podSpec.InitContainers))
+	assert.Equal(t, 0, len(podSpec.Volumes))
 }
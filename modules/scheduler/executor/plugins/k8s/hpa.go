@@ -0,0 +1,195 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+// newHPA generates the hpa which scales the deployment of service, hpa has the same name as deployment
+func newHPA(service *apistructs.Service) (*autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	a := service.Autoscaling
+	name := getDeployName(service)
+	minReplicas := int32(a.GetMinReplicas())
+
+	var metrics []autoscalingv2beta2.MetricSpec
+	resourceMetric := func(name apiv1.ResourceName, utilization int) autoscalingv2beta2.MetricSpec {
+		u := int32(utilization)
+		return autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name: name,
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:               autoscalingv2beta2.UtilizationMetricType,
+					AverageUtilization: &u,
+				},
+			},
+		}
+	}
+	if a.TargetCPU > 0 {
+		metrics = append(metrics, resourceMetric(apiv1.ResourceCPU, a.TargetCPU))
+	}
+	if a.TargetMem > 0 {
+		metrics = append(metrics, resourceMetric(apiv1.ResourceMemory, a.TargetMem))
+	}
+	for _, m := range a.Metrics {
+		value, err := resource.ParseQuantity(m.TargetAverageValue)
+		if err != nil {
+			return nil, errors.Errorf("invalid target_average_value of autoscaling metric %s, (%v)", m.Name, err)
+		}
+		target := autoscalingv2beta2.MetricTarget{
+			Type:         autoscalingv2beta2.AverageValueMetricType,
+			AverageValue: &value,
+		}
+		switch m.GetType() {
+		case diceyml.AutoscalingMetricTypePods:
+			metrics = append(metrics, autoscalingv2beta2.MetricSpec{
+				Type: autoscalingv2beta2.PodsMetricSourceType,
+				Pods: &autoscalingv2beta2.PodsMetricSource{
+					Metric: autoscalingv2beta2.MetricIdentifier{Name: m.Name},
+					Target: target,
+				},
+			})
+		case diceyml.AutoscalingMetricTypeExternal:
+			identifier := autoscalingv2beta2.MetricIdentifier{Name: m.Name}
+			if len(m.Selector) > 0 {
+				identifier.Selector = &metav1.LabelSelector{MatchLabels: m.Selector}
+			}
+			metrics = append(metrics, autoscalingv2beta2.MetricSpec{
+				Type: autoscalingv2beta2.ExternalMetricSourceType,
+				External: &autoscalingv2beta2.ExternalMetricSource{
+					Metric: identifier,
+					Target: target,
+				},
+			})
+		default:
+			return nil, errors.Errorf("invalid type of autoscaling metric %s: %s", m.Name, m.Type)
+		}
+	}
+
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "autoscaling/v2beta2",
+			Kind:       "HorizontalPodAutoscaler",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: service.Namespace,
+			Labels:    map[string]string{"app": service.Name},
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       name,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: int32(a.MaxReplicas),
+			Metrics:     metrics,
+		},
+	}
+	if a.ScaleDownStabilization > 0 {
+		window := int32(a.ScaleDownStabilization)
+		hpa.Spec.Behavior = &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+			ScaleDown: &autoscalingv2beta2.HPAScalingRules{
+				StabilizationWindowSeconds: &window,
+			},
+		}
+	}
+	return hpa, nil
+}
+
+// autoscalingReplicas returns the replicas of deployment when autoscaling enabled.
+// current is the replicas of the running deployment, which may be scaled by hpa,
+// keep it on redeploy, otherwise use service.Scale as the initial replicas
+func autoscalingReplicas(service *apistructs.Service, current *int32) *int32 {
+	replicas := int32(service.Scale)
+	if current != nil {
+		replicas = *current
+	}
+	if minReplicas := int32(service.Autoscaling.GetMinReplicas()); replicas < minReplicas {
+		replicas = minReplicas
+	}
+	if maxReplicas := int32(service.Autoscaling.MaxReplicas); replicas > maxReplicas {
+		replicas = maxReplicas
+	}
+	return &replicas
+}
+
+// keepAutoscaledReplicas keeps the replicas scaled by hpa when updating deployment
+func (k *Kubernetes) keepAutoscaledReplicas(desired *appsv1.Deployment, service *apistructs.Service) {
+	if service.Autoscaling == nil {
+		return
+	}
+	current, err := k.getDeployment(desired.Namespace, desired.Name)
+	if err != nil {
+		logrus.Warnf("failed to get deployment %s/%s, use initial replicas, (%v)", desired.Namespace, desired.Name, err)
+		return
+	}
+	desired.Spec.Replicas = autoscalingReplicas(service, current.Spec.Replicas)
+}
+
+// checkManualScale rejects changing the replicas of a deployment scaled by hpa,
+// otherwise hpa would scale it back, min_replicas and max_replicas should be changed instead
+func (k *Kubernetes) checkManualScale(namespace, name string, service *apistructs.Service, current *int32) error {
+	if _, err := k.hpa.Get(namespace, name); err != nil {
+		if k8serror.NotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if current != nil && *current == int32(service.Scale) {
+		return nil
+	}
+	return errors.Errorf("service %s is autoscaling enabled, change min_replicas and max_replicas of autoscaling instead of replicas", service.Name)
+}
+
+// syncHPA creates or updates the hpa of service, and deletes it if autoscaling is disabled
+func (k *Kubernetes) syncHPA(service *apistructs.Service) error {
+	name := getDeployName(service)
+	if service.Autoscaling == nil {
+		return k.deleteHPA(service.Namespace, name)
+	}
+	desired, err := newHPA(service)
+	if err != nil {
+		return err
+	}
+	current, err := k.hpa.Get(service.Namespace, name)
+	if err != nil {
+		if !k8serror.NotFound(err) {
+			return err
+		}
+		return k.hpa.Create(desired)
+	}
+	desired.ResourceVersion = current.ResourceVersion
+	return k.hpa.Put(desired)
+}
+
+func (k *Kubernetes) deleteHPA(namespace, name string) error {
+	if err := k.hpa.Delete(namespace, name); err != nil && !k8serror.NotFound(err) {
+		return err
+	}
+	return nil
+}
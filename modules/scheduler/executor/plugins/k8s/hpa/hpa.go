@@ -0,0 +1,146 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hpa manipulates the k8s api of horizontalpodautoscaler object
+package hpa
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8sapi"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+)
+
+// HPA is the object to manipulate k8s api of horizontalpodautoscaler
+type HPA struct {
+	addr   string
+	client *httpclient.HTTPClient
+}
+
+// Option configures a HPA
+type Option func(*HPA)
+
+// New news a HPA
+func New(options ...Option) *HPA {
+	h := &HPA{}
+
+	for _, op := range options {
+		op(h)
+	}
+
+	return h
+}
+
+// WithCompleteParams provides an Option
+func WithCompleteParams(addr string, client *httpclient.HTTPClient) Option {
+	return func(h *HPA) {
+		h.addr = addr
+		h.client = client
+	}
+}
+
+// Get gets a k8s hpa object
+func (h *HPA) Get(namespace, name string) (*autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	var b bytes.Buffer
+	resp, err := h.client.Get(h.addr).
+		Path("/apis/autoscaling/v2beta2/namespaces/" + namespace + "/horizontalpodautoscalers/" + name).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return nil, errors.Errorf("failed to get hpa, name: %s, (%v)", name, err)
+	}
+
+	if !resp.IsOK() {
+		if resp.IsNotfound() {
+			return nil, k8serror.ErrNotFound
+		}
+		return nil, errors.Errorf("failed to get hpa, name: %s, statuscode: %v, body: %v",
+			name, resp.StatusCode(), b.String())
+	}
+
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	if err := json.NewDecoder(&b).Decode(hpa); err != nil {
+		return nil, err
+	}
+	return hpa, nil
+}
+
+// Create creates a k8s hpa object
+func (h *HPA) Create(hpa *autoscalingv2beta2.HorizontalPodAutoscaler) error {
+	var b bytes.Buffer
+	resp, err := h.client.Post(h.addr).
+		Path("/apis/autoscaling/v2beta2/namespaces/" + hpa.Namespace + "/horizontalpodautoscalers").
+		JSONBody(hpa).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to create hpa, name: %s, (%v)", hpa.Name, err)
+	}
+
+	if !resp.IsOK() {
+		return errors.Errorf("failed to create hpa, name: %s, statuscode: %v, body: %v",
+			hpa.Name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
+
+// Put updates a k8s hpa object
+func (h *HPA) Put(hpa *autoscalingv2beta2.HorizontalPodAutoscaler) error {
+	var b bytes.Buffer
+	resp, err := h.client.Put(h.addr).
+		Path("/apis/autoscaling/v2beta2/namespaces/" + hpa.Namespace + "/horizontalpodautoscalers/" + hpa.Name).
+		JSONBody(hpa).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to put hpa, name: %s, (%v)", hpa.Name, err)
+	}
+
+	if !resp.IsOK() {
+		return errors.Errorf("failed to put hpa, name: %s, statuscode: %v, body: %v",
+			hpa.Name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
+
+// Delete deletes a k8s hpa object
+func (h *HPA) Delete(namespace, name string) error {
+	var b bytes.Buffer
+	resp, err := h.client.Delete(h.addr).
+		Path("/apis/autoscaling/v2beta2/namespaces/" + namespace + "/horizontalpodautoscalers/" + name).
+		JSONBody(k8sapi.DeleteOptions).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to delete hpa, name: %s, (%v)", name, err)
+	}
+
+	if !resp.IsOK() {
+		if resp.IsNotfound() {
+			return k8serror.ErrNotFound
+		}
+		return errors.Errorf("failed to delete hpa, name: %s, statuscode: %v, body: %v",
+			name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
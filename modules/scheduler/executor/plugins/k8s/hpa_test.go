@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+func TestNewHPA(t *testing.T) {
+	service := &apistructs.Service{
+		Name:      "web",
+		Namespace: "project-1-dev",
+		Scale:     2,
+		Autoscaling: &diceyml.Autoscaling{
+			MaxReplicas: 10,
+			TargetCPU:   70,
+			Metrics: []diceyml.AutoscalingMetric{
+				{Name: "http_requests_per_second", TargetAverageValue: "100"},
+				{Type: "external", Name: "queue_messages_ready", Selector: map[string]string{"queue": "orders"}, TargetAverageValue: "30"},
+			},
+			ScaleDownStabilization: 300,
+		},
+		ProjectServiceName: "web-abc",
+	}
+	hpa, err := newHPA(service)
+	assert.NoError(t, err)
+	assert.Equal(t, "web-abc", hpa.Name)
+	assert.Equal(t, "web-abc", hpa.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, int32(1), *hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(10), hpa.Spec.MaxReplicas)
+	assert.Equal(t, 3, len(hpa.Spec.Metrics))
+	assert.Equal(t, apiv1.ResourceCPU, hpa.Spec.Metrics[0].Resource.Name)
+	assert.Equal(t, int32(70), *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+	assert.Equal(t, autoscalingv2beta2.PodsMetricSourceType, hpa.Spec.Metrics[1].Type)
+	assert.Equal(t, int64(100), hpa.Spec.Metrics[1].Pods.Target.AverageValue.Value())
+	assert.Equal(t, "orders", hpa.Spec.Metrics[2].External.Metric.Selector.MatchLabels["queue"])
+	assert.Equal(t, int32(300), *hpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds)
+
+	service.Autoscaling.Metrics[0].TargetAverageValue = "abc"
+	_, err = newHPA(service)
+	assert.Error(t, err)
+}
+
+func TestAutoscalingReplicas(t *testing.T) {
+	service := &apistructs.Service{
+		Scale:       1,
+		Autoscaling: &diceyml.Autoscaling{MinReplicas: 2, MaxReplicas: 5, TargetCPU: 70},
+	}
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	assert.Equal(t, int32(2), *autoscalingReplicas(service, nil))
+	assert.Equal(t, int32(4), *autoscalingReplicas(service, int32Ptr(4)))
+	assert.Equal(t, int32(5), *autoscalingReplicas(service, int32Ptr(8)))
+	service.Scale = 3
+	assert.Equal(t, int32(3), *autoscalingReplicas(service, nil))
+}
@@ -44,6 +44,7 @@ import (
 	ds "github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/daemonset"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/deployment"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/event"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/hpa"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/ingress"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/instanceinfosync"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
@@ -64,6 +65,7 @@ import (
 	"github.com/erda-project/erda/pkg/http/httpclient"
 	"github.com/erda-project/erda/pkg/istioctl"
 	"github.com/erda-project/erda/pkg/istioctl/engines"
+	"github.com/erda-project/erda/pkg/kms/envelope"
 	"github.com/erda-project/erda/pkg/schedule/schedulepolicy/cpupolicy"
 	"github.com/erda-project/erda/pkg/strutil"
 )
@@ -176,6 +178,7 @@ type Kubernetes struct {
 	sts          *statefulset.StatefulSet
 	pod          *pod.Pod
 	secret       *secret.Secret
+	hpa          *hpa.HPA
 	sa           *serviceaccount.ServiceAccount
 	nodeLabel    *nodelabel.NodeLabel
 	ClusterInfo  *clusterinfo.ClusterInfo
@@ -206,6 +209,9 @@ type Kubernetes struct {
 	dbclient *instanceinfo.Client
 
 	istioEngine istioctl.IstioEngine
+
+	// kms is used to open secret envs sealed by KMS envelope encryption
+	kms envelope.DataKeyService
 }
 
 func (k *Kubernetes) GetK8SAddr() string {
@@ -324,6 +330,7 @@ func New(name executortypes.Name, clusterName string, options map[string]string)
 	sts := statefulset.New(statefulset.WithCompleteParams(addr, client))
 	k8spod := pod.New(pod.WithCompleteParams(addr, client))
 	k8ssecret := secret.New(secret.WithCompleteParams(addr, client))
+	k8shpa := hpa.New(hpa.WithCompleteParams(addr, client))
 	sa := serviceaccount.New(serviceaccount.WithCompleteParams(addr, client))
 	nodeLabel := nodelabel.New(addr, client)
 	event := event.New(event.WithCompleteParams(addr, client))
@@ -377,6 +384,7 @@ func New(name executortypes.Name, clusterName string, options map[string]string)
 		sts:                      sts,
 		pod:                      k8spod,
 		secret:                   k8ssecret,
+		hpa:                      k8shpa,
 		sa:                       sa,
 		nodeLabel:                nodeLabel,
 		ClusterInfo:              clusterInfo,
@@ -392,6 +400,7 @@ func New(name executortypes.Name, clusterName string, options map[string]string)
 		stagingMemSubscribeRatio: stagingMemSubscribeRatio,
 		cpuNumQuota:              cpuNumQuota,
 		dbclient:                 dbclient,
+		kms:                      bundle.New(bundle.WithKMS()).KMSDataKeyService(),
 	}
 
 	if istioEngine != nil {
@@ -676,7 +685,11 @@ func (k *Kubernetes) createOne(service *apistructs.Service, sg *apistructs.Servi
 		err = k.createDaemonSet(service, sg)
 	default:
 		// Step 2. Create related deployment
-		err = k.createDeployment(service, sg)
+		if err = k.createDeployment(service, sg); err != nil {
+			return err
+		}
+		// Step 3. Create hpa if autoscaling enabled
+		err = k.syncHPA(service)
 	}
 	if err != nil {
 		return err
@@ -738,6 +751,14 @@ func (k *Kubernetes) tryDelete(namespace, name string) error {
 			namespace, name, err2)
 	}
 
+	if err := k.deleteHPA(namespace, name); err != nil {
+		return errors.Errorf("failed to delete hpa, namespace: %s, name: %s, (%v)", namespace, name, err)
+	}
+
+	if err := k.deleteSecretEnvs(namespace, name); err != nil {
+		return errors.Errorf("failed to delete secret envs, namespace: %s, name: %s, (%v)", namespace, name, err)
+	}
+
 	return nil
 }
 
@@ -798,10 +819,16 @@ func (k *Kubernetes) updateOneByOne(sg *apistructs.ServiceGroup) error {
 				if err != nil {
 					return err
 				}
+				k.keepAutoscaledReplicas(desiredDeployment, &svc)
 				if err = k.putDeployment(desiredDeployment, &svc); err != nil {
 					logrus.Debugf("failed to update deployment in update interface, name: %s, (%v)", svc.Name, err)
 					return err
 				}
+				// hpa is kept in sync with dice.yml, and deleted if autoscaling disabled
+				if err = k.syncHPA(&svc); err != nil {
+					logrus.Errorf("failed to sync hpa in update interface, name: %s, (%v)", svc.Name, err)
+					return err
+				}
 			}
 			if k.istioEngine != istioctl.EmptyEngine {
 				if err := k.istioEngine.OnServiceOperator(istioctl.ServiceUpdate, &svc); err != nil {
@@ -93,11 +93,17 @@ func (k *Kubernetes) destroyRuntimeByProjectNamespace(ns string, sg *apistructs.
 		case ServicePerNode:
 			err = k.deleteDaemonSet(ns, service.ProjectServiceName)
 		default:
+			if err = k.deleteHPA(ns, service.ProjectServiceName); err != nil {
+				return fmt.Errorf("delete hpa %s error: %v", service.ProjectServiceName, err)
+			}
 			err = k.deleteDeployment(ns, service.ProjectServiceName)
 		}
 		if err != nil && !util.IsNotFound(err) {
 			return fmt.Errorf("delete resource %s, %s error: %v", service.WorkLoad, service.ProjectServiceName, err)
 		}
+		if err = k.deleteSecretEnvs(ns, service.ProjectServiceName); err != nil {
+			return fmt.Errorf("delete secret envs of %s error: %v", service.ProjectServiceName, err)
+		}
 
 		labelSelector := map[string]string{
 			"app": service.Name,
@@ -18,12 +18,16 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/pkg/errors"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
+	"github.com/erda-project/erda/pkg/kms/envelope"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
@@ -157,3 +161,67 @@ func (k *Kubernetes) SecretVolume(secret *apiv1.Secret) (apiv1.Volume, apiv1.Vol
 			ReadOnly:  true,
 		}
 }
+
+// secretEnvsName returns the name of k8s secret which holds secret envs of the service
+func secretEnvsName(serviceName string) string {
+	return serviceName + "-secret-envs"
+}
+
+// deleteSecretEnvs deletes the k8s secret which holds secret envs of the service, ignore if not found
+func (k *Kubernetes) deleteSecretEnvs(namespace, serviceName string) error {
+	if err := k.secret.Delete(namespace, secretEnvsName(serviceName)); err != nil && !k8serror.NotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// CreateOrUpdateSecretEnvs opens the secret envs sealed by KMS, saves them into k8s secret of the service,
+// and returns env vars which reference the secret, so no plaintext appears in pod spec
+func (k *Kubernetes) CreateOrUpdateSecretEnvs(namespace, serviceName string, service *apistructs.Service) ([]apiv1.EnvVar, error) {
+	if len(service.SecretEnvs) == 0 {
+		// secret envs may be removed from the service, prune the secret created before
+		return nil, k.deleteSecretEnvs(namespace, serviceName)
+	}
+	name := secretEnvsName(serviceName)
+	data := make(map[string][]byte, len(service.SecretEnvs))
+	keys := make([]string, 0, len(service.SecretEnvs))
+	for key, sealed := range service.SecretEnvs {
+		plaintext, err := envelope.Open(k.kms, sealed)
+		if err != nil {
+			return nil, errors.Errorf("failed to open secret env, service: %s, env: %s, (%v)", service.Name, key, err)
+		}
+		data[key] = plaintext
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	secret := &apiv1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: data,
+		Type: apiv1.SecretTypeOpaque,
+	}
+	if err := k.secret.CreateOrUpdate(secret); err != nil {
+		return nil, err
+	}
+
+	envs := make([]apiv1.EnvVar, 0, len(keys))
+	for _, key := range keys {
+		envs = append(envs, apiv1.EnvVar{
+			Name: key,
+			ValueFrom: &apiv1.EnvVarSource{
+				SecretKeyRef: &apiv1.SecretKeySelector{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: name},
+					Key:                  key,
+				},
+			},
+		})
+	}
+	return envs, nil
+}
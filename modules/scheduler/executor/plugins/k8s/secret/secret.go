@@ -22,6 +22,8 @@ import (
 	"github.com/pkg/errors"
 	apiv1 "k8s.io/api/core/v1"
 
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8sapi"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
 	"github.com/erda-project/erda/pkg/http/httpclient"
 	"github.com/erda-project/erda/pkg/strutil"
 )
@@ -124,6 +126,39 @@ func (p *Secret) Create(secret *apiv1.Secret) error {
 	return nil
 }
 
+// Update updates a k8s secret
+func (p *Secret) Update(secret *apiv1.Secret) error {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", secret.Namespace, "/secrets/", secret.Name)
+
+	resp, err := p.client.Put(p.addr).
+		Path(path).
+		JSONBody(secret).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to update secret, name: %s, (%v)", secret.Name, err)
+	}
+
+	if !resp.IsOK() {
+		return errors.Errorf("failed to update secret, statuscode: %v, body: %v", resp.StatusCode(), b.String())
+	}
+	return nil
+}
+
+// CreateOrUpdate creates the secret if not exist, otherwise updates it
+func (p *Secret) CreateOrUpdate(secret *apiv1.Secret) error {
+	_, err := p.Get(secret.Namespace, secret.Name)
+	if err == nil {
+		return p.Update(secret)
+	}
+	if err.Error() == "not found" {
+		return p.Create(secret)
+	}
+	return err
+}
+
 func (p *Secret) CreateIfNotExist(secret *apiv1.Secret) error {
 	_, err := p.Get(secret.Namespace, secret.Name)
 	if err == nil {
@@ -134,3 +169,27 @@ func (p *Secret) CreateIfNotExist(secret *apiv1.Secret) error {
 	}
 	return err
 }
+
+// Delete deletes a k8s secret
+func (p *Secret) Delete(namespace, name string) error {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", namespace, "/secrets/", name)
+
+	resp, err := p.client.Delete(p.addr).
+		Path(path).
+		JSONBody(k8sapi.DeleteOptions).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to delete secret, name: %s, (%v)", name, err)
+	}
+	if !resp.IsOK() {
+		if resp.IsNotfound() {
+			return k8serror.ErrNotFound
+		}
+		return errors.Errorf("failed to delete secret, name: %s, statuscode: %v, body: %v",
+			name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
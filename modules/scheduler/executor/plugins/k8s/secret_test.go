@@ -0,0 +1,134 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/secret"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+	"github.com/erda-project/erda/pkg/kms/envelope"
+	"github.com/erda-project/erda/pkg/kms/kmscrypto"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// plainDataKeyService does not encrypt DEK, only for test
+type plainDataKeyService struct{}
+
+func (plainDataKeyService) GenerateDataKey(req *kmstypes.GenerateDataKeyRequest) (*kmstypes.GenerateDataKeyResponse, error) {
+	dek, err := kmscrypto.GenerateAes256Key()
+	if err != nil {
+		return nil, err
+	}
+	dekBase64 := base64.StdEncoding.EncodeToString(dek)
+	return &kmstypes.GenerateDataKeyResponse{KeyID: req.KeyID, CiphertextBase64: dekBase64, PlaintextBase64: dekBase64}, nil
+}
+
+func (plainDataKeyService) Decrypt(req *kmstypes.DecryptRequest) (*kmstypes.DecryptResponse, error) {
+	return &kmstypes.DecryptResponse{PlaintextBase64: req.CiphertextBase64}, nil
+}
+
+func TestCreateOrUpdateSecretEnvs(t *testing.T) {
+	svc := plainDataKeyService{}
+	sealed, err := envelope.Seal(svc, "key-a", []byte("p@ssw0rd"))
+	assert.NoError(t, err)
+
+	var created apiv1.Secret
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPost:
+			assert.Equal(t, "/api/v1/namespaces/ns/secrets", r.URL.Path)
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	k := &Kubernetes{secret: secret.New(secret.WithCompleteParams(addr, httpclient.New())), kms: svc}
+
+	envs, err := k.CreateOrUpdateSecretEnvs("ns", "web", &apistructs.Service{
+		Name:       "web",
+		SecretEnvs: map[string]string{"DB_PASSWORD": sealed},
+	})
+	assert.NoError(t, err)
+	// plaintext only lands in the secret, pod env references it
+	assert.Equal(t, "web-secret-envs", created.Name)
+	assert.Equal(t, apiv1.SecretTypeOpaque, created.Type)
+	assert.Equal(t, map[string][]byte{"DB_PASSWORD": []byte("p@ssw0rd")}, created.Data)
+	assert.Equal(t, []apiv1.EnvVar{{
+		Name: "DB_PASSWORD",
+		ValueFrom: &apiv1.EnvVarSource{
+			SecretKeyRef: &apiv1.SecretKeySelector{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: "web-secret-envs"},
+				Key:                  "DB_PASSWORD",
+			},
+		},
+	}}, envs)
+
+	// value not sealed by KMS can not be opened
+	_, err = k.CreateOrUpdateSecretEnvs("ns", "web", &apistructs.Service{
+		Name:       "web",
+		SecretEnvs: map[string]string{"DB_PASSWORD": "p@ssw0rd"},
+	})
+	assert.Error(t, err)
+}
+
+func TestCreateOrUpdateSecretEnvs_Prune(t *testing.T) {
+	var deleted []string
+	exists := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		deleted = append(deleted, r.URL.Path)
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		exists = false
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	k := &Kubernetes{secret: secret.New(secret.WithCompleteParams(addr, httpclient.New()))}
+
+	// secret envs removed from the service, the secret created before is deleted
+	envs, err := k.CreateOrUpdateSecretEnvs("ns", "web", &apistructs.Service{Name: "web"})
+	assert.NoError(t, err)
+	assert.Nil(t, envs)
+
+	// not found is ignored
+	assert.NoError(t, k.deleteSecretEnvs("ns", "web"))
+	assert.Equal(t, []string{
+		"/api/v1/namespaces/ns/secrets/web-secret-envs",
+		"/api/v1/namespaces/ns/secrets/web-secret-envs",
+	}, deleted)
+}
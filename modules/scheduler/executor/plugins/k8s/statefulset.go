@@ -29,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/conf"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/toleration"
 	"github.com/erda-project/erda/pkg/schedule/schedulepolicy/constraintbuilders"
 	"github.com/erda-project/erda/pkg/schedule/schedulepolicy/constraintbuilders/constraints"
@@ -148,6 +149,7 @@ func (k *Kubernetes) createStatefulSet(info StatefulsetInfo) error {
 	setEnv(container, info.envs, info.sg, info.namespace)
 
 	set.Spec.Template.Spec.Containers = []apiv1.Container{*container}
+	InjectGrpcHealthProbe(&set.Spec.Template.Spec, conf.GrpcHealthProbeImage())
 	if info.namespace == "fake-test" {
 		return nil
 	}
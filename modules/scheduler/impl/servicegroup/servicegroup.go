@@ -274,6 +274,7 @@ func convertServiceGroup(req apistructs.ServiceGroupCreateV2Request, clusterinfo
 			},
 			Depends:          service.DependsOn,
 			Env:              service.Envs,
+			SecretEnvs:       req.SecretEnvs[name],
 			Labels:           service.Labels,
 			Selectors:        service.Deployments.Selectors,
 			WorkLoad:         service.Deployments.Workload,
@@ -287,6 +288,7 @@ func convertServiceGroup(req apistructs.ServiceGroupCreateV2Request, clusterinfo
 			MeshEnable:       service.MeshEnable,
 			TrafficSecurity:  service.TrafficSecurity,
 			K8SSnippet:       service.K8SSnippet,
+			Autoscaling:      service.Deployments.Autoscaling,
 		}
 		sgServices = append(sgServices, sgService)
 	}
@@ -311,6 +313,25 @@ func convertHealthcheck(hc diceyml.HealthCheck) *apistructs.NewHealthCheck {
 			Duration: hc.Exec.Duration,
 		}
 	}
+	if hc.TCP != nil && hc.TCP.Port != 0 {
+		nhc.TcpHealthCheck = &apistructs.TcpHealthCheck{
+			Port:             hc.TCP.Port,
+			Duration:         hc.TCP.Duration,
+			InitialDelay:     hc.TCP.InitialDelay,
+			FailureThreshold: hc.TCP.FailureThreshold,
+			SuccessThreshold: hc.TCP.SuccessThreshold,
+		}
+	}
+	if hc.GRPC != nil && hc.GRPC.Port != 0 {
+		nhc.GrpcHealthCheck = &apistructs.GrpcHealthCheck{
+			Port:             hc.GRPC.Port,
+			Service:          hc.GRPC.Service,
+			Duration:         hc.GRPC.Duration,
+			InitialDelay:     hc.GRPC.InitialDelay,
+			FailureThreshold: hc.GRPC.FailureThreshold,
+			SuccessThreshold: hc.GRPC.SuccessThreshold,
+		}
+	}
 	return &nhc
 }
 
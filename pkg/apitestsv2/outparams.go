@@ -16,10 +16,13 @@ package apitestsv2
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/pkg/encoding/jsonparse"
+	"github.com/erda-project/erda/pkg/encoding/xpath"
 )
 
 // ParseOutParams 解析 API 执行结果的出参，存储为全局变量，供后续使用
@@ -53,6 +56,12 @@ func (at *APITest) ParseOutParams(apiOutParams []apistructs.APIOutParam, apiResp
 		case apistructs.APIOutParamSourceBodyText:
 			pam.Type = apistructs.APIOutParamSourceStatus.String()
 			pam.Value = fmt.Sprint(apiResp.Body)
+		case apistructs.APIOutParamSourceBodyTextRegex:
+			pam.Type = apistructs.APIOutParamSourceStatus.String()
+			pam.Value = filterRegex(apiResp.Body, t.Expression, t.MatchIndex)
+		case apistructs.APIOutParamSourceBodyXmlXPath:
+			pam.Type = apistructs.APIOutParamSourceStatus.String()
+			pam.Value = filterXPath(apiResp.Body, t.Expression)
 		case apistructs.APIOutParamSourceHeader:
 			pam.Type = apistructs.APIOutParamSourceStatus.String()
 			express := strings.TrimSpace(t.Expression)
@@ -81,3 +90,40 @@ func (at *APITest) ParseOutParams(apiOutParams []apistructs.APIOutParam, apiResp
 
 	return outParams
 }
+
+// filterRegex 返回正则在 body 中第一次匹配的捕获组, 没有捕获组时返回整个匹配, 未匹配时返回空字符串
+func filterRegex(body []byte, express, matchIndex string) interface{} {
+	re, err := regexp.Compile(strings.TrimSpace(express))
+	if err != nil {
+		return ""
+	}
+	match := re.FindSubmatch(body)
+	if match == nil {
+		return ""
+	}
+
+	group := 0
+	if re.NumSubexp() > 0 {
+		group = 1
+	}
+	if matchIndex = strings.TrimSpace(matchIndex); matchIndex != "" {
+		if i, err := strconv.Atoi(matchIndex); err == nil {
+			group = i
+		} else if group = re.SubexpIndex(matchIndex); group < 0 {
+			return ""
+		}
+	}
+	if group < 0 || group >= len(match) {
+		return ""
+	}
+	return string(match[group])
+}
+
+// filterXPath 返回 xml body 中 XPath 匹配的值, 未匹配时返回空字符串
+func filterXPath(body []byte, express string) interface{} {
+	v, err := xpath.Get(body, express)
+	if err != nil {
+		return ""
+	}
+	return v
+}
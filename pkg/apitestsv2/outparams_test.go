@@ -0,0 +1,53 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitestsv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestParseOutParams(t *testing.T) {
+	resp := &apistructs.APIResp{
+		Status: 200,
+		Body: []byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <GetOrderResponse><Order id="1001"><Status>paid</Status><Token>token=abc-123;</Token></Order></GetOrderResponse>
+  </soap:Body>
+</soap:Envelope>`),
+	}
+	outParams := (&APITest{}).ParseOutParams([]apistructs.APIOutParam{
+		{Key: "orderID", Source: apistructs.APIOutParamSourceBodyXmlXPath, Expression: "//Order/@id"},
+		{Key: "status", Source: apistructs.APIOutParamSourceBodyXmlXPath, Expression: "/soap:Envelope/soap:Body/GetOrderResponse/Order/Status"},
+		{Key: "missing", Source: apistructs.APIOutParamSourceBodyXmlXPath, Expression: "//Missing"},
+		{Key: "token", Source: apistructs.APIOutParamSourceBodyTextRegex, Expression: `token=([\w-]+);`},
+		{Key: "tokenWhole", Source: apistructs.APIOutParamSourceBodyTextRegex, Expression: `token=([\w-]+);`, MatchIndex: "0"},
+		{Key: "tokenNamed", Source: apistructs.APIOutParamSourceBodyTextRegex, Expression: `token=(?P<prefix>\w+)-(?P<num>\d+)`, MatchIndex: "num"},
+		{Key: "noGroup", Source: apistructs.APIOutParamSourceBodyTextRegex, Expression: `\d{4}`},
+		{Key: "noMatch", Source: apistructs.APIOutParamSourceBodyTextRegex, Expression: `nothing`},
+	}, resp, map[string]*apistructs.CaseParams{})
+
+	assert.Equal(t, "1001", outParams["orderID"])
+	assert.Equal(t, "paid", outParams["status"])
+	assert.Equal(t, "", outParams["missing"])
+	assert.Equal(t, "abc-123", outParams["token"])
+	assert.Equal(t, "token=abc-123;", outParams["tokenWhole"])
+	assert.Equal(t, "123", outParams["tokenNamed"])
+	assert.Equal(t, "1001", outParams["noGroup"])
+	assert.Equal(t, "", outParams["noMatch"])
+}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
 
 	"github.com/erda-project/erda/pkg/encoding/jsonparse"
 	"github.com/erda-project/erda/pkg/encoding/jsonpath"
@@ -82,6 +83,8 @@ func DoAssert(value interface{}, op string, expect string) (bool, error) {
 		return isExist(value, expect), nil
 	case "not_exist":
 		return !isExist(value, expect), nil
+	case "json_schema":
+		return matchJSONSchema(value, expect)
 	default:
 		return false, fmt.Errorf("invalid operator")
 	}
@@ -345,3 +348,44 @@ func moreThan(value interface{}, expect interface{}, isEqual bool) (bool, error)
 
 	return valDigital > expectDigital, nil
 }
+
+// matchJSONSchema 校验值是否符合 JSON Schema (draft-04/06/07), expect 为 json 格式的 schema, 不支持引用外部文档
+func matchJSONSchema(value interface{}, expect string) (bool, error) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(expect))
+	if err != nil {
+		return false, errors.Errorf("invalid json schema, err: %v", err)
+	}
+
+	// 出参中的数字可能是 json.Number, body 可能是未解析的文本, 统一转换为 encoding/json 解析的类型
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return false, errors.Errorf("failed to marshal, value:%+v, (%+v)", value, err)
+		}
+		data = b
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		// 非 json 文本按字符串校验
+		v = value
+	}
+
+	result, err := schema.Validate(gojsonschema.NewGoLoader(v))
+	if err != nil {
+		return false, err
+	}
+	if !result.Valid() {
+		var msgs []string
+		for _, e := range result.Errors() {
+			msgs = append(msgs, e.String())
+		}
+		return false, errors.Errorf("json schema mismatch: %s", strings.Join(msgs, "; "))
+	}
+	return true, nil
+}
@@ -247,4 +247,30 @@ func TestAssert(t *testing.T) {
 	ret, err = DoAssert(val1, op, e)
 	ast.Equal(t, err, nil)
 	ast.Equal(t, ret, true)
+
+	// 测试 json_schema
+	schema := `{"type":"object","required":["id","name"],"properties":{"id":{"type":"integer"},"name":{"type":"string"},"tags":{"type":"array","items":{"type":"string"}}}}`
+	op = "json_schema"
+	ret, err = DoAssert(map[string]interface{}{"id": json.Number("1"), "name": "kitty", "tags": []interface{}{"cat"}}, op, schema)
+	ast.Equal(t, err, nil)
+	ast.Equal(t, ret, true)
+
+	ret, err = DoAssert(`{"id":1,"name":"kitty"}`, op, schema)
+	ast.Equal(t, err, nil)
+	ast.Equal(t, ret, true)
+
+	ret, err = DoAssert(`{"id":"1","name":"kitty"}`, op, schema)
+	ast.NotEqual(t, err, nil)
+	ast.Equal(t, ret, false)
+
+	ret, err = DoAssert(`{"id":1}`, op, schema)
+	ast.NotEqual(t, err, nil)
+	ast.Equal(t, ret, false)
+
+	ret, err = DoAssert("kitty", op, `{"type":"string","minLength":3}`)
+	ast.Equal(t, err, nil)
+	ast.Equal(t, ret, true)
+
+	_, err = DoAssert("kitty", op, `{"type":`)
+	ast.NotEqual(t, err, nil)
 }
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverage
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+type coberturaReport struct {
+	Packages []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name    string           `xml:"name,attr"`
+	Classes []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Filename string          `xml:"filename,attr"`
+	Lines    []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// ParseCobertura parses a cobertura xml report, which is produced by
+// coverage.py, istanbul, gocov-xml and the cobertura maven plugin.
+// Classes of the same file, e.g. java inner classes, are merged.
+func ParseCobertura(r io.Reader) (*Report, error) {
+	var report coberturaReport
+	if err := xml.NewDecoder(r).Decode(&report); err != nil {
+		return nil, errors.Wrap(err, "decode cobertura report")
+	}
+	files := newFileSet()
+	for _, pkg := range report.Packages {
+		for _, class := range pkg.Classes {
+			if class.Filename == "" {
+				continue
+			}
+			name := pkg.Name
+			if name == "" {
+				name = path.Dir(cleanPath(class.Filename))
+			}
+			f := files.get(class.Filename, name)
+			for _, line := range class.Lines {
+				f.addLine(line.Number, line.Hits)
+			}
+		}
+	}
+	return files.report, nil
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package coverage parses line coverage reports of different tools into a common model.
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Format 覆盖率报告格式
+type Format string
+
+const (
+	FormatCobertura Format = "cobertura"
+	FormatJaCoCo    Format = "jacoco"
+	FormatGo        Format = "go"
+)
+
+// Report 一次测试的行覆盖率
+type Report struct {
+	Files []*File `json:"files"`
+}
+
+// File 单个源文件的行覆盖率
+type File struct {
+	Path    string `json:"path"`
+	Package string `json:"package"`
+	// Lines 行号 -> 执行次数，只包含可执行的行
+	Lines map[int]int `json:"lines"`
+}
+
+// Summary 覆盖率统计
+type Summary struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+	// Rate 覆盖率百分比，没有可执行的行时为 100
+	Rate float64 `json:"rate"`
+}
+
+// PackageSummary 包级别覆盖率统计
+type PackageSummary struct {
+	Package string `json:"package"`
+	Summary
+}
+
+// FileSummary 文件级别覆盖率统计
+type FileSummary struct {
+	Path    string `json:"path"`
+	Package string `json:"package"`
+	Summary
+}
+
+// Parse parses a coverage report of the given format.
+func Parse(format Format, r io.Reader) (*Report, error) {
+	switch format {
+	case FormatCobertura:
+		return ParseCobertura(r)
+	case FormatJaCoCo:
+		return ParseJaCoCo(r)
+	case FormatGo:
+		return ParseGoCoverProfile(r)
+	default:
+		return nil, fmt.Errorf("unsupported coverage format: %s", format)
+	}
+}
+
+func newSummary(covered, total int) Summary {
+	s := Summary{Covered: covered, Total: total, Rate: 100}
+	if total > 0 {
+		s.Rate = math.Round(float64(covered)*10000/float64(total)) / 100
+	}
+	return s
+}
+
+func (s *Summary) add(hits int) {
+	s.Total++
+	if hits > 0 {
+		s.Covered++
+	}
+}
+
+// Summary returns the line coverage of the file.
+func (f *File) Summary() Summary {
+	var s Summary
+	for _, hits := range f.Lines {
+		s.add(hits)
+	}
+	return newSummary(s.Covered, s.Total)
+}
+
+// Summary returns the line coverage of the whole report.
+func (r *Report) Summary() Summary {
+	var s Summary
+	for _, f := range r.Files {
+		for _, hits := range f.Lines {
+			s.add(hits)
+		}
+	}
+	return newSummary(s.Covered, s.Total)
+}
+
+// Packages returns the line coverage of each package, sorted by package name.
+func (r *Report) Packages() []PackageSummary {
+	grouped := map[string]*Summary{}
+	for _, f := range r.Files {
+		s, ok := grouped[f.Package]
+		if !ok {
+			s = &Summary{}
+			grouped[f.Package] = s
+		}
+		for _, hits := range f.Lines {
+			s.add(hits)
+		}
+	}
+	packages := make([]PackageSummary, 0, len(grouped))
+	for name, s := range grouped {
+		packages = append(packages, PackageSummary{Package: name, Summary: newSummary(s.Covered, s.Total)})
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Package < packages[j].Package })
+	return packages
+}
+
+// FileSummaries returns the line coverage of each file, sorted by path.
+func (r *Report) FileSummaries() []FileSummary {
+	files := make([]FileSummary, 0, len(r.Files))
+	for _, f := range r.Files {
+		files = append(files, FileSummary{Path: f.Path, Package: f.Package, Summary: f.Summary()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// Merge merges other into r, hits of the same line are summed up.
+// It is used when a pipeline has several tasks reporting coverage.
+func (r *Report) Merge(other *Report) {
+	if other == nil {
+		return
+	}
+	index := make(map[string]*File, len(r.Files))
+	for _, f := range r.Files {
+		index[f.Path] = f
+	}
+	for _, of := range other.Files {
+		f, ok := index[of.Path]
+		if !ok {
+			f = &File{Path: of.Path, Package: of.Package, Lines: map[int]int{}}
+			index[f.Path] = f
+			r.Files = append(r.Files, f)
+		}
+		for line, hits := range of.Lines {
+			f.Lines[line] += hits
+		}
+	}
+}
+
+// FindFile finds the file by a path relative to the repository root.
+//
+// Coverage tools record paths differently: go uses the import path, jacoco
+// uses the package directory and cobertura is relative to one of its sources.
+// So paths are matched by suffix on a path separator boundary and the longest
+// match wins.
+func (r *Report) FindFile(path string) *File {
+	path = cleanPath(path)
+	if path == "" {
+		return nil
+	}
+	var (
+		found   *File
+		longest int
+	)
+	for _, f := range r.Files {
+		p := cleanPath(f.Path)
+		var n int
+		switch {
+		case p == path:
+			return f
+		case strings.HasSuffix(p, "/"+path):
+			n = len(path)
+		case strings.HasSuffix(path, "/"+p):
+			n = len(p)
+		default:
+			continue
+		}
+		if n > longest {
+			found, longest = f, n
+		}
+	}
+	return found
+}
+
+func cleanPath(path string) string {
+	path = strings.ReplaceAll(path, "\\", "/")
+	path = strings.TrimPrefix(path, "./")
+	return strings.Trim(path, "/")
+}
+
+// addLine records hits of a line, hits of the same line are summed up.
+func (f *File) addLine(line, hits int) {
+	if line <= 0 {
+		return
+	}
+	if hits < 0 {
+		hits = 0
+	}
+	f.Lines[line] += hits
+}
+
+// fileSet keeps the files in the order they first appear.
+type fileSet struct {
+	report *Report
+	index  map[string]*File
+}
+
+func newFileSet() *fileSet {
+	return &fileSet{report: &Report{Files: []*File{}}, index: map[string]*File{}}
+}
+
+func (s *fileSet) get(path, pkg string) *File {
+	f, ok := s.index[path]
+	if !ok {
+		f = &File{Path: path, Package: pkg, Lines: map[int]int{}}
+		s.index[path] = f
+		s.report.Files = append(s.report.Files, f)
+	}
+	return f
+}
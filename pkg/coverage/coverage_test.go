@@ -0,0 +1,161 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+const coberturaXML = `<?xml version="1.0" ?>
+<coverage version="5.5" line-rate="0.6" branch-rate="0">
+	<sources><source>/app/src</source></sources>
+	<packages>
+		<package name="calc" line-rate="0.6">
+			<classes>
+				<class name="add.py" filename="calc/add.py" line-rate="0.66">
+					<lines>
+						<line number="1" hits="1"/>
+						<line number="2" hits="3"/>
+						<line number="4" hits="0"/>
+					</lines>
+				</class>
+				<class name="sub.py" filename="calc/sub.py" line-rate="0.5">
+					<lines>
+						<line number="1" hits="1"/>
+						<line number="2" hits="0"/>
+					</lines>
+				</class>
+			</classes>
+		</package>
+	</packages>
+</coverage>`
+
+const jacocoXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="demo">
+	<group name="module-a">
+		<package name="com/example/calc">
+			<class name="com/example/calc/Add" sourcefilename="Add.java"/>
+			<sourcefile name="Add.java">
+				<line nr="3" mi="3" ci="0" mb="0" cb="0"/>
+				<line nr="5" mi="0" ci="4" mb="0" cb="0"/>
+				<line nr="6" mi="1" ci="2" mb="1" cb="1"/>
+				<counter type="LINE" missed="1" covered="2"/>
+			</sourcefile>
+		</package>
+	</group>
+</report>`
+
+const goCoverProfile = `mode: count
+example.com/demo/calc/add.go:3.24,5.2 1 2
+example.com/demo/calc/add.go:7.24,8.15 1 1
+example.com/demo/calc/add.go:8.15,10.3 1 0
+example.com/demo/calc/sub.go:3.24,5.2 1 0
+mode: count
+example.com/demo/calc/add.go:3.24,5.2 1 1
+`
+
+func TestParseCobertura(t *testing.T) {
+	report, err := Parse(FormatCobertura, strings.NewReader(coberturaXML))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(report.Files))
+	assert.Equal(t, "calc/add.py", report.Files[0].Path)
+	assert.Equal(t, "calc", report.Files[0].Package)
+	assert.Equal(t, Summary{Covered: 2, Total: 3, Rate: 66.67}, report.Files[0].Summary())
+	assert.Equal(t, Summary{Covered: 3, Total: 5, Rate: 60}, report.Summary())
+}
+
+func TestParseJaCoCo(t *testing.T) {
+	report, err := Parse(FormatJaCoCo, strings.NewReader(jacocoXML))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(report.Files))
+	f := report.Files[0]
+	assert.Equal(t, "com/example/calc/Add.java", f.Path)
+	assert.Equal(t, "com.example.calc", f.Package)
+	assert.Equal(t, map[int]int{3: 0, 5: 4, 6: 2}, f.Lines)
+	assert.Equal(t, f, report.FindFile("module-a/src/main/java/com/example/calc/Add.java"))
+}
+
+func TestParseGoCoverProfile(t *testing.T) {
+	report, err := Parse(FormatGo, strings.NewReader(goCoverProfile))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(report.Files))
+
+	add := report.FindFile("calc/add.go")
+	assert.NotNil(t, add)
+	assert.Equal(t, "example.com/demo/calc", add.Package)
+	// line 8 belongs to both an executed and an unexecuted block
+	assert.Equal(t, map[int]int{3: 3, 4: 3, 5: 3, 7: 1, 8: 1, 9: 0, 10: 0}, add.Lines)
+
+	packages := report.Packages()
+	assert.Equal(t, 1, len(packages))
+	assert.Equal(t, Summary{Covered: 5, Total: 10, Rate: 50}, packages[0].Summary)
+
+	_, err = Parse(FormatGo, strings.NewReader("mode: set\nadd.go:3.24 1 1\n"))
+	assert.Error(t, err)
+	_, err = Parse("lcov", strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestDiffCoverage(t *testing.T) {
+	report, err := ParseGoCoverProfile(strings.NewReader(goCoverProfile))
+	assert.NoError(t, err)
+
+	diff := &apistructs.Diff{Files: []*apistructs.DiffFile{
+		{
+			Name: "calc/add.go",
+			Type: "modified",
+			Sections: []*apistructs.DiffSection{{Lines: []*apistructs.DiffLine{
+				{OldLineNo: 2, NewLineNo: 2, Type: "context"},
+				{OldLineNo: -1, NewLineNo: 6, Type: "add"},
+				{OldLineNo: -1, NewLineNo: 8, Type: "add"},
+				{OldLineNo: -1, NewLineNo: 9, Type: "add"},
+				{OldLineNo: 7, NewLineNo: -1, Type: "delete"},
+			}}},
+		},
+		{
+			Name: "README.md",
+			Type: "modified",
+			Sections: []*apistructs.DiffSection{{Lines: []*apistructs.DiffLine{
+				{OldLineNo: -1, NewLineNo: 1, Type: "add"},
+			}}},
+		},
+		{Name: "calc/old.go", Type: "delete"},
+	}}
+	changed := ChangedLines(diff)
+	assert.Equal(t, map[string][]int{"calc/add.go": {6, 8, 9}, "README.md": {1}}, changed)
+
+	result := report.DiffCoverage(changed)
+	assert.Equal(t, Summary{Covered: 1, Total: 2, Rate: 50}, result.Summary)
+	assert.Equal(t, 1, len(result.Files))
+	assert.Equal(t, []int{9}, result.Files[0].Uncovered)
+}
+
+func TestMerge(t *testing.T) {
+	a, err := ParseCobertura(strings.NewReader(coberturaXML))
+	assert.NoError(t, err)
+	b := &Report{Files: []*File{
+		{Path: "calc/sub.py", Package: "calc", Lines: map[int]int{2: 1}},
+		{Path: "calc/mul.py", Package: "calc", Lines: map[int]int{1: 0}},
+	}}
+	a.Merge(b)
+	assert.Equal(t, 3, len(a.Files))
+	assert.Equal(t, Summary{Covered: 4, Total: 6, Rate: 66.67}, a.Summary())
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverage
+
+import (
+	"sort"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+const (
+	diffFileDelete = "delete"
+	diffLineAdd    = "add"
+)
+
+// DiffFileCoverage 变更文件的增量覆盖率
+type DiffFileCoverage struct {
+	Path string `json:"path"`
+	Summary
+	// Uncovered 未覆盖的新增行
+	Uncovered []int `json:"uncovered,omitempty"`
+}
+
+// DiffCoverage 增量覆盖率，只统计新增且可执行的行
+type DiffCoverage struct {
+	Summary
+	Files []DiffFileCoverage `json:"files"`
+}
+
+// ChangedLines returns the added line numbers of each file in the gittar diff.
+// Deleted and binary files are ignored.
+func ChangedLines(diff *apistructs.Diff) map[string][]int {
+	changed := map[string][]int{}
+	if diff == nil {
+		return changed
+	}
+	for _, file := range diff.Files {
+		if file == nil || file.IsBin || file.Type == diffFileDelete {
+			continue
+		}
+		var lines []int
+		for _, section := range file.Sections {
+			for _, line := range section.Lines {
+				if line.Type == diffLineAdd && line.NewLineNo > 0 {
+					lines = append(lines, line.NewLineNo)
+				}
+			}
+		}
+		if len(lines) > 0 {
+			changed[file.Name] = lines
+		}
+	}
+	return changed
+}
+
+// DiffCoverage returns the coverage of the changed lines.
+// Files not found in the report and lines which are not executable,
+// e.g. comments and blank lines, are not counted.
+func (r *Report) DiffCoverage(changed map[string][]int) DiffCoverage {
+	var (
+		result DiffCoverage
+		total  Summary
+	)
+	result.Files = []DiffFileCoverage{}
+	for filePath, lines := range changed {
+		f := r.FindFile(filePath)
+		if f == nil {
+			continue
+		}
+		var (
+			s         Summary
+			uncovered []int
+		)
+		for _, line := range lines {
+			hits, ok := f.Lines[line]
+			if !ok {
+				continue
+			}
+			s.add(hits)
+			total.add(hits)
+			if hits == 0 {
+				uncovered = append(uncovered, line)
+			}
+		}
+		if s.Total == 0 {
+			continue
+		}
+		sort.Ints(uncovered)
+		result.Files = append(result.Files, DiffFileCoverage{
+			Path:      filePath,
+			Summary:   newSummary(s.Covered, s.Total),
+			Uncovered: uncovered,
+		})
+	}
+	sort.Slice(result.Files, func(i, j int) bool { return result.Files[i].Path < result.Files[j].Path })
+	result.Summary = newSummary(total.Covered, total.Total)
+	return result
+}
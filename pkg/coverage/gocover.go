@@ -0,0 +1,121 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseGoCoverProfile parses the output of `go test -coverprofile`.
+//
+// Each line describes a block: `name.go:startLine.startCol,endLine.endCol numStmts count`.
+// A line is covered when any block containing it is executed, so the max count
+// of the blocks is used as hits. Profiles concatenated from several runs are
+// allowed, counts of the same block are summed up.
+func ParseGoCoverProfile(r io.Reader) (*Report, error) {
+	type block struct {
+		name       string
+		start, end int
+		count      int
+	}
+	var (
+		blocks []*block
+		// 多次运行合并的 profile 中相同的块执行次数累加
+		index = map[string]*block{}
+	)
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		name, start, end, count, err := parseGoCoverBlock(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", lineNo)
+		}
+		key := line[:strings.LastIndex(line, " ")]
+		if b, ok := index[key]; ok {
+			b.count += count
+			continue
+		}
+		b := &block{name: name, start: start, end: end, count: count}
+		index[key] = b
+		blocks = append(blocks, b)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read go cover profile")
+	}
+
+	files := newFileSet()
+	for _, b := range blocks {
+		f := files.get(b.name, path.Dir(b.name))
+		// 同一行可能属于多个块，取执行次数最大值
+		for l := b.start; l <= b.end; l++ {
+			if old, ok := f.Lines[l]; !ok || b.count > old {
+				f.Lines[l] = b.count
+			}
+		}
+	}
+	return files.report, nil
+}
+
+// parseGoCoverBlock parses `name.go:10.13,12.2 2 1`.
+func parseGoCoverBlock(line string) (name string, start, end, count int, err error) {
+	colon := strings.LastIndex(line, ":")
+	if colon <= 0 {
+		return "", 0, 0, 0, fmt.Errorf("invalid cover profile block: %s", line)
+	}
+	name = line[:colon]
+	fields := strings.Fields(line[colon+1:])
+	if len(fields) != 3 {
+		return "", 0, 0, 0, fmt.Errorf("invalid cover profile block: %s", line)
+	}
+	positions := strings.SplitN(fields[0], ",", 2)
+	if len(positions) != 2 {
+		return "", 0, 0, 0, fmt.Errorf("invalid cover profile block: %s", line)
+	}
+	if start, err = parseGoCoverLine(positions[0]); err != nil {
+		return "", 0, 0, 0, err
+	}
+	if end, err = parseGoCoverLine(positions[1]); err != nil {
+		return "", 0, 0, 0, err
+	}
+	if count, err = strconv.Atoi(fields[2]); err != nil {
+		return "", 0, 0, 0, fmt.Errorf("invalid count: %s", fields[2])
+	}
+	if end < start {
+		return "", 0, 0, 0, fmt.Errorf("invalid block range: %s", fields[0])
+	}
+	return name, start, end, count, nil
+}
+
+// parseGoCoverLine parses `line.col` and returns the line.
+func parseGoCoverLine(pos string) (int, error) {
+	l, err := strconv.Atoi(strings.SplitN(pos, ".", 2)[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid position: %s", pos)
+	}
+	return l, nil
+}
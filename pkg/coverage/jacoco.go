@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverage
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// jacocoGroup is both the root <report> and the nested <group> of multi-module reports.
+type jacocoGroup struct {
+	Groups   []jacocoGroup   `xml:"group"`
+	Packages []jacocoPackage `xml:"package"`
+}
+
+type jacocoPackage struct {
+	Name        string             `xml:"name,attr"`
+	SourceFiles []jacocoSourceFile `xml:"sourcefile"`
+}
+
+type jacocoSourceFile struct {
+	Name  string       `xml:"name,attr"`
+	Lines []jacocoLine `xml:"line"`
+}
+
+// jacocoLine mi/ci: missed/covered instructions of the line.
+type jacocoLine struct {
+	Nr int `xml:"nr,attr"`
+	MI int `xml:"mi,attr"`
+	CI int `xml:"ci,attr"`
+}
+
+// ParseJaCoCo parses a jacoco xml report.
+// JaCoCo has no hit count, a line is covered when any of its instructions is covered,
+// so the number of covered instructions is used as hits.
+func ParseJaCoCo(r io.Reader) (*Report, error) {
+	var report jacocoGroup
+	decoder := xml.NewDecoder(r)
+	// jacoco report declares a DOCTYPE with an external dtd, which is not needed
+	decoder.Strict = false
+	if err := decoder.Decode(&report); err != nil {
+		return nil, errors.Wrap(err, "decode jacoco report")
+	}
+	files := newFileSet()
+	walkJaCoCoGroup(report, files)
+	return files.report, nil
+}
+
+func walkJaCoCoGroup(group jacocoGroup, files *fileSet) {
+	for _, g := range group.Groups {
+		walkJaCoCoGroup(g, files)
+	}
+	for _, pkg := range group.Packages {
+		for _, source := range pkg.SourceFiles {
+			filePath := source.Name
+			if pkg.Name != "" {
+				filePath = pkg.Name + "/" + source.Name
+			}
+			f := files.get(filePath, strings.ReplaceAll(pkg.Name, "/", "."))
+			for _, line := range source.Lines {
+				if line.MI+line.CI == 0 {
+					continue
+				}
+				f.addLine(line.Nr, line.CI)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xpath evaluates XPath 1.0 expressions against xml documents,
+// see also https://github.com/antchfx/xmlquery.
+package xpath
+
+import (
+	"bytes"
+	"math"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	antxpath "github.com/antchfx/xpath"
+	"github.com/pkg/errors"
+)
+
+// Get evaluates the expression against the xml document.
+//
+// Node-sets return the string-value of the matched node when only one node matches,
+// and a []interface{} of string-values when there are more. An error is returned when
+// nothing matches. Numbers, e.g. the result of count(), are returned as int when they
+// are integral, otherwise float64. Strings and booleans are returned as is.
+func Get(data []byte, expr string) (interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, errors.New("empty xpath expression")
+	}
+	e, err := antxpath.Compile(expr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid xpath expression: %s", expr)
+	}
+	doc, err := xmlquery.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid xml")
+	}
+
+	switch v := e.Evaluate(xmlquery.CreateXPathNavigator(doc)).(type) {
+	case *antxpath.NodeIterator:
+		var values []interface{}
+		for v.MoveNext() {
+			values = append(values, strings.TrimSpace(v.Current().Value()))
+		}
+		switch len(values) {
+		case 0:
+			return nil, errors.Errorf("no node matched: %s", expr)
+		case 1:
+			return values[0], nil
+		}
+		return values, nil
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return int(v), nil
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+const soap = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:m="http://example.com/orders">
+  <soap:Header/>
+  <soap:Body>
+    <m:GetOrdersResponse>
+      <m:Order id="1" status="paid">
+        <m:Name>apple</m:Name>
+        <m:Price>1.5</m:Price>
+      </m:Order>
+      <m:Order id="2" status="unpaid">
+        <m:Name><![CDATA[banana & co]]></m:Name>
+        <m:Price>2</m:Price>
+      </m:Order>
+      <m:Order id="3" status="paid">
+        <m:Name>cherry</m:Name>
+        <m:Price>3</m:Price>
+      </m:Order>
+    </m:GetOrdersResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestGet(t *testing.T) {
+	cases := []struct {
+		expr string
+		want interface{}
+	}{
+		{"/soap:Envelope/soap:Body/m:GetOrdersResponse/m:Order[1]/m:Name", "apple"},
+		{"/*[local-name()='Envelope']/*[local-name()='Body']//*[local-name()='Order'][2]/*[local-name()='Name']", "banana & co"},
+		{"soap:Envelope/soap:Body/m:GetOrdersResponse/m:Order[last()]/@id", "3"},
+		{"//m:Order[last()-1]/@status", "unpaid"},
+		{"//m:Order[@id='3']/m:Name/text()", "cherry"},
+		{"//m:Order[m:Name=\"apple\"]/m:Price", "1.5"},
+		{"//m:Order[@status!='paid']/@id", "2"},
+		{"//m:Order[@status='paid']/@id", []interface{}{"1", "3"}},
+		{"//m:Order[@status='paid'][2]/m:Name", "cherry"},
+		{"//m:Price[.='2']/../@id", "2"},
+		{"//m:Order[contains(m:Name, 'an')]/@id", "2"},
+		{"//m:Order[1]/@*", []interface{}{"1", "paid"}},
+		{"count(//m:Order)", 3},
+		{"count(//m:Order[@status='paid'])", 2},
+		{"count(//Missing)", 0},
+		{"sum(//m:Price)", 6.5},
+		{"string(//m:Order[2]/@status)", "unpaid"},
+		{"//m:Order[1]/m:Price > 1", true},
+		{"//*[@id='1']/*[2]", "1.5"},
+	}
+	for _, c := range cases {
+		got, err := Get([]byte(soap), c.expr)
+		if err != nil {
+			t.Errorf("%s: %v", c.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: want %#v, got %#v", c.expr, c.want, got)
+		}
+	}
+}
+
+func TestGetError(t *testing.T) {
+	for _, expr := range []string{"", "//Missing", "//Order[", "//Order/", "//Order[unknown(Name)]"} {
+		if _, err := Get([]byte(soap), expr); err == nil {
+			t.Errorf("%q: want error", expr)
+		}
+	}
+	if _, err := Get([]byte("not xml"), "/a"); err == nil {
+		t.Errorf("want error for invalid xml")
+	}
+}
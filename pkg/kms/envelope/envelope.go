@@ -0,0 +1,122 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package envelope seals small secrets with KMS envelope encryption:
+// the secret is encrypted locally by a DEK, and only the DEK ciphertext (encrypted by CMK) is stored with it.
+package envelope
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/erda-project/erda/pkg/kms/kmscrypto"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// Prefix is the prefix of a sealed value.
+// format: kms:v1:<keyID>:<DEKCiphertextBase64>:<CiphertextBase64>
+const Prefix = "kms:v1:"
+
+// DataKeyService is the subset of KMS used by envelope encryption.
+type DataKeyService interface {
+	GenerateDataKey(req *kmstypes.GenerateDataKeyRequest) (*kmstypes.GenerateDataKeyResponse, error)
+	Decrypt(req *kmstypes.DecryptRequest) (*kmstypes.DecryptResponse, error)
+}
+
+// Sealed is a parsed sealed value.
+type Sealed struct {
+	KeyID               string
+	DEKCiphertextBase64 string
+	CiphertextBase64    string
+}
+
+// String returns the text form of sealed value.
+func (s Sealed) String() string {
+	return Prefix + strings.Join([]string{s.KeyID, s.DEKCiphertextBase64, s.CiphertextBase64}, ":")
+}
+
+// IsSealed returns whether s looks like a sealed value.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// Parse parses the text form of sealed value.
+func Parse(s string) (*Sealed, error) {
+	if !IsSealed(s) {
+		return nil, fmt.Errorf("not a sealed value, missing prefix %q", Prefix)
+	}
+	parts := strings.Split(strings.TrimPrefix(s, Prefix), ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid sealed value, want 3 parts after prefix, got %d", len(parts))
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid sealed value, empty part")
+		}
+	}
+	return &Sealed{KeyID: parts[0], DEKCiphertextBase64: parts[1], CiphertextBase64: parts[2]}, nil
+}
+
+// Seal encrypts plaintext under CMK keyID and returns the text form of sealed value.
+func Seal(svc DataKeyService, keyID string, plaintext []byte) (string, error) {
+	dekResp, err := svc.GenerateDataKey(&kmstypes.GenerateDataKeyRequest{KeyID: keyID})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate data key, err: %v", err)
+	}
+	dek, err := base64.StdEncoding.DecodeString(dekResp.PlaintextBase64)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode data key, err: %v", err)
+	}
+	// keyID 作为 additionalData，防止密文被挪用到其他 CMK 的引用下
+	ciphertext, err := kmscrypto.AesGcmEncrypt(dek, plaintext, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt, err: %v", err)
+	}
+	return Sealed{
+		KeyID:               keyID,
+		DEKCiphertextBase64: dekResp.CiphertextBase64,
+		CiphertextBase64:    base64.StdEncoding.EncodeToString(ciphertext),
+	}.String(), nil
+}
+
+// Open decrypts the text form of sealed value.
+func Open(svc DataKeyService, sealed string) ([]byte, error) {
+	s, err := Parse(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return s.Open(svc)
+}
+
+// Open decrypts the sealed value.
+func (s *Sealed) Open(svc DataKeyService) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(s.CiphertextBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ciphertext, err: %v", err)
+	}
+	dekResp, err := svc.Decrypt(&kmstypes.DecryptRequest{KeyID: s.KeyID, CiphertextBase64: s.DEKCiphertextBase64})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key, err: %v", err)
+	}
+	dek, err := base64.StdEncoding.DecodeString(dekResp.PlaintextBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode data key, err: %v", err)
+	}
+	plaintext, err := kmscrypto.AesGcmDecrypt(dek, ciphertext, []byte(s.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt, err: %v", err)
+	}
+	return plaintext, nil
+}
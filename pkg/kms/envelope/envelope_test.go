@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/kmscrypto"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// fakeKMS encrypts DEK by one in-memory CMK per keyID
+type fakeKMS struct {
+	cmks map[string][]byte
+}
+
+func (f *fakeKMS) GenerateDataKey(req *kmstypes.GenerateDataKeyRequest) (*kmstypes.GenerateDataKeyResponse, error) {
+	cmk, ok := f.cmks[req.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("key not found")
+	}
+	dek, err := kmscrypto.GenerateAes256Key()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := kmscrypto.AesGcmEncrypt(cmk, dek, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &kmstypes.GenerateDataKeyResponse{
+		KeyID:            req.KeyID,
+		CiphertextBase64: base64.StdEncoding.EncodeToString(ciphertext),
+		PlaintextBase64:  base64.StdEncoding.EncodeToString(dek),
+	}, nil
+}
+
+func (f *fakeKMS) Decrypt(req *kmstypes.DecryptRequest) (*kmstypes.DecryptResponse, error) {
+	cmk, ok := f.cmks[req.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("key not found")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(req.CiphertextBase64)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := kmscrypto.AesGcmDecrypt(cmk, ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &kmstypes.DecryptResponse{PlaintextBase64: base64.StdEncoding.EncodeToString(dek)}, nil
+}
+
+func newFakeKMS(t *testing.T, keyIDs ...string) *fakeKMS {
+	f := &fakeKMS{cmks: make(map[string][]byte)}
+	for _, keyID := range keyIDs {
+		cmk, err := kmscrypto.GenerateAes256Key()
+		assert.NoError(t, err)
+		f.cmks[keyID] = cmk
+	}
+	return f
+}
+
+func TestSealOpen(t *testing.T) {
+	svc := newFakeKMS(t, "key-a", "key-b")
+
+	sealed, err := Seal(svc, "key-a", []byte("p@ssw0rd"))
+	assert.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+
+	s, err := Parse(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "key-a", s.KeyID)
+	assert.Equal(t, sealed, s.String())
+
+	plaintext, err := Open(svc, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "p@ssw0rd", string(plaintext))
+
+	// 密文不能挪用到其他 CMK 下
+	s.KeyID = "key-b"
+	_, err = s.Open(svc)
+	assert.Error(t, err)
+
+	_, err = Seal(svc, "key-not-exist", []byte("p@ssw0rd"))
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		sealed string
+		valid  bool
+	}{
+		{sealed: "kms:v1:key:ZGVr:Y2lwaGVy", valid: true},
+		{sealed: "plain", valid: false},
+		{sealed: "kms:v1:key:ZGVr", valid: false},
+		{sealed: "kms:v1:key::Y2lwaGVy", valid: false},
+		{sealed: "kms:v1:key:ZGVr:Y2lwaGVy:extra", valid: false},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.sealed)
+		assert.Equal(t, c.valid, err == nil, c.sealed)
+	}
+}
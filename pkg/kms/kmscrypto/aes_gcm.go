@@ -121,10 +121,16 @@ func PrefixAppend000Length(b []byte) ([]byte, error) {
 // PrefixUnAppend000Length
 // 000[]byte(b)[]byte(a) -> []byte(b), []byte(a)
 func PrefixUnAppend000Length(b []byte) (under, remains []byte, err error) {
+	if len(b) < 3 {
+		return nil, nil, fmt.Errorf("byte length too short")
+	}
 	// parse keyVersion
 	prefixLen, err := strconv.ParseInt(string(b[:3]), 10, 64)
 	if err != nil {
 		return nil, nil, err
 	}
+	if prefixLen < 0 || int64(len(b)) < 3+prefixLen {
+		return nil, nil, fmt.Errorf("invalid length prefix: %d", prefixLen)
+	}
 	return b[3 : 3+prefixLen], b[3+prefixLen:], nil
 }
@@ -52,3 +52,10 @@ func TestWrapWithLenPrefix(t *testing.T) {
 	assert.Equal(t, string(b), string(under))
 	assert.Equal(t, 0, len(remains))
 }
+
+func TestPrefixUnAppend000LengthInvalid(t *testing.T) {
+	for _, b := range []string{"", "01", "abc", "010hello", "-01"} {
+		_, _, err := PrefixUnAppend000Length([]byte(b))
+		assert.Error(t, err, b)
+	}
+}
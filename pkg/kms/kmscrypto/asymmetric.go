@@ -0,0 +1,137 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // register SHA-384 and SHA-512
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// GenerateRsaKey generate rsa private key with the given bits.
+func GenerateRsaKey(bits int) (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, bits)
+}
+
+// GenerateEcdsaKey generate ecdsa private key on the given curve.
+func GenerateEcdsaKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(curve, rand.Reader)
+}
+
+// MarshalPrivateKeyPem encode private key as PKCS #8, ASN.1 DER form in PEM.
+func MarshalPrivateKeyPem(key crypto.PrivateKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParsePrivateKeyPem parse PKCS #8 private key encoded by MarshalPrivateKeyPem.
+func ParsePrivateKeyPem(pemStr string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key pem")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type: %T", key)
+	}
+	return signer, nil
+}
+
+// MarshalPublicKeyPem encode public key as PKIX, ASN.1 DER form in PEM.
+func MarshalPublicKeyPem(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ParsePublicKeyPem parse PKIX public key encoded by MarshalPublicKeyPem.
+func ParsePublicKeyPem(pemStr string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("invalid public key pem")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// RsaOaepSha256Encrypt encrypt plaintext with RSAES-OAEP, SHA-256 is used as hash function and MGF1.
+func RsaOaepSha256Encrypt(key *rsa.PublicKey, plaintext []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, key, plaintext, nil)
+}
+
+// RsaOaepSha256Decrypt decrypt ciphertext encrypted by RsaOaepSha256Encrypt.
+func RsaOaepSha256Decrypt(key *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext, nil)
+}
+
+// Digest hash the message by hash.
+func Digest(hash crypto.Hash, message []byte) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("unavailable hash function: %v", hash)
+	}
+	h := hash.New()
+	h.Write(message)
+	return h.Sum(nil), nil
+}
+
+// SignDigest sign the digest hashed by hash.
+// For rsa key, pss determines whether to use RSASSA-PSS or RSASSA-PKCS1-v1_5;
+// for ecdsa key, the signature is ASN.1 DER encoded.
+func SignDigest(key crypto.Signer, hash crypto.Hash, pss bool, digest []byte) ([]byte, error) {
+	if len(digest) != hash.Size() {
+		return nil, fmt.Errorf("invalid digest length: %d, expect: %d", len(digest), hash.Size())
+	}
+	var opts crypto.SignerOpts = hash
+	if _, ok := key.(*rsa.PrivateKey); ok && pss {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	}
+	return key.Sign(rand.Reader, digest, opts)
+}
+
+// VerifyDigest verify the signature generated by SignDigest, return nil if valid.
+func VerifyDigest(key crypto.PublicKey, hash crypto.Hash, pss bool, digest, signature []byte) error {
+	if len(digest) != hash.Size() {
+		return fmt.Errorf("invalid digest length: %d, expect: %d", len(digest), hash.Size())
+	}
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if pss {
+			return rsa.VerifyPSS(pub, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash})
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			return fmt.Errorf("ecdsa: verification error")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type: %T", key)
+	}
+}
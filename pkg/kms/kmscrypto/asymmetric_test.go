@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmscrypto
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRsaOaep(t *testing.T) {
+	key, err := GenerateRsaKey(2048)
+	assert.NoError(t, err)
+
+	// pem round trip
+	privPem, err := MarshalPrivateKeyPem(key)
+	assert.NoError(t, err)
+	signer, err := ParsePrivateKeyPem(privPem)
+	assert.NoError(t, err)
+	pubPem, err := MarshalPublicKeyPem(signer.Public())
+	assert.NoError(t, err)
+	pub, err := ParsePublicKeyPem(pubPem)
+	assert.NoError(t, err)
+	assert.Equal(t, &key.PublicKey, pub)
+
+	plaintext := []byte("hello world")
+	ciphertext, err := RsaOaepSha256Encrypt(&key.PublicKey, plaintext)
+	assert.NoError(t, err)
+	decrypted, err := RsaOaepSha256Decrypt(key, ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestSignDigest(t *testing.T) {
+	rsaKey, err := GenerateRsaKey(2048)
+	assert.NoError(t, err)
+	ecKey, err := GenerateEcdsaKey(elliptic.P384())
+	assert.NoError(t, err)
+
+	sha256Digest := sha256.Sum256([]byte("hello world"))
+	sha384Digest := sha512.Sum384([]byte("hello world"))
+	cases := []struct {
+		name   string
+		key    crypto.Signer
+		hash   crypto.Hash
+		pss    bool
+		digest []byte
+	}{
+		{"rsa pss", rsaKey, crypto.SHA256, true, sha256Digest[:]},
+		{"rsa pkcs1", rsaKey, crypto.SHA256, false, sha256Digest[:]},
+		{"ecdsa", ecKey, crypto.SHA384, false, sha384Digest[:]},
+	}
+	for _, c := range cases {
+		signature, err := SignDigest(c.key, c.hash, c.pss, c.digest)
+		assert.NoError(t, err, c.name)
+		assert.NoError(t, VerifyDigest(c.key.Public(), c.hash, c.pss, c.digest, signature), c.name)
+
+		// tampered digest
+		tampered := append([]byte{}, c.digest...)
+		tampered[0] ^= 0xff
+		assert.Error(t, VerifyDigest(c.key.Public(), c.hash, c.pss, tampered, signature), c.name)
+	}
+
+	// pss signature can not be verified as pkcs1
+	signature, err := SignDigest(rsaKey, crypto.SHA256, true, sha256Digest[:])
+	assert.NoError(t, err)
+	assert.Error(t, VerifyDigest(&rsaKey.PublicKey, crypto.SHA256, false, sha256Digest[:], signature))
+
+	// digest length mismatch
+	_, err = SignDigest(ecKey, crypto.SHA384, false, sha256Digest[:])
+	assert.Error(t, err)
+}
@@ -14,19 +14,187 @@
 
 package kmstypes
 
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+type (
+	EncryptionAlgorithm string
+	SigningAlgorithm    string
+	MessageType         string
+)
+
+// EncryptionAlgorithms 返回 key spec 支持的非对称加密算法
+func (s CustomerMasterKeySpec) EncryptionAlgorithms() []EncryptionAlgorithm {
+	switch s {
+	case CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, CustomerMasterKeySpec_ASYMMETRIC_RSA_3072, CustomerMasterKeySpec_ASYMMETRIC_RSA_4096:
+		return []EncryptionAlgorithm{EncryptionAlgorithm_RSAES_OAEP_SHA_256}
+	}
+	return nil
+}
+
+// SigningAlgorithms 返回 key spec 支持的签名算法
+func (s CustomerMasterKeySpec) SigningAlgorithms() []SigningAlgorithm {
+	switch s {
+	case CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, CustomerMasterKeySpec_ASYMMETRIC_RSA_3072, CustomerMasterKeySpec_ASYMMETRIC_RSA_4096:
+		return []SigningAlgorithm{
+			SigningAlgorithm_RSASSA_PSS_SHA_256, SigningAlgorithm_RSASSA_PSS_SHA_384, SigningAlgorithm_RSASSA_PSS_SHA_512,
+			SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_256, SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_384, SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_512,
+		}
+	case CustomerMasterKeySpec_ASYMMETRIC_EC_P256:
+		return []SigningAlgorithm{SigningAlgorithm_ECDSA_SHA_256}
+	case CustomerMasterKeySpec_ASYMMETRIC_EC_P384:
+		return []SigningAlgorithm{SigningAlgorithm_ECDSA_SHA_384}
+	}
+	return nil
+}
+
+// IsAsymmetric 是否为非对称密钥
+func (s CustomerMasterKeySpec) IsAsymmetric() bool {
+	return len(s.SigningAlgorithms()) > 0
+}
+
 type AsymmetricDecryptRequest struct {
-	KeyID            string `json:"keyID,omitempty"`
-	CiphertextBase64 []byte `json:"ciphertextBase64,omitempty"`
+	KeyID string `json:"keyID,omitempty"`
+	// Optional. Default is the primary key version.
+	KeyVersionID string `json:"keyVersionID,omitempty"`
+	// Optional. Default is RSAES_OAEP_SHA_256.
+	EncryptionAlgorithm EncryptionAlgorithm `json:"encryptionAlgorithm,omitempty"`
+	// Required. The data encrypted by the public key.
+	// A base64-encoded string.
+	CiphertextBase64 string `json:"ciphertextBase64,omitempty"`
+}
+
+func (req *AsymmetricDecryptRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if req.EncryptionAlgorithm == "" {
+		req.EncryptionAlgorithm = EncryptionAlgorithm_RSAES_OAEP_SHA_256
+	}
+	if len(req.CiphertextBase64) == 0 {
+		return fmt.Errorf("missing ciphertextBase64")
+	}
+	if _, err := base64.StdEncoding.DecodeString(req.CiphertextBase64); err != nil {
+		return fmt.Errorf("cannot decode base64 ciphertext, err: %v", err)
+	}
+	return nil
 }
+
 type AsymmetricDecryptResponse struct {
-	PlaintextBase64 []byte `json:"plaintextBase64,omitempty"`
+	KeyID           string `json:"keyID,omitempty"`
+	KeyVersionID    string `json:"keyVersionID,omitempty"`
+	PlaintextBase64 string `json:"plaintextBase64,omitempty"`
 }
 
 type GetPublicKeyRequest struct {
 	KeyID string `json:"keyID,omitempty"`
+	// Optional. Default is the primary key version.
+	KeyVersionID string `json:"keyVersionID,omitempty"`
+}
+
+func (req *GetPublicKeyRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	return nil
 }
 
 type PublicKey struct {
-	Pem       string `json:"pem,omitempty"`
-	Algorithm string `json:"algorithm,omitempty"`
+	KeyID                 string                `json:"keyID,omitempty"`
+	KeyVersionID          string                `json:"keyVersionID,omitempty"`
+	CustomerMasterKeySpec CustomerMasterKeySpec `json:"customerMasterKeySpec,omitempty"`
+	KeyUsage              KeyUsage              `json:"keyUsage,omitempty"`
+	// PKIX, ASN.1 DER form public key in PEM encoding
+	Pem string `json:"pem,omitempty"`
+	// only for ENCRYPT_DECRYPT keys
+	EncryptionAlgorithms []EncryptionAlgorithm `json:"encryptionAlgorithms,omitempty"`
+	// only for SIGN_VERIFY keys
+	SigningAlgorithms []SigningAlgorithm `json:"signingAlgorithms,omitempty"`
+}
+
+// MaxRawMessageLength is the max length of RAW message to sign or verify, use DIGEST for larger messages.
+const MaxRawMessageLength = 4096
+
+type SignRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// Optional. Default is the primary key version.
+	KeyVersionID     string           `json:"keyVersionID,omitempty"`
+	SigningAlgorithm SigningAlgorithm `json:"signingAlgorithm,omitempty"`
+	// Optional. RAW or DIGEST, default is RAW.
+	// RAW message is hashed by the hash function of the signing algorithm, must be no larger than 4KiB;
+	// DIGEST message is already hashed by the same hash function.
+	MessageType MessageType `json:"messageType,omitempty"`
+	// A base64-encoded string.
+	MessageBase64 string `json:"messageBase64,omitempty"`
+}
+
+func (req *SignRequest) ValidateRequest() error {
+	return validateSignMessage(req.KeyID, req.SigningAlgorithm, &req.MessageType, req.MessageBase64)
+}
+
+type SignResponse struct {
+	KeyID            string           `json:"keyID,omitempty"`
+	KeyVersionID     string           `json:"keyVersionID,omitempty"`
+	SigningAlgorithm SigningAlgorithm `json:"signingAlgorithm,omitempty"`
+	// A base64-encoded string.
+	SignatureBase64 string `json:"signatureBase64,omitempty"`
+}
+
+type VerifyRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// Optional. Default is the primary key version, should be the version used to sign.
+	KeyVersionID     string           `json:"keyVersionID,omitempty"`
+	SigningAlgorithm SigningAlgorithm `json:"signingAlgorithm,omitempty"`
+	MessageType      MessageType      `json:"messageType,omitempty"`
+	MessageBase64    string           `json:"messageBase64,omitempty"`
+	SignatureBase64  string           `json:"signatureBase64,omitempty"`
+}
+
+func (req *VerifyRequest) ValidateRequest() error {
+	if err := validateSignMessage(req.KeyID, req.SigningAlgorithm, &req.MessageType, req.MessageBase64); err != nil {
+		return err
+	}
+	if len(req.SignatureBase64) == 0 {
+		return fmt.Errorf("missing signatureBase64")
+	}
+	if _, err := base64.StdEncoding.DecodeString(req.SignatureBase64); err != nil {
+		return fmt.Errorf("cannot decode base64 signature, err: %v", err)
+	}
+	return nil
+}
+
+type VerifyResponse struct {
+	KeyID            string           `json:"keyID,omitempty"`
+	KeyVersionID     string           `json:"keyVersionID,omitempty"`
+	SigningAlgorithm SigningAlgorithm `json:"signingAlgorithm,omitempty"`
+	SignatureValid   bool             `json:"signatureValid"`
+}
+
+func validateSignMessage(keyID string, algorithm SigningAlgorithm, messageType *MessageType, messageBase64 string) error {
+	if keyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if algorithm == "" {
+		return fmt.Errorf("missing signingAlgorithm")
+	}
+	switch *messageType {
+	case "":
+		*messageType = MessageType_RAW
+	case MessageType_RAW, MessageType_DIGEST:
+	default:
+		return fmt.Errorf("invalid messageType: %s", *messageType)
+	}
+	if len(messageBase64) == 0 {
+		return fmt.Errorf("missing messageBase64")
+	}
+	message, err := base64.StdEncoding.DecodeString(messageBase64)
+	if err != nil {
+		return fmt.Errorf("cannot decode base64 message, err: %v", err)
+	}
+	if *messageType == MessageType_RAW && len(message) > MaxRawMessageLength {
+		return fmt.Errorf("raw message is larger than %d bytes, use DIGEST instead", MaxRawMessageLength)
+	}
+	return nil
 }
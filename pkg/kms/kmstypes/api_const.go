@@ -34,6 +34,8 @@ const (
 	CustomerMasterKeySpec_ASYMMETRIC_RSA_2048 CustomerMasterKeySpec = "RSA_2048"
 	CustomerMasterKeySpec_ASYMMETRIC_RSA_3072 CustomerMasterKeySpec = "RSA_3072"
 	CustomerMasterKeySpec_ASYMMETRIC_RSA_4096 CustomerMasterKeySpec = "RSA_4096"
+	CustomerMasterKeySpec_ASYMMETRIC_EC_P256  CustomerMasterKeySpec = "EC_P256"
+	CustomerMasterKeySpec_ASYMMETRIC_EC_P384  CustomerMasterKeySpec = "EC_P384"
 
 	KeyUsage_ENCRYPT_DECRYPT KeyUsage = "ENCRYPT_DECRYPT"
 	KeyUsage_SIGN_VERIFY     KeyUsage = "SIGN_VERIFY"
@@ -43,4 +45,18 @@ const (
 	KeyStatePendingDeletion KeyState = "PendingDeletion"
 	KeyStatePendingImport   KeyState = "PendingImport"
 	KeyStateUnavailable     KeyState = "Unavailable"
+
+	EncryptionAlgorithm_RSAES_OAEP_SHA_256 EncryptionAlgorithm = "RSAES_OAEP_SHA_256"
+
+	SigningAlgorithm_RSASSA_PSS_SHA_256        SigningAlgorithm = "RSASSA_PSS_SHA_256"
+	SigningAlgorithm_RSASSA_PSS_SHA_384        SigningAlgorithm = "RSASSA_PSS_SHA_384"
+	SigningAlgorithm_RSASSA_PSS_SHA_512        SigningAlgorithm = "RSASSA_PSS_SHA_512"
+	SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_256 SigningAlgorithm = "RSASSA_PKCS1_V1_5_SHA_256"
+	SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_384 SigningAlgorithm = "RSASSA_PKCS1_V1_5_SHA_384"
+	SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_512 SigningAlgorithm = "RSASSA_PKCS1_V1_5_SHA_512"
+	SigningAlgorithm_ECDSA_SHA_256             SigningAlgorithm = "ECDSA_SHA_256"
+	SigningAlgorithm_ECDSA_SHA_384             SigningAlgorithm = "ECDSA_SHA_384"
+
+	MessageType_RAW    MessageType = "RAW"
+	MessageType_DIGEST MessageType = "DIGEST"
 )
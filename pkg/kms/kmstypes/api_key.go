@@ -30,6 +30,7 @@ type (
 		KeyUsage              KeyUsage              `json:"keyUsage,omitempty"`
 		KeyState              KeyState              `json:"keyState,omitempty"`
 		Description           string                `json:"description,omitempty"`
+		RotationPolicy        *RotationPolicy       `json:"rotationPolicy,omitempty"`
 	}
 
 	KeyListEntry struct {
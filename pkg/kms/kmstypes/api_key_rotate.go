@@ -14,7 +14,10 @@
 
 package kmstypes
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type RotateKeyVersionRequest struct {
 	KeyID string `json:"keyID,omitempty"`
@@ -30,3 +33,55 @@ func (req *RotateKeyVersionRequest) ValidateRequest() error {
 type RotateKeyVersionResponse struct {
 	KeyMetadata KeyMetadata `json:"keyMetadata,omitempty"`
 }
+
+const (
+	MinRotationIntervalDays = 7
+	MaxRotationIntervalDays = 730
+)
+
+// RotationPolicy 用户主密钥的自动轮转策略, 仅支持对称密钥
+type RotationPolicy struct {
+	// 自动轮转周期, 单位: 天
+	RotationIntervalDays int `json:"rotationIntervalDays,omitempty"`
+	// 下次自动轮转时间, 每次轮转 (包括手动轮转) 后根据轮转周期更新
+	NextRotationTime *time.Time `json:"nextRotationTime,omitempty"`
+}
+
+// RotationInterval return the interval as time.Duration.
+func (p *RotationPolicy) RotationInterval() time.Duration {
+	return time.Duration(p.RotationIntervalDays) * 24 * time.Hour
+}
+
+// IsDue return true if the key should be rotated automatically at the given time.
+func (p *RotationPolicy) IsDue(now time.Time) bool {
+	if p == nil || p.RotationIntervalDays <= 0 || p.NextRotationTime == nil {
+		return false
+	}
+	return !now.Before(*p.NextRotationTime)
+}
+
+type UpdateRotationPolicyRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// false to disable automatic rotation
+	EnableAutomaticRotation bool `json:"enableAutomaticRotation"`
+	// Required if EnableAutomaticRotation is true, between 7 and 730.
+	RotationIntervalDays int `json:"rotationIntervalDays,omitempty"`
+}
+
+func (req *UpdateRotationPolicyRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if !req.EnableAutomaticRotation {
+		return nil
+	}
+	if req.RotationIntervalDays < MinRotationIntervalDays || req.RotationIntervalDays > MaxRotationIntervalDays {
+		return fmt.Errorf("invalid rotationIntervalDays: %d, should be between %d and %d",
+			req.RotationIntervalDays, MinRotationIntervalDays, MaxRotationIntervalDays)
+	}
+	return nil
+}
+
+type UpdateRotationPolicyResponse struct {
+	KeyMetadata KeyMetadata `json:"keyMetadata,omitempty"`
+}
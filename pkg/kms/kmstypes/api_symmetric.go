@@ -69,3 +69,69 @@ func (req *DecryptRequest) ValidateRequest() error {
 type DecryptResponse struct {
 	PlaintextBase64 string `json:"plaintextBase64,omitempty"`
 }
+
+type SealRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// Required. The secret to seal by envelope encryption.
+	// A base64-encoded string.
+	PlaintextBase64 string `json:"plaintextBase64,omitempty"`
+}
+
+func (req *SealRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if len(req.PlaintextBase64) == 0 {
+		return fmt.Errorf("missing plaintextBase64")
+	}
+	if _, err := base64.StdEncoding.DecodeString(req.PlaintextBase64); err != nil {
+		return fmt.Errorf("cannot decode base64 plaintext, err: %v", err)
+	}
+	return nil
+}
+
+type SealResponse struct {
+	KeyID string `json:"keyID,omitempty"`
+	// The text form of sealed value, can be referenced by secret://<keyID>/<name> in dice.yml
+	// after saved into config-center as <name>.
+	Sealed string `json:"sealed,omitempty"`
+}
+
+// MaxReEncryptCiphertexts is the max count of ciphertexts in one ReEncrypt request.
+const MaxReEncryptCiphertexts = 100
+
+type ReEncryptRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// Required. The data encrypted by Encrypt under any key version of the CMK.
+	// Base64-encoded strings.
+	CiphertextBase64List []string `json:"ciphertextBase64List,omitempty"`
+}
+
+func (req *ReEncryptRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if len(req.CiphertextBase64List) == 0 {
+		return fmt.Errorf("missing ciphertextBase64List")
+	}
+	if len(req.CiphertextBase64List) > MaxReEncryptCiphertexts {
+		return fmt.Errorf("too many ciphertexts: %d, max: %d", len(req.CiphertextBase64List), MaxReEncryptCiphertexts)
+	}
+	for i, ciphertext := range req.CiphertextBase64List {
+		if len(ciphertext) == 0 {
+			return fmt.Errorf("missing ciphertextBase64List[%d]", i)
+		}
+		if _, err := base64.StdEncoding.DecodeString(ciphertext); err != nil {
+			return fmt.Errorf("cannot decode base64 ciphertextBase64List[%d], err: %v", i, err)
+		}
+	}
+	return nil
+}
+
+type ReEncryptResponse struct {
+	KeyID string `json:"keyID,omitempty"`
+	// the primary key version that ciphertexts are encrypted under now
+	KeyVersionID string `json:"keyVersionID,omitempty"`
+	// in the same order as the request, ciphertext already under the primary key version is returned as it is
+	CiphertextBase64List []string `json:"ciphertextBase64List,omitempty"`
+}
@@ -41,6 +41,9 @@ type KeyInfo interface {
 	GetDescription() string
 	SetDescription(string)
 
+	GetRotationPolicy() *RotationPolicy
+	SetRotationPolicy(*RotationPolicy)
+
 	GetCreatedAt() *time.Time
 	SetCreatedAt(time.Time)
 	GetUpdatedAt() *time.Time
@@ -55,6 +58,7 @@ func GetKeyMetadata(keyInfo KeyInfo) KeyMetadata {
 		KeyUsage:              keyInfo.GetKeyUsage(),
 		KeyState:              keyInfo.GetKeyState(),
 		Description:           keyInfo.GetDescription(),
+		RotationPolicy:        keyInfo.GetRotationPolicy(),
 	}
 }
 
@@ -67,6 +71,11 @@ type KeyVersionInfo interface {
 	GetSymmetricKeyBase64() string
 	SetSymmetricKeyBase64(string)
 
+	GetPrivateKeyPem() string
+	SetPrivateKeyPem(string)
+	GetPublicKeyPem() string
+	SetPublicKeyPem(string)
+
 	GetCreatedAt() *time.Time
 	SetCreatedAt(time.Time)
 
@@ -104,6 +113,7 @@ type Key struct {
 	KeyUsage          KeyUsage              `json:"keyUsage,omitempty"`
 	KeyState          KeyState              `json:"keyState,omitempty"`
 	Description       string                `json:"description,omitempty"`
+	RotationPolicy    *RotationPolicy       `json:"rotationPolicy,omitempty"`
 	CreatedAt         *time.Time            `json:"createdAt,omitempty"`
 	UpdatedAt         *time.Time            `json:"updatedAt,omitempty"`
 }
@@ -121,6 +131,8 @@ func (k *Key) GetKeyState() KeyState                 { return k.KeyState }
 func (k *Key) SetKeyState(state KeyState)            { k.KeyState = state }
 func (k *Key) GetDescription() string                { return k.Description }
 func (k *Key) SetDescription(desc string)            { k.Description = desc }
+func (k *Key) GetRotationPolicy() *RotationPolicy    { return k.RotationPolicy }
+func (k *Key) SetRotationPolicy(p *RotationPolicy)   { k.RotationPolicy = p }
 func (k *Key) GetCreatedAt() *time.Time              { return k.CreatedAt }
 func (k *Key) SetCreatedAt(t time.Time)              { k.CreatedAt = &t }
 func (k *Key) GetUpdatedAt() *time.Time              { return k.UpdatedAt }
@@ -130,6 +142,8 @@ func (k *Key) SetPrimaryKeyVersion(version KeyVersionInfo) {
 	k.PrimaryKeyVersion = KeyVersion{
 		VersionID:          version.GetVersionID(),
 		SymmetricKeyBase64: version.GetSymmetricKeyBase64(),
+		PrivateKeyPem:      version.GetPrivateKeyPem(),
+		PublicKeyPem:       version.GetPublicKeyPem(),
 		CreatedAt:          version.GetCreatedAt(),
 		UpdatedAt:          version.GetUpdatedAt(),
 	}
@@ -138,9 +152,12 @@ func (k *Key) SetPrimaryKeyVersion(version KeyVersionInfo) {
 type KeyVersion struct {
 	VersionID string `json:"versionID,omitempty"`
 	// base64 encoded
-	SymmetricKeyBase64 string     `json:"symmetricKeyBase64,omitempty"`
-	CreatedAt          *time.Time `json:"createdAt,omitempty"`
-	UpdatedAt          *time.Time `json:"updatedAt,omitempty"`
+	SymmetricKeyBase64 string `json:"symmetricKeyBase64,omitempty"`
+	// PKCS #8 private key and PKIX public key in PEM encoding, only for asymmetric keys
+	PrivateKeyPem string     `json:"privateKeyPem,omitempty"`
+	PublicKeyPem  string     `json:"publicKeyPem,omitempty"`
+	CreatedAt     *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
 }
 
 func (k *KeyVersion) New() KeyVersionInfo            { return &KeyVersion{} }
@@ -148,6 +165,10 @@ func (k *KeyVersion) GetVersionID() string           { return k.VersionID }
 func (k *KeyVersion) SetVersionID(s string)          { k.VersionID = s }
 func (k *KeyVersion) GetSymmetricKeyBase64() string  { return k.SymmetricKeyBase64 }
 func (k *KeyVersion) SetSymmetricKeyBase64(s string) { k.SymmetricKeyBase64 = s }
+func (k *KeyVersion) GetPrivateKeyPem() string       { return k.PrivateKeyPem }
+func (k *KeyVersion) SetPrivateKeyPem(s string)      { k.PrivateKeyPem = s }
+func (k *KeyVersion) GetPublicKeyPem() string        { return k.PublicKeyPem }
+func (k *KeyVersion) SetPublicKeyPem(s string)       { k.PublicKeyPem = s }
 func (k *KeyVersion) GetCreatedAt() *time.Time       { return k.CreatedAt }
 func (k *KeyVersion) SetCreatedAt(t time.Time)       { k.CreatedAt = &t }
 func (k *KeyVersion) GetUpdatedAt() *time.Time       { return k.UpdatedAt }
@@ -54,6 +54,11 @@ type SymmetricPlugin interface {
 	GenerateDataKey(ctx context.Context, req *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error)
 	// RotateKeyVersion rotate key version for CMK manually, old key version still can be used to decrypt old data
 	RotateKeyVersion(ctx context.Context, req *RotateKeyVersionRequest) (*RotateKeyVersionResponse, error)
+	// UpdateRotationPolicy enable or disable automatic rotation of CMK
+	UpdateRotationPolicy(ctx context.Context, req *UpdateRotationPolicyRequest) (*UpdateRotationPolicyResponse, error)
+	// ReEncrypt decrypt ciphertexts under old key versions and encrypt them under the primary key version,
+	// plaintext never leaves KMS
+	ReEncrypt(ctx context.Context, req *ReEncryptRequest) (*ReEncryptResponse, error)
 }
 
 // AsymmetricPlugin 非对称加密插件
@@ -62,10 +67,19 @@ type SymmetricPlugin interface {
 // 2. 使用公钥加密数据
 // 3. 存储加密后的数据以及密钥版本
 // 解密流程：
-// 1. 调用 AsymmetricDecrypt，传入密文和 解密
+// 1. 调用 AsymmetricDecrypt，传入密文和密钥版本解密
+// 签名流程：
+// 1. 调用 Sign 使用私钥签名，私钥不会离开 KMS
+// 2. 存储签名以及密钥版本
+// 验签流程：
+// 1. 调用 Verify，或使用 GetPublicKey 获取的公钥在本地验签
 type AsymmetricPlugin interface {
 	GetPublicKey(ctx context.Context, req *GetPublicKeyRequest) (*PublicKey, error)
 	// AsymmetricDecrypt decrypts data that was encrypted with a public key retrieved from GetPublicKey
 	// corresponding to a CryptoKeyVersion with CryptoKey.purpose ASYMMETRIC_DECRYPT.
 	AsymmetricDecrypt(ctx context.Context, req *AsymmetricDecryptRequest) (*AsymmetricDecryptResponse, error)
+	// Sign creates a digital signature for a message or message digest by a SIGN_VERIFY CMK
+	Sign(ctx context.Context, req *SignRequest) (*SignResponse, error)
+	// Verify verifies a digital signature that was generated by Sign
+	Verify(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error)
 }
@@ -22,6 +22,9 @@ type Store interface {
 	// Create create and store new CMK
 	CreateKey(info KeyInfo) error
 
+	// UpdateKey update the mutable fields of CMK, such as key state, description and rotation policy
+	UpdateKey(info KeyInfo) error
+
 	// GetKey use keyID to find CMK
 	GetKey(keyID string) (KeyInfo, error)
 
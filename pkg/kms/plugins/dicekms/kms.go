@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -63,40 +64,26 @@ func (d *Dice) CreateKey(ctx context.Context, req *kmstypes.CreateKeyRequest) (*
 		return nil, fmt.Errorf("invalid pluginKind: %s, expect: %s", req.PluginKind, kmstypes.PluginKind_DICE_KMS)
 	}
 
-	// key spec
-	if req.CustomerMasterKeySpec != kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT {
-		return nil, fmt.Errorf("not supported key spec: %s", req.CustomerMasterKeySpec)
-	}
-
-	// key usage
-	if req.KeyUsage != kmstypes.KeyUsage_ENCRYPT_DECRYPT {
-		return nil, fmt.Errorf("not supported key usage: %s", req.KeyUsage)
+	// key spec and key usage
+	if err := checkKeySpecAndUsage(req.CustomerMasterKeySpec, req.KeyUsage); err != nil {
+		return nil, err
 	}
 
 	// write key to store
-	primaryKeyVersion := kmstypes.KeyVersion{
-		VersionID: uuid.UUID(),
-	}
-	switch req.CustomerMasterKeySpec {
-	case kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT:
-		symmetricKeyBytes, err := kmscrypto.GenerateAes256Key()
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate symmetric key, err: %v", err)
-		}
-		symmetricKeyBase64 := base64.StdEncoding.EncodeToString(symmetricKeyBytes)
-		primaryKeyVersion.SymmetricKeyBase64 = symmetricKeyBase64
+	primaryKeyVersion, err := newKeyVersion(req.CustomerMasterKeySpec)
+	if err != nil {
+		return nil, err
 	}
 	key := kmstypes.Key{
 		PluginKind:        kmstypes.PluginKind_DICE_KMS,
 		KeyID:             uuid.UUID(),
-		PrimaryKeyVersion: primaryKeyVersion,
+		PrimaryKeyVersion: *primaryKeyVersion,
 		KeySpec:           req.CustomerMasterKeySpec,
 		KeyUsage:          req.KeyUsage,
 		KeyState:          kmstypes.KeyStateEnabled,
 		Description:       req.Description,
 	}
-	err := d.store.CreateKey(&key)
-	if err != nil {
+	if err := d.store.CreateKey(&key); err != nil {
 		return nil, fmt.Errorf("failed to create key in store, err: %v", err)
 	}
 
@@ -146,6 +133,9 @@ func (d *Dice) Encrypt(ctx context.Context, req *kmstypes.EncryptRequest) (*kmst
 	if err != nil {
 		return nil, err
 	}
+	if keyInfo.GetKeySpec() != kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT {
+		return nil, fmt.Errorf("not symmetric key, key spec: %s", keyInfo.GetKeySpec())
+	}
 
 	// encrypt
 	additionalData := additionalData{
@@ -186,6 +176,9 @@ func (d *Dice) Decrypt(ctx context.Context, req *kmstypes.DecryptRequest) (resp
 	if kerr != nil {
 		return nil, kerr
 	}
+	if keyInfo.GetKeySpec() != kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT {
+		return nil, fmt.Errorf("not symmetric key, key spec: %s", keyInfo.GetKeySpec())
+	}
 
 	defer func() {
 		// not expose concrete error to frontend, log err and return `broken ciphertext`
@@ -268,34 +261,114 @@ func (d *Dice) GenerateDataKey(ctx context.Context, req *kmstypes.GenerateDataKe
 }
 
 func (d *Dice) RotateKeyVersion(ctx context.Context, req *kmstypes.RotateKeyVersionRequest) (*kmstypes.RotateKeyVersionResponse, error) {
-	// generate new symmetric key
-	symmetricKey, err := kmscrypto.GenerateAes256Key()
+	keyInfo, err := d.store.GetKey(req.KeyID)
 	if err != nil {
 		return nil, err
 	}
-	newKeyVersion := kmstypes.KeyVersion{
-		VersionID:          uuid.UUID(),
-		SymmetricKeyBase64: base64.StdEncoding.EncodeToString(symmetricKey),
+
+	// generate new key material with the same key spec
+	newKeyVersion, err := newKeyVersion(keyInfo.GetKeySpec())
+	if err != nil {
+		return nil, err
 	}
 
 	// rotate key version
-	_, err = d.store.RotateKeyVersion(req.KeyID, &newKeyVersion)
+	rotatedKeyVersion, err := d.store.RotateKeyVersion(req.KeyID, newKeyVersion)
 	if err != nil {
 		return nil, err
 	}
-	keyInfo, err := d.store.GetKey(req.KeyID)
+	keyInfo, err = d.store.GetKey(req.KeyID)
 	if err != nil {
 		return nil, err
 	}
 
+	// update next rotation time of automatic rotation
+	if policy := keyInfo.GetRotationPolicy(); policy != nil && policy.RotationIntervalDays > 0 {
+		rotatedAt := time.Now()
+		if t := rotatedKeyVersion.GetCreatedAt(); t != nil {
+			rotatedAt = *t
+		}
+		next := rotatedAt.Add(policy.RotationInterval())
+		policy.NextRotationTime = &next
+		keyInfo.SetRotationPolicy(policy)
+		if err := d.store.UpdateKey(keyInfo); err != nil {
+			return nil, fmt.Errorf("failed to update rotation policy, err: %v", err)
+		}
+	}
+
 	resp := kmstypes.RotateKeyVersionResponse{KeyMetadata: kmstypes.GetKeyMetadata(keyInfo)}
 	return &resp, nil
 }
 
-func (d *Dice) GetPublicKey(ctx context.Context, req *kmstypes.GetPublicKeyRequest) (*kmstypes.PublicKey, error) {
-	panic("implement me")
+func (d *Dice) UpdateRotationPolicy(ctx context.Context, req *kmstypes.UpdateRotationPolicyRequest) (*kmstypes.UpdateRotationPolicyResponse, error) {
+	keyInfo, err := d.store.GetKey(req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	// 非对称密钥轮转后公钥会变化, 需要使用方重新获取, 不支持自动轮转
+	if keyInfo.GetKeySpec() != kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT {
+		return nil, fmt.Errorf("automatic rotation is only supported for symmetric key, key spec: %s", keyInfo.GetKeySpec())
+	}
+
+	if !req.EnableAutomaticRotation {
+		keyInfo.SetRotationPolicy(nil)
+	} else {
+		// 从主版本创建时间开始计算, 主版本已经超过轮转周期时会尽快轮转
+		lastRotatedAt := time.Now()
+		if t := keyInfo.GetPrimaryKeyVersion().GetCreatedAt(); t != nil {
+			lastRotatedAt = *t
+		}
+		policy := kmstypes.RotationPolicy{RotationIntervalDays: req.RotationIntervalDays}
+		next := lastRotatedAt.Add(policy.RotationInterval())
+		policy.NextRotationTime = &next
+		keyInfo.SetRotationPolicy(&policy)
+	}
+	if err := d.store.UpdateKey(keyInfo); err != nil {
+		return nil, err
+	}
+
+	resp := kmstypes.UpdateRotationPolicyResponse{KeyMetadata: kmstypes.GetKeyMetadata(keyInfo)}
+	return &resp, nil
 }
 
-func (d *Dice) AsymmetricDecrypt(ctx context.Context, req *kmstypes.AsymmetricDecryptRequest) (*kmstypes.AsymmetricDecryptResponse, error) {
-	panic("implement me")
+func (d *Dice) ReEncrypt(ctx context.Context, req *kmstypes.ReEncryptRequest) (*kmstypes.ReEncryptResponse, error) {
+	keyInfo, err := d.store.GetKey(req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if keyInfo.GetKeySpec() != kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT {
+		return nil, fmt.Errorf("not symmetric key, key spec: %s", keyInfo.GetKeySpec())
+	}
+	primaryKeyVersionID := keyInfo.GetPrimaryKeyVersion().GetVersionID()
+
+	resp := kmstypes.ReEncryptResponse{
+		KeyID:                req.KeyID,
+		KeyVersionID:         primaryKeyVersionID,
+		CiphertextBase64List: make([]string, 0, len(req.CiphertextBase64List)),
+	}
+	for i, ciphertextBase64 := range req.CiphertextBase64List {
+		// 已经是主版本加密的数据不需要重新加密
+		ciphertextBytes, err := base64.StdEncoding.DecodeString(ciphertextBase64)
+		if err != nil {
+			return nil, fmt.Errorf("ciphertextBase64List[%d]: broken ciphertext", i)
+		}
+		keyVersionIDBytes, _, err := kmscrypto.PrefixUnAppend000Length(ciphertextBytes)
+		if err == nil && string(keyVersionIDBytes) == primaryKeyVersionID {
+			resp.CiphertextBase64List = append(resp.CiphertextBase64List, ciphertextBase64)
+			continue
+		}
+
+		// plaintext only exists in memory
+		decryptResp, err := d.Decrypt(ctx, &kmstypes.DecryptRequest{KeyID: req.KeyID, CiphertextBase64: ciphertextBase64})
+		if err != nil {
+			return nil, fmt.Errorf("ciphertextBase64List[%d]: %v", i, err)
+		}
+		encryptResp, err := d.Encrypt(ctx, &kmstypes.EncryptRequest{KeyID: req.KeyID, PlaintextBase64: decryptResp.PlaintextBase64})
+		if err != nil {
+			return nil, fmt.Errorf("ciphertextBase64List[%d]: %v", i, err)
+		}
+		resp.CiphertextBase64List = append(resp.CiphertextBase64List, encryptResp.CiphertextBase64)
+	}
+
+	return &resp, nil
 }
@@ -0,0 +1,296 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dicekms
+
+import (
+	"context"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/erda-project/erda/pkg/crypto/uuid"
+	"github.com/erda-project/erda/pkg/kms/kmscrypto"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+	"github.com/erda-project/erda/pkg/kms/log"
+)
+
+// checkKeySpecAndUsage 对称密钥只能用于加解密, RSA 密钥可用于加解密或签名验签, EC 密钥只能用于签名验签
+func checkKeySpecAndUsage(spec kmstypes.CustomerMasterKeySpec, usage kmstypes.KeyUsage) error {
+	var usages []kmstypes.KeyUsage
+	switch spec {
+	case kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT:
+		usages = []kmstypes.KeyUsage{kmstypes.KeyUsage_ENCRYPT_DECRYPT}
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048,
+		kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_3072,
+		kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_4096:
+		usages = []kmstypes.KeyUsage{kmstypes.KeyUsage_ENCRYPT_DECRYPT, kmstypes.KeyUsage_SIGN_VERIFY}
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256,
+		kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P384:
+		usages = []kmstypes.KeyUsage{kmstypes.KeyUsage_SIGN_VERIFY}
+	default:
+		return fmt.Errorf("not supported key spec: %s", spec)
+	}
+	for _, u := range usages {
+		if u == usage {
+			return nil
+		}
+	}
+	return fmt.Errorf("not supported key usage: %s for key spec: %s", usage, spec)
+}
+
+// newKeyVersion 根据 key spec 生成新的密钥版本
+func newKeyVersion(spec kmstypes.CustomerMasterKeySpec) (*kmstypes.KeyVersion, error) {
+	keyVersion := kmstypes.KeyVersion{
+		VersionID: uuid.UUID(),
+	}
+
+	var (
+		privateKey crypto.Signer
+		err        error
+	)
+	switch spec {
+	case kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT:
+		symmetricKeyBytes, err := kmscrypto.GenerateAes256Key()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate symmetric key, err: %v", err)
+		}
+		keyVersion.SymmetricKeyBase64 = base64.StdEncoding.EncodeToString(symmetricKeyBytes)
+		return &keyVersion, nil
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048:
+		privateKey, err = kmscrypto.GenerateRsaKey(2048)
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_3072:
+		privateKey, err = kmscrypto.GenerateRsaKey(3072)
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_4096:
+		privateKey, err = kmscrypto.GenerateRsaKey(4096)
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256:
+		privateKey, err = kmscrypto.GenerateEcdsaKey(elliptic.P256())
+	case kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P384:
+		privateKey, err = kmscrypto.GenerateEcdsaKey(elliptic.P384())
+	default:
+		return nil, fmt.Errorf("not supported key spec: %s", spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate asymmetric key, err: %v", err)
+	}
+	if keyVersion.PrivateKeyPem, err = kmscrypto.MarshalPrivateKeyPem(privateKey); err != nil {
+		return nil, err
+	}
+	if keyVersion.PublicKeyPem, err = kmscrypto.MarshalPublicKeyPem(privateKey.Public()); err != nil {
+		return nil, err
+	}
+	return &keyVersion, nil
+}
+
+// getAsymmetricKeyVersion 获取非对称密钥的指定版本, keyVersionID 为空时使用主版本
+func (d *Dice) getAsymmetricKeyVersion(keyID, keyVersionID string, usage kmstypes.KeyUsage) (kmstypes.KeyInfo, kmstypes.KeyVersionInfo, error) {
+	keyInfo, err := d.store.GetKey(keyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !keyInfo.GetKeySpec().IsAsymmetric() {
+		return nil, nil, fmt.Errorf("not asymmetric key, key spec: %s", keyInfo.GetKeySpec())
+	}
+	if usage != "" && keyInfo.GetKeyUsage() != usage {
+		return nil, nil, fmt.Errorf("invalid key usage: %s, expect: %s", keyInfo.GetKeyUsage(), usage)
+	}
+	if keyInfo.GetKeyState() != kmstypes.KeyStateEnabled {
+		return nil, nil, fmt.Errorf("key is not enabled, key state: %s", keyInfo.GetKeyState())
+	}
+	if keyVersionID == "" || keyVersionID == keyInfo.GetPrimaryKeyVersion().GetVersionID() {
+		return keyInfo, keyInfo.GetPrimaryKeyVersion(), nil
+	}
+	keyVersionInfo, err := d.store.GetKeyVersion(keyID, keyVersionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keyInfo, keyVersionInfo, nil
+}
+
+// signingParams 返回签名算法的哈希函数以及是否使用 PSS 填充, 同时校验算法是否被 key spec 支持
+func signingParams(spec kmstypes.CustomerMasterKeySpec, algorithm kmstypes.SigningAlgorithm) (crypto.Hash, bool, error) {
+	supported := false
+	for _, alg := range spec.SigningAlgorithms() {
+		if alg == algorithm {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return 0, false, fmt.Errorf("not supported signing algorithm: %s for key spec: %s", algorithm, spec)
+	}
+	switch algorithm {
+	case kmstypes.SigningAlgorithm_RSASSA_PSS_SHA_256:
+		return crypto.SHA256, true, nil
+	case kmstypes.SigningAlgorithm_RSASSA_PSS_SHA_384:
+		return crypto.SHA384, true, nil
+	case kmstypes.SigningAlgorithm_RSASSA_PSS_SHA_512:
+		return crypto.SHA512, true, nil
+	case kmstypes.SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_256, kmstypes.SigningAlgorithm_ECDSA_SHA_256:
+		return crypto.SHA256, false, nil
+	case kmstypes.SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_384, kmstypes.SigningAlgorithm_ECDSA_SHA_384:
+		return crypto.SHA384, false, nil
+	case kmstypes.SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_512:
+		return crypto.SHA512, false, nil
+	}
+	return 0, false, fmt.Errorf("not supported signing algorithm: %s", algorithm)
+}
+
+// messageDigest 将 RAW 消息哈希为摘要, DIGEST 消息直接返回
+func messageDigest(hash crypto.Hash, messageType kmstypes.MessageType, messageBase64 string) ([]byte, error) {
+	message, err := base64.StdEncoding.DecodeString(messageBase64)
+	if err != nil {
+		return nil, err
+	}
+	if messageType == kmstypes.MessageType_DIGEST {
+		if len(message) != hash.Size() {
+			return nil, fmt.Errorf("invalid digest length: %d, expect: %d", len(message), hash.Size())
+		}
+		return message, nil
+	}
+	return kmscrypto.Digest(hash, message)
+}
+
+func (d *Dice) GetPublicKey(ctx context.Context, req *kmstypes.GetPublicKeyRequest) (*kmstypes.PublicKey, error) {
+	keyInfo, keyVersionInfo, err := d.getAsymmetricKeyVersion(req.KeyID, req.KeyVersionID, "")
+	if err != nil {
+		return nil, err
+	}
+	publicKey := kmstypes.PublicKey{
+		KeyID:                 keyInfo.GetKeyID(),
+		KeyVersionID:          keyVersionInfo.GetVersionID(),
+		CustomerMasterKeySpec: keyInfo.GetKeySpec(),
+		KeyUsage:              keyInfo.GetKeyUsage(),
+		Pem:                   keyVersionInfo.GetPublicKeyPem(),
+	}
+	switch keyInfo.GetKeyUsage() {
+	case kmstypes.KeyUsage_ENCRYPT_DECRYPT:
+		publicKey.EncryptionAlgorithms = keyInfo.GetKeySpec().EncryptionAlgorithms()
+	case kmstypes.KeyUsage_SIGN_VERIFY:
+		publicKey.SigningAlgorithms = keyInfo.GetKeySpec().SigningAlgorithms()
+	}
+	return &publicKey, nil
+}
+
+func (d *Dice) AsymmetricDecrypt(ctx context.Context, req *kmstypes.AsymmetricDecryptRequest) (resp *kmstypes.AsymmetricDecryptResponse, err error) {
+	keyInfo, keyVersionInfo, kerr := d.getAsymmetricKeyVersion(req.KeyID, req.KeyVersionID, kmstypes.KeyUsage_ENCRYPT_DECRYPT)
+	if kerr != nil {
+		return nil, kerr
+	}
+	if req.EncryptionAlgorithm != kmstypes.EncryptionAlgorithm_RSAES_OAEP_SHA_256 {
+		return nil, fmt.Errorf("not supported encryption algorithm: %s", req.EncryptionAlgorithm)
+	}
+
+	defer func() {
+		// not expose concrete error to frontend, log err and return `broken ciphertext`
+		if err != nil {
+			log.WithTraceID(ctx).Errorf("asymmetric decrypt failed, err: %v", err)
+			resp = nil
+			err = fmt.Errorf("broken ciphertext")
+		}
+	}()
+
+	ciphertext, err := base64.StdEncoding.DecodeString(req.CiphertextBase64)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := kmscrypto.ParsePrivateKeyPem(keyVersionInfo.GetPrivateKeyPem())
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not rsa private key: %T", privateKey)
+	}
+	plaintext, err := kmscrypto.RsaOaepSha256Decrypt(rsaKey, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kmstypes.AsymmetricDecryptResponse{
+		KeyID:           keyInfo.GetKeyID(),
+		KeyVersionID:    keyVersionInfo.GetVersionID(),
+		PlaintextBase64: base64.StdEncoding.EncodeToString(plaintext),
+	}, nil
+}
+
+func (d *Dice) Sign(ctx context.Context, req *kmstypes.SignRequest) (*kmstypes.SignResponse, error) {
+	keyInfo, keyVersionInfo, err := d.getAsymmetricKeyVersion(req.KeyID, req.KeyVersionID, kmstypes.KeyUsage_SIGN_VERIFY)
+	if err != nil {
+		return nil, err
+	}
+	hash, pss, err := signingParams(keyInfo.GetKeySpec(), req.SigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := messageDigest(hash, req.MessageType, req.MessageBase64)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := kmscrypto.ParsePrivateKeyPem(keyVersionInfo.GetPrivateKeyPem())
+	if err != nil {
+		log.WithTraceID(ctx).Errorf("failed to parse private key of key version: %s, err: %v", keyVersionInfo.GetVersionID(), err)
+		return nil, fmt.Errorf("broken key material")
+	}
+	signature, err := kmscrypto.SignDigest(privateKey, hash, pss, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kmstypes.SignResponse{
+		KeyID:            keyInfo.GetKeyID(),
+		KeyVersionID:     keyVersionInfo.GetVersionID(),
+		SigningAlgorithm: req.SigningAlgorithm,
+		SignatureBase64:  base64.StdEncoding.EncodeToString(signature),
+	}, nil
+}
+
+func (d *Dice) Verify(ctx context.Context, req *kmstypes.VerifyRequest) (*kmstypes.VerifyResponse, error) {
+	keyInfo, keyVersionInfo, err := d.getAsymmetricKeyVersion(req.KeyID, req.KeyVersionID, kmstypes.KeyUsage_SIGN_VERIFY)
+	if err != nil {
+		return nil, err
+	}
+	hash, pss, err := signingParams(keyInfo.GetKeySpec(), req.SigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := messageDigest(hash, req.MessageType, req.MessageBase64)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(req.SignatureBase64)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := kmscrypto.ParsePublicKeyPem(keyVersionInfo.GetPublicKeyPem())
+	if err != nil {
+		log.WithTraceID(ctx).Errorf("failed to parse public key of key version: %s, err: %v", keyVersionInfo.GetVersionID(), err)
+		return nil, fmt.Errorf("broken key material")
+	}
+	resp := kmstypes.VerifyResponse{
+		KeyID:            keyInfo.GetKeyID(),
+		KeyVersionID:     keyVersionInfo.GetVersionID(),
+		SigningAlgorithm: req.SigningAlgorithm,
+	}
+	if err := kmscrypto.VerifyDigest(publicKey, hash, pss, digest, signature); err != nil {
+		log.WithTraceID(ctx).Infof("signature is invalid, keyID: %s, keyVersionID: %s, err: %v", keyInfo.GetKeyID(), keyVersionInfo.GetVersionID(), err)
+		return &resp, nil
+	}
+	resp.SignatureValid = true
+	return &resp, nil
+}
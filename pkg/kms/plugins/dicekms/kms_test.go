@@ -0,0 +1,324 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dicekms
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/kmscrypto"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// memStore 内存 store, 仅用于测试
+type memStore struct {
+	keys     map[string]*kmstypes.Key
+	versions map[string]map[string]kmstypes.KeyVersionInfo
+}
+
+func newMemStore() *memStore {
+	return &memStore{keys: map[string]*kmstypes.Key{}, versions: map[string]map[string]kmstypes.KeyVersionInfo{}}
+}
+
+func (s *memStore) GetKind() kmstypes.StoreKind { return "MEMORY" }
+
+func (s *memStore) CreateKey(info kmstypes.KeyInfo) error {
+	key := *info.(*kmstypes.Key)
+	version := key.PrimaryKeyVersion
+	s.keys[key.KeyID] = &key
+	s.versions[key.KeyID] = map[string]kmstypes.KeyVersionInfo{version.VersionID: &version}
+	return nil
+}
+
+func (s *memStore) UpdateKey(info kmstypes.KeyInfo) error {
+	key, ok := s.keys[info.GetKeyID()]
+	if !ok {
+		return fmt.Errorf("key not exist")
+	}
+	key.SetKeyState(info.GetKeyState())
+	key.SetDescription(info.GetDescription())
+	key.SetRotationPolicy(info.GetRotationPolicy())
+	return nil
+}
+
+func (s *memStore) GetKey(keyID string) (kmstypes.KeyInfo, error) {
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key not exist")
+	}
+	k := *key
+	return &k, nil
+}
+
+func (s *memStore) ListKeysByKind(kind kmstypes.PluginKind) ([]string, error) {
+	var keyIDs []string
+	for id := range s.keys {
+		keyIDs = append(keyIDs, id)
+	}
+	return keyIDs, nil
+}
+
+func (s *memStore) DeleteByKeyID(keyID string) error {
+	delete(s.keys, keyID)
+	return nil
+}
+
+func (s *memStore) GetKeyVersion(keyID, keyVersionID string) (kmstypes.KeyVersionInfo, error) {
+	version, ok := s.versions[keyID][keyVersionID]
+	if !ok {
+		return nil, fmt.Errorf("key version not exist")
+	}
+	return version, nil
+}
+
+func (s *memStore) RotateKeyVersion(keyID string, newKeyVersionInfo kmstypes.KeyVersionInfo) (kmstypes.KeyVersionInfo, error) {
+	newKeyVersionInfo.SetCreatedAt(time.Now())
+	s.keys[keyID].SetPrimaryKeyVersion(newKeyVersionInfo)
+	s.versions[keyID][newKeyVersionInfo.GetVersionID()] = newKeyVersionInfo
+	return newKeyVersionInfo, nil
+}
+
+func newTestDice() *Dice {
+	d := &Dice{}
+	d.SetStore(newMemStore())
+	return d
+}
+
+func createKey(t *testing.T, d *Dice, spec kmstypes.CustomerMasterKeySpec, usage kmstypes.KeyUsage) kmstypes.KeyMetadata {
+	resp, err := d.CreateKey(context.Background(), &kmstypes.CreateKeyRequest{
+		PluginKind:            kmstypes.PluginKind_DICE_KMS,
+		CustomerMasterKeySpec: spec,
+		KeyUsage:              usage,
+	})
+	assert.NoError(t, err)
+	return resp.KeyMetadata
+}
+
+func TestDice_CreateKey(t *testing.T) {
+	d := newTestDice()
+	cases := []struct {
+		spec  kmstypes.CustomerMasterKeySpec
+		usage kmstypes.KeyUsage
+		ok    bool
+	}{
+		{kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT, kmstypes.KeyUsage_ENCRYPT_DECRYPT, true},
+		{kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT, kmstypes.KeyUsage_SIGN_VERIFY, false},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, kmstypes.KeyUsage_ENCRYPT_DECRYPT, true},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, kmstypes.KeyUsage_SIGN_VERIFY, true},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256, kmstypes.KeyUsage_SIGN_VERIFY, true},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256, kmstypes.KeyUsage_ENCRYPT_DECRYPT, false},
+		{"RSA_1024", kmstypes.KeyUsage_SIGN_VERIFY, false},
+	}
+	for _, c := range cases {
+		_, err := d.CreateKey(context.Background(), &kmstypes.CreateKeyRequest{
+			PluginKind:            kmstypes.PluginKind_DICE_KMS,
+			CustomerMasterKeySpec: c.spec,
+			KeyUsage:              c.usage,
+		})
+		assert.Equal(t, c.ok, err == nil, "%s %s: %v", c.spec, c.usage, err)
+	}
+}
+
+func TestDice_AsymmetricDecrypt(t *testing.T) {
+	d := newTestDice()
+	ctx := context.Background()
+	key := createKey(t, d, kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, kmstypes.KeyUsage_ENCRYPT_DECRYPT)
+
+	// encrypt by the public key locally
+	publicKey, err := d.GetPublicKey(ctx, &kmstypes.GetPublicKeyRequest{KeyID: key.KeyID})
+	assert.NoError(t, err)
+	assert.Equal(t, key.PrimaryKeyVersionID, publicKey.KeyVersionID)
+	assert.Equal(t, []kmstypes.EncryptionAlgorithm{kmstypes.EncryptionAlgorithm_RSAES_OAEP_SHA_256}, publicKey.EncryptionAlgorithms)
+	pub, err := kmscrypto.ParsePublicKeyPem(publicKey.Pem)
+	assert.NoError(t, err)
+	ciphertext, err := kmscrypto.RsaOaepSha256Encrypt(pub.(*rsa.PublicKey), []byte("hello world"))
+	assert.NoError(t, err)
+
+	// rotate, the old key version still can be used to decrypt
+	_, err = d.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: key.KeyID})
+	assert.NoError(t, err)
+	req := kmstypes.AsymmetricDecryptRequest{
+		KeyID:            key.KeyID,
+		KeyVersionID:     publicKey.KeyVersionID,
+		CiphertextBase64: base64.StdEncoding.EncodeToString(ciphertext),
+	}
+	assert.NoError(t, req.ValidateRequest())
+	decryptResp, err := d.AsymmetricDecrypt(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("hello world")), decryptResp.PlaintextBase64)
+
+	// the primary key version is rotated
+	req.KeyVersionID = ""
+	_, err = d.AsymmetricDecrypt(ctx, &req)
+	assert.EqualError(t, err, "broken ciphertext")
+
+	// symmetric operations are not allowed
+	_, err = d.Encrypt(ctx, &kmstypes.EncryptRequest{KeyID: key.KeyID, PlaintextBase64: "aGVsbG8="})
+	assert.Error(t, err)
+}
+
+func TestDice_SignVerify(t *testing.T) {
+	d := newTestDice()
+	ctx := context.Background()
+	message := base64.StdEncoding.EncodeToString([]byte("hello world"))
+	digest := sha256.Sum256([]byte("hello world"))
+
+	cases := []struct {
+		spec        kmstypes.CustomerMasterKeySpec
+		algorithm   kmstypes.SigningAlgorithm
+		messageType kmstypes.MessageType
+		message     string
+	}{
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, kmstypes.SigningAlgorithm_RSASSA_PSS_SHA_256, kmstypes.MessageType_RAW, message},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, kmstypes.SigningAlgorithm_RSASSA_PKCS1_V1_5_SHA_512, kmstypes.MessageType_RAW, message},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256, kmstypes.SigningAlgorithm_ECDSA_SHA_256, kmstypes.MessageType_DIGEST, base64.StdEncoding.EncodeToString(digest[:])},
+		{kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P384, kmstypes.SigningAlgorithm_ECDSA_SHA_384, kmstypes.MessageType_RAW, message},
+	}
+	for _, c := range cases {
+		key := createKey(t, d, c.spec, kmstypes.KeyUsage_SIGN_VERIFY)
+		signResp, err := d.Sign(ctx, &kmstypes.SignRequest{
+			KeyID:            key.KeyID,
+			SigningAlgorithm: c.algorithm,
+			MessageType:      c.messageType,
+			MessageBase64:    c.message,
+		})
+		if !assert.NoError(t, err, c.algorithm) {
+			continue
+		}
+		assert.Equal(t, key.PrimaryKeyVersionID, signResp.KeyVersionID)
+
+		// rotate, verify with the key version used to sign
+		_, err = d.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: key.KeyID})
+		assert.NoError(t, err)
+		verifyReq := kmstypes.VerifyRequest{
+			KeyID:            key.KeyID,
+			KeyVersionID:     signResp.KeyVersionID,
+			SigningAlgorithm: c.algorithm,
+			MessageType:      c.messageType,
+			MessageBase64:    c.message,
+			SignatureBase64:  signResp.SignatureBase64,
+		}
+		verifyResp, err := d.Verify(ctx, &verifyReq)
+		assert.NoError(t, err, c.algorithm)
+		assert.True(t, verifyResp.SignatureValid, c.algorithm)
+
+		// the primary key version is rotated
+		verifyReq.KeyVersionID = ""
+		verifyResp, err = d.Verify(ctx, &verifyReq)
+		assert.NoError(t, err, c.algorithm)
+		assert.False(t, verifyResp.SignatureValid, c.algorithm)
+	}
+
+	// algorithm not supported by key spec
+	key := createKey(t, d, kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256, kmstypes.KeyUsage_SIGN_VERIFY)
+	_, err := d.Sign(ctx, &kmstypes.SignRequest{KeyID: key.KeyID, SigningAlgorithm: kmstypes.SigningAlgorithm_ECDSA_SHA_384, MessageType: kmstypes.MessageType_RAW, MessageBase64: message})
+	assert.Error(t, err)
+	// wrong digest length
+	_, err = d.Sign(ctx, &kmstypes.SignRequest{KeyID: key.KeyID, SigningAlgorithm: kmstypes.SigningAlgorithm_ECDSA_SHA_256, MessageType: kmstypes.MessageType_DIGEST, MessageBase64: message})
+	assert.Error(t, err)
+	// decrypt key can not be used to sign
+	key = createKey(t, d, kmstypes.CustomerMasterKeySpec_ASYMMETRIC_RSA_2048, kmstypes.KeyUsage_ENCRYPT_DECRYPT)
+	_, err = d.Sign(ctx, &kmstypes.SignRequest{KeyID: key.KeyID, SigningAlgorithm: kmstypes.SigningAlgorithm_RSASSA_PSS_SHA_256, MessageType: kmstypes.MessageType_RAW, MessageBase64: message})
+	assert.Error(t, err)
+}
+
+func TestDice_UpdateRotationPolicy(t *testing.T) {
+	d := newTestDice()
+	ctx := context.Background()
+	key := createKey(t, d, kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT, kmstypes.KeyUsage_ENCRYPT_DECRYPT)
+
+	resp, err := d.UpdateRotationPolicy(ctx, &kmstypes.UpdateRotationPolicyRequest{KeyID: key.KeyID, EnableAutomaticRotation: true, RotationIntervalDays: 90})
+	assert.NoError(t, err)
+	policy := resp.KeyMetadata.RotationPolicy
+	assert.Equal(t, 90, policy.RotationIntervalDays)
+	assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), *policy.NextRotationTime, time.Minute)
+	assert.False(t, policy.IsDue(time.Now()))
+	assert.True(t, policy.IsDue(time.Now().Add(91*24*time.Hour)))
+
+	// rotation moves the next rotation time forward
+	past := time.Now().Add(-time.Hour)
+	d.store.(*memStore).keys[key.KeyID].RotationPolicy = &kmstypes.RotationPolicy{RotationIntervalDays: 90, NextRotationTime: &past}
+	rotateResp, err := d.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: key.KeyID})
+	assert.NoError(t, err)
+	assert.False(t, rotateResp.KeyMetadata.RotationPolicy.IsDue(time.Now()))
+
+	// disable
+	resp, err = d.UpdateRotationPolicy(ctx, &kmstypes.UpdateRotationPolicyRequest{KeyID: key.KeyID})
+	assert.NoError(t, err)
+	assert.Nil(t, resp.KeyMetadata.RotationPolicy)
+
+	// invalid interval
+	assert.Error(t, (&kmstypes.UpdateRotationPolicyRequest{KeyID: key.KeyID, EnableAutomaticRotation: true, RotationIntervalDays: 1}).ValidateRequest())
+
+	// asymmetric key is not supported
+	key = createKey(t, d, kmstypes.CustomerMasterKeySpec_ASYMMETRIC_EC_P256, kmstypes.KeyUsage_SIGN_VERIFY)
+	_, err = d.UpdateRotationPolicy(ctx, &kmstypes.UpdateRotationPolicyRequest{KeyID: key.KeyID, EnableAutomaticRotation: true, RotationIntervalDays: 90})
+	assert.Error(t, err)
+}
+
+func TestDice_ReEncrypt(t *testing.T) {
+	d := newTestDice()
+	ctx := context.Background()
+	key := createKey(t, d, kmstypes.CustomerMasterKeySpec_SYMMETRIC_DEFAULT, kmstypes.KeyUsage_ENCRYPT_DECRYPT)
+
+	var ciphertexts, plaintexts []string
+	for _, text := range []string{"hello", "world"} {
+		plaintext := base64.StdEncoding.EncodeToString([]byte(text))
+		encryptResp, err := d.Encrypt(ctx, &kmstypes.EncryptRequest{KeyID: key.KeyID, PlaintextBase64: plaintext})
+		assert.NoError(t, err)
+		plaintexts = append(plaintexts, plaintext)
+		ciphertexts = append(ciphertexts, encryptResp.CiphertextBase64)
+		// rotate after each encryption, so ciphertexts are under different key versions
+		_, err = d.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: key.KeyID})
+		assert.NoError(t, err)
+	}
+	// already under the primary key version
+	plaintext := base64.StdEncoding.EncodeToString([]byte("!"))
+	encryptResp, err := d.Encrypt(ctx, &kmstypes.EncryptRequest{KeyID: key.KeyID, PlaintextBase64: plaintext})
+	assert.NoError(t, err)
+	plaintexts = append(plaintexts, plaintext)
+	ciphertexts = append(ciphertexts, encryptResp.CiphertextBase64)
+
+	req := kmstypes.ReEncryptRequest{KeyID: key.KeyID, CiphertextBase64List: ciphertexts}
+	assert.NoError(t, req.ValidateRequest())
+	resp, err := d.ReEncrypt(ctx, &req)
+	assert.NoError(t, err)
+	primary, err := d.DescribeKey(ctx, &kmstypes.DescribeKeyRequest{KeyID: key.KeyID})
+	assert.NoError(t, err)
+	assert.Equal(t, primary.KeyMetadata.PrimaryKeyVersionID, resp.KeyVersionID)
+	assert.Equal(t, ciphertexts[2], resp.CiphertextBase64List[2])
+	for i, ciphertext := range resp.CiphertextBase64List {
+		ciphertextBytes, err := base64.StdEncoding.DecodeString(ciphertext)
+		assert.NoError(t, err)
+		keyVersionID, _, err := kmscrypto.PrefixUnAppend000Length(ciphertextBytes)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.KeyVersionID, string(keyVersionID))
+
+		decryptResp, err := d.Decrypt(ctx, &kmstypes.DecryptRequest{KeyID: key.KeyID, CiphertextBase64: ciphertext})
+		assert.NoError(t, err)
+		assert.Equal(t, plaintexts[i], decryptResp.PlaintextBase64)
+	}
+
+	// broken ciphertext
+	_, err = d.ReEncrypt(ctx, &kmstypes.ReEncryptRequest{KeyID: key.KeyID, CiphertextBase64List: []string{ciphertexts[0], "aGVsbG8="}})
+	assert.EqualError(t, err, "ciphertextBase64List[1]: broken ciphertext")
+}
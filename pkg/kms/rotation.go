@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// RotateDueKeys rotate all enabled CMKs whose automatic rotation is due at now, return the rotated keyIDs.
+// A failed key does not stop rotating others, it will be retried in the next round.
+func (m *Manager) RotateDueKeys(ctx context.Context, storeKind kmstypes.StoreKind, now time.Time) ([]string, error) {
+	store, err := m.GetStore(storeKind)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginKinds := make([]string, 0, len(m.plugins))
+	for kind := range m.plugins {
+		pluginKinds = append(pluginKinds, kind.String())
+	}
+	sort.Strings(pluginKinds)
+
+	var (
+		rotated []string
+		failed  int
+	)
+	for _, kind := range pluginKinds {
+		pluginKind := kmstypes.PluginKind(kind)
+		keyIDs, err := store.ListKeysByKind(pluginKind)
+		if err != nil {
+			return rotated, fmt.Errorf("failed to list keys of plugin %s, err: %v", pluginKind, err)
+		}
+		for _, keyID := range keyIDs {
+			keyInfo, err := store.GetKey(keyID)
+			if err != nil {
+				logrus.Errorf("failed to get key %s for rotation, err: %v", keyID, err)
+				failed++
+				continue
+			}
+			if keyInfo.GetKeyState() != kmstypes.KeyStateEnabled || !keyInfo.GetRotationPolicy().IsDue(now) {
+				continue
+			}
+			plugin, err := m.GetPlugin(pluginKind, storeKind)
+			if err != nil {
+				return rotated, err
+			}
+			resp, err := plugin.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: keyID})
+			if err != nil {
+				logrus.Errorf("failed to rotate key %s automatically, err: %v", keyID, err)
+				failed++
+				continue
+			}
+			logrus.Infof("key %s rotated automatically, primary key version: %s", keyID, resp.KeyMetadata.PrimaryKeyVersionID)
+			rotated = append(rotated, keyID)
+		}
+	}
+	if failed > 0 {
+		return rotated, fmt.Errorf("failed to rotate %d keys", failed)
+	}
+	return rotated, nil
+}
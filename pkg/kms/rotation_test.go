@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+type fakeStore struct {
+	kmstypes.Store
+	keys map[string]*kmstypes.Key
+}
+
+func (s *fakeStore) ListKeysByKind(kind kmstypes.PluginKind) ([]string, error) {
+	return []string{"due", "not-due", "disabled", "no-policy", "broken"}, nil
+}
+
+func (s *fakeStore) GetKey(keyID string) (kmstypes.KeyInfo, error) {
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key not exist")
+	}
+	return key, nil
+}
+
+type fakePlugin struct {
+	kmstypes.Plugin
+	rotated []string
+}
+
+func (p *fakePlugin) SetStore(kmstypes.Store) {}
+
+func (p *fakePlugin) RotateKeyVersion(ctx context.Context, req *kmstypes.RotateKeyVersionRequest) (*kmstypes.RotateKeyVersionResponse, error) {
+	p.rotated = append(p.rotated, req.KeyID)
+	return &kmstypes.RotateKeyVersionResponse{}, nil
+}
+
+func TestManager_RotateDueKeys(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	store := &fakeStore{keys: map[string]*kmstypes.Key{
+		"due":       {KeyID: "due", KeyState: kmstypes.KeyStateEnabled, RotationPolicy: &kmstypes.RotationPolicy{RotationIntervalDays: 90, NextRotationTime: &past}},
+		"not-due":   {KeyID: "not-due", KeyState: kmstypes.KeyStateEnabled, RotationPolicy: &kmstypes.RotationPolicy{RotationIntervalDays: 90, NextRotationTime: &future}},
+		"disabled":  {KeyID: "disabled", KeyState: kmstypes.KeyStateDisabled, RotationPolicy: &kmstypes.RotationPolicy{RotationIntervalDays: 90, NextRotationTime: &past}},
+		"no-policy": {KeyID: "no-policy", KeyState: kmstypes.KeyStateEnabled},
+	}}
+	plugin := &fakePlugin{}
+	m := Manager{
+		plugins: map[kmstypes.PluginKind]kmstypes.Plugin{kmstypes.PluginKind_DICE_KMS: plugin},
+		stores:  map[kmstypes.StoreKind]kmstypes.Store{kmstypes.StoreKind_ETCD: store},
+	}
+
+	rotated, err := m.RotateDueKeys(context.Background(), kmstypes.StoreKind_ETCD, now)
+	// "broken" key can not be found
+	assert.Error(t, err)
+	assert.Equal(t, []string{"due"}, rotated)
+	assert.Equal(t, []string{"due"}, plugin.rotated)
+
+	_, err = m.RotateDueKeys(context.Background(), kmstypes.StoreKind_MYSQL, now)
+	assert.Error(t, err)
+}
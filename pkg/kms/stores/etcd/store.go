@@ -90,6 +90,8 @@ func (s *Store) CreateKey(keyInfo kmstypes.KeyInfo) error {
 	keyVersion := kmstypes.KeyVersion{
 		VersionID:          keyInfo.GetPrimaryKeyVersion().GetVersionID(),
 		SymmetricKeyBase64: keyInfo.GetPrimaryKeyVersion().GetSymmetricKeyBase64(),
+		PrivateKeyPem:      keyInfo.GetPrimaryKeyVersion().GetPrivateKeyPem(),
+		PublicKeyPem:       keyInfo.GetPrimaryKeyVersion().GetPublicKeyPem(),
 		CreatedAt:          &now,
 		UpdatedAt:          &now,
 	}
@@ -105,6 +107,7 @@ func (s *Store) CreateKey(keyInfo kmstypes.KeyInfo) error {
 		KeyUsage:          keyInfo.GetKeyUsage(),
 		KeyState:          keyInfo.GetKeyState(),
 		Description:       keyInfo.GetDescription(),
+		RotationPolicy:    keyInfo.GetRotationPolicy(),
 		CreatedAt:         &now,
 		UpdatedAt:         &now,
 	}
@@ -134,6 +137,29 @@ func (s *Store) CreateKey(keyInfo kmstypes.KeyInfo) error {
 	return nil
 }
 
+func (s *Store) UpdateKey(keyInfo kmstypes.KeyInfo) error {
+	ctx := context.Background()
+
+	// 只更新可变字段, 避免覆盖并发轮转的主版本
+	key, err := getKeyFromEtcd(ctx, keyInfo.GetKeyID(), s.etcdClient)
+	if err != nil {
+		if isNotFoundErr(err) {
+			return fmt.Errorf("key not exist")
+		}
+		return fmt.Errorf("get key from etcd failed, err: %v", err)
+	}
+	key.SetKeyState(keyInfo.GetKeyState())
+	key.SetDescription(keyInfo.GetDescription())
+	key.SetRotationPolicy(keyInfo.GetRotationPolicy())
+	key.SetUpdatedAt(time.Now())
+
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	return s.etcdClient.Put(ctx, makeEtcdKeyID(key.GetKeyID()), string(keyJSON))
+}
+
 func (s *Store) GetKey(keyID string) (kmstypes.KeyInfo, error) {
 	ctx := context.Background()
 	key, err := getKeyFromEtcd(ctx, keyID, s.etcdClient)
@@ -153,7 +179,8 @@ func (s *Store) ListKeysByKind(kind kmstypes.PluginKind) ([]string, error) {
 		return nil, err
 	}
 	var keys []string
-	prefix := makeEtcdPluginKindPrefix(kind)
+	// value is the etcd key of CMK, see makeEtcdKeyIDUnderPlugin
+	prefix := makeEtcdKeyID("")
 	for _, v := range values {
 		keys = append(keys, strings.TrimPrefix(string(v.Value), prefix))
 	}
@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type BasicValidateVisitor struct {
@@ -156,6 +157,99 @@ func (o *BasicValidateVisitor) VisitDeployments(v DiceYmlVisitor, obj *Deploymen
 	if obj.Policies != "" && obj.Policies != "shuffle" && obj.Policies != "affinity" && obj.Policies != "unique" {
 		o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService, "deployments"}, "policies")] = errors.Wrap(invalidPolicy, o.currentService)
 	}
+	if obj.Autoscaling != nil {
+		o.validateAutoscaling(obj.Autoscaling)
+		// per_node is deployed as daemonset, which can not be scaled by hpa
+		if obj.Workload == "per_node" {
+			o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService, "deployments"}, "workload")] = errors.Wrap(autoscalingPerNode, o.currentService)
+		}
+	}
+}
+
+func (o *BasicValidateVisitor) validateAutoscaling(obj *Autoscaling) {
+	header := []string{o.currentService, "deployments", "autoscaling"}
+	if obj.MinReplicas < 0 || obj.MaxReplicas < obj.GetMinReplicas() {
+		o.collectErrors[yamlHeaderRegexWithUpperHeader(header, "max_replicas")] = errors.Wrap(invalidAutoscalingReplicas, o.currentService)
+	}
+	if obj.TargetCPU < 0 {
+		o.collectErrors[yamlHeaderRegexWithUpperHeader(header, "target_cpu")] = errors.Wrap(invalidAutoscalingTarget, o.currentService)
+	}
+	if obj.TargetMem < 0 {
+		o.collectErrors[yamlHeaderRegexWithUpperHeader(header, "target_mem")] = errors.Wrap(invalidAutoscalingTarget, o.currentService)
+	}
+	if obj.TargetCPU == 0 && obj.TargetMem == 0 && len(obj.Metrics) == 0 {
+		o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService, "deployments"}, "autoscaling")] = errors.Wrap(emptyAutoscalingTarget, o.currentService)
+	}
+	for _, metric := range obj.Metrics {
+		if !isValidAutoscalingMetric(metric) {
+			o.collectErrors[yamlHeaderRegexWithUpperHeader(header, "metrics")] = errors.Wrap(invalidAutoscalingMetric, o.currentService+":["+metric.Name+"]")
+			break
+		}
+	}
+	if obj.ScaleDownStabilization < 0 || obj.ScaleDownStabilization > 3600 {
+		o.collectErrors[yamlHeaderRegexWithUpperHeader(header, "scale_down_stabilization")] = errors.Wrap(invalidAutoscalingStabilization, o.currentService)
+	}
+}
+
+func isValidAutoscalingMetric(metric AutoscalingMetric) bool {
+	switch metric.GetType() {
+	case AutoscalingMetricTypePods, AutoscalingMetricTypeExternal:
+	default:
+		return false
+	}
+	if metric.Name == "" {
+		return false
+	}
+	q, err := resource.ParseQuantity(metric.TargetAverageValue)
+	return err == nil && q.Sign() > 0
+}
+
+func (o *BasicValidateVisitor) VisitHealthCheck(v DiceYmlVisitor, obj *HealthCheck) {
+	if o.currentService == "" {
+		return
+	}
+	var kinds int
+	if obj.HTTP != nil && *obj.HTTP != (HTTPCheck{}) {
+		kinds++
+	}
+	if obj.Exec != nil && *obj.Exec != (ExecCheck{}) {
+		kinds++
+	}
+	tcp := obj.TCP != nil && *obj.TCP != (TCPCheck{})
+	grpc := obj.GRPC != nil && *obj.GRPC != (GRPCCheck{})
+	if (tcp || grpc) && (kinds > 0 || (tcp && grpc)) {
+		o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService}, "health_check")] = errors.Wrap(multipleHealthCheck, o.currentService)
+	}
+}
+
+func (o *BasicValidateVisitor) VisitTCPCheck(v DiceYmlVisitor, obj *TCPCheck) {
+	if o.currentService == "" || *obj == (TCPCheck{}) {
+		return
+	}
+	o.validateProbe("tcp", obj.Port, obj.InitialDelay, obj.FailureThreshold, obj.SuccessThreshold)
+}
+
+func (o *BasicValidateVisitor) VisitGRPCCheck(v DiceYmlVisitor, obj *GRPCCheck) {
+	if o.currentService == "" || *obj == (GRPCCheck{}) {
+		return
+	}
+	o.validateProbe("grpc", obj.Port, obj.InitialDelay, obj.FailureThreshold, obj.SuccessThreshold)
+	if obj.Service != "" && !grpcServiceNameRegex.MatchString(obj.Service) {
+		o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService, "health_check", "grpc"}, "service")] = errors.Wrap(invalidGrpcHealthCheckService, o.currentService)
+	}
+}
+
+// grpcServiceNameRegex fully qualified grpc service name, e.g. helloworld.Greeter
+var grpcServiceNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*$`)
+
+func (o *BasicValidateVisitor) validateProbe(kind string, port, initialDelay, failureThreshold, successThreshold int) {
+	header := []string{o.currentService, "health_check", kind}
+	if port <= 0 || port > 65535 {
+		o.collectErrors[yamlHeaderRegexWithUpperHeader(header, "port")] = errors.Wrap(invalidHealthCheckPort, o.currentService)
+	}
+	if initialDelay < 0 || failureThreshold < 0 || successThreshold < 0 {
+		o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService, "health_check"}, kind)] = errors.Wrap(invalidHealthCheckThreshold, o.currentService)
+	}
 }
 
 func (o *BasicValidateVisitor) VisitAddOns(v DiceYmlVisitor, obj *AddOns) {
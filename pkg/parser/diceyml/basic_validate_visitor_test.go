@@ -85,3 +85,133 @@ func TestBasicValidate(t *testing.T) {
 	assert.Equal(t, 3, len(es), "%v", es)
 
 }
+
+var autoscaling_validate_yml = `version: 2.0
+services:
+  web:
+    resources:
+      cpu: 0.1
+      mem: 256
+    deployments:
+      replicas: 2
+      autoscaling:
+        min_replicas: 2
+        max_replicas: 10
+        target_cpu: 70
+        target_mem: 80
+        metrics:
+        - name: http_requests_per_second
+          target_average_value: 100
+        - type: external
+          name: queue_messages_ready
+          selector:
+            queue: orders
+          target_average_value: 30
+        scale_down_stabilization: 300
+  worker:
+    resources:
+      cpu: 0.1
+      mem: 256
+    deployments:
+      replicas: 1
+      autoscaling:
+        min_replicas: 3
+        max_replicas: 2
+        metrics:
+        - type: object
+          name: foo
+          target_average_value: 1
+        scale_down_stabilization: 7200
+  job:
+    resources:
+      cpu: 0.1
+      mem: 256
+    deployments:
+      replicas: 1
+      autoscaling:
+        max_replicas: 2
+  agent:
+    resources:
+      cpu: 0.1
+      mem: 256
+    deployments:
+      workload: per_node
+      autoscaling:
+        max_replicas: 2
+        target_cpu: 70
+`
+
+func TestBasicValidateAutoscaling(t *testing.T) {
+	d, err := New([]byte(autoscaling_validate_yml), false)
+	assert.Nil(t, err)
+	web := d.Obj().Services["web"].Deployments.Autoscaling
+	assert.Equal(t, 2, len(web.Metrics))
+	assert.Equal(t, AutoscalingMetricTypePods, web.Metrics[0].GetType())
+	assert.Equal(t, "orders", web.Metrics[1].Selector["queue"])
+	assert.Equal(t, 1, d.Obj().Services["job"].Deployments.Autoscaling.GetMinReplicas())
+
+	es := BasicValidate(d.Obj())
+	// worker: replicas, metric, stabilization; job: empty target; agent: per_node
+	assert.Equal(t, 5, len(es), "%v", es)
+}
+
+var healthcheck_validate_yml = `version: 2.0
+services:
+  tcp-svc:
+    resources:
+      cpu: 0.1
+      mem: 256
+    health_check:
+      tcp:
+        port: 8080
+        initial_delay: 20
+        failure_threshold: 5
+  grpc-svc:
+    resources:
+      cpu: 0.1
+      mem: 256
+    health_check:
+      grpc:
+        port: 9090
+        service: helloworld.Greeter
+        success_threshold: 2
+  bad-port:
+    resources:
+      cpu: 0.1
+      mem: 256
+    health_check:
+      grpc:
+        service: helloworld.Greeter
+        failure_threshold: -1
+  bad-service:
+    resources:
+      cpu: 0.1
+      mem: 256
+    health_check:
+      grpc:
+        port: 9090
+        service: helloworld/Greeter
+  multiple:
+    resources:
+      cpu: 0.1
+      mem: 256
+    health_check:
+      http:
+        port: 8080
+        path: /health
+      tcp:
+        port: 8080
+`
+
+func TestBasicValidateHealthCheck(t *testing.T) {
+	d, err := New([]byte(healthcheck_validate_yml), false)
+	assert.Nil(t, err)
+	assert.Equal(t, "helloworld.Greeter", d.Obj().Services["grpc-svc"].HealthCheck.GRPC.Service)
+	assert.Equal(t, 20, d.Obj().Services["tcp-svc"].HealthCheck.TCP.InitialDelay)
+
+	es := BasicValidate(d.Obj())
+	// bad-port: port, threshold; bad-service: service; multiple: multiple health checks
+	assert.Equal(t, 4, len(es), "%v", es)
+	es = FieldnameValidate(d.Obj(), []byte(healthcheck_validate_yml))
+	assert.Equal(t, 0, len(es), "%v", es)
+}
@@ -505,6 +505,8 @@ type SideCar struct {
 type HealthCheck struct {
 	HTTP *HTTPCheck `yaml:"http,omitempty" json:"http,omitempty"`
 	Exec *ExecCheck `yaml:"exec,omitempty" json:"exec,omitempty"`
+	TCP  *TCPCheck  `yaml:"tcp,omitempty" json:"tcp,omitempty"`
+	GRPC *GRPCCheck `yaml:"grpc,omitempty" json:"grpc,omitempty"`
 }
 
 type HTTPCheck struct {
@@ -518,6 +520,34 @@ type ExecCheck struct {
 	Duration int    `yaml:"duration,omitempty" json:"duration,omitempty"`
 }
 
+// TCPCheck 检查端口是否可以建立 tcp 连接
+type TCPCheck struct {
+	Port     int `yaml:"port,omitempty" json:"port,omitempty"`
+	Duration int `yaml:"duration,omitempty" json:"duration,omitempty"`
+	// InitialDelay 容器启动后开始检查的延迟, 单位: 秒
+	InitialDelay int `yaml:"initial_delay,omitempty" json:"initial_delay,omitempty"`
+	// FailureThreshold 连续失败多少次视为不健康
+	FailureThreshold int `yaml:"failure_threshold,omitempty" json:"failure_threshold,omitempty"`
+	// SuccessThreshold 连续成功多少次视为就绪, 只对 readiness 生效
+	SuccessThreshold int `yaml:"success_threshold,omitempty" json:"success_threshold,omitempty"`
+}
+
+// GRPCCheck 基于 grpc health checking protocol (grpc.health.v1.Health/Check) 检查,
+// 服务需要实现 grpc.health.v1.Health, 检查通过 grpc_health_probe 执行:
+// 平台配置了 GRPC_HEALTH_PROBE_IMAGE 时由 init container 注入, 否则需要服务镜像的 PATH 中提供 grpc_health_probe
+type GRPCCheck struct {
+	Port int `yaml:"port,omitempty" json:"port,omitempty"`
+	// Service 检查的 grpc service name, 为空则检查整个 server
+	Service  string `yaml:"service,omitempty" json:"service,omitempty"`
+	Duration int    `yaml:"duration,omitempty" json:"duration,omitempty"`
+	// InitialDelay 容器启动后开始检查的延迟, 单位: 秒
+	InitialDelay int `yaml:"initial_delay,omitempty" json:"initial_delay,omitempty"`
+	// FailureThreshold 连续失败多少次视为不健康
+	FailureThreshold int `yaml:"failure_threshold,omitempty" json:"failure_threshold,omitempty"`
+	// SuccessThreshold 连续成功多少次视为就绪, 只对 readiness 生效
+	SuccessThreshold int `yaml:"success_threshold,omitempty" json:"success_threshold,omitempty"`
+}
+
 type Resources struct {
 	CPU     float64           `yaml:"cpu,omitempty" json:"cpu"`
 	Mem     int               `yaml:"mem,omitempty" json:"mem"`
@@ -536,6 +566,55 @@ type Deployments struct {
 	// Selectors available selectors:
 	// [location]
 	Selectors Selectors `yaml:"selectors,omitempty" json:"selectors,omitempty"`
+	// Autoscaling horizontal pod autoscaling, replicas is used as the initial replicas when enabled
+	Autoscaling *Autoscaling `yaml:"autoscaling,omitempty" json:"autoscaling,omitempty"`
+}
+
+const (
+	AutoscalingMetricTypePods     = "pods"
+	AutoscalingMetricTypeExternal = "external"
+)
+
+// Autoscaling 水平自动伸缩, 至少需要一个伸缩指标
+type Autoscaling struct {
+	// MinReplicas 默认为 1
+	MinReplicas int `yaml:"min_replicas,omitempty" json:"min_replicas,omitempty"`
+	MaxReplicas int `yaml:"max_replicas,omitempty" json:"max_replicas,omitempty"`
+	// TargetCPU 目标平均 cpu 使用率, 相对于 resources.cpu 的百分比
+	TargetCPU int `yaml:"target_cpu,omitempty" json:"target_cpu,omitempty"`
+	// TargetMem 目标平均内存使用率, 相对于 resources.mem 的百分比
+	TargetMem int `yaml:"target_mem,omitempty" json:"target_mem,omitempty"`
+	// Metrics 自定义指标
+	Metrics []AutoscalingMetric `yaml:"metrics,omitempty" json:"metrics,omitempty"`
+	// ScaleDownStabilization 缩容稳定窗口, 单位: 秒
+	ScaleDownStabilization int `yaml:"scale_down_stabilization,omitempty" json:"scale_down_stabilization,omitempty"`
+}
+
+// GetMinReplicas returns min replicas, default 1
+func (a *Autoscaling) GetMinReplicas() int {
+	if a.MinReplicas <= 0 {
+		return 1
+	}
+	return a.MinReplicas
+}
+
+// AutoscalingMetric 自定义伸缩指标
+type AutoscalingMetric struct {
+	// Type pods(default) or external
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Selector 只对 external 类型指标生效
+	Selector map[string]string `yaml:"selector,omitempty" json:"selector,omitempty"`
+	// TargetAverageValue 目标平均值, k8s quantity 格式, e.g. 100, 500m
+	TargetAverageValue string `yaml:"target_average_value,omitempty" json:"target_average_value,omitempty"`
+}
+
+// GetType returns metric type, default pods
+func (m AutoscalingMetric) GetType() string {
+	if m.Type == "" {
+		return AutoscalingMetricTypePods
+	}
+	return m.Type
 }
 
 type TrafficSecurity struct {
@@ -606,6 +685,8 @@ type DiceYmlVisitor interface {
 	VisitHealthCheck(v DiceYmlVisitor, obj *HealthCheck)
 	VisitHTTPCheck(v DiceYmlVisitor, obj *HTTPCheck)
 	VisitExecCheck(v DiceYmlVisitor, obj *ExecCheck)
+	VisitTCPCheck(v DiceYmlVisitor, obj *TCPCheck)
+	VisitGRPCCheck(v DiceYmlVisitor, obj *GRPCCheck)
 	VisitDeployments(v DiceYmlVisitor, obj *Deployments)
 	VisitBinds(v DiceYmlVisitor, obj *Binds)
 	VisitK8SSnippet(v DiceYmlVisitor, obj *K8SSnippet)
@@ -683,6 +764,14 @@ func (obj *HealthCheck) Accept(v DiceYmlVisitor) {
 		obj.Exec = new(ExecCheck)
 	}
 	obj.Exec.Accept(v)
+	if obj.TCP == nil {
+		obj.TCP = new(TCPCheck)
+	}
+	obj.TCP.Accept(v)
+	if obj.GRPC == nil {
+		obj.GRPC = new(GRPCCheck)
+	}
+	obj.GRPC.Accept(v)
 
 	v.VisitHealthCheck(v, obj)
 }
@@ -692,6 +781,12 @@ func (obj *HTTPCheck) Accept(v DiceYmlVisitor) {
 func (obj *ExecCheck) Accept(v DiceYmlVisitor) {
 	v.VisitExecCheck(v, obj)
 }
+func (obj *TCPCheck) Accept(v DiceYmlVisitor) {
+	v.VisitTCPCheck(v, obj)
+}
+func (obj *GRPCCheck) Accept(v DiceYmlVisitor) {
+	v.VisitGRPCCheck(v, obj)
+}
 
 func (obj *Deployments) Accept(v DiceYmlVisitor) {
 	v.VisitDeployments(v, obj)
@@ -743,6 +838,8 @@ func (*DefaultVisitor) VisitResources(v DiceYmlVisitor, obj *Resources)
 func (*DefaultVisitor) VisitHealthCheck(v DiceYmlVisitor, obj *HealthCheck)           {}
 func (*DefaultVisitor) VisitHTTPCheck(v DiceYmlVisitor, obj *HTTPCheck)               {}
 func (*DefaultVisitor) VisitExecCheck(v DiceYmlVisitor, obj *ExecCheck)               {}
+func (*DefaultVisitor) VisitTCPCheck(v DiceYmlVisitor, obj *TCPCheck)                 {}
+func (*DefaultVisitor) VisitGRPCCheck(v DiceYmlVisitor, obj *GRPCCheck)               {}
 func (*DefaultVisitor) VisitDeployments(v DiceYmlVisitor, obj *Deployments)           {}
 func (*DefaultVisitor) VisitBinds(v DiceYmlVisitor, obj *Binds)                       {}
 func (*DefaultVisitor) VisitK8SSnippet(v DiceYmlVisitor, obj *K8SSnippet)             {}
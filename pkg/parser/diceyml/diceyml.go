@@ -354,6 +354,10 @@ func validate(obj *Object, raw []byte) ValidateError {
 	if len(checkEnvSize) > 0 {
 		errs = mergeValidateErr(errs, checkEnvSize)
 	}
+	secretRefs := SecretRefValidate(obj)
+	if len(secretRefs) > 0 {
+		errs = mergeValidateErr(errs, secretRefs)
+	}
 
 	return errs
 
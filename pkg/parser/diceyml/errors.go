@@ -41,6 +41,22 @@ var (
 	invalidEndpointPath        = errortype("invalid path in endpoints, must start with '/'")
 )
 
+var (
+	invalidAutoscalingReplicas      = errortype("invalid autoscaling replicas, must be 1 <= min_replicas <= max_replicas")
+	invalidAutoscalingTarget        = errortype("invalid autoscaling target, must be positive percentage")
+	emptyAutoscalingTarget          = errortype("empty autoscaling target, at least one of target_cpu, target_mem and metrics is required")
+	invalidAutoscalingMetric        = errortype("invalid autoscaling metric, type must be 'pods' or 'external', name and target_average_value required")
+	invalidAutoscalingStabilization = errortype("invalid autoscaling scale_down_stabilization, must be between 0 and 3600 seconds")
+	autoscalingPerNode              = errortype("autoscaling can not be used with workload per_node")
+)
+
+var (
+	invalidHealthCheckPort        = errortype("invalid health check port, must be between 1 and 65535")
+	invalidHealthCheckThreshold   = errortype("invalid health check initial_delay or thresholds, must not be negative")
+	multipleHealthCheck           = errortype("tcp or grpc health check can not be used with other health checks")
+	invalidGrpcHealthCheckService = errortype("invalid grpc health check service, must be a fully qualified grpc service name, e.g. helloworld.Greeter")
+)
+
 type errortype string
 
 func (e errortype) Error() string {
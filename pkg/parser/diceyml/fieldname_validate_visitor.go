@@ -148,8 +148,8 @@ func (o *FieldnameValidateVisitor) VisitDeployments(v DiceYmlVisitor, obj *Deplo
 	for k := range dep {
 		switch i := k.(type) {
 		case string:
-			if !contain(i, []string{"workload", "replicas", "policies", "labels", "selectors"}) {
-				o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentServiceName, "deployments"}, i)] = fmt.Errorf("[%s]/[deployments] field '%s' not one of [replicas, policies, labels, selectors]", o.currentServiceName, i)
+			if !contain(i, []string{"workload", "replicas", "policies", "labels", "selectors", "autoscaling"}) {
+				o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentServiceName, "deployments"}, i)] = fmt.Errorf("[%s]/[deployments] field '%s' not one of [replicas, policies, labels, selectors, autoscaling]", o.currentServiceName, i)
 			}
 		default:
 			o.collectErrors[yamlHeaderRegex("_"+strconv.Itoa(len(o.collectErrors)))] = fmt.Errorf("[%s]/[deployments] %v not string type", o.currentServiceName, k)
@@ -165,8 +165,8 @@ func (o *FieldnameValidateVisitor) VisitHealthCheck(v DiceYmlVisitor, obj *Healt
 	for k := range hc {
 		switch i := k.(type) {
 		case string:
-			if !contain(i, []string{"http", "exec"}) {
-				o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentServiceName, "health_check"}, i)] = fmt.Errorf("[%s]/[health_check] field '%s' not one of [http, exec]", o.currentServiceName, i)
+			if !contain(i, []string{"http", "exec", "tcp", "grpc"}) {
+				o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentServiceName, "health_check"}, i)] = fmt.Errorf("[%s]/[health_check] field '%s' not one of [http, exec, tcp, grpc]", o.currentServiceName, i)
 			}
 		default:
 			o.collectErrors[yamlHeaderRegex("_"+strconv.Itoa(len(o.collectErrors)))] = fmt.Errorf("[%s]/[health_check] %v not string type", o.currentServiceName, k)
@@ -216,6 +216,48 @@ func (o *FieldnameValidateVisitor) VisitExecCheck(v DiceYmlVisitor, obj *ExecChe
 	}
 }
 
+func (o *FieldnameValidateVisitor) VisitTCPCheck(v DiceYmlVisitor, obj *TCPCheck) {
+	hc, ok := o.currentService["health_check"].(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+	tcp, ok := hc["tcp"].(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+	for k := range tcp {
+		switch i := k.(type) {
+		case string:
+			if !contain(i, []string{"port", "duration", "initial_delay", "failure_threshold", "success_threshold"}) {
+				o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentServiceName, "health_check", "tcp"}, i)] = fmt.Errorf("[%s]/[health_check]/[tcp] field '%s' not one of [port, duration, initial_delay, failure_threshold, success_threshold]", o.currentServiceName, i)
+			}
+		default:
+			o.collectErrors[yamlHeaderRegex("_"+strconv.Itoa(len(o.collectErrors)))] = fmt.Errorf("[%s]/[health_check]/[tcp] %v not string type", o.currentServiceName, k)
+		}
+	}
+}
+
+func (o *FieldnameValidateVisitor) VisitGRPCCheck(v DiceYmlVisitor, obj *GRPCCheck) {
+	hc, ok := o.currentService["health_check"].(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+	grpc, ok := hc["grpc"].(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+	for k := range grpc {
+		switch i := k.(type) {
+		case string:
+			if !contain(i, []string{"port", "service", "duration", "initial_delay", "failure_threshold", "success_threshold"}) {
+				o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentServiceName, "health_check", "grpc"}, i)] = fmt.Errorf("[%s]/[health_check]/[grpc] field '%s' not one of [port, service, duration, initial_delay, failure_threshold, success_threshold]", o.currentServiceName, i)
+			}
+		default:
+			o.collectErrors[yamlHeaderRegex("_"+strconv.Itoa(len(o.collectErrors)))] = fmt.Errorf("[%s]/[health_check]/[grpc] %v not string type", o.currentServiceName, k)
+		}
+	}
+}
+
 func (o *FieldnameValidateVisitor) VisitK8SSnippet(v DiceYmlVisitor, obj *K8SSnippet) {
 	res, ok := o.currentService["k8s_snippet"].(map[interface{}]interface{})
 	if !ok {
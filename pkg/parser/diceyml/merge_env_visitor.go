@@ -100,6 +100,9 @@ func (o *MergeEnvVisitor) VisitDeployments(v DiceYmlVisitor, obj *Deployments) {
 	}
 	overrideIfNotZero(o.envObj.Services[o.currentService].Deployments.Policies, &obj.Policies)
 	overrideIfNotZero(o.envObj.Services[o.currentService].Deployments.Labels, &obj.Labels)
+	if o.envObj.Services[o.currentService].Deployments.Autoscaling != nil {
+		obj.Autoscaling = o.envObj.Services[o.currentService].Deployments.Autoscaling
+	}
 }
 
 func (o *MergeEnvVisitor) VisitHTTPCheck(v DiceYmlVisitor, obj *HTTPCheck) {
@@ -131,6 +134,41 @@ func (o *MergeEnvVisitor) VisitExecCheck(v DiceYmlVisitor, obj *ExecCheck) {
 	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.Exec.Duration, &obj.Duration)
 }
 
+func (o *MergeEnvVisitor) VisitTCPCheck(v DiceYmlVisitor, obj *TCPCheck) {
+	if o.currentService == "" {
+		panic("should not be empty")
+	}
+	if s, ok := o.envObj.Services[o.currentService]; !ok || s == nil {
+		return
+	}
+	if o.envObj.Services[o.currentService].HealthCheck.TCP == nil {
+		return
+	}
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.TCP.Port, &obj.Port)
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.TCP.Duration, &obj.Duration)
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.TCP.InitialDelay, &obj.InitialDelay)
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.TCP.FailureThreshold, &obj.FailureThreshold)
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.TCP.SuccessThreshold, &obj.SuccessThreshold)
+}
+
+func (o *MergeEnvVisitor) VisitGRPCCheck(v DiceYmlVisitor, obj *GRPCCheck) {
+	if o.currentService == "" {
+		panic("should not be empty")
+	}
+	if s, ok := o.envObj.Services[o.currentService]; !ok || s == nil {
+		return
+	}
+	if o.envObj.Services[o.currentService].HealthCheck.GRPC == nil {
+		return
+	}
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.GRPC.Port, &obj.Port)
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.GRPC.Service, &obj.Service)
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.GRPC.Duration, &obj.Duration)
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.GRPC.InitialDelay, &obj.InitialDelay)
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.GRPC.FailureThreshold, &obj.FailureThreshold)
+	overrideIfNotZero(o.envObj.Services[o.currentService].HealthCheck.GRPC.SuccessThreshold, &obj.SuccessThreshold)
+}
+
 func (o *MergeEnvVisitor) VisitAddOns(v DiceYmlVisitor, obj *AddOns) {
 	if len(o.envObj.AddOns) == 0 {
 		return
@@ -0,0 +1,104 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diceyml
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SecretRefPrefix env 值以此为前缀时表示引用 KMS 加密的 secret，
+// 格式: secret://<keyID>/<name>，部署时由 orchestrator 解析并以 k8s Secret 的形式注入
+const SecretRefPrefix = "secret://"
+
+var (
+	secretRefKeyIDRegex = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+	secretRefNameRegex  = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+)
+
+// SecretRef is a parsed `secret://<keyID>/<name>` reference.
+type SecretRef struct {
+	// KeyID is the KMS CMK which the secret is sealed under
+	KeyID string
+	// Name is the name of the sealed secret in config-center
+	Name string
+}
+
+func (r SecretRef) String() string {
+	return SecretRefPrefix + r.KeyID + "/" + r.Name
+}
+
+// IsSecretRef returns whether env value is a secret reference.
+func IsSecretRef(v string) bool {
+	return strings.HasPrefix(v, SecretRefPrefix)
+}
+
+// ParseSecretRef parses `secret://<keyID>/<name>`.
+func ParseSecretRef(v string) (*SecretRef, error) {
+	if !IsSecretRef(v) {
+		return nil, fmt.Errorf("secret reference must start with %s", SecretRefPrefix)
+	}
+	parts := strings.Split(strings.TrimPrefix(v, SecretRefPrefix), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid secret reference: %s, format: %s<keyID>/<name>", v, SecretRefPrefix)
+	}
+	if !secretRefKeyIDRegex.MatchString(parts[0]) {
+		return nil, fmt.Errorf("invalid keyID in secret reference: %s", v)
+	}
+	if !secretRefNameRegex.MatchString(parts[1]) {
+		return nil, fmt.Errorf("invalid name in secret reference: %s", v)
+	}
+	return &SecretRef{KeyID: parts[0], Name: parts[1]}, nil
+}
+
+type SecretRefVisitor struct {
+	DefaultVisitor
+	collectErrors ValidateError
+}
+
+func NewSecretRefVisitor() DiceYmlVisitor {
+	return &SecretRefVisitor{
+		collectErrors: ValidateError{},
+	}
+}
+
+func (o *SecretRefVisitor) VisitObject(v DiceYmlVisitor, obj *Object) {
+	for k, v := range obj.Envs {
+		if !IsSecretRef(v) {
+			continue
+		}
+		if _, err := ParseSecretRef(v); err != nil {
+			o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{"envs"}, k)] = fmt.Errorf("global env [%s]: %v", k, err)
+		}
+	}
+}
+
+func (o *SecretRefVisitor) VisitService(v DiceYmlVisitor, obj *Service) {
+	for k, v := range obj.Envs {
+		if !IsSecretRef(v) {
+			continue
+		}
+		if _, err := ParseSecretRef(v); err != nil {
+			o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService, "envs"}, k)] = fmt.Errorf("env [%s]: %v", k, err)
+		}
+	}
+}
+
+func SecretRefValidate(obj *Object) ValidateError {
+	visitor := NewSecretRefVisitor()
+	obj.Accept(visitor)
+	return visitor.(*SecretRefVisitor).collectErrors
+}
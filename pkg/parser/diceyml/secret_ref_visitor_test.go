@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diceyml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var secretref_yml = `version: 2.0
+
+envs:
+  DB_PASSWORD: secret://0bb4d5a3-a8c1-4b5b-9a2f-3b1e2f7f0a11/db.password
+  BAD_GLOBAL: secret://key/a/b
+
+services:
+  web:
+    image: nginx
+    envs:
+      API_TOKEN: secret://0bb4d5a3-a8c1-4b5b-9a2f-3b1e2f7f0a11/api_token
+      BAD_KEY: secret://key$/name
+      BAD_NAME: secret://key/
+      PLAIN: not-a-secret
+    resources:
+      cpu: 0.1
+      mem: 256
+    deployments:
+      replicas: 1
+`
+
+func TestSecretRefValidate(t *testing.T) {
+	d, err := New([]byte(secretref_yml), false)
+	assert.Nil(t, err)
+	es := SecretRefValidate(d.Obj())
+	assert.Equal(t, 3, len(es), "%v", es)
+}
+
+func TestParseSecretRef(t *testing.T) {
+	ref, err := ParseSecretRef("secret://my-key/db.password")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-key", ref.KeyID)
+	assert.Equal(t, "db.password", ref.Name)
+	assert.Equal(t, "secret://my-key/db.password", ref.String())
+
+	for _, v := range []string{"my-key/db.password", "secret://", "secret://my-key", "secret:///name", "secret://my key/name"} {
+		_, err := ParseSecretRef(v)
+		assert.NotNil(t, err, v)
+	}
+}
@@ -72,8 +72,9 @@ type StorageConfig struct {
 }
 
 type TriggerConfig struct {
-	Push  *PushTrigger  `yaml:"push,omitempty"`
-	Merge *MergeTrigger `yaml:"merge,omitempty"`
+	Push    *PushTrigger    `yaml:"push,omitempty"`
+	Merge   *MergeTrigger   `yaml:"merge,omitempty"`
+	Webhook *WebhookTrigger `yaml:"webhook,omitempty"`
 }
 
 type PushTrigger struct {
@@ -85,11 +86,31 @@ type MergeTrigger struct {
 	Branches []string `yaml:"branches,omitempty"`
 }
 
+// WebhookTrigger 外部系统通过 webhook 触发流水线
+type WebhookTrigger struct {
+	// Secret references the HMAC-SHA256 key used to sign the payload, e.g. ${{ configs.webhook_secret }}
+	Secret string `yaml:"secret,omitempty"`
+	// Params maps payload into pipeline params, e.g. version: ${{ payload.artifact.version }}
+	Params map[string]string `yaml:"params,omitempty"`
+}
+
 type indexedAction struct {
 	*Action
 	stageIndex int
 }
 
+func (a *indexedAction) NodeName() string {
+	return a.Alias.String()
+}
+
+func (a *indexedAction) PrevNodeNames() []string {
+	var names []string
+	for _, need := range a.Needs {
+		names = append(names, need.String())
+	}
+	return names
+}
+
 // Stage represents a stage.
 // Stages executes in series;
 // Actions under a same stage executes in parallel.
@@ -126,6 +147,13 @@ type Action struct {
 	Commands  []string                     `yaml:"commands,omitempty"`
 	Loop      *apistructs.PipelineTaskLoop `yaml:"loop,omitempty"`
 
+	// Matrix 构建矩阵，parser 按变量组合将 action 展开为多个并行的 action
+	Matrix *Matrix `yaml:"matrix,omitempty"`
+	// MatrixGroup 由 matrix 展开的 action 对应的原始 alias，由 parser 自动赋值
+	MatrixGroup ActionAlias `yaml:"-"`
+	// MatrixFailFast 同组 action 失败时是否取消其余仍在运行的 action，由 parser 自动赋值
+	MatrixFailFast bool `yaml:"-"`
+
 	Timeout int64 `yaml:"timeout,omitempty"` // unit: second
 
 	Resources Resources `yaml:"resources,omitempty"`
@@ -138,21 +166,24 @@ type Action struct {
 
 	If string `yaml:"if,omitempty"` // 条件执行
 
-	// TODO 在未来版本中，可能去除 stage，依赖关系则必须通过 Needs 来声明。
-	// 目前不开放给用户使用。由 parser 自动赋值。
 	// Needs 显式声明依赖的 actions。隐式依赖关系是下一个 stage 依赖之前所有 stage 里的 action。
 	// Needs 可以绕开 stage 限制，以 DAG 方式声明依赖关系。
 	// Needs 一旦声明，只包含声明的值，不会注入其他依赖。
-	Needs []ActionAlias `yaml:"-"`
+	// 未声明时由 parser 根据 stage 顺序自动赋值。
+	Needs []ActionAlias `yaml:"needs,omitempty"`
 
 	// TODO 该字段目前是兼容字段。
 	// 在 1.1 版本中，Needs = NeedNamespaces
 	// 在 1.0 版本中，Needs <= NeedNamespaces
 	// 目前不开放给用户使用。由 parser 自动赋值。
 	// NeedNamespaces 显式声明依赖的 namespaces。隐式依赖关系是下一个 stage 依赖之前所有 stage 的 namespaces。
+	// 若显式声明了 Needs，则为所依赖 actions 的 namespaces。
 	// NeedNamespaces 一旦声明，只包含声明的值，不会注入其他依赖。
 	NeedNamespaces []string `yaml:"-"`
 
+	// implicitNeeds 表示 Needs 是否由 parser 根据 stage 顺序自动赋值，生成 yaml 时不输出
+	implicitNeeds bool
+
 	// TODO 该字段目前是兼容字段，在未来版本中可以通过该字段扩展上下文。
 	// 目前不开放给用户使用。由 parser 自动赋值。
 	// Namespaces 显式声明 action 的命名空间，每个命名空间在流水线上下文目录下是唯一的，可以是目录或者文件。
@@ -161,6 +192,30 @@ type Action struct {
 	Namespaces []string `yaml:"namespaces,omitempty"`
 }
 
+// Matrix 构建矩阵，除 exclude/include/fail_fast 外的 key 均为变量，值为标量列表
+//
+//	matrix:
+//	  go: [1.16, 1.17]
+//	  os: [linux, darwin]
+//	  exclude:
+//	    - go: 1.16
+//	      os: darwin
+//	  include:
+//	    - go: 1.18
+//	      os: linux
+//	  fail_fast: false
+type Matrix struct {
+	Axes     []MatrixAxis        // 按声明顺序
+	Exclude  []map[string]string // 从组合中排除，变量全部相等时命中
+	Include  []map[string]string // 额外追加的组合
+	FailFast *bool               // 默认 true
+}
+
+type MatrixAxis struct {
+	Name   string
+	Values []string
+}
+
 type SnippetConfig struct {
 	Source string            `yaml:"source,omitempty"` // 来源 gittar dice test
 	Name   string            `yaml:"name,omitempty"`   // 名称
@@ -25,6 +25,7 @@ import (
 // GenerateYml 根据 spec 重新生成 yaml 文本，一般用于对 spec 进行调整后重新生成 yaml 文本
 func GenerateYml(s *Spec) ([]byte, error) {
 	polishNamespaces(s)
+	defer polishNeeds(s)()
 	var newYmlBuf bytes.Buffer
 	encoder := yaml.NewEncoder(&newYmlBuf)
 	encoder.SetIndent(1)
@@ -48,3 +49,25 @@ func polishNamespaces(s *Spec) {
 		}
 	}
 }
+
+// polishNeeds 遍历 actions，生成 yaml 时临时去除 parser 根据 stage 顺序自动注入的 needs，只保留用户显式声明的 needs。
+// 返回的函数用于恢复自动注入的 needs，保证 spec 本身不受影响。
+func polishNeeds(s *Spec) (restore func()) {
+	implicitNeeds := make(map[*Action][]ActionAlias)
+	for _, stage := range s.Stages {
+		for _, typedAction := range stage.Actions {
+			for _, action := range typedAction {
+				if action == nil || !action.implicitNeeds {
+					continue
+				}
+				implicitNeeds[action] = action.Needs
+				action.Needs = nil
+			}
+		}
+	}
+	return func() {
+		for action, needs := range implicitNeeds {
+			action.Needs = needs
+		}
+	}
+}
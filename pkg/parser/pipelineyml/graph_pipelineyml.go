@@ -137,7 +137,8 @@ func ConvertToGraphPipelineYml(data []byte) (*apistructs.PipelineYml, error) {
 	if pipelineYml.Spec().On != nil {
 		merge := pipelineYml.Spec().On.Merge
 		push := pipelineYml.Spec().On.Push
-		if merge != nil || push != nil {
+		webhook := pipelineYml.Spec().On.Webhook
+		if merge != nil || push != nil || webhook != nil {
 			on = &apistructs.TriggerConfig{}
 			if merge != nil {
 				var branches []string
@@ -159,6 +160,12 @@ func ConvertToGraphPipelineYml(data []byte) (*apistructs.PipelineYml, error) {
 					Tags:     tags,
 				}
 			}
+			if webhook != nil {
+				on.Webhook = &apistructs.WebhookTrigger{
+					Secret: webhook.Secret,
+					Params: webhook.Params,
+				}
+			}
 		}
 	}
 
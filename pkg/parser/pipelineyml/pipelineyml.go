@@ -138,10 +138,13 @@ func New(b []byte, ops ...Option) (_ *PipelineYml, err error) {
 
 	// 遍历 action，为 render ref,output 做准备
 	// 不做 flatParams，JSON 序列化在最后进行，防止简单 render 后 JSON 无效
+	// matrix 需要在 stageVisitor 之前展开，展开后的 action 才能参与 alias、needs 等校验
+	y.s.Accept(NewMatrixVisitor())
 	y.s.Accept(NewStageVisitor(false))
 
 	y.s.Accept(NewCronVisitor())
 	y.s.Accept(NewTimeoutVisitor())
+	y.s.Accept(NewWebhookVisitor(y.secrets != nil))
 
 	if len(y.aliasToCheckRefOp) > 0 {
 		y.s.Accept(NewRefOpVisitor(y.aliasToCheckRefOp, y.refs, y.outputs, y.allowMissingCustomScriptOutputs, y.globalSnippetConfigLabels))
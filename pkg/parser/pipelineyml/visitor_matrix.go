@@ -0,0 +1,399 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/erda-project/erda/pkg/expression"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml/pexpr"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// MatrixPrefix matrix 变量引用前缀，例如：${{ matrix.go }}
+const MatrixPrefix = "matrix"
+
+const (
+	matrixKeyExclude  = "exclude"
+	matrixKeyInclude  = "include"
+	matrixKeyFailFast = "fail_fast"
+)
+
+var matrixAliasInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func (m *Matrix) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return errors.Errorf("matrix must be a map")
+	}
+	*m = Matrix{}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, node := value.Content[i].Value, value.Content[i+1]
+		switch key {
+		case matrixKeyExclude:
+			if err := node.Decode(&m.Exclude); err != nil {
+				return errors.Errorf("invalid matrix %s: %v", key, err)
+			}
+		case matrixKeyInclude:
+			if err := node.Decode(&m.Include); err != nil {
+				return errors.Errorf("invalid matrix %s: %v", key, err)
+			}
+		case matrixKeyFailFast:
+			var failFast bool
+			if err := node.Decode(&failFast); err != nil {
+				return errors.Errorf("invalid matrix %s: %v", key, err)
+			}
+			m.FailFast = &failFast
+		default:
+			var values []string
+			if err := node.Decode(&values); err != nil {
+				return errors.Errorf("invalid matrix variable %q, must be a list of scalars: %v", key, err)
+			}
+			m.Axes = append(m.Axes, MatrixAxis{Name: key, Values: values})
+		}
+	}
+	return nil
+}
+
+func (m Matrix) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, v interface{}) error {
+		var value yaml.Node
+		if err := value.Encode(v); err != nil {
+			return err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &value)
+		return nil
+	}
+	for _, axis := range m.Axes {
+		if err := add(axis.Name, axis.Values); err != nil {
+			return nil, err
+		}
+	}
+	if len(m.Exclude) > 0 {
+		if err := add(matrixKeyExclude, m.Exclude); err != nil {
+			return nil, err
+		}
+	}
+	if len(m.Include) > 0 {
+		if err := add(matrixKeyInclude, m.Include); err != nil {
+			return nil, err
+		}
+	}
+	if m.FailFast != nil {
+		if err := add(matrixKeyFailFast, *m.FailFast); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// IsFailFast 未声明时默认为 true
+func (m *Matrix) IsFailFast() bool {
+	return m.FailFast == nil || *m.FailFast
+}
+
+// Combinations 返回所有变量组合：先做笛卡尔积，再去掉 exclude 命中的组合，最后追加 include 中不重复的组合
+func (m *Matrix) Combinations() []map[string]string {
+	var combos []map[string]string
+	if len(m.Axes) > 0 {
+		combos = []map[string]string{{}}
+	}
+	for _, axis := range m.Axes {
+		var next []map[string]string
+		for _, combo := range combos {
+			for _, value := range axis.Values {
+				c := copyMatrixCombo(combo)
+				c[axis.Name] = value
+				next = append(next, c)
+			}
+		}
+		combos = next
+	}
+
+	var result []map[string]string
+	for _, combo := range combos {
+		excluded := false
+		for _, exclude := range m.Exclude {
+			if matchMatrixCombo(combo, exclude) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, combo)
+		}
+	}
+	for _, include := range m.Include {
+		duplicated := false
+		for _, combo := range result {
+			if len(combo) == len(include) && matchMatrixCombo(combo, include) {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated && len(include) > 0 {
+			result = append(result, copyMatrixCombo(include))
+		}
+	}
+	return result
+}
+
+// comboKeys 返回组合中变量的顺序：先按声明顺序，include 额外的变量按字母序
+func (m *Matrix) comboKeys(combo map[string]string) []string {
+	var keys, extra []string
+	declared := make(map[string]bool, len(m.Axes))
+	for _, axis := range m.Axes {
+		declared[axis.Name] = true
+		if _, ok := combo[axis.Name]; ok {
+			keys = append(keys, axis.Name)
+		}
+	}
+	for k := range combo {
+		if !declared[k] {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
+
+func copyMatrixCombo(combo map[string]string) map[string]string {
+	c := make(map[string]string, len(combo))
+	for k, v := range combo {
+		c[k] = v
+	}
+	return c
+}
+
+// matchMatrixCombo pattern 中的变量全部与 combo 相等时命中
+func matchMatrixCombo(combo, pattern map[string]string) bool {
+	for k, v := range pattern {
+		if cv, ok := combo[k]; !ok || cv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// MatrixVisitor 将声明了 matrix 的 action 展开为同一 stage 内多个并行的 action。
+// 展开后的 alias 为 <alias>-<value1>-<value2>，needs 中引用原 alias 的替换为展开后的全部 alias。
+type MatrixVisitor struct{}
+
+func NewMatrixVisitor() *MatrixVisitor {
+	return &MatrixVisitor{}
+}
+
+func (v *MatrixVisitor) Visit(s *Spec) {
+	expanded := make(map[ActionAlias][]ActionAlias)
+
+	for stageIndex, stage := range s.Stages {
+		var actions []typedActionMap
+		for _, typedActionMap := range stage.Actions {
+			// 缩进错误等情况由 stageVisitor 处理
+			if len(typedActionMap) != 1 {
+				actions = append(actions, typedActionMap)
+				continue
+			}
+			for actionType, action := range typedActionMap {
+				if action == nil || action.Matrix == nil {
+					actions = append(actions, typedActionMap)
+					continue
+				}
+				alias := action.Alias
+				if alias == "" {
+					alias = ActionAlias(actionType)
+				}
+				combos := action.Matrix.Combinations()
+				if len(combos) == 0 {
+					s.appendError(errors.New("matrix doesn't have any combinations"), stageIndex, alias)
+					continue
+				}
+				// 不同组合的值替换非法字符后可能得到相同的 alias，例如 1.17/x 与 1.17:x
+				matrixAliases := make(map[ActionAlias]struct{}, len(combos))
+				for _, combo := range combos {
+					matrixAction, err := action.renderMatrix(combo)
+					if err != nil {
+						s.appendError(err, stageIndex, alias)
+						break
+					}
+					var values []string
+					for _, k := range action.Matrix.comboKeys(combo) {
+						values = append(values, matrixAliasInvalidChars.ReplaceAllString(combo[k], "_"))
+					}
+					matrixAction.Alias = ActionAlias(strings.Join(append([]string{alias.String()}, values...), "-"))
+					if _, ok := matrixAliases[matrixAction.Alias]; ok {
+						s.appendError(errors.Errorf("matrix alias %q is duplicated, combinations must be distinct after replacing invalid chars with '_'", matrixAction.Alias), stageIndex, alias)
+						break
+					}
+					matrixAliases[matrixAction.Alias] = struct{}{}
+					matrixAction.Matrix = nil
+					matrixAction.MatrixGroup = alias
+					matrixAction.MatrixFailFast = action.Matrix.IsFailFast()
+					actions = append(actions, map[ActionType]*Action{actionType: matrixAction})
+					expanded[alias] = append(expanded[alias], matrixAction.Alias)
+				}
+			}
+		}
+		stage.Actions = actions
+	}
+
+	if len(expanded) == 0 {
+		return
+	}
+	s.LoopStagesActions(func(stage int, action *Action) {
+		if len(action.Needs) == 0 {
+			return
+		}
+		var needs []ActionAlias
+		for _, need := range action.Needs {
+			if aliases, ok := expanded[need]; ok && action.MatrixGroup != need {
+				needs = append(needs, aliases...)
+				continue
+			}
+			needs = append(needs, need)
+		}
+		action.Needs = needs
+	})
+	s.LoopStagesActions(func(stage int, action *Action) {
+		for _, ref := range action.matrixOutputRefs(expanded) {
+			s.appendError(errors.Errorf("cannot reference outputs of matrix action %q, reference one of the expanded actions instead: %s",
+				ref, strutil.Join(aliasesToStrings(expanded[ref]), ", ")), stage, action.Alias)
+		}
+	})
+}
+
+// matrixOutputRefs 返回 action 中通过 ${{ outputs.alias.key }} 或 ${alias:OUTPUT:key} 引用的已展开 matrix alias。
+// 展开后的 action 各自产生 outputs，引用原 alias 时无法确定使用哪一个
+func (action *Action) matrixOutputRefs(expanded map[ActionAlias][]ActionAlias) []ActionAlias {
+	found := make(map[ActionAlias]struct{})
+	check := func(s string) {
+		for _, sub := range expression.Re.FindAllStringSubmatch(s, -1) {
+			ss := strings.SplitN(sub[1], ".", 3)
+			if len(ss) == 3 && ss[0] == expression.Outputs {
+				found[ActionAlias(ss[1])] = struct{}{}
+			}
+		}
+		for _, sub := range expression.OldRe.FindAllStringSubmatch(s, -1) {
+			ss := strings.SplitN(sub[1], ":", 3)
+			if len(ss) == 3 && ss[1] == RefOpOutput {
+				found[ActionAlias(ss[0])] = struct{}{}
+			}
+		}
+	}
+	if b, err := yaml.Marshal(action.Params); err == nil {
+		check(string(b))
+	}
+	for _, command := range action.Commands {
+		check(command)
+	}
+	check(action.If)
+
+	var refs []ActionAlias
+	for alias := range found {
+		if _, ok := expanded[alias]; ok && action.MatrixGroup != alias {
+			refs = append(refs, alias)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i] < refs[j] })
+	return refs
+}
+
+func aliasesToStrings(aliases []ActionAlias) []string {
+	ss := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		ss = append(ss, alias.String())
+	}
+	return ss
+}
+
+// renderMatrix 复制 action 并渲染其中的 ${{ matrix.xxx }}
+func (action *Action) renderMatrix(combo map[string]string) (*Action, error) {
+	b, err := yaml.Marshal(action)
+	if err != nil {
+		return nil, err
+	}
+	var copied Action
+	if err := yaml.Unmarshal(b, &copied); err != nil {
+		return nil, err
+	}
+
+	var renderErr error
+	render := func(s string) string {
+		return strutil.ReplaceAllStringSubmatchFunc(pexpr.PhRe, s, func(subs []string) string {
+			ss := strings.SplitN(subs[1], ".", 2)
+			if ss[0] != MatrixPrefix {
+				return subs[0]
+			}
+			value, ok := combo[strings.TrimPrefix(subs[1], MatrixPrefix+".")]
+			if !ok {
+				renderErr = errors.Errorf("matrix variable %q is not defined", subs[1])
+				return subs[0]
+			}
+			return value
+		})
+	}
+
+	copied.Description = render(copied.Description)
+	copied.Image = render(copied.Image)
+	copied.Workspace = render(copied.Workspace)
+	copied.If = render(copied.If)
+	for i := range copied.Commands {
+		copied.Commands[i] = render(copied.Commands[i])
+	}
+	for k, v := range copied.Labels {
+		copied.Labels[k] = render(v)
+	}
+	for k, v := range copied.Params {
+		copied.Params[k] = renderMatrixValue(v, render)
+	}
+	for i := range copied.Caches {
+		copied.Caches[i].Key = render(copied.Caches[i].Key)
+		copied.Caches[i].Path = render(copied.Caches[i].Path)
+	}
+	if copied.SnippetConfig != nil {
+		copied.SnippetConfig.Name = render(copied.SnippetConfig.Name)
+		for k, v := range copied.SnippetConfig.Labels {
+			copied.SnippetConfig.Labels[k] = render(v)
+		}
+	}
+	if renderErr != nil {
+		return nil, renderErr
+	}
+	return &copied, nil
+}
+
+func renderMatrixValue(v interface{}, render func(string) string) interface{} {
+	switch vv := v.(type) {
+	case string:
+		return render(vv)
+	case map[string]interface{}:
+		for k, e := range vv {
+			vv[k] = renderMatrixValue(e, render)
+		}
+		return vv
+	case []interface{}:
+		for i, e := range vv {
+			vv[i] = renderMatrixValue(e, render)
+		}
+		return vv
+	default:
+		return v
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrixVisitor(t *testing.T) {
+	y, err := New([]byte(`
+version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: build
+          image: golang:${{ matrix.go }}
+          commands:
+            - GOOS=${{ matrix.os }} go build ./...
+          params:
+            tags: ["${{ matrix.os }}"]
+          matrix:
+            go: [1.16, "1.17"]
+            os: [linux, darwin]
+            exclude:
+              - go: 1.16
+                os: darwin
+            include:
+              - go: 1.17
+                os: windows
+                arch: arm64
+            fail_fast: false
+  - stage:
+      - custom-script:
+          alias: release
+          needs: [build]
+`))
+	assert.NoError(t, err)
+
+	var aliases []ActionAlias
+	y.Spec().LoopStagesActions(func(stage int, action *Action) {
+		if stage == 0 {
+			aliases = append(aliases, action.Alias)
+			assert.Nil(t, action.Matrix)
+			assert.Equal(t, ActionAlias("build"), action.MatrixGroup)
+			assert.False(t, action.MatrixFailFast)
+		}
+	})
+	assert.Equal(t, []ActionAlias{"build-1.16-linux", "build-1.17-linux", "build-1.17-darwin", "build-1.17-windows-arm64"}, aliases)
+
+	build := y.Spec().Stages[0].Actions[2]["custom-script"]
+	assert.Equal(t, "golang:1.17", build.Image)
+	assert.Equal(t, []string{"GOOS=darwin go build ./..."}, build.Commands)
+	assert.Equal(t, []interface{}{"darwin"}, build.Params["tags"])
+
+	release := y.Spec().Stages[1].Actions[0]["custom-script"]
+	assert.ElementsMatch(t, aliases, release.Needs)
+}
+
+func TestMatrixVisitor_Invalid(t *testing.T) {
+	_, err := New([]byte(`
+version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          image: golang:${{ matrix.go }}
+          matrix:
+            os: [linux]
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `matrix variable "matrix.go" is not defined`)
+
+	_, err = New([]byte(`
+version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          matrix:
+            os: linux
+`))
+	assert.Error(t, err)
+
+	_, err = New([]byte(`
+version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: build
+          matrix:
+            tag: ["1.17/x", "1.17:x"]
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `matrix alias "build-1.17_x" is duplicated`)
+
+	_, err = New([]byte(`
+version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: build
+          matrix:
+            os: [linux, darwin]
+  - stage:
+      - custom-script:
+          alias: release
+          commands:
+            - echo ${{ outputs.build.version }}
+      - custom-script:
+          alias: notify
+          params:
+            version: ${build:OUTPUT:version}
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `cannot reference outputs of matrix action "build", reference one of the expanded actions instead: build-linux, build-darwin`)
+	assert.Contains(t, err.Error(), "notify")
+}
+
+func TestMatrix_Combinations(t *testing.T) {
+	failFast := true
+	m := &Matrix{
+		Axes:     []MatrixAxis{{Name: "a", Values: []string{"1", "2"}}},
+		Include:  []map[string]string{{"a": "1"}},
+		FailFast: &failFast,
+	}
+	assert.Equal(t, []map[string]string{{"a": "1"}, {"a": "2"}}, m.Combinations())
+	assert.True(t, m.IsFailFast())
+
+	b, err := GenerateYml(&Spec{Version: "1.1", Stages: []*Stage{{Actions: []typedActionMap{{"custom-script": {Matrix: m}}}}}})
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "fail_fast: true")
+}
@@ -44,6 +44,7 @@ type RefOp struct {
 	IsNamespace       bool // 是否是 namespace
 	RefStageIndex     int  // ref 所属 stage index
 	CurrentStageIndex int  // 当前 action 的 stage index
+	IsUpstream        bool // ref 是否是当前 action 通过 needs 声明的上游 action
 }
 
 type Refs map[string]string
@@ -148,6 +149,7 @@ func (v *RefOpVisitor) handleOneParamOrCmdV2(ori string) string {
 			IsNamespace:       isNamespace,
 			RefStageIndex:     refStageIndex,
 			CurrentStageIndex: v.currentAction.stageIndex,
+			IsUpstream:        v.isUpstream(ss[1]),
 		}
 		switch ss[0] {
 		case expression.Dirs:
@@ -258,6 +260,7 @@ func (v *RefOpVisitor) handleOneParamOrCmd(ori string) string {
 			IsNamespace:       isNamespace,
 			RefStageIndex:     refStageIndex,
 			CurrentStageIndex: v.currentAction.stageIndex,
+			IsUpstream:        v.isUpstream(ss[0]),
 		}
 
 		switch len(ss) {
@@ -356,7 +359,7 @@ func (v *RefOpVisitor) handleOneRefOpOutput(refOp RefOp) (replaced string) {
 	}
 
 	// not found
-	if refOp.RefStageIndex < refOp.CurrentStageIndex {
+	if refOp.RefStageIndex < refOp.CurrentStageIndex || refOp.IsUpstream {
 		if v.allowMissingCustomScriptOutputs {
 			v.result.AppendWarn(fmt.Sprintf("%q, action %q may not have output %q", refOp.Ori, refOp.Ref, refOp.Key))
 		} else {
@@ -388,6 +391,42 @@ func (v *RefOpVisitor) getStageIndex(namespace string) (stageIndex int, isAlias
 	return
 }
 
+// isUpstream returns whether the ref (alias or namespace) belongs to an upstream action of current action,
+// upstream actions are calculated from needs recursively.
+func (v *RefOpVisitor) isUpstream(ref string) bool {
+	if v.currentAction == nil {
+		return false
+	}
+	var refAlias ActionAlias
+	for _, action := range v.allActions {
+		if action.Alias.String() == ref || strutil.Exist(action.Namespaces, ref) {
+			refAlias = action.Alias
+			break
+		}
+	}
+	if refAlias == "" || refAlias == v.currentAction.Alias {
+		return false
+	}
+
+	visited := make(map[ActionAlias]struct{})
+	queue := append([]ActionAlias{}, v.currentAction.Needs...)
+	for len(queue) > 0 {
+		alias := queue[0]
+		queue = queue[1:]
+		if alias == refAlias {
+			return true
+		}
+		if _, ok := visited[alias]; ok {
+			continue
+		}
+		visited[alias] = struct{}{}
+		if action, ok := v.allActions[alias]; ok {
+			queue = append(queue, action.Needs...)
+		}
+	}
+	return false
+}
+
 func (v *RefOpVisitor) handleRefEx(output string, refOp RefOp) string {
 	switch refOp.Ex {
 	case RefOpExEscape:
@@ -22,6 +22,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/erda-project/erda/pkg/dag"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
@@ -108,10 +109,11 @@ func (v *StageVisitor) Visit(s *Spec) {
 				// needs
 				if len(action.Needs) == 0 {
 					action.Needs = toList(availableActions)
+					action.implicitNeeds = true
 				}
 
-				// needNamespaces
-				if len(action.NeedNamespaces) == 0 {
+				// needNamespaces, explicit needs are handled after all actions visited
+				if len(action.NeedNamespaces) == 0 && action.implicitNeeds {
 					action.NeedNamespaces = toListStr(availableNamespaces)
 				}
 
@@ -142,6 +144,55 @@ func (v *StageVisitor) Visit(s *Spec) {
 			availableActions[action] = struct{}{}
 		}
 	}
+
+	v.visitExplicitNeeds(s)
+}
+
+// visitExplicitNeeds validates needs declared by user and checks cycle among all actions.
+func (v *StageVisitor) visitExplicitNeeds(s *Spec) {
+	var (
+		nodes           []dag.NamedNode
+		hasInvalidNeeds bool
+	)
+	for _, action := range s.allActions {
+		nodes = append(nodes, action)
+		if action.implicitNeeds {
+			continue
+		}
+		valid := true
+		action.Needs = dedupAliases(action.Needs)
+		for _, need := range action.Needs {
+			if need == action.Alias {
+				s.appendError(errors.Errorf("cannot need itself"), action.stageIndex, action.Alias)
+				valid = false
+				continue
+			}
+			if _, ok := s.allActions[need]; !ok {
+				s.appendError(errors.Errorf("needs nonexistent action %q", need), action.stageIndex, action.Alias)
+				valid = false
+			}
+		}
+		if !valid {
+			hasInvalidNeeds = true
+			continue
+		}
+		// needNamespaces
+		if len(action.NeedNamespaces) == 0 {
+			needNamespaces := make(map[string]struct{})
+			for _, need := range action.Needs {
+				for _, ns := range s.allActions[need].Namespaces {
+					needNamespaces[ns] = struct{}{}
+				}
+			}
+			action.NeedNamespaces = toListStr(needNamespaces)
+		}
+	}
+	if hasInvalidNeeds {
+		return
+	}
+	if _, err := dag.New(nodes); err != nil {
+		s.appendError(errors.Errorf("invalid needs: %v", err))
+	}
 }
 
 // flatParams 将 params 的 value (包括复杂结构体) 转换为 json(string)
@@ -184,6 +235,19 @@ func (action *Action) markParamsValueType() {
 	}
 }
 
+func dedupAliases(aliases []ActionAlias) []ActionAlias {
+	var r []ActionAlias
+	seen := make(map[ActionAlias]struct{}, len(aliases))
+	for _, alias := range aliases {
+		if _, ok := seen[alias]; ok {
+			continue
+		}
+		seen[alias] = struct{}{}
+		r = append(r, alias)
+	}
+	return r
+}
+
 func toList(m map[ActionAlias]struct{}) []ActionAlias {
 	var r []ActionAlias
 	for k := range m {
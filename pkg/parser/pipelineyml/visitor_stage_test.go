@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestStageVisitor_ExplicitNeeds(t *testing.T) {
+	y, err := New([]byte(`
+version: "1.1"
+stages:
+  - stage:
+      - git-checkout:
+          alias: repo
+      - custom-script:
+          alias: lint
+          needs: [repo]
+  - stage:
+      - custom-script:
+          alias: build
+          needs: [repo]
+      - custom-script:
+          alias: test
+`))
+	assert.NoError(t, err)
+
+	actions := y.Spec().allActions
+	assert.Equal(t, []ActionAlias{"repo"}, actions["lint"].Needs)
+	assert.Equal(t, []string{"repo"}, actions["lint"].NeedNamespaces)
+	assert.Equal(t, []ActionAlias{"repo"}, actions["build"].Needs)
+	assert.ElementsMatch(t, []ActionAlias{"repo", "lint"}, actions["test"].Needs)
+
+	// only explicit needs are generated, implicit needs of previous stages are omitted
+	b, err := GenerateYml(y.Spec())
+	assert.NoError(t, err)
+	var generated Spec
+	assert.NoError(t, yaml.Unmarshal(b, &generated))
+	generatedNeeds := make(map[ActionAlias][]ActionAlias)
+	for _, stage := range generated.Stages {
+		for _, typed := range stage.Actions {
+			for _, action := range typed {
+				generatedNeeds[action.Alias] = action.Needs
+			}
+		}
+	}
+	assert.Equal(t, map[ActionAlias][]ActionAlias{
+		"repo":  nil,
+		"lint":  {"repo"},
+		"build": {"repo"},
+		"test":  nil,
+	}, generatedNeeds)
+}
+
+func TestStageVisitor_InvalidNeeds(t *testing.T) {
+	_, err := New([]byte(`
+version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: a
+          needs: [b]
+      - custom-script:
+          alias: b
+          needs: [a]
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cycle detected")
+
+	_, err = New([]byte(`
+version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: a
+          needs: [not-exist]
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "nonexistent action")
+
+	_, err = New([]byte(`
+version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          alias: a
+          needs: [a]
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot need itself")
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/pkg/expression"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml/pexpr"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// WebhookPayload is the expression prefix to reference the posted webhook payload, e.g. ${{ payload.artifact.version }}
+const WebhookPayload = "payload"
+
+type WebhookVisitor struct {
+	// secretsRendered 为 true 时 secret 引用已被渲染为明文, 不再校验引用格式
+	secretsRendered bool
+}
+
+func NewWebhookVisitor(secretsRendered bool) *WebhookVisitor {
+	return &WebhookVisitor{secretsRendered: secretsRendered}
+}
+
+func (v *WebhookVisitor) Visit(s *Spec) {
+	if s.On == nil || s.On.Webhook == nil {
+		return
+	}
+	webhook := s.On.Webhook
+	if webhook.Secret == "" {
+		s.errs = append(s.errs, errors.New("on.webhook: secret must not be empty"))
+	} else if _, ok := WebhookSecretConfigKey(webhook.Secret); !ok && !v.secretsRendered {
+		s.errs = append(s.errs, errors.Errorf("on.webhook: secret must reference a config like ${{ %s.webhook_secret }}, plaintext is not allowed", expression.Configs))
+	}
+
+	declared := make(map[string]struct{}, len(s.Params))
+	for _, param := range s.Params {
+		declared[param.Name] = struct{}{}
+	}
+	for name, value := range webhook.Params {
+		if _, ok := declared[name]; !ok {
+			s.errs = append(s.errs, errors.Errorf("on.webhook: param %q is not declared in params", name))
+		}
+		if invalids := pexpr.FindInvalidPlaceholders(value); len(invalids) > 0 {
+			s.errs = append(s.errs, errors.Errorf("on.webhook: param %q has invalid placeholders: %s", name, strings.Join(invalids, ", ")))
+			continue
+		}
+		for _, sub := range pexpr.PhRe.FindAllStringSubmatch(value, -1) {
+			if sub[1] != WebhookPayload && !strings.HasPrefix(sub[1], WebhookPayload+".") {
+				s.errs = append(s.errs, errors.Errorf("on.webhook: param %q can only reference %s, but got %q", name, WebhookPayload, sub[1]))
+			}
+		}
+	}
+}
+
+// WebhookSecretConfigKey returns the config key referenced by on.webhook.secret,
+// e.g. ${{ configs.webhook_secret }} -> webhook_secret.
+func WebhookSecretConfigKey(secret string) (string, bool) {
+	secret = strings.TrimSpace(secret)
+	subs := pexpr.PhRe.FindStringSubmatch(secret)
+	if len(subs) != 2 || subs[0] != secret || !strings.HasPrefix(subs[1], expression.Configs+".") {
+		return "", false
+	}
+	key := strings.TrimPrefix(subs[1], expression.Configs+".")
+	return key, key != ""
+}
+
+// RenderWebhookParams renders webhook params expressions by the decoded json payload,
+// objects and arrays are rendered as json.
+func RenderWebhookParams(params map[string]string, payload interface{}) (map[string]string, error) {
+	result := make(map[string]string, len(params))
+	for name, value := range params {
+		var err error
+		result[name] = strutil.ReplaceAllStringSubmatchFunc(pexpr.PhRe, value, func(subs []string) string {
+			if err != nil {
+				return ""
+			}
+			var v interface{}
+			v, err = lookupWebhookPayload(payload, subs[1])
+			if err != nil {
+				return ""
+			}
+			return webhookValueString(v)
+		})
+		if err != nil {
+			return nil, errors.Errorf("failed to render webhook param %q: %v", name, err)
+		}
+	}
+	return result, nil
+}
+
+// lookupWebhookPayload finds the value by path like payload.a.b.0.c, a missing key returns nil.
+func lookupWebhookPayload(payload interface{}, path string) (interface{}, error) {
+	keys := strings.Split(path, ".")
+	if keys[0] != WebhookPayload {
+		return nil, fmt.Errorf("invalid reference %q", path)
+	}
+	current := payload
+	for _, key := range keys[1:] {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			current = v[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("invalid index %q of %q", key, path)
+			}
+			current = v[index]
+		default:
+			return nil, nil
+		}
+	}
+	return current, nil
+}
+
+func webhookValueString(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vv
+	case float64:
+		return strconv.FormatFloat(vv, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(vv)
+	default:
+		b, _ := json.Marshal(vv)
+		return string(b)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelineyml
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookVisitor(t *testing.T) {
+	y, err := New([]byte(`
+version: "1.1"
+on:
+  webhook:
+    secret: ${{ configs.webhook_secret }}
+    params:
+      version: ${{ payload.artifact.version }}
+params:
+  - name: version
+stages:
+  - stage:
+      - custom-script:
+          alias: a
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "${{ configs.webhook_secret }}", y.Spec().On.Webhook.Secret)
+
+	graph, err := ConvertToGraphPipelineYml([]byte(`
+version: "1.1"
+on:
+  webhook:
+    secret: ${{ configs.webhook_secret }}
+stages: []
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "${{ configs.webhook_secret }}", graph.On.Webhook.Secret)
+
+	_, err = New([]byte(`
+version: "1.1"
+on:
+  webhook:
+    secret: abc
+stages: []
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "plaintext is not allowed")
+
+	_, err = New([]byte(`
+version: "1.1"
+on:
+  webhook:
+    params:
+      version: ${{ configs.version }}
+stages: []
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "secret must not be empty")
+	assert.Contains(t, err.Error(), "is not declared in params")
+	assert.Contains(t, err.Error(), "can only reference payload")
+}
+
+func TestWebhookSecretConfigKey(t *testing.T) {
+	tests := []struct {
+		secret string
+		want   string
+		wantOk bool
+	}{
+		{secret: "${{ configs.webhook_secret }}", want: "webhook_secret", wantOk: true},
+		{secret: " ${{ configs.a.b }} ", want: "a.b", wantOk: true},
+		{secret: "abc"},
+		{secret: "${{ params.secret }}"},
+		{secret: "${{ configs. }}"},
+		{secret: "prefix-${{ configs.webhook_secret }}"},
+		{secret: "${{configs.webhook_secret}}"},
+	}
+	for _, tt := range tests {
+		got, ok := WebhookSecretConfigKey(tt.secret)
+		assert.Equal(t, tt.wantOk, ok, tt.secret)
+		assert.Equal(t, tt.want, got, tt.secret)
+	}
+}
+
+func TestRenderWebhookParams(t *testing.T) {
+	var payload interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"artifact":{"version":"1.0.1","tags":["latest","stable"],"size":1024,"meta":{"a":1}}}`), &payload))
+
+	params, err := RenderWebhookParams(map[string]string{
+		"version": "${{ payload.artifact.version }}",
+		"tag":     "v-${{ payload.artifact.tags.1 }}",
+		"size":    "${{ payload.artifact.size }}",
+		"meta":    "${{ payload.artifact.meta }}",
+		"missing": "${{ payload.artifact.missing }}",
+	}, payload)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"version": "1.0.1",
+		"tag":     "v-stable",
+		"size":    "1024",
+		"meta":    `{"a":1}`,
+		"missing": "",
+	}, params)
+
+	_, err = RenderWebhookParams(map[string]string{"tag": "${{ payload.artifact.tags.5 }}"}, payload)
+	assert.Error(t, err)
+}
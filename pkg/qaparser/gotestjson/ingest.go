@@ -0,0 +1,156 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gotestjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+)
+
+// TestEvent is a single line emitted by `go test -json`, see `go doc test2json`.
+type TestEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+type packageResult struct {
+	suite  *apistructs.TestSuite
+	tests  map[string]*apistructs.Test
+	output strings.Builder
+	failed bool
+}
+
+// Ingest reads `go test -json` events from r and returns one suite per package.
+// Lines that are not json events, e.g. build output mixed into the stream, are ignored.
+func Ingest(r io.Reader) ([]*apistructs.TestSuite, error) {
+	var (
+		packages = map[string]*packageResult{}
+		order    []string
+	)
+	getPackage := func(name string) *packageResult {
+		pkg, ok := packages[name]
+		if !ok {
+			pkg = &packageResult{
+				suite: &apistructs.TestSuite{Name: name, Package: name},
+				tests: map[string]*apistructs.Test{},
+			}
+			packages[name] = pkg
+			order = append(order, name)
+		}
+		return pkg
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var event TestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+		pkg := getPackage(event.Package)
+		if event.Test == "" {
+			switch event.Action {
+			case "output":
+				pkg.output.WriteString(event.Output)
+			case "fail":
+				pkg.failed = true
+			}
+			continue
+		}
+		test, ok := pkg.tests[event.Test]
+		if !ok {
+			test = &apistructs.Test{
+				Name:      event.Test,
+				Classname: event.Package,
+				Status:    apistructs.TestStatusPassed,
+			}
+			pkg.tests[event.Test] = test
+			pkg.suite.Tests = append(pkg.suite.Tests, test)
+		}
+		switch event.Action {
+		case "output":
+			test.SystemOut += event.Output
+		case "pass":
+			test.Status = apistructs.TestStatusPassed
+			test.Duration = elapsed(event.Elapsed)
+		case "skip":
+			test.Status = apistructs.TestStatusSkipped
+			test.Duration = elapsed(event.Elapsed)
+		case "fail":
+			test.Status = apistructs.TestStatusFailed
+			test.Duration = elapsed(event.Elapsed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read go test output")
+	}
+
+	var suites []*apistructs.TestSuite
+	for _, name := range order {
+		pkg := packages[name]
+		pkg.suite.SystemOut = pkg.output.String()
+		hasFailure := false
+		for _, test := range pkg.suite.Tests {
+			if test.Status == apistructs.TestStatusFailed {
+				hasFailure = true
+				test.Error = apistructs.TestError{
+					Message: "test failed",
+					Body:    test.SystemOut,
+				}
+			}
+		}
+		// 包级别失败但没有失败的用例，通常是编译失败或 TestMain 失败，记录为一个错误用例
+		if pkg.failed && !hasFailure {
+			pkg.suite.Tests = append(pkg.suite.Tests, &apistructs.Test{
+				Name:      name,
+				Classname: name,
+				Status:    apistructs.TestStatusError,
+				Error: apistructs.TestError{
+					Message: "package failed",
+					Body:    pkg.suite.SystemOut,
+				},
+			})
+		}
+		if len(pkg.suite.Tests) == 0 {
+			continue
+		}
+		su := &qaparser.Suite{TestSuite: pkg.suite}
+		su.Aggregate()
+		suites = append(suites, pkg.suite)
+	}
+
+	return suites, nil
+}
+
+func elapsed(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gotestjson
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+	"github.com/erda-project/erda/pkg/qaparser/types"
+)
+
+type GoTestParser struct {
+}
+
+func init() {
+	logrus.Info("register GoTest Parser to manager")
+	(GoTestParser{}).Register()
+}
+
+func (g GoTestParser) Register() {
+	qaparser.Register(g, types.GoTest)
+}
+
+func (g GoTestParser) Parse(endpoint, ak, sk, bucket, objectName string) ([]*apistructs.TestSuite, error) {
+	return qaparser.ParseObject(g, endpoint, ak, sk, bucket, objectName)
+}
+
+func (GoTestParser) ParseReader(r io.Reader) ([]*apistructs.TestSuite, error) {
+	return Ingest(r)
+}
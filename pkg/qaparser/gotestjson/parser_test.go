@@ -0,0 +1,49 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gotestjson
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestIngest(t *testing.T) {
+	f, err := os.Open("../testdata/gotest.json")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	suites, err := (GoTestParser{}).ParseReader(f)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(suites))
+
+	calc := suites[0]
+	assert.Equal(t, "example.com/calc", calc.Name)
+	assert.Equal(t, 3, calc.Totals.Tests)
+	assert.Equal(t, 1, calc.Totals.Statuses[apistructs.TestStatusPassed])
+	assert.Equal(t, 1, calc.Totals.Statuses[apistructs.TestStatusFailed])
+	assert.Equal(t, 1, calc.Totals.Statuses[apistructs.TestStatusSkipped])
+	assert.Equal(t, 10*time.Millisecond, calc.Tests[0].Duration)
+	assert.Contains(t, calc.Tests[1].Error.(apistructs.TestError).Body, "want 2, got 3")
+
+	broken := suites[1]
+	assert.Equal(t, "example.com/broken", broken.Name)
+	assert.Equal(t, 1, broken.Totals.Statuses[apistructs.TestStatusError])
+	assert.Contains(t, broken.Tests[0].Error.(apistructs.TestError).Body, "build failed")
+}
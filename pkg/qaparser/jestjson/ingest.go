@@ -0,0 +1,133 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jestjson
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+)
+
+// Report is the output of `jest --json`.
+type Report struct {
+	TestResults []FileResult `json:"testResults"`
+}
+
+// FileResult is the result of one test file.
+type FileResult struct {
+	Name             string            `json:"name"`
+	Status           string            `json:"status"`
+	Message          string            `json:"message"`
+	StartTime        int64             `json:"startTime"`
+	EndTime          int64             `json:"endTime"`
+	AssertionResults []AssertionResult `json:"assertionResults"`
+	FailureMessage   string            `json:"failureMessage"`
+}
+
+// AssertionResult is the result of one test case.
+type AssertionResult struct {
+	AncestorTitles  []string `json:"ancestorTitles"`
+	Title           string   `json:"title"`
+	FullName        string   `json:"fullName"`
+	Status          string   `json:"status"`
+	Duration        *float64 `json:"duration"`
+	FailureMessages []string `json:"failureMessages"`
+}
+
+// Ingest parses a `jest --json` report and returns one suite per test file.
+func Ingest(r io.Reader) ([]*apistructs.TestSuite, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, errors.Wrap(err, "decode jest report")
+	}
+
+	var suites []*apistructs.TestSuite
+	for _, file := range report.TestResults {
+		suite := &apistructs.TestSuite{
+			Name:    file.Name,
+			Package: file.Name,
+		}
+		for _, assertion := range file.AssertionResults {
+			test := &apistructs.Test{
+				Name:      assertion.FullName,
+				Classname: strings.Join(assertion.AncestorTitles, " "),
+				Status:    status(assertion.Status),
+			}
+			if test.Name == "" {
+				test.Name = assertion.Title
+			}
+			if assertion.Duration != nil {
+				test.Duration = time.Duration(*assertion.Duration * float64(time.Millisecond))
+			}
+			if test.Status == apistructs.TestStatusFailed {
+				test.Error = apistructs.TestError{
+					Message: firstLine(assertion.FailureMessages),
+					Body:    strings.Join(assertion.FailureMessages, "\n"),
+				}
+			}
+			suite.Tests = append(suite.Tests, test)
+		}
+		// 测试文件运行失败（例如语法错误）时没有用例结果，记录为一个错误用例
+		if file.Status == "failed" && len(file.AssertionResults) == 0 {
+			message := file.Message
+			if message == "" {
+				message = file.FailureMessage
+			}
+			suite.Tests = append(suite.Tests, &apistructs.Test{
+				Name:      file.Name,
+				Classname: file.Name,
+				Duration:  time.Duration(file.EndTime-file.StartTime) * time.Millisecond,
+				Status:    apistructs.TestStatusError,
+				Error: apistructs.TestError{
+					Message: "test suite failed to run",
+					Body:    message,
+				},
+			})
+		}
+		if len(suite.Tests) == 0 {
+			continue
+		}
+		su := &qaparser.Suite{TestSuite: suite}
+		su.Aggregate()
+		suites = append(suites, suite)
+	}
+
+	return suites, nil
+}
+
+func status(s string) apistructs.TestStatus {
+	switch s {
+	case "passed":
+		return apistructs.TestStatusPassed
+	case "failed":
+		return apistructs.TestStatusFailed
+	default:
+		// pending, skipped, todo, disabled
+		return apistructs.TestStatusSkipped
+	}
+}
+
+func firstLine(messages []string) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(messages[0]), "\n", 2)[0])
+}
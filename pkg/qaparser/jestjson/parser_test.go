@@ -0,0 +1,50 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jestjson
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestIngest(t *testing.T) {
+	f, err := os.Open("../testdata/jest.json")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	suites, err := (JestParser{}).ParseReader(f)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(suites))
+
+	sum := suites[0]
+	assert.Equal(t, "/app/src/sum.test.js", sum.Name)
+	assert.Equal(t, 3, sum.Totals.Tests)
+	assert.Equal(t, 1, sum.Totals.Statuses[apistructs.TestStatusPassed])
+	assert.Equal(t, 1, sum.Totals.Statuses[apistructs.TestStatusFailed])
+	assert.Equal(t, 1, sum.Totals.Statuses[apistructs.TestStatusSkipped])
+	assert.Equal(t, "sum adds numbers", sum.Tests[0].Name)
+	assert.Equal(t, "sum", sum.Tests[0].Classname)
+	assert.Equal(t, 3*time.Millisecond, sum.Tests[0].Duration)
+	assert.Equal(t, "Error: expect(received).toBe(expected)", sum.Tests[1].Error.(apistructs.TestError).Message)
+
+	broken := suites[1]
+	assert.Equal(t, 1, broken.Totals.Statuses[apistructs.TestStatusError])
+	assert.Contains(t, broken.Tests[0].Error.(apistructs.TestError).Body, "SyntaxError")
+}
@@ -15,17 +15,25 @@
 package qaparser
 
 import (
+	"bytes"
+	"io"
+	"os"
+
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/cloudstorage"
 	"github.com/erda-project/erda/pkg/qaparser/types"
 )
 
 var m Manager
 
 type Parser interface {
+	// Parse downloads the report from object storage and parses it.
 	Parse(endpoint, ak, sk, bucket, objectName string) ([]*apistructs.TestSuite, error)
+	// ParseReader parses the report read from r.
+	ParseReader(r io.Reader) ([]*apistructs.TestSuite, error)
 	Register()
 }
 
@@ -64,3 +72,37 @@ func Register(p Parser, types ...types.TestParserType) error {
 
 	return nil
 }
+
+// ParseReader parses the report read from r with the parser registered for t.
+func (m *Manager) ParseReader(t types.TestParserType, r io.Reader) ([]*apistructs.TestSuite, error) {
+	p, ok := m.parsers[t]
+	if !ok {
+		return nil, errors.Errorf("not found parser, type=%s", t)
+	}
+	return p.ParseReader(r)
+}
+
+// ParseFile parses the local report file with the parser registered for t.
+func (m *Manager) ParseFile(t types.TestParserType, filename string) ([]*apistructs.TestSuite, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return m.ParseReader(t, f)
+}
+
+// ParseObject downloads the report from object storage and parses it with p.
+func ParseObject(p Parser, endpoint, ak, sk, bucket, objectName string) ([]*apistructs.TestSuite, error) {
+	client, err := cloudstorage.New(endpoint, ak, sk)
+	if err != nil {
+		return nil, errors.Wrap(err, "get cloud storage client")
+	}
+
+	byteArray, err := client.DownloadFile(bucket, objectName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "download filename=%s", objectName)
+	}
+
+	return p.ParseReader(bytes.NewReader(byteArray))
+}
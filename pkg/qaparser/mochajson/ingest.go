@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mochajson
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+)
+
+// Report is the output of `mocha --reporter json`.
+type Report struct {
+	Tests   []Test `json:"tests"`
+	Pending []Test `json:"pending"`
+}
+
+type Test struct {
+	Title     string    `json:"title"`
+	FullTitle string    `json:"fullTitle"`
+	File      string    `json:"file"`
+	Duration  float64   `json:"duration"`
+	Err       TestError `json:"err"`
+}
+
+type TestError struct {
+	Message string `json:"message"`
+	Stack   string `json:"stack"`
+	Name    string `json:"name"`
+}
+
+func (t Test) key() string {
+	return t.File + "#" + t.FullTitle
+}
+
+// Ingest parses a `mocha --reporter json` report and returns one suite per test file.
+func Ingest(r io.Reader) ([]*apistructs.TestSuite, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, errors.Wrap(err, "decode mocha report")
+	}
+
+	pending := map[string]bool{}
+	for _, t := range report.Pending {
+		pending[t.key()] = true
+	}
+
+	var (
+		suites  []*apistructs.TestSuite
+		grouped = map[string]*apistructs.TestSuite{}
+	)
+	for _, t := range report.Tests {
+		suite, ok := grouped[t.File]
+		if !ok {
+			suite = &apistructs.TestSuite{
+				Name:    t.File,
+				Package: t.File,
+			}
+			grouped[t.File] = suite
+			suites = append(suites, suite)
+		}
+		test := &apistructs.Test{
+			Name:      t.FullTitle,
+			Classname: strings.TrimSpace(strings.TrimSuffix(t.FullTitle, t.Title)),
+			Duration:  time.Duration(t.Duration * float64(time.Millisecond)),
+			Status:    apistructs.TestStatusPassed,
+		}
+		if test.Name == "" {
+			test.Name = t.Title
+		}
+		switch {
+		case pending[t.key()]:
+			test.Status = apistructs.TestStatusSkipped
+		case t.Err.Message != "" || t.Err.Stack != "":
+			test.Status = apistructs.TestStatusFailed
+			test.Error = apistructs.TestError{
+				Message: t.Err.Message,
+				Type:    t.Err.Name,
+				Body:    t.Err.Stack,
+			}
+		}
+		suite.Tests = append(suite.Tests, test)
+	}
+	for _, suite := range suites {
+		su := &qaparser.Suite{TestSuite: suite}
+		su.Aggregate()
+	}
+
+	return suites, nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mochajson
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestIngest(t *testing.T) {
+	f, err := os.Open("../testdata/mocha.json")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	suites, err := (MochaParser{}).ParseReader(f)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(suites))
+
+	user := suites[0]
+	assert.Equal(t, "/app/test/user.spec.js", user.Name)
+	assert.Equal(t, 2, user.Totals.Tests)
+	assert.Equal(t, 1, user.Totals.Statuses[apistructs.TestStatusPassed])
+	assert.Equal(t, 1, user.Totals.Statuses[apistructs.TestStatusFailed])
+	assert.Equal(t, "UserService get", user.Tests[0].Classname)
+	assert.Equal(t, "AssertionError", user.Tests[1].Error.(apistructs.TestError).Type)
+
+	order := suites[1]
+	assert.Equal(t, 1, order.Totals.Statuses[apistructs.TestStatusSkipped])
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pytestxml
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+	"github.com/erda-project/erda/pkg/qaparser/surefilexml"
+)
+
+// Ingest parses a pytest --junitxml report.
+//
+// pytest writes every test case into a single testsuite named "pytest" (xunit2)
+// or one testsuite per session (xunit1), so the cases are regrouped into one
+// suite per classname, i.e. per test module or test class, to match the suite
+// granularity of surefire reports.
+func Ingest(r io.Reader) ([]*apistructs.TestSuite, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := surefilexml.Ingest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		suites  []*apistructs.TestSuite
+		grouped = map[string]*apistructs.TestSuite{}
+	)
+	for _, rawSuite := range raw {
+		for _, test := range rawSuite.Tests {
+			name := test.Classname
+			if name == "" {
+				// collection errors have no classname
+				name = rawSuite.Name
+			}
+			suite, ok := grouped[name]
+			if !ok {
+				suite = &apistructs.TestSuite{
+					Name:       name,
+					Package:    rawSuite.Name,
+					Properties: rawSuite.Properties,
+				}
+				grouped[name] = suite
+				suites = append(suites, suite)
+			}
+			suite.Tests = append(suite.Tests, test)
+		}
+	}
+	for _, suite := range suites {
+		su := &qaparser.Suite{TestSuite: suite}
+		su.Aggregate()
+	}
+
+	return suites, nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pytestxml
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestIngest(t *testing.T) {
+	f, err := os.Open("../testdata/pytest.xml")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	suites, err := (PyTestParser{}).ParseReader(f)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(suites))
+
+	assert.Equal(t, "tests.test_api.TestUser", suites[0].Name)
+	assert.Equal(t, "pytest", suites[0].Package)
+	assert.Equal(t, 2, suites[0].Totals.Tests)
+	assert.Equal(t, 1, suites[0].Totals.Statuses[apistructs.TestStatusFailed])
+
+	assert.Equal(t, "tests.test_utils", suites[1].Name)
+	assert.Equal(t, 3, suites[1].Totals.Tests)
+	assert.Equal(t, 1, suites[1].Totals.Statuses[apistructs.TestStatusSkipped])
+	assert.Equal(t, 1, suites[1].Totals.Statuses[apistructs.TestStatusError])
+	assert.Equal(t, 1, suites[1].Totals.Statuses[apistructs.TestStatusPassed])
+}
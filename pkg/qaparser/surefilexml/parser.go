@@ -15,11 +15,12 @@
 package surefilexml
 
 import (
-	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
-	"github.com/erda-project/erda/pkg/cloudstorage"
 	"github.com/erda-project/erda/pkg/qaparser"
 	"github.com/erda-project/erda/pkg/qaparser/types"
 )
@@ -39,21 +40,15 @@ func (d DefaultParser) Register() {
 	qaparser.Register(d, types.Default, types.JUnit)
 }
 
-func (DefaultParser) Parse(endpoint, ak, sk, bucket, objectName string) ([]*apistructs.TestSuite, error) {
-	client, err := cloudstorage.New(endpoint, ak, sk)
-	if err != nil {
-		return nil, errors.Wrap(err, "get cloud storage client")
-	}
+func (d DefaultParser) Parse(endpoint, ak, sk, bucket, objectName string) ([]*apistructs.TestSuite, error) {
+	return qaparser.ParseObject(d, endpoint, ak, sk, bucket, objectName)
+}
 
-	byteArray, err := client.DownloadFile(bucket, objectName)
+func (DefaultParser) ParseReader(r io.Reader) ([]*apistructs.TestSuite, error) {
+	byteArray, err := ioutil.ReadAll(r)
 	if err != nil {
-		return nil, errors.Wrapf(err, "download filename=%s", objectName)
-	}
-
-	var suites []*apistructs.TestSuite
-	if suites, err = Ingest(byteArray); err != nil {
 		return nil, err
 	}
 
-	return suites, nil
+	return Ingest(byteArray)
 }
@@ -15,11 +15,12 @@
 package testngxml
 
 import (
-	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
-	"github.com/erda-project/erda/pkg/cloudstorage"
 	"github.com/erda-project/erda/pkg/qaparser"
 	"github.com/erda-project/erda/pkg/qaparser/types"
 )
@@ -39,15 +40,14 @@ func (ng NgParser) Register() {
 // parse xml to entity
 // 1. get file from cloud storage
 // 2. parse
-func (NgParser) Parse(endpoint, ak, sk, bucket, objectName string) ([]*apistructs.TestSuite, error) {
-	client, err := cloudstorage.New(endpoint, ak, sk)
-	if err != nil {
-		return nil, errors.Wrap(err, "get cloud storage client")
-	}
+func (ng NgParser) Parse(endpoint, ak, sk, bucket, objectName string) ([]*apistructs.TestSuite, error) {
+	return qaparser.ParseObject(ng, endpoint, ak, sk, bucket, objectName)
+}
 
-	byteArray, err := client.DownloadFile(bucket, objectName)
+func (NgParser) ParseReader(r io.Reader) ([]*apistructs.TestSuite, error) {
+	byteArray, err := ioutil.ReadAll(r)
 	if err != nil {
-		return nil, errors.Wrapf(err, "download filename=%s", objectName)
+		return nil, err
 	}
 
 	var ng *NgTestResult
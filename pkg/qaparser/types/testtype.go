@@ -24,6 +24,14 @@ const (
 	NGTest TestParserType = "NGTEST"
 	// 使用 junit 生成的 xml 格式进行解析
 	JUnit TestParserType = "JUNIT"
+	// 使用 go test -json 的输出进行解析
+	GoTest TestParserType = "GOTEST"
+	// 使用 pytest --junitxml 生成的 xml 格式进行解析，支持 xunit1 和 xunit2
+	PyTest TestParserType = "PYTEST"
+	// 使用 jest --json 生成的 json 格式进行解析
+	Jest TestParserType = "JEST"
+	// 使用 mocha --reporter json 生成的 json 格式进行解析
+	Mocha TestParserType = "MOCHA"
 )
 
 func (t TestParserType) TPValue() string {
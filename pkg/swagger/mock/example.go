@@ -0,0 +1,228 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxDepth 递归 schema 的最大展开层数, 超过后 object 只生成必填字段, array 为空;
+// 必填字段自身构成递归时在 2*maxDepth 层截断
+const maxDepth = 8
+
+// Example generates a value which is valid against the schema.
+//
+// The example, default and enum declared in the schema are preferred. Otherwise
+// a value is generated according to the type, format and constraints such as
+// minimum, minLength and minItems. Recursive schemas are expanded at most maxDepth levels.
+func Example(ref *openapi3.SchemaRef) interface{} {
+	return example(ref, 0)
+}
+
+func example(ref *openapi3.SchemaRef, depth int) interface{} {
+	if ref == nil || ref.Value == nil || depth > 2*maxDepth {
+		return nil
+	}
+	schema := ref.Value
+
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	if len(schema.AllOf) > 0 {
+		return allOfExample(schema, depth)
+	}
+	if len(schema.OneOf) > 0 {
+		return example(schema.OneOf[0], depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return example(schema.AnyOf[0], depth+1)
+	}
+
+	switch schema.Type {
+	case "string":
+		return stringExample(schema)
+	case "integer":
+		return int64(numberExample(schema, true))
+	case "number":
+		return numberExample(schema, false)
+	case "boolean":
+		return true
+	case "array":
+		return arrayExample(schema, depth)
+	case "object":
+		return objectExample(schema, depth)
+	case "":
+		if len(schema.Properties) > 0 {
+			return objectExample(schema, depth)
+		}
+		if schema.Items != nil {
+			return arrayExample(schema, depth)
+		}
+		if schema.Nullable {
+			return nil
+		}
+		return map[string]interface{}{}
+	default:
+		return nil
+	}
+}
+
+// allOfExample merges the object examples of all sub schemas.
+func allOfExample(schema *openapi3.Schema, depth int) interface{} {
+	merged := map[string]interface{}{}
+	if len(schema.Properties) > 0 {
+		if m, ok := objectExample(schema, depth).(map[string]interface{}); ok {
+			merged = m
+		}
+	}
+	for _, sub := range schema.AllOf {
+		v := example(sub, depth+1)
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			// 非 object 的 allOf 没有合并的意义, 取第一个子 schema 的值
+			if len(merged) == 0 && v != nil {
+				return v
+			}
+			continue
+		}
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func objectExample(schema *openapi3.Schema, depth int) interface{} {
+	obj := make(map[string]interface{}, len(schema.Properties))
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		required := contains(schema.Required, name)
+		if depth >= maxDepth && !required {
+			continue
+		}
+		prop := schema.Properties[name]
+		// 生成的是响应数据, 只写字段不返回
+		if prop != nil && prop.Value != nil && prop.Value.WriteOnly {
+			continue
+		}
+		v := example(prop, depth+1)
+		if v == nil && !required {
+			continue
+		}
+		obj[name] = v
+	}
+	return obj
+}
+
+func arrayExample(schema *openapi3.Schema, depth int) interface{} {
+	n := int(schema.MinItems)
+	if n == 0 {
+		n = 1
+	}
+	if schema.MaxItems != nil && uint64(n) > *schema.MaxItems {
+		n = int(*schema.MaxItems)
+	}
+	items := make([]interface{}, 0, n)
+	if depth >= maxDepth && schema.MinItems == 0 {
+		return items
+	}
+	item := example(schema.Items, depth+1)
+	if item == nil {
+		return items
+	}
+	for i := 0; i < n; i++ {
+		items = append(items, item)
+	}
+	return items
+}
+
+func numberExample(schema *openapi3.Schema, integer bool) float64 {
+	v := 0.0
+	if schema.Min != nil {
+		v = *schema.Min
+		if schema.ExclusiveMin {
+			if integer {
+				v = math.Floor(v) + 1
+			} else {
+				v += 0.5
+			}
+		}
+	} else if schema.Max != nil && (*schema.Max < 0 || *schema.Max == 0 && schema.ExclusiveMax) {
+		v = *schema.Max
+		if schema.ExclusiveMax {
+			v--
+		}
+	}
+	if integer {
+		v = math.Ceil(v)
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		v = math.Ceil(v / *schema.MultipleOf) * *schema.MultipleOf
+	}
+	return v
+}
+
+var formatExamples = map[string]string{
+	"date":      "2021-01-01",
+	"date-time": "2021-01-01T00:00:00Z",
+	"time":      "00:00:00",
+	"email":     "user@example.com",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "127.0.0.1",
+	"ipv6":      "::1",
+	"byte":      "c3RyaW5n",
+	"password":  "password",
+}
+
+func stringExample(schema *openapi3.Schema) string {
+	s, ok := formatExamples[schema.Format]
+	if !ok {
+		s = "string"
+	}
+	if uint64(len(s)) < schema.MinLength {
+		s += strings.Repeat("x", int(schema.MinLength)-len(s))
+	}
+	if schema.MaxLength != nil && uint64(len(s)) > *schema.MaxLength {
+		s = s[:*schema.MaxLength]
+	}
+	return s
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mock serves an OpenAPI 3 document as a mock server.
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// HeaderPrefer 调用方通过 Prefer 头指定返回的响应, 如 `Prefer: code=404`, `Prefer: example=notFound`
+	HeaderPrefer = "Prefer"
+
+	contentTypeJSON = "application/json"
+)
+
+// Server mocks the operations of an OpenAPI 3 document.
+//
+// For each request it finds the operation, validates the request against the
+// parameters and request body, then responds with the examples declared in the
+// document or generated from the response schema.
+type Server struct {
+	swagger  *openapi3.Swagger
+	router   *openapi3filter.Router
+	basePath string
+	validate bool
+}
+
+type Option func(*Server)
+
+// WithBasePath sets the path prefix stripped before routing.
+// By default it is the path of the first server declared in the document.
+func WithBasePath(basePath string) Option {
+	return func(s *Server) {
+		s.basePath = "/" + strings.Trim(basePath, "/")
+	}
+}
+
+// WithValidation enables or disables validating requests, it is enabled by default.
+func WithValidation(validate bool) Option {
+	return func(s *Server) {
+		s.validate = validate
+	}
+}
+
+// New creates a mock server of the document.
+func New(swagger *openapi3.Swagger, options ...Option) (*Server, error) {
+	if swagger == nil {
+		return nil, errors.New("swagger is nil")
+	}
+	// 转换自 OAS2 的文档中引用可能没有解析
+	if err := openapi3.NewSwaggerLoader().ResolveRefsIn(swagger, nil); err != nil {
+		return nil, errors.Wrap(err, "failed to resolve refs")
+	}
+
+	s := &Server{swagger: swagger, validate: true}
+	if len(swagger.Servers) > 0 {
+		if u, err := url.Parse(swagger.Servers[0].URL); err == nil {
+			s.basePath = "/" + strings.Trim(u.Path, "/")
+		}
+	}
+	for _, op := range options {
+		op(s)
+	}
+	if s.basePath == "/" {
+		s.basePath = ""
+	}
+
+	// 路由时不匹配 servers, 由 basePath 处理前缀
+	routed := *swagger
+	routed.Servers = nil
+	router := openapi3filter.NewRouter()
+	if err := router.AddSwagger(&routed); err != nil {
+		return nil, errors.Wrap(err, "invalid swagger")
+	}
+	s.router = router
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if s.basePath != "" {
+		// 按路径段匹配, /api/v1 不匹配 /api/v1pets
+		rest := strings.TrimPrefix(path, s.basePath)
+		if !strings.HasPrefix(path, s.basePath) || (rest != "" && !strings.HasPrefix(rest, "/")) {
+			writeError(w, http.StatusNotFound, "path not found: "+path)
+			return
+		}
+		path = "/" + strings.TrimPrefix(rest, "/")
+	}
+	u := *r.URL
+	u.Path = path
+
+	route, pathParams, err := s.router.FindRoute(r.Method, &u)
+	if err != nil {
+		status := http.StatusNotFound
+		if re, ok := err.(*openapi3filter.RouteError); ok && strings.Contains(re.Reason, "method") {
+			status = http.StatusMethodNotAllowed
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+
+	if s.validate {
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(context.Background(), input); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	prefer := parsePrefer(r.Header.Get(HeaderPrefer))
+	code, response, err := selectResponse(route.Operation, prefer["code"])
+	if err != nil {
+		writeError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	for name, header := range response.Headers {
+		if header == nil || header.Value == nil {
+			continue
+		}
+		if v := Example(header.Value.Schema); v != nil {
+			w.Header().Set(name, fmt.Sprint(v))
+		}
+	}
+
+	mediaType, media := selectMediaType(response.Content, r.Header.Get("Accept"))
+	if media == nil {
+		w.WriteHeader(code)
+		return
+	}
+	body, err := mediaExample(media, prefer["example"])
+	if err != nil {
+		writeError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	writeBody(w, code, mediaType, body)
+}
+
+// selectResponse 优先选择 Prefer 指定的响应码, 否则选择最小的 2xx 响应, 再否则选择 default
+func selectResponse(operation *openapi3.Operation, preferCode string) (int, *openapi3.Response, error) {
+	responses := operation.Responses
+	if preferCode != "" {
+		code, err := strconv.Atoi(preferCode)
+		if err != nil {
+			return 0, nil, errors.Errorf("invalid code in Prefer header: %s", preferCode)
+		}
+		if ref, ok := responses[preferCode]; ok && ref != nil && ref.Value != nil {
+			return code, ref.Value, nil
+		}
+		if ref := responses.Default(); ref != nil && ref.Value != nil {
+			return code, ref.Value, nil
+		}
+		return 0, nil, errors.Errorf("response %s is not defined", preferCode)
+	}
+
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			status, err := strconv.Atoi(code)
+			if err != nil {
+				// 2XX 这样的范围
+				status = http.StatusOK
+			}
+			if ref := responses[code]; ref != nil && ref.Value != nil {
+				return status, ref.Value, nil
+			}
+		}
+	}
+	if ref := responses.Default(); ref != nil && ref.Value != nil {
+		return http.StatusOK, ref.Value, nil
+	}
+	return 0, nil, errors.New("no success response is defined")
+}
+
+// selectMediaType 根据 Accept 选择媒体类型, 没有匹配时优先 application/json
+func selectMediaType(content openapi3.Content, accept string) (string, *openapi3.MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if mediaType == "" || mediaType == "*/*" {
+			continue
+		}
+		if media := content.Get(mediaType); media != nil {
+			return mediaType, media
+		}
+	}
+	if media, ok := content[contentTypeJSON]; ok {
+		return contentTypeJSON, media
+	}
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes[0], content[mediaTypes[0]]
+}
+
+// mediaExample 优先使用 Prefer 指定的具名示例, 其次是文档中的示例, 最后根据 schema 生成
+func mediaExample(media *openapi3.MediaType, name string) (interface{}, error) {
+	if name != "" {
+		ref, ok := media.Examples[name]
+		if !ok || ref == nil || ref.Value == nil {
+			return nil, errors.Errorf("example %s is not defined", name)
+		}
+		return ref.Value.Value, nil
+	}
+	if media.Example != nil {
+		return media.Example, nil
+	}
+	if len(media.Examples) > 0 {
+		names := make([]string, 0, len(media.Examples))
+		for name := range media.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if ref := media.Examples[names[0]]; ref != nil && ref.Value != nil {
+			return ref.Value.Value, nil
+		}
+	}
+	return Example(media.Schema), nil
+}
+
+// parsePrefer parses `Prefer: code=404, example=notFound`, see RFC 7240.
+func parsePrefer(header string) map[string]string {
+	prefer := map[string]string{}
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		prefer[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+	}
+	return prefer
+}
+
+func writeBody(w http.ResponseWriter, code int, mediaType string, body interface{}) {
+	var data []byte
+	switch v := body.(type) {
+	case string:
+		if isJSON(mediaType) {
+			data, _ = json.Marshal(v)
+		} else {
+			data = []byte(v)
+		}
+	case []byte:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(code)
+	if _, err := w.Write(data); err != nil {
+		logrus.Errorf("failed to write mock response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	data, _ := json.Marshal(map[string]interface{}{
+		"success": false,
+		"err": map[string]interface{}{
+			"code": http.StatusText(code),
+			"msg":  message,
+		},
+	})
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == contentTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
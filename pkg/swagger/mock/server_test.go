@@ -0,0 +1,219 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/erda-project/erda/pkg/swagger/mock"
+	"github.com/erda-project/erda/pkg/swagger/oas3"
+)
+
+const petstore = `{
+  "openapi": "3.0.0",
+  "info": {"title": "pets", "version": "1.0.0"},
+  "servers": [{"url": "https://pets.example.com/api/v1"}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}}],
+        "responses": {
+          "200": {
+            "description": "ok",
+            "headers": {"X-Total": {"schema": {"type": "integer", "minimum": 1}}},
+            "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}
+          }
+        }
+      },
+      "post": {
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+        "responses": {"201": {"description": "created"}}
+      }
+    },
+    "/pets/{id}": {
+      "get": {
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {
+          "200": {
+            "description": "ok",
+            "content": {"application/json": {
+              "schema": {"$ref": "#/components/schemas/Pet"},
+              "examples": {
+                "cat": {"value": {"id": 1, "name": "kitty"}},
+                "dog": {"value": {"id": 2, "name": "doggy"}}
+              }
+            }}
+          },
+          "404": {"description": "not found", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "id": {"type": "integer", "format": "int64", "readOnly": true},
+          "name": {"type": "string", "minLength": 1},
+          "tag": {"type": "string", "enum": ["cat", "dog"]},
+          "birthday": {"type": "string", "format": "date"},
+          "password": {"type": "string", "writeOnly": true},
+          "owner": {"$ref": "#/components/schemas/Pet"}
+        }
+      },
+      "Error": {
+        "type": "object",
+        "properties": {"code": {"type": "integer", "minimum": 400, "exclusiveMinimum": true}, "message": {"type": "string"}}
+      }
+    }
+  }
+}`
+
+func newServer(t *testing.T, options ...mock.Option) (*mock.Server, *openapi3.Swagger) {
+	swagger, err := oas3.LoadFromData([]byte(petstore))
+	if err != nil {
+		t.Fatalf("failed to load swagger: %v", err)
+	}
+	server, err := mock.New(swagger, options...)
+	if err != nil {
+		t.Fatalf("failed to create mock server: %v", err)
+	}
+	return server, swagger
+}
+
+func do(server http.Handler, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	var r *http.Request
+	if body == "" {
+		r = httptest.NewRequest(method, target, nil)
+	} else {
+		r = httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+	return w
+}
+
+func TestServer(t *testing.T) {
+	server, swagger := newServer(t)
+
+	// 根据 schema 生成的响应应当能通过 schema 校验
+	w := do(server, http.MethodGet, "/api/v1/pets?limit=10", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Total") != "1" {
+		t.Errorf("unexpected X-Total header: %q", w.Header().Get("X-Total"))
+	}
+	var pets []interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &pets); err != nil {
+		t.Fatalf("failed to unmarshal body: %v", err)
+	}
+	schema := swagger.Paths["/pets"].Get.Responses["200"].Value.Content["application/json"].Schema.Value
+	if err := schema.VisitJSON(pets); err != nil {
+		t.Errorf("generated body is invalid: %v", err)
+	}
+	pet := pets[0].(map[string]interface{})
+	if _, ok := pet["password"]; ok {
+		t.Errorf("writeOnly property should be omitted: %v", pet)
+	}
+	if pet["birthday"] != "2021-01-01" || pet["tag"] != "cat" {
+		t.Errorf("unexpected pet: %v", pet)
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		header   map[string]string
+		wantCode int
+		wantBody string
+	}{
+		{"first named example", http.MethodGet, "/api/v1/pets/1", "", nil, http.StatusOK, `{"id":1,"name":"kitty"}`},
+		{"prefer example", http.MethodGet, "/api/v1/pets/2", "", map[string]string{"Prefer": "example=dog"}, http.StatusOK, `{"id":2,"name":"doggy"}`},
+		{"prefer code", http.MethodGet, "/api/v1/pets/3", "", map[string]string{"Prefer": "code=404"}, http.StatusNotFound, `{"code":401,"message":"string"}`},
+		{"undefined example", http.MethodGet, "/api/v1/pets/3", "", map[string]string{"Prefer": "example=bird"}, http.StatusNotImplemented, ""},
+		{"undefined code", http.MethodGet, "/api/v1/pets/3", "", map[string]string{"Prefer": "code=500"}, http.StatusNotImplemented, ""},
+		{"no content", http.MethodPost, "/api/v1/pets", `{"name":"kitty"}`, nil, http.StatusCreated, ""},
+		{"invalid query", http.MethodGet, "/api/v1/pets?limit=1000", "", nil, http.StatusBadRequest, ""},
+		{"invalid path param", http.MethodGet, "/api/v1/pets/abc", "", nil, http.StatusBadRequest, ""},
+		{"invalid body", http.MethodPost, "/api/v1/pets", `{"tag":"cat"}`, nil, http.StatusBadRequest, ""},
+		{"path not found", http.MethodGet, "/api/v1/stores", "", nil, http.StatusNotFound, ""},
+		{"base path not matched", http.MethodGet, "/pets", "", nil, http.StatusNotFound, ""},
+		{"base path not matched by segment", http.MethodGet, "/api/v1pets", "", nil, http.StatusNotFound, ""},
+		{"method not allowed", http.MethodDelete, "/api/v1/pets", "", nil, http.StatusMethodNotAllowed, ""},
+	}
+	for _, c := range cases {
+		w := do(server, c.method, c.target, c.body, c.header)
+		if w.Code != c.wantCode {
+			t.Errorf("%s: want code %d, got %d: %s", c.name, c.wantCode, w.Code, w.Body.String())
+			continue
+		}
+		if c.wantBody != "" && strings.TrimSpace(w.Body.String()) != c.wantBody {
+			t.Errorf("%s: want body %s, got %s", c.name, c.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestServerOptions(t *testing.T) {
+	server, _ := newServer(t, mock.WithBasePath("/"), mock.WithValidation(false))
+
+	if w := do(server, http.MethodGet, "/pets?limit=1000", "", nil); w.Code != http.StatusOK {
+		t.Errorf("want 200 without validation, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(server, http.MethodGet, "/api/v1/pets", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 with the base path overridden, got %d", w.Code)
+	}
+}
+
+func ExampleServer() {
+	swagger, _ := oas3.LoadFromData([]byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "hello", "version": "1.0.0"},
+  "paths": {
+    "/hello": {
+      "get": {"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {
+        "type": "object", "properties": {"message": {"type": "string", "example": "hello world"}}
+      }}}}}}
+    }
+  }
+}`))
+	server, _ := mock.New(swagger)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/hello")
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	fmt.Println(resp.StatusCode, string(body))
+	// Output: 200 {"message":"hello world"}
+}
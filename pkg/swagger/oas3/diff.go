@@ -0,0 +1,568 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oas3
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ChangeLevel 变更级别
+type ChangeLevel string
+
+const (
+	// ChangeLevelBreaking 不兼容变更, 已有的调用方可能会因此出错
+	ChangeLevelBreaking ChangeLevel = "breaking"
+	// ChangeLevelCompatible 兼容变更
+	ChangeLevelCompatible ChangeLevel = "compatible"
+)
+
+// ChangeType 变更类型
+type ChangeType string
+
+const (
+	ChangePathAdded              ChangeType = "path-added"
+	ChangePathRemoved            ChangeType = "path-removed"
+	ChangeOperationAdded         ChangeType = "operation-added"
+	ChangeOperationRemoved       ChangeType = "operation-removed"
+	ChangeParamAdded             ChangeType = "param-added"
+	ChangeParamRemoved           ChangeType = "param-removed"
+	ChangeParamBecameRequired    ChangeType = "param-became-required"
+	ChangeParamBecameOptional    ChangeType = "param-became-optional"
+	ChangeRequestBodyAdded       ChangeType = "request-body-added"
+	ChangeRequestBodyRemoved     ChangeType = "request-body-removed"
+	ChangeRequestBodyRequired    ChangeType = "request-body-became-required"
+	ChangeResponseAdded          ChangeType = "response-added"
+	ChangeResponseRemoved        ChangeType = "response-removed"
+	ChangeMediaTypeAdded         ChangeType = "media-type-added"
+	ChangeMediaTypeRemoved       ChangeType = "media-type-removed"
+	ChangeTypeChanged            ChangeType = "type-changed"
+	ChangeEnumNarrowed           ChangeType = "enum-narrowed"
+	ChangeEnumWidened            ChangeType = "enum-widened"
+	ChangePropertyAdded          ChangeType = "property-added"
+	ChangePropertyRemoved        ChangeType = "property-removed"
+	ChangePropertyBecameRequired ChangeType = "property-became-required"
+	ChangePropertyBecameOptional ChangeType = "property-became-optional"
+)
+
+// Change 一处变更
+type Change struct {
+	Type   ChangeType  `json:"type"`
+	Level  ChangeLevel `json:"level"`
+	Path   string      `json:"path"`
+	Method string      `json:"method,omitempty"`
+	// Location 变更在接口中的位置, 如 parameters.query.id, requestBody.application/json.user.name
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
+
+// DiffResult 两个版本文档的差异
+type DiffResult struct {
+	Changes    []*Change `json:"changes"`
+	Breaking   int       `json:"breaking"`
+	Compatible int       `json:"compatible"`
+}
+
+// HasBreaking returns true if there is any breaking change.
+func (r *DiffResult) HasBreaking() bool {
+	return r.Breaking > 0
+}
+
+// BreakingChanges returns the breaking changes only.
+func (r *DiffResult) BreakingChanges() []*Change {
+	var changes []*Change
+	for _, c := range r.Changes {
+		if c.Level == ChangeLevelBreaking {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// direction 数据流向, 请求由调用方构造, 响应由调用方读取, 同一种变更在两个方向上的兼容性相反
+type direction int
+
+const (
+	directionRequest direction = iota
+	directionResponse
+)
+
+var pathParamRegexp = regexp.MustCompile(`{[^}]*}`)
+
+type differ struct {
+	base, revision *openapi3.Swagger
+	result         *DiffResult
+
+	path, method string
+	visited      map[[2]*openapi3.Schema]bool
+}
+
+// Diff compares two OAS3 documents and classifies the changes from base to revision
+// as breaking or compatible for existing API consumers.
+// Paths are matched regardless of path parameter names, e.g. /users/{id} equals /users/{userID}.
+func Diff(base, revision *openapi3.Swagger) *DiffResult {
+	d := &differ{
+		base:     base,
+		revision: revision,
+		result:   &DiffResult{Changes: []*Change{}},
+	}
+	basePaths, revisionPaths := normalizePaths(base), normalizePaths(revision)
+
+	for _, key := range sortedKeys(basePaths, revisionPaths) {
+		b, r := basePaths[key], revisionPaths[key]
+		switch {
+		case r == nil:
+			d.path, d.method = b.path, ""
+			d.add(ChangePathRemoved, ChangeLevelBreaking, "", "删除了路径 %s", b.path)
+		case b == nil:
+			d.path, d.method = r.path, ""
+			d.add(ChangePathAdded, ChangeLevelCompatible, "", "新增了路径 %s", r.path)
+		default:
+			d.path = r.path
+			d.diffPathItem(b, r)
+		}
+	}
+
+	for _, c := range d.result.Changes {
+		if c.Level == ChangeLevelBreaking {
+			d.result.Breaking++
+		} else {
+			d.result.Compatible++
+		}
+	}
+	return d.result
+}
+
+type pathEntry struct {
+	path string
+	item *openapi3.PathItem
+	// params 路径参数名 -> 在路径中的位置
+	params map[string]int
+}
+
+func normalizePaths(swagger *openapi3.Swagger) map[string]*pathEntry {
+	paths := map[string]*pathEntry{}
+	if swagger == nil {
+		return paths
+	}
+	for path_, item := range swagger.Paths {
+		if item == nil {
+			continue
+		}
+		entry := &pathEntry{path: path_, item: item, params: map[string]int{}}
+		for i, name := range pathParamRegexp.FindAllString(path_, -1) {
+			entry.params[strings.Trim(name, "{}")] = i
+		}
+		paths[pathParamRegexp.ReplaceAllString(path_, "{}")] = entry
+	}
+	return paths
+}
+
+func (d *differ) diffPathItem(b, r *pathEntry) {
+	baseOps, revisionOps := b.item.Operations(), r.item.Operations()
+	methods := make([]string, 0, len(baseOps)+len(revisionOps))
+	for method := range baseOps {
+		methods = append(methods, method)
+	}
+	for method := range revisionOps {
+		if _, ok := baseOps[method]; !ok {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		d.method = method
+		bo, ro := baseOps[method], revisionOps[method]
+		switch {
+		case ro == nil:
+			d.add(ChangeOperationRemoved, ChangeLevelBreaking, "", "删除了接口 %s %s", method, b.path)
+		case bo == nil:
+			d.add(ChangeOperationAdded, ChangeLevelCompatible, "", "新增了接口 %s %s", method, r.path)
+		default:
+			d.diffParameters(d.parameters(d.base, b, bo), d.parameters(d.revision, r, ro))
+			d.diffRequestBody(bo.RequestBody, ro.RequestBody)
+			d.diffResponses(bo.Responses, ro.Responses)
+		}
+	}
+}
+
+// parameters returns the parameters of the operation keyed by location and name,
+// path parameters are keyed by their position because they may be renamed.
+func (d *differ) parameters(swagger *openapi3.Swagger, entry *pathEntry, operation *openapi3.Operation) map[string]*openapi3.Parameter {
+	params := map[string]*openapi3.Parameter{}
+	for _, refs := range []openapi3.Parameters{entry.item.Parameters, operation.Parameters} {
+		for _, ref := range refs {
+			param := resolveParameter(swagger, ref)
+			if param == nil {
+				continue
+			}
+			key := param.In + "." + param.Name
+			switch param.In {
+			case openapi3.ParameterInPath:
+				if i, ok := entry.params[param.Name]; ok {
+					key = fmt.Sprintf("%s.#%d", param.In, i)
+				}
+			case openapi3.ParameterInHeader:
+				key = strings.ToLower(key)
+			}
+			params[key] = param
+		}
+	}
+	return params
+}
+
+func (d *differ) diffParameters(base, revision map[string]*openapi3.Parameter) {
+	for _, key := range sortedKeys(base, revision) {
+		b, r := base[key], revision[key]
+		switch {
+		case r == nil:
+			d.add(ChangeParamRemoved, ChangeLevelCompatible, "parameters."+b.In+"."+b.Name,
+				"删除了参数 %s", b.Name)
+		case b == nil:
+			level := ChangeLevelCompatible
+			if r.Required {
+				level = ChangeLevelBreaking
+			}
+			d.add(ChangeParamAdded, level, "parameters."+r.In+"."+r.Name,
+				"新增了%s参数 %s", requiredText(r.Required), r.Name)
+		default:
+			loc := "parameters." + r.In + "." + r.Name
+			if !b.Required && r.Required {
+				d.add(ChangeParamBecameRequired, ChangeLevelBreaking, loc, "参数 %s 变为必填", r.Name)
+			}
+			if b.Required && !r.Required {
+				d.add(ChangeParamBecameOptional, ChangeLevelCompatible, loc, "参数 %s 变为非必填", r.Name)
+			}
+			d.diffSchema(directionRequest, loc, b.Schema, r.Schema)
+		}
+	}
+}
+
+func (d *differ) diffRequestBody(baseRef, revisionRef *openapi3.RequestBodyRef) {
+	const loc = "requestBody"
+	b, r := resolveRequestBody(d.base, baseRef), resolveRequestBody(d.revision, revisionRef)
+	switch {
+	case b == nil && r == nil:
+		return
+	case r == nil:
+		d.add(ChangeRequestBodyRemoved, ChangeLevelCompatible, loc, "删除了请求体")
+		return
+	case b == nil:
+		level := ChangeLevelCompatible
+		if r.Required {
+			level = ChangeLevelBreaking
+		}
+		d.add(ChangeRequestBodyAdded, level, loc, "新增了%s请求体", requiredText(r.Required))
+		return
+	}
+	if !b.Required && r.Required {
+		d.add(ChangeRequestBodyRequired, ChangeLevelBreaking, loc, "请求体变为必填")
+	}
+	d.diffContent(directionRequest, loc, b.Content, r.Content)
+}
+
+func (d *differ) diffResponses(base, revision openapi3.Responses) {
+	for _, code := range sortedKeys(base, revision) {
+		loc := "responses." + code
+		b, r := resolveResponse(d.base, base[code]), resolveResponse(d.revision, revision[code])
+		switch {
+		case b == nil && r == nil:
+		case r == nil:
+			// 删除成功响应会让调用方无法解析结果, 删除错误响应不影响调用方
+			level := ChangeLevelCompatible
+			if strings.HasPrefix(code, "2") {
+				level = ChangeLevelBreaking
+			}
+			d.add(ChangeResponseRemoved, level, loc, "删除了响应 %s", code)
+		case b == nil:
+			d.add(ChangeResponseAdded, ChangeLevelCompatible, loc, "新增了响应 %s", code)
+		default:
+			d.diffContent(directionResponse, loc, b.Content, r.Content)
+		}
+	}
+}
+
+func (d *differ) diffContent(dir direction, loc string, base, revision openapi3.Content) {
+	for _, mediaType := range sortedKeys(base, revision) {
+		b, r := base[mediaType], revision[mediaType]
+		mtLoc := loc + "." + mediaType
+		switch {
+		case r == nil:
+			d.add(ChangeMediaTypeRemoved, ChangeLevelBreaking, mtLoc, "删除了媒体类型 %s", mediaType)
+		case b == nil:
+			d.add(ChangeMediaTypeAdded, ChangeLevelCompatible, mtLoc, "新增了媒体类型 %s", mediaType)
+		default:
+			d.diffSchema(dir, mtLoc, b.Schema, r.Schema)
+		}
+	}
+}
+
+func (d *differ) diffSchema(dir direction, loc string, baseRef, revisionRef *openapi3.SchemaRef) {
+	b, r := resolveSchema(d.base, baseRef), resolveSchema(d.revision, revisionRef)
+	if b == nil || r == nil {
+		return
+	}
+	// 递归的 schema 只比较一次
+	if d.visited == nil {
+		d.visited = map[[2]*openapi3.Schema]bool{}
+	}
+	pair := [2]*openapi3.Schema{b, r}
+	if d.visited[pair] {
+		return
+	}
+	d.visited[pair] = true
+	defer delete(d.visited, pair)
+
+	if b.Type != "" && r.Type != "" && b.Type != r.Type {
+		level := ChangeLevelBreaking
+		// 请求中 integer 放宽为 number 不影响调用方
+		if dir == directionRequest && b.Type == "integer" && r.Type == "number" {
+			level = ChangeLevelCompatible
+		}
+		d.add(ChangeTypeChanged, level, loc, "%s 的类型由 %s 变为 %s", loc, b.Type, r.Type)
+		return
+	}
+
+	d.diffEnum(dir, loc, b.Enum, r.Enum)
+
+	baseProps, baseRequired := d.properties(d.base, b)
+	revisionProps, revisionRequired := d.properties(d.revision, r)
+	for _, name := range sortedKeys(baseProps, revisionProps) {
+		bp, rp := baseProps[name], revisionProps[name]
+		propLoc := loc + "." + name
+		switch {
+		case rp == nil:
+			// 请求中删除字段服务端会忽略, 响应中删除字段调用方读不到
+			d.add(ChangePropertyRemoved, levelOf(dir, ChangeLevelCompatible), propLoc, "删除了字段 %s", name)
+		case bp == nil:
+			level := ChangeLevelCompatible
+			if dir == directionRequest && revisionRequired[name] {
+				level = ChangeLevelBreaking
+			}
+			d.add(ChangePropertyAdded, level, propLoc, "新增了%s字段 %s", requiredText(revisionRequired[name]), name)
+		default:
+			if !baseRequired[name] && revisionRequired[name] {
+				d.add(ChangePropertyBecameRequired, levelOf(dir, ChangeLevelBreaking), propLoc, "字段 %s 变为必填", name)
+			}
+			if baseRequired[name] && !revisionRequired[name] {
+				d.add(ChangePropertyBecameOptional, levelOf(dir, ChangeLevelCompatible), propLoc, "字段 %s 变为非必填", name)
+			}
+			d.diffSchema(dir, propLoc, bp, rp)
+		}
+	}
+
+	if b.Items != nil && r.Items != nil {
+		d.diffSchema(dir, loc+"[]", b.Items, r.Items)
+	}
+}
+
+// diffEnum 请求中可选值变少, 或响应中可选值变多, 调用方都可能出错
+func (d *differ) diffEnum(dir direction, loc string, base, revision []interface{}) {
+	if len(base) == 0 && len(revision) == 0 {
+		return
+	}
+	var removed, added []string
+	switch {
+	case len(base) == 0:
+		// 由任意值变为枚举
+		d.add(ChangeEnumNarrowed, levelOf(dir, ChangeLevelBreaking), loc, "%s 限制为枚举值 %s", loc, enumText(revision))
+		return
+	case len(revision) == 0:
+		d.add(ChangeEnumWidened, levelOf(dir, ChangeLevelCompatible), loc, "%s 不再限制枚举值", loc)
+		return
+	}
+	baseValues, revisionValues := enumSet(base), enumSet(revision)
+	for v := range baseValues {
+		if !revisionValues[v] {
+			removed = append(removed, v)
+		}
+	}
+	for v := range revisionValues {
+		if !baseValues[v] {
+			added = append(added, v)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	if len(removed) > 0 {
+		d.add(ChangeEnumNarrowed, levelOf(dir, ChangeLevelBreaking), loc, "%s 删除了枚举值 %s", loc, strings.Join(removed, ", "))
+	}
+	if len(added) > 0 {
+		d.add(ChangeEnumWidened, levelOf(dir, ChangeLevelCompatible), loc, "%s 新增了枚举值 %s", loc, strings.Join(added, ", "))
+	}
+}
+
+// properties returns the properties and required fields of the schema, allOf are merged.
+func (d *differ) properties(swagger *openapi3.Swagger, schema *openapi3.Schema) (map[string]*openapi3.SchemaRef, map[string]bool) {
+	props, required := map[string]*openapi3.SchemaRef{}, map[string]bool{}
+	var walk func(s *openapi3.Schema, depth int)
+	walk = func(s *openapi3.Schema, depth int) {
+		if s == nil || depth > 8 {
+			return
+		}
+		for name, prop := range s.Properties {
+			props[name] = prop
+		}
+		for _, name := range s.Required {
+			required[name] = true
+		}
+		for _, ref := range s.AllOf {
+			walk(resolveSchema(swagger, ref), depth+1)
+		}
+	}
+	walk(schema, 0)
+	return props, required
+}
+
+// levelOf returns the level of a change in request direction, the level is reversed for response.
+func levelOf(dir direction, requestLevel ChangeLevel) ChangeLevel {
+	if dir == directionRequest {
+		return requestLevel
+	}
+	if requestLevel == ChangeLevelBreaking {
+		return ChangeLevelCompatible
+	}
+	return ChangeLevelBreaking
+}
+
+func (d *differ) add(typ ChangeType, level ChangeLevel, loc, format string, args ...interface{}) {
+	d.result.Changes = append(d.result.Changes, &Change{
+		Type:     typ,
+		Level:    level,
+		Path:     d.path,
+		Method:   d.method,
+		Location: loc,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func resolveSchema(swagger *openapi3.Swagger, ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value != nil || ref.Ref == "" || swagger == nil {
+		return ref.Value
+	}
+	if s, ok := swagger.Components.Schemas[refName(ref.Ref)]; ok && s != nil && s.Ref != ref.Ref {
+		return resolveSchema(swagger, s)
+	}
+	return nil
+}
+
+func resolveParameter(swagger *openapi3.Swagger, ref *openapi3.ParameterRef) *openapi3.Parameter {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value != nil || ref.Ref == "" || swagger == nil {
+		return ref.Value
+	}
+	if p, ok := swagger.Components.Parameters[refName(ref.Ref)]; ok && p != nil && p.Ref != ref.Ref {
+		return resolveParameter(swagger, p)
+	}
+	return nil
+}
+
+func resolveRequestBody(swagger *openapi3.Swagger, ref *openapi3.RequestBodyRef) *openapi3.RequestBody {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value != nil || ref.Ref == "" || swagger == nil {
+		return ref.Value
+	}
+	if rb, ok := swagger.Components.RequestBodies[refName(ref.Ref)]; ok && rb != nil && rb.Ref != ref.Ref {
+		return resolveRequestBody(swagger, rb)
+	}
+	return nil
+}
+
+func resolveResponse(swagger *openapi3.Swagger, ref *openapi3.ResponseRef) *openapi3.Response {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value != nil || ref.Ref == "" || swagger == nil {
+		return ref.Value
+	}
+	if resp, ok := swagger.Components.Responses[refName(ref.Ref)]; ok && resp != nil && resp.Ref != ref.Ref {
+		return resolveResponse(swagger, resp)
+	}
+	return nil
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// sortedKeys returns the union of the keys of two maps with the same key type, sorted.
+func sortedKeys(maps ...interface{}) []string {
+	set := map[string]struct{}{}
+	for _, m := range maps {
+		switch m := m.(type) {
+		case map[string]*pathEntry:
+			for k := range m {
+				set[k] = struct{}{}
+			}
+		case map[string]*openapi3.Parameter:
+			for k := range m {
+				set[k] = struct{}{}
+			}
+		case map[string]*openapi3.SchemaRef:
+			for k := range m {
+				set[k] = struct{}{}
+			}
+		case openapi3.Responses:
+			for k := range m {
+				set[k] = struct{}{}
+			}
+		case openapi3.Content:
+			for k := range m {
+				set[k] = struct{}{}
+			}
+		}
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func enumSet(values []interface{}) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[fmt.Sprint(v)] = true
+	}
+	return set
+}
+
+func enumText(values []interface{}) string {
+	texts := make([]string, 0, len(values))
+	for _, v := range values {
+		texts = append(texts, fmt.Sprint(v))
+	}
+	return strings.Join(texts, ", ")
+}
+
+func requiredText(required bool) string {
+	if required {
+		return "必填"
+	}
+	return "非必填"
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oas3_test
+
+import (
+	"testing"
+
+	"github.com/erda-project/erda/pkg/swagger/oas3"
+)
+
+const diffBase = `{
+  "openapi": "3.0.0",
+  "info": {"title": "pets", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "parameters": [
+          {"name": "status", "in": "query", "schema": {"type": "string", "enum": ["available", "pending", "sold"]}}
+        ],
+        "responses": {
+          "200": {"description": "ok", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}}
+        }
+      },
+      "post": {
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+        "responses": {"200": {"description": "ok"}}
+      }
+    },
+    "/pets/{id}": {
+      "get": {
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}}
+      }
+    },
+    "/stores": {
+      "get": {"responses": {"200": {"description": "ok"}}}
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "id": {"type": "integer"},
+          "name": {"type": "string"},
+          "tag": {"type": "string"},
+          "owner": {"$ref": "#/components/schemas/Pet"}
+        }
+      }
+    }
+  }
+}`
+
+const diffRevision = `{
+  "openapi": "3.0.0",
+  "info": {"title": "pets", "version": "1.1.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "parameters": [
+          {"name": "status", "in": "query", "schema": {"type": "string", "enum": ["available", "sold"]}},
+          {"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string"}},
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}}
+        ],
+        "responses": {
+          "200": {"description": "ok", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}}
+        }
+      },
+      "post": {
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+        "responses": {"200": {"description": "ok"}}
+      }
+    },
+    "/pets/{petID}": {
+      "get": {
+        "parameters": [{"name": "petID", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}}
+      }
+    },
+    "/owners": {
+      "get": {"responses": {"200": {"description": "ok"}}}
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "id": {"type": "string"},
+          "name": {"type": "string"},
+          "age": {"type": "integer"},
+          "owner": {"$ref": "#/components/schemas/Pet"}
+        }
+      }
+    }
+  }
+}`
+
+func TestDiff(t *testing.T) {
+	base, err := oas3.LoadFromData([]byte(diffBase))
+	if err != nil {
+		t.Fatalf("failed to load base: %v", err)
+	}
+	revision, err := oas3.LoadFromData([]byte(diffRevision))
+	if err != nil {
+		t.Fatalf("failed to load revision: %v", err)
+	}
+
+	result := oas3.Diff(base, revision)
+	got := map[string]oas3.ChangeLevel{}
+	for _, c := range result.Changes {
+		key := c.Method + " " + c.Path + " " + string(c.Type) + " " + c.Location
+		got[key] = c.Level
+		t.Logf("%s: %s", key, c.Message)
+	}
+
+	want := map[string]oas3.ChangeLevel{
+		" /stores path-removed ":                                                oas3.ChangeLevelBreaking,
+		" /owners path-added ":                                                  oas3.ChangeLevelCompatible,
+		"GET /pets enum-narrowed parameters.query.status":                       oas3.ChangeLevelBreaking,
+		"GET /pets param-added parameters.header.X-Tenant":                      oas3.ChangeLevelBreaking,
+		"GET /pets param-added parameters.query.limit":                          oas3.ChangeLevelCompatible,
+		"GET /pets type-changed responses.200.application/json[].id":            oas3.ChangeLevelBreaking,
+		"GET /pets property-removed responses.200.application/json[].tag":       oas3.ChangeLevelBreaking,
+		"GET /pets property-added responses.200.application/json[].age":         oas3.ChangeLevelCompatible,
+		"POST /pets type-changed requestBody.application/json.id":               oas3.ChangeLevelBreaking,
+		"POST /pets property-removed requestBody.application/json.tag":          oas3.ChangeLevelCompatible,
+		"POST /pets property-added requestBody.application/json.age":            oas3.ChangeLevelCompatible,
+		"GET /pets/{petID} type-changed responses.200.application/json.id":      oas3.ChangeLevelBreaking,
+		"GET /pets/{petID} property-removed responses.200.application/json.tag": oas3.ChangeLevelBreaking,
+		"GET /pets/{petID} property-added responses.200.application/json.age":   oas3.ChangeLevelCompatible,
+	}
+	for key, level := range want {
+		if got[key] != level {
+			t.Errorf("%s: want %q, got %q", key, level, got[key])
+		}
+	}
+	// 路径参数改名不算变更, 递归的 owner 只比较一层
+	if len(result.Changes) != len(want) {
+		t.Errorf("want %d changes, got %d", len(want), len(result.Changes))
+	}
+	if !result.HasBreaking() || result.Breaking != 8 || result.Compatible != 6 {
+		t.Errorf("unexpected counts, breaking: %d, compatible: %d", result.Breaking, result.Compatible)
+	}
+
+	same := oas3.Diff(base, base)
+	if len(same.Changes) != 0 {
+		t.Errorf("want no changes for the same document, got %d", len(same.Changes))
+	}
+}